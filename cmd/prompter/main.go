@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/imdevan/prompter/internal/app"
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
 	"github.com/spf13/cobra"
-	"prompter-cli/internal/app"
-	"prompter-cli/internal/config"
-	"prompter-cli/pkg/models"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 // Build-time variables injected via ldflags
@@ -21,7 +26,7 @@ var (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "prompter [base-prompt]",
+	Use:   "prompter [base-prompt] [-]",
 	Short: "A CLI tool for assembling AI coding prompts",
 	Long: `Prompter CLI assembles high-quality prompts for AI coding agents by combining 
 base prompts with optional pre/post templates and contextual information from files, 
@@ -29,11 +34,18 @@ directories, and captured command output.
 
 The base prompt can be provided as an argument, entered interactively, or read from 
 clipboard using --clipboard. When both an argument and --clipboard are provided, 
-the clipboard content is appended to the base prompt.
+the clipboard content is appended to the base prompt. Piped stdin is read when no
+base prompt is given, or with "-" as an argument: git diff | prompter "review this" -
 
 Interactive mode can be controlled via config (interactive_default), overridden with 
 -i (force interactive) or -y (force non-interactive).`,
-	Args: cobra.MaximumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		// A trailing "-" reads stdin along with the base prompt
+		if len(args) == 2 && args[1] == "-" {
+			return nil
+		}
+		return cobra.MaximumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check if version flag is set
 		if versionFlag, _ := cmd.Flags().GetBool("version"); versionFlag {
@@ -66,31 +78,26 @@ var versionCmd = &cobra.Command{
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available prompt templates",
-	Long:  "List all available pre and post prompt templates from the configured prompts directory.",
+	Long:  "List all available pre and post prompt templates from every prompt location. A name found in several locations resolves to the first in precedence order (local, global, custom templates, packs); the others are still reachable as namespace/name, e.g. local/review. Use --verbose to see the file each name resolves to.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		request := models.NewPromptRequest()
-		
-		// Get config path from flag
-		if configPath, err := cmd.Flags().GetString("config"); err == nil {
-			request.ConfigPath = configPath
-		}
+		request := newRequest(cmd)
+		var opts app.ListOptions
+		opts.Verbose, _ = cmd.Flags().GetBool("verbose")
+		opts.JSON, _ = cmd.Flags().GetBool("json")
+		opts.Type, _ = cmd.Flags().GetString("type")
+		opts.Filter, _ = cmd.Flags().GetString("filter")
 		
-		return app.ListTemplates(request)
+		return app.ListTemplates(request, opts)
 	},
 }
 
 var addCmd = &cobra.Command{
 	Use:   "add [content]",
 	Short: "Add a new prompt template",
-	Long:  "Add a new prompt template to the configured prompts directory. Use -p for pre-templates or -o for post-templates. If no flags are provided, interactive mode will ask for template type and name.",
+	Long:  "Add a new prompt template to the configured prompts directory. Use -p for pre-templates or -o for post-templates. If no flags are provided, interactive mode will ask for template type and name. --from-file and --from-dir import existing markdown files, named after each file.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		request := models.NewPromptRequest()
-		
-		// Get config path from flag
-		if configPath, err := cmd.Flags().GetString("config"); err == nil {
-			request.ConfigPath = configPath
-		}
+		request := newRequest(cmd)
 		
 		// Handle interactive mode flags
 		if forceNonInteractive, err := cmd.Flags().GetBool("yes"); err == nil {
@@ -120,70 +127,572 @@ var addCmd = &cobra.Command{
 		postName, _ := cmd.Flags().GetString("post")
 		fromClipboard, _ := cmd.Flags().GetBool("clipboard")
 		overwrite, _ := cmd.Flags().GetBool("overwrite")
+		encrypt, _ := cmd.Flags().GetBool("encrypt")
+		scaffold, _ := cmd.Flags().GetString("scaffold")
+		
+		// Importing takes names and content from the files instead
+		fromFiles, _ := cmd.Flags().GetStringArray("from-file")
+		fromDir, _ := cmd.Flags().GetString("from-dir")
+		if len(fromFiles) > 0 || fromDir != "" {
+			if content != "" || fromClipboard || scaffold != "" {
+				return fmt.Errorf("cannot combine --from-file or --from-dir with content, --clipboard, or --scaffold")
+			}
+			if preName != "" && postName != "" {
+				return fmt.Errorf("cannot specify both --pre and --post flags")
+			}
+			opts := app.ImportOptions{Files: fromFiles, Dir: fromDir, Overwrite: overwrite, Encrypt: encrypt}
+			opts.Type, _ = cmd.Flags().GetString("type")
+			if preName != "" {
+				opts.Type, opts.Name = "pre", preName
+			} else if postName != "" {
+				opts.Type, opts.Name = "post", postName
+			}
+			return app.ImportTemplates(request, opts)
+		}
+		
+		return app.AddTemplate(request, content, preName, postName, scaffold, fromClipboard, overwrite, encrypt)
+	},
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open [template]",
+	Short: "Open the prompts directory or a template in your editor",
+	Long:  "Open the configured prompts directory, or the file a template name resolves to (including namespace/name), in the editor chosen by --editor, $VISUAL, $EDITOR, or the editor config option.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+		request.ForceNonInteractive, _ = cmd.Flags().GetBool("yes")
+		request.ForceInteractive, _ = cmd.Flags().GetBool("interactive")
+		request.Editor, _ = cmd.Flags().GetString("editor")
 		
-		return app.AddTemplate(request, content, preName, postName, fromClipboard, overwrite)
+		templateName := ""
+		if len(args) > 0 {
+			templateName = args[0]
+		}
+		return app.OpenInEditor(request, templateName)
 	},
 }
 
+// templateTransferCmd builds the mv and cp commands, which share their flags and argument handling
+func templateTransferCmd(use, short, long string, transfer func(*models.PromptRequest, string, string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := newRequest(cmd)
+			request.ForceNonInteractive, _ = cmd.Flags().GetBool("yes")
+			request.ForceInteractive, _ = cmd.Flags().GetBool("interactive")
+			
+			return transfer(request, args[0], args[1])
+		},
+	}
+}
+
+var mvCmd = templateTransferCmd(
+	"mv <old> <new>",
+	"Rename a template",
+	"Rename the template <old> resolves to. <new> is a name, kept in the same directory, or namespace/name to move it to another namespace with the same type. default_pre and default_post are updated in the config file when they referenced the template.",
+	app.MoveTemplate,
+)
+
+var cpCmd = templateTransferCmd(
+	"cp <src> <dst>",
+	"Copy a template",
+	"Copy the template <src> resolves to. <dst> is a name, kept in the same directory, or namespace/name to copy it to another namespace with the same type, e.g. to customize a pack's template: prompter cp acme/review global/review",
+	app.CopyTemplate,
+)
+
 var promptsCmd = &cobra.Command{
 	Use:   "prompts",
 	Short: "Open prompts directory in editor",
 	Long:  "Open the configured prompts directory in the default editor for easy template management.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		request := models.NewPromptRequest()
-		
-		// Get config path from flag
-		if configPath, err := cmd.Flags().GetString("config"); err == nil {
-			request.ConfigPath = configPath
-		}
+		request := newRequest(cmd)
 		
 		return app.OpenPromptsDirectory(request)
 	},
 }
 
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage template packs installed from git and the starter templates",
+	Long:  "Install, update, and uninstall template packs: git repositories with pre/ and post/ template directories. Packs are cloned into the packs directory of prompts_location, and their templates are used as pack/name (or by name alone when nothing else matches). 'prompter templates bootstrap' installs the starter templates built into prompter.",
+}
+
+var templatesInstallCmd = &cobra.Command{
+	Use:   "install <git-url>",
+	Short: "Install a template pack from a git repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		name, _ := cmd.Flags().GetString("name")
+		return app.InstallTemplatePack(request, args[0], name)
+	},
+}
+
+var templatesUpdateCmd = &cobra.Command{
+	Use:   "update [pack...]",
+	Short: "Pull the latest templates for installed packs (all when none are named)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.UpdateTemplatePacks(request, args)
+	},
+}
+
+var templatesUninstallCmd = &cobra.Command{
+	Use:   "uninstall <pack>",
+	Short: "Remove an installed template pack",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.UninstallTemplatePack(request, args[0])
+	},
+}
+
+var templatesBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Install the starter templates built into prompter",
+	Long:  "Copy the starter templates built into prompter (review, explain, refactor, and tests pre-templates, concise and steps post-templates, and fix prompts) into prompts_location. Templates that already exist are kept. With --default, the pre- and post-templates are written as name.default.md so they are listed first.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		asDefault, _ := cmd.Flags().GetBool("default")
+		return app.BootstrapTemplates(request, asDefault)
+	},
+}
+
+var browseCmd = &cobra.Command{
+	Use:   "browse [query]",
+	Short: "Find and install template packs from the pack index",
+	Long:  "List the template packs in the index at pack_index_url whose name, description, or tags match the query, most installed first. From a terminal it asks which to install; otherwise, or with --install <name>, install one directly. Packs are installed as 'prompter templates install' would.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+		request.ForceNonInteractive, _ = cmd.Flags().GetBool("yes")
+
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+		install, _ := cmd.Flags().GetString("install")
+		return app.BrowsePacks(request, query, install, os.Stdout)
+	},
+}
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Trust the current directory's project-local templates",
+	Long:  "Record that project-local templates in the current directory may be used. Prompter otherwise asks the first time it finds them, and ignores them in noninteractive runs until the directory is trusted. Trust also lets --pack run the commands in the project's .prompter-pack.toml. Use --revoke to stop using them.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		revoke, _ := cmd.Flags().GetBool("revoke")
+		return app.TrustWorkspace(request, !revoke)
+	},
+}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Run the guided setup",
+	Long:  "Ask for the prompts location, editor, default target, and interactive default, write them to the config file, and optionally install starter templates. The first run from a terminal without a config file starts this automatically; set PROMPTER_NO_SETUP=1 to skip it.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		return app.Setup(configPath, true)
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the config file and environment for problems",
+	Long:  "Check the config file for unknown keys and invalid values, that the prompts directories exist and are readable, that the editor is on PATH, which clipboard backend is available, and that fix_file can be written. Each check prints ok, warn, or FAIL with what to do about it; the command fails when any check does.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.ConfigDoctor(request, os.Stdout)
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move files out of ~/.config/prompter to the current config location",
+	Long:  "Move config.toml and prompts to $XDG_CONFIG_HOME/prompter (%APPDATA%\\prompter on Windows), and state.db and invocations.jsonl to $XDG_DATA_HOME/prompter when it's set. Paths in the moved config file that point at the old location are updated. Files already at the new location are left alone; until the new config directory exists, prompter keeps reading ~/.config/prompter.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return app.MigrateConfig(os.Stdout)
+	},
+}
+
+var varsCmd = &cobra.Command{
+	Use:   "vars",
+	Short: "Manage variables templates read as .Vars",
+	Long:  "Store values templates read as .Vars.<name>, such as a team or service name, so they don't need repeating in every run. Variables are global unless set with --project, which keeps them to the current project (the git repository or project root); a project variable replaces a global one with the same name.",
+}
+
+var varsSetCmd = &cobra.Command{
+	Use:   "set <name> <value>",
+	Short: "Set a variable",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		project, _ := cmd.Flags().GetBool("project")
+		return app.SetVar(request, args[0], args[1], project)
+	},
+}
+
+var varsUnsetCmd = &cobra.Command{
+	Use:   "unset <name>",
+	Short: "Remove a variable",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		project, _ := cmd.Flags().GetBool("project")
+		return app.UnsetVar(request, args[0], project)
+	},
+}
+
+var varsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the global variables and the current project's",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.ListVars(request, os.Stdout)
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the prompts output recently, or the fix captures with --captures",
+	Long:  "List the prompts output recently, newest first, with the directory, templates, and start of the base prompt of each. With --captures, list the command output fix mode captured instead; --fix-source last assembles a fix prompt from the latest capture in the current directory again. Both are kept in state_file, with secrets redacted, while history = true.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		captures, _ := cmd.Flags().GetBool("captures")
+		limit, _ := cmd.Flags().GetInt("limit")
+		return app.ShowHistory(request, captures, limit, os.Stdout)
+	},
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Keep a conversation across prompts",
+	Long:  "Start a session to carry a conversation across runs. While it is active, each prompt output for the current project is added to it, along with the templates, files, and other context it included, and begins with a condensed transcript of the session so far. Paste a model's responses back in with 'session add' so the next prompt includes them too.",
+}
+
+var sessionStartCmd = &cobra.Command{
+	Use:   "start [name]",
+	Short: "Start a session for the current project",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return app.StartSession(request, name, os.Stdout)
+	},
+}
+
+var sessionAddCmd = &cobra.Command{
+	Use:   "add [response]",
+	Short: "Add a model's response to the session (from the argument, --clipboard, or stdin)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		response := ""
+		if len(args) > 0 {
+			response = args[0]
+		}
+		fromClipboard, _ := cmd.Flags().GetBool("clipboard")
+		return app.AddSessionResponse(request, response, fromClipboard, os.Stdout)
+	},
+}
+
+var sessionShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the session's transcript",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.ShowSession(request, os.Stdout)
+	},
+}
+
+var sessionEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "End the session; later prompts no longer include its transcript",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.EndSession(request, os.Stdout)
+	},
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch (--clipboard | --file <path>)",
+	Short: "Assemble fix prompts as errors are copied or a log changes",
+	Long:  "Watch the clipboard or a file until interrupted, assembling fix prompts with fix.md and fix_default_pre/post.\n\nWith --clipboard, each time a stack trace or compiler, test, or linter error is copied (or content matching --match), a fix prompt is assembled from it and output to the target: the clipboard by default, replacing what was copied.\n\nWith --file, a fix prompt is assembled from the file, as --fix-file would, and again each time it changes, e.g. --file build.log --target file:/tmp/prompt.md for an editor to read.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clipboard, _ := cmd.Flags().GetBool("clipboard")
+		file, _ := cmd.Flags().GetString("file")
+		if clipboard == (file != "") {
+			return fmt.Errorf("use one of --clipboard or --file to say what to watch")
+		}
+		request := newRequest(cmd)
+
+		request.Target, _ = cmd.Flags().GetString("target")
+		if file != "" {
+			return app.WatchFile(request, file, os.Stdout)
+		}
+		pattern, _ := cmd.Flags().GetString("match")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		return app.WatchClipboard(request, pattern, interval, os.Stdout)
+	},
+}
+
+var configExplainCmd = &cobra.Command{
+	Use:   "explain <key>",
+	Short: "Show a config key's value and which layer set it",
+	Long:  "Print the resolved value of a config key and the layer that supplied it: a --set flag, a PROMPTER_ environment variable, the active profile, the config file, or the default. Lower layers that also set it are listed with their values. --set and --profile show how they would change a run.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		request.ConfigOverrides, _ = cmd.Flags().GetStringArray("set")
+		return app.ConfigExplain(request, args[0], os.Stdout)
+	},
+}
+
+var hookCmd = &cobra.Command{
+	Use:       "hook <zsh|bash|fish>",
+	Short:     "Print a shell hook that captures command output for fix mode",
+	Long:      "Print a shell snippet that records each command and its output into the configured fix_file, so 'prompter --fix' uses real output instead of re-running commands from history. Install with eval \"$(prompter hook zsh)\" (or bash), or 'prompter hook fish | source'.",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: app.HookShells,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		return app.PrintShellHook(request, args[0])
+	},
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Print suggested shell aliases for prompter",
+	Long:  "Print the aliases 'prompter alias install' writes: pf for 'prompter --fix -y' and pc for a commit message from the staged diff. Replace them with an aliases.sh Go template in the prompts location; .Shell is zsh, bash, or fish.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		shell, _ := cmd.Flags().GetString("shell")
+		return app.PrintShellAliases(request, shell)
+	},
+}
+
+var aliasInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Write prompter's shell aliases into your shell rc file",
+	Long:  "Write the aliases into a marked block in ~/.zshrc, ~/.bashrc, or ~/.config/fish/config.fish, replacing the block if it is already there. Remove it with 'prompter alias uninstall'.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := newRequest(cmd)
+
+		shell, _ := cmd.Flags().GetString("shell")
+		rcFile, _ := cmd.Flags().GetString("rc")
+		return app.InstallShellAliases(request, shell, rcFile)
+	},
+}
+
+var aliasUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove prompter's shell aliases from your shell rc file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, _ := cmd.Flags().GetString("shell")
+		rcFile, _ := cmd.Flags().GetString("rc")
+		return app.UninstallShellAliases(shell, rcFile)
+	},
+}
+
 func init() {
+	app.Version = version
+
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(promptsCmd)
+	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(cpCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(templatesCmd)
+	rootCmd.AddCommand(browseCmd)
+	rootCmd.AddCommand(trustCmd)
+	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDoctorCmd, configExplainCmd, configMigrateCmd)
+	rootCmd.AddCommand(varsCmd)
+	varsCmd.AddCommand(varsSetCmd, varsUnsetCmd, varsListCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionStartCmd, sessionAddCmd, sessionShowCmd, sessionEndCmd)
+	rootCmd.AddCommand(watchCmd)
+	templatesCmd.AddCommand(templatesInstallCmd, templatesUpdateCmd, templatesUninstallCmd, templatesBootstrapCmd)
+	aliasCmd.AddCommand(aliasInstallCmd, aliasUninstallCmd)
 	
 	// Add command specific flags
 	addCmd.Flags().StringP("pre", "p", "", "create a pre-template with the specified name")
 	addCmd.Flags().StringP("post", "o", "", "create a post-template with the specified name")
 	addCmd.Flags().BoolP("clipboard", "b", false, "create template from clipboard content")
 	addCmd.Flags().BoolP("overwrite", "r", false, "overwrite existing template file without prompting")
+	addCmd.Flags().String("scaffold", "", "start from a skeleton: "+strings.Join(app.Scaffolds(), ", ")+" (fix writes fix.md)")
+	addCmd.Flags().StringArray("from-file", []string{}, "import a markdown file as a template (repeatable)")
+	addCmd.Flags().String("from-dir", "", "import every markdown file under a directory as templates")
+	addCmd.Flags().String("type", "", "template type for imported files: pre or post (default: inferred from pre/ or post/ directories or front matter)")
+	addCmd.Flags().Bool("encrypt", false, "encrypt the template at rest with the configured encryption_tool (age or gpg)")
+	openCmd.Flags().StringP("editor", "e", "", "editor to open in (overrides $VISUAL, $EDITOR, and config)")
+	aliasCmd.PersistentFlags().String("shell", "", "shell to write aliases for: zsh, bash, or fish (default: from $SHELL)")
+	aliasInstallCmd.Flags().String("rc", "", "rc file to write (default: the shell's usual startup file)")
+	aliasUninstallCmd.Flags().String("rc", "", "rc file to remove the aliases from (default: the shell's usual startup file)")
+	listCmd.Flags().BoolP("verbose", "V", false, "show the file each template name resolves to and the templates it shadows")
+	listCmd.Flags().String("type", "", "only list pre or post templates")
+	listCmd.Flags().String("filter", "", "only list templates whose name contains this (case-insensitive)")
+	listCmd.Flags().Bool("json", false, "print templates as JSON (name, type, namespace, path, description, modified, shadowed)")
+	templatesInstallCmd.Flags().String("name", "", "pack name (default: the repository name)")
+	templatesBootstrapCmd.Flags().Bool("default", false, "write pre- and post-templates as name.default.md, listed first in pickers")
+	browseCmd.Flags().String("install", "", "install the named pack from the index instead of listing")
+	trustCmd.Flags().Bool("revoke", false, "stop using project-local templates in the current directory")
+	configExplainCmd.Flags().StringArray("set", []string{}, "override a config key as a run's --set would, e.g. --set target=stdout (repeatable)")
+	varsSetCmd.Flags().Bool("project", false, "set the variable for the current project only")
+	varsUnsetCmd.Flags().Bool("project", false, "remove the current project's variable instead of the global one")
+	historyCmd.Flags().Bool("captures", false, "list fix captures instead of prompts")
+	historyCmd.Flags().IntP("limit", "n", 20, "how many entries to list")
+	sessionAddCmd.Flags().BoolP("clipboard", "b", false, "add the response from the clipboard")
+	watchCmd.Flags().BoolP("clipboard", "b", false, "watch the clipboard")
+	watchCmd.Flags().String("file", "", "watch a file, such as a build log")
+	watchCmd.Flags().String("match", "", "regular expression copied content must match (default: stack traces and compiler, test, and linter errors)")
+	watchCmd.Flags().Duration("interval", app.DefaultWatchInterval, "how often to check the clipboard")
+	watchCmd.Flags().StringP("target", "t", "", "output target for fix prompts (default: the clipboard with --clipboard, otherwise the configured target)")
 
 	// Global flags
-	rootCmd.PersistentFlags().StringP("config", "c", "", "config file path (default ~/.config/prompter/config.toml)")
+	rootCmd.PersistentFlags().StringP("config", "c", "", "config file path (default ~/.config/prompter/config.toml, or under XDG_CONFIG_HOME or %APPDATA%)")
 	rootCmd.PersistentFlags().BoolP("yes", "y", false, "noninteractive mode - use defaults without prompts")
 	rootCmd.PersistentFlags().BoolP("interactive", "i", false, "force interactive mode (overrides config default)")
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "print version information")
+	rootCmd.PersistentFlags().String("profile", "", "config profile to use, a [profiles.<name>] table (default: $PROMPTER_PROFILE)")
+	rootCmd.PersistentFlags().Bool("no-color", false, "print prompts and errors without color (also set by NO_COLOR)")
+
+	// --profile is passed on as PROMPTER_PROFILE, so every command's configuration picks it up
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if colorDisabled(cmd.Flags()) {
+			interactive.DisableColor()
+		}
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			return os.Setenv("PROMPTER_PROFILE", profile)
+		}
+		return nil
+	}
 
 	// Main command flags
 	rootCmd.Flags().StringP("pre", "p", "", "pre-template name")
 	rootCmd.Flags().StringP("post", "o", "", "post-template name")
-	rootCmd.Flags().StringSlice("file", []string{}, "files to include")
+	rootCmd.Flags().String("pre-inline", "", "pre-template text to use instead of a template file")
+	rootCmd.Flags().String("post-inline", "", "post-template text to use instead of a template file")
+	rootCmd.Flags().StringSlice("file", []string{}, "files to include (ssh://[user@]host[:port]/path fetches a remote file)")
 	rootCmd.Flags().BoolP("directory", "d", false, "include current directory")
-	rootCmd.Flags().StringP("target", "t", "", "output target (clipboard, stdout, file:/path)")
+	rootCmd.Flags().Bool("files-from-diff", false, "include the full contents of files changed in the git working tree")
+	rootCmd.Flags().String("changed", "", "include changed and untracked files; --changed=ref also includes what the branch changed since ref")
+	rootCmd.Flags().Lookup("changed").NoOptDefVal = "HEAD"
+	rootCmd.Flags().Bool("include-binary", false, "embed binary files instead of listing them by path")
+	rootCmd.Flags().Bool("include-generated", false, "embed lock files and minified and generated files instead of listing them by path")
+	rootCmd.Flags().StringArray("symbol", []string{}, "include one Go declaration and its doc comment, as pkg.Name or pkg.Type.Method, instead of its file (repeatable)")
+	rootCmd.Flags().StringArray("grep", []string{}, "include the lines matching a regular expression in the workspace, with context (repeatable)")
+	rootCmd.Flags().Int("grep-context", 3, "lines of context around each --grep match")
+	rootCmd.Flags().Bool("grep-files", false, "include whole files that match --grep instead of the matching lines")
+	rootCmd.Flags().Bool("map", false, "include an outline of the repository (its files and their exported symbols) instead of file contents")
+	rootCmd.Flags().StringArray("url", []string{}, "fetch a page or raw file and include it as context (repeatable)")
+	rootCmd.Flags().StringArray("github", []string{}, "include a GitHub issue or PR (owner/repo#123 or URL) as context (repeatable)")
+	rootCmd.Flags().StringArray("jira", []string{}, "include a Jira ticket (KEY-123) as context (repeatable)")
+	rootCmd.Flags().StringArray("pack", []string{}, "include a context pack's files and command output from .prompter-pack.toml (repeatable)")
+	rootCmd.Flags().StringP("target", "t", "", "output target (clipboard, tmux, osc52, stdout, file:/path, file+:/path, file+prepend:/path, cmd:command, or a [targets] alias)")
+	rootCmd.Flags().String("pipe-to", "", "pipe the prompt into a command and show its output, e.g. \"claude -p\" (same as --target cmd:...)")
+	rootCmd.Flags().Bool("append", false, "append to a file: target instead of replacing it")
 	rootCmd.Flags().StringP("editor", "e", "", "editor to open prompt in")
-	rootCmd.Flags().BoolP("fix", "f", false, "fix mode - process captured command output")
+	rootCmd.Flags().Bool("no-editor-wait", false, "don't wait for GUI editors to close the prompt (overrides config)")
+	rootCmd.Flags().Bool("preview", false, "show the prompt and confirm, edit, or re-pick templates before output")
+	rootCmd.Flags().Bool("minimal", false, "turn off clipboard, editor, interactive prompts, color, and network access (automatic in CI and containers)")
+	rootCmd.Flags().Bool("no-minimal", false, "don't use minimal mode, even in CI or a container")
+	rootCmd.Flags().Bool("ci", false, "never prompt: fail with exit status 7 where a prompt would be needed")
+	rootCmd.Flags().Bool("no-update-check", false, "don't check for a newer release (overrides config)")
+	rootCmd.Flags().Duration("timeout", 0, "give up after this long, e.g. 30s or 2m, stopping any command still running")
+	rootCmd.Flags().StringP("fix", "f", "", "fix mode - process captured command output; --fix=name uses the fix/name.md prompt")
+	// A bare --fix works as before; true and false still parse for scripts that pass them
+	rootCmd.Flags().Lookup("fix").NoOptDefVal = "true"
 	rootCmd.Flags().String("fix-file", "", "file containing command output to fix (overrides config)")
+	rootCmd.Flags().String("fix-cmd", "", "run a command and fix its captured output (implies --fix)")
+	rootCmd.Flags().String("fix-source", "", "where fix content comes from: rerun (default), command, tasks, file, stdin, clipboard, tmux, script, or last (implies --fix)")
+	rootCmd.Flags().StringArray("task", []string{}, "run a [tasks] command, or name=command, and fix the failures of all of them in one prompt (repeatable, implies --fix)")
+	rootCmd.Flags().Bool("no-fix-files", false, "don't attach files referenced in fix output")
+	rootCmd.Flags().Bool("no-redact", false, "keep secrets in included content instead of replacing them with placeholders")
 	rootCmd.Flags().BoolP("numbers", "n", false, "enable number key selection for templates")
 	rootCmd.Flags().BoolP("clipboard", "b", false, "append clipboard content to prompt (or use as base prompt if none provided)")
+	rootCmd.Flags().Int("budget", 0, "maximum estimated tokens for the prompt, trimmed by section priority (overrides config)")
+	rootCmd.Flags().Int("wrap", 0, "reflow prose to at most this many columns, leaving code blocks as they are")
+	rootCmd.Flags().String("format", "", "how to write the prompt: text (default), json (its sections with sources and token counts), or messages (a system/user messages array)")
+	rootCmd.Flags().String("schema", "", "JSON Schema file the response must match, added to the end of the prompt as an output contract")
+	rootCmd.Flags().Bool("cache", false, "reuse the last prompt when the request, config, templates, and files are unchanged")
+	rootCmd.Flags().StringArray("set", []string{}, "override a config key for this run, e.g. --set directory_strategy=filesystem (repeatable)")
+	rootCmd.Flags().Bool("debug", false, "report internal decisions, such as which clipboard backend was used, on stderr")
+	rootCmd.Flags().BoolP("quiet", "q", false, "don't print the summary of templates, files, size, and target after output")
 	
 	// Register custom template flags dynamically
 	registerCustomTemplateFlags()
 }
 
+// newRequest returns a PromptRequest for a subcommand, reading the config path from --config
+func newRequest(cmd *cobra.Command) *models.PromptRequest {
+	request := models.NewPromptRequest()
+	request.ConfigPath, _ = cmd.Flags().GetString("config")
+	return request
+}
+
 // buildRequestFromFlags constructs a PromptRequest from command flags and arguments
 func buildRequestFromFlags(cmd *cobra.Command, args []string) (*models.PromptRequest, error) {
 	request := models.NewPromptRequest()
 
-	// Get base prompt from positional argument
+	// Get base prompt from positional argument; "-" in place of it or after it reads stdin
 	if len(args) > 0 {
 		request.BasePrompt = strings.TrimSpace(args[0])
 	}
+	if request.BasePrompt == "-" {
+		request.BasePrompt = ""
+	}
+	request.ReadStdin = len(args) == 2 || (len(args) == 1 && args[0] == "-")
 
 	// Extract flags
 	var err error
@@ -217,10 +726,73 @@ func buildRequestFromFlags(cmd *cobra.Command, args []string) (*models.PromptReq
 		return nil, fmt.Errorf("invalid post flag: %w", err)
 	}
 
+	if request.PreInline, err = cmd.Flags().GetString("pre-inline"); err != nil {
+		return nil, fmt.Errorf("invalid pre-inline flag: %w", err)
+	}
+
+	if request.PostInline, err = cmd.Flags().GetString("post-inline"); err != nil {
+		return nil, fmt.Errorf("invalid post-inline flag: %w", err)
+	}
+
 	if request.Files, err = cmd.Flags().GetStringSlice("file"); err != nil {
 		return nil, fmt.Errorf("invalid file flag: %w", err)
 	}
 
+	if request.URLs, err = cmd.Flags().GetStringArray("url"); err != nil {
+		return nil, fmt.Errorf("invalid url flag: %w", err)
+	}
+
+	if request.GitHubRefs, err = cmd.Flags().GetStringArray("github"); err != nil {
+		return nil, fmt.Errorf("invalid github flag: %w", err)
+	}
+
+	if request.JiraKeys, err = cmd.Flags().GetStringArray("jira"); err != nil {
+		return nil, fmt.Errorf("invalid jira flag: %w", err)
+	}
+
+	if request.ContextPacks, err = cmd.Flags().GetStringArray("pack"); err != nil {
+		return nil, fmt.Errorf("invalid pack flag: %w", err)
+	}
+
+	if request.FilesFromDiff, err = cmd.Flags().GetBool("files-from-diff"); err != nil {
+		return nil, fmt.Errorf("invalid files-from-diff flag: %w", err)
+	}
+
+	if cmd.Flags().Changed("changed") {
+		if request.ChangedSince, err = cmd.Flags().GetString("changed"); err != nil {
+			return nil, fmt.Errorf("invalid changed flag: %w", err)
+		}
+		request.FilesFromDiff, request.ChangedUntracked = true, true
+	}
+
+	if request.IncludeBinary, err = cmd.Flags().GetBool("include-binary"); err != nil {
+		return nil, fmt.Errorf("invalid include-binary flag: %w", err)
+	}
+
+	if request.IncludeGenerated, err = cmd.Flags().GetBool("include-generated"); err != nil {
+		return nil, fmt.Errorf("invalid include-generated flag: %w", err)
+	}
+
+	if request.RepoMap, err = cmd.Flags().GetBool("map"); err != nil {
+		return nil, fmt.Errorf("invalid map flag: %w", err)
+	}
+
+	if request.Symbols, err = cmd.Flags().GetStringArray("symbol"); err != nil {
+		return nil, fmt.Errorf("invalid symbol flag: %w", err)
+	}
+
+	if request.GrepPatterns, err = cmd.Flags().GetStringArray("grep"); err != nil {
+		return nil, fmt.Errorf("invalid grep flag: %w", err)
+	}
+
+	if request.GrepContext, err = cmd.Flags().GetInt("grep-context"); err != nil {
+		return nil, fmt.Errorf("invalid grep-context flag: %w", err)
+	}
+
+	if request.GrepFiles, err = cmd.Flags().GetBool("grep-files"); err != nil {
+		return nil, fmt.Errorf("invalid grep-files flag: %w", err)
+	}
+
 	var includeDirectory bool
 	if includeDirectory, err = cmd.Flags().GetBool("directory"); err != nil {
 		return nil, fmt.Errorf("invalid directory flag: %w", err)
@@ -239,14 +811,61 @@ func buildRequestFromFlags(cmd *cobra.Command, args []string) (*models.PromptReq
 		return nil, fmt.Errorf("invalid target flag: %w", err)
 	}
 
+	pipeTo, err := cmd.Flags().GetString("pipe-to")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipe-to flag: %w", err)
+	}
+	if pipeTo != "" {
+		if request.Target != "" {
+			return nil, fmt.Errorf("--pipe-to and --target both set where the prompt goes; use one")
+		}
+		request.Target = "cmd:" + pipeTo
+	}
+
+	if request.AppendOutput, err = cmd.Flags().GetBool("append"); err != nil {
+		return nil, fmt.Errorf("invalid append flag: %w", err)
+	}
+
 	if request.Editor, err = cmd.Flags().GetString("editor"); err != nil {
 		return nil, fmt.Errorf("invalid editor flag: %w", err)
 	}
 	// Track if --editor flag was explicitly set
 	request.EditorRequested = cmd.Flags().Changed("editor")
+	if request.NoEditorWait, err = cmd.Flags().GetBool("no-editor-wait"); err != nil {
+		return nil, fmt.Errorf("invalid no-editor-wait flag: %w", err)
+	}
+
+	if request.Preview, err = cmd.Flags().GetBool("preview"); err != nil {
+		return nil, fmt.Errorf("invalid preview flag: %w", err)
+	}
+
+	if request.Minimal, err = cmd.Flags().GetBool("minimal"); err != nil {
+		return nil, fmt.Errorf("invalid minimal flag: %w", err)
+	}
+
+	if request.NoMinimal, err = cmd.Flags().GetBool("no-minimal"); err != nil {
+		return nil, fmt.Errorf("invalid no-minimal flag: %w", err)
+	}
 
-	if request.FixMode, err = cmd.Flags().GetBool("fix"); err != nil {
+	if request.CI, err = cmd.Flags().GetBool("ci"); err != nil {
+		return nil, fmt.Errorf("invalid ci flag: %w", err)
+	}
+
+	if request.NoUpdateCheck, err = cmd.Flags().GetBool("no-update-check"); err != nil {
+		return nil, fmt.Errorf("invalid no-update-check flag: %w", err)
+	}
+
+	if request.Timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return nil, fmt.Errorf("invalid timeout flag: %w", err)
+	}
+
+	if fix, err := cmd.Flags().GetString("fix"); err != nil {
 		return nil, fmt.Errorf("invalid fix flag: %w", err)
+	} else if enabled, err := strconv.ParseBool(fix); err == nil {
+		request.FixMode = enabled
+	} else if fix = strings.TrimSpace(fix); fix != "" {
+		request.FixMode = true
+		request.FixTemplate = fix
 	}
 
 	// Handle fix-file flag (overrides config)
@@ -256,6 +875,40 @@ func buildRequestFromFlags(cmd *cobra.Command, args []string) (*models.PromptReq
 		request.FixFile = fixFile
 	}
 
+	// Handle fix-cmd flag (implies fix mode)
+	if request.FixCommand, err = cmd.Flags().GetString("fix-cmd"); err != nil {
+		return nil, fmt.Errorf("invalid fix-cmd flag: %w", err)
+	}
+	request.FixCommand = strings.TrimSpace(request.FixCommand)
+	if request.FixCommand != "" {
+		request.FixMode = true
+	}
+
+	// Handle fix-source flag (implies fix mode)
+	if request.FixSource, err = cmd.Flags().GetString("fix-source"); err != nil {
+		return nil, fmt.Errorf("invalid fix-source flag: %w", err)
+	}
+	request.FixSource = strings.TrimSpace(request.FixSource)
+	if request.FixSource != "" {
+		request.FixMode = true
+	}
+
+	// Handle task flags (implies fix mode)
+	if request.FixTasks, err = cmd.Flags().GetStringArray("task"); err != nil {
+		return nil, fmt.Errorf("invalid task flag: %w", err)
+	}
+	if len(request.FixTasks) > 0 {
+		request.FixMode = true
+	}
+
+	if request.NoFixFiles, err = cmd.Flags().GetBool("no-fix-files"); err != nil {
+		return nil, fmt.Errorf("invalid no-fix-files flag: %w", err)
+	}
+
+	if request.NoRedact, err = cmd.Flags().GetBool("no-redact"); err != nil {
+		return nil, fmt.Errorf("invalid no-redact flag: %w", err)
+	}
+
 	if request.NumberSelect, err = cmd.Flags().GetBool("numbers"); err != nil {
 		return nil, fmt.Errorf("invalid numbers flag: %w", err)
 	}
@@ -264,14 +917,86 @@ func buildRequestFromFlags(cmd *cobra.Command, args []string) (*models.PromptReq
 		return nil, fmt.Errorf("invalid clipboard flag: %w", err)
 	}
 
+	if request.TokenBudget, err = cmd.Flags().GetInt("budget"); err != nil {
+		return nil, fmt.Errorf("invalid budget flag: %w", err)
+	}
+
+	if request.WrapWidth, err = cmd.Flags().GetInt("wrap"); err != nil {
+		return nil, fmt.Errorf("invalid wrap flag: %w", err)
+	}
+
+	if request.Format, err = cmd.Flags().GetString("format"); err != nil {
+		return nil, fmt.Errorf("invalid format flag: %w", err)
+	}
+
+	if request.SchemaFile, err = cmd.Flags().GetString("schema"); err != nil {
+		return nil, fmt.Errorf("invalid schema flag: %w", err)
+	}
+
+	if request.Debug, err = cmd.Flags().GetBool("debug"); err != nil {
+		return nil, fmt.Errorf("invalid debug flag: %w", err)
+	}
+
+	if request.Quiet, err = cmd.Flags().GetBool("quiet"); err != nil {
+		return nil, fmt.Errorf("invalid quiet flag: %w", err)
+	}
+
+	if request.UseCache, err = cmd.Flags().GetBool("cache"); err != nil {
+		return nil, fmt.Errorf("invalid cache flag: %w", err)
+	}
+
+	if request.ConfigOverrides, err = cmd.Flags().GetStringArray("set"); err != nil {
+		return nil, fmt.Errorf("invalid set flag: %w", err)
+	}
+
 	// Handle custom template flags
 	if err := applyCustomTemplateFlags(cmd, request); err != nil {
 		return nil, fmt.Errorf("invalid custom template flag: %w", err)
 	}
 
+	// Inline text takes the template's place, so both can't be given
+	if request.PreInline != "" && request.PreTemplate != "" {
+		return nil, fmt.Errorf("--pre-inline and a pre-template (%s) can't be used together", request.PreTemplate)
+	}
+	if request.PostInline != "" && request.PostTemplate != "" {
+		return nil, fmt.Errorf("--post-inline and a post-template (%s) can't be used together", request.PostTemplate)
+	}
+
+	request.CommandLine = redactedCommandLine(cmd, args)
+
 	return request, nil
 }
 
+// loggedFlagValues are the flags whose values the invocation log keeps: template names,
+// targets, and settings. Every other value, such as --set github_token=..., --fix-cmd, or a
+// file path, could hold a secret and is logged as <redacted>.
+var loggedFlagValues = map[string]bool{
+	"pre": true, "post": true, "fix": true, "target": true, "profile": true, "pack": true,
+	"fix-source": true, "format": true, "budget": true, "wrap": true, "timeout": true,
+	"grep-context": true, "changed": true,
+}
+
+// redactedCommandLine reconstructs the invocation from the flags that were set, keeping
+// only the values of loggedFlagValues and replacing the base prompt argument, so the
+// invocation log never holds prompt text or credentials
+func redactedCommandLine(cmd *cobra.Command, args []string) []string {
+	commandLine := []string{cmd.Name()}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		switch {
+		case flag.Value.Type() == "bool" || (flag.NoOptDefVal != "" && flag.Value.String() == flag.NoOptDefVal):
+			commandLine = append(commandLine, "--"+flag.Name)
+		case loggedFlagValues[flag.Name]:
+			commandLine = append(commandLine, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+		default:
+			commandLine = append(commandLine, "--"+flag.Name+"=<redacted>")
+		}
+	})
+	for range args {
+		commandLine = append(commandLine, "<prompt>")
+	}
+	return commandLine
+}
+
 // getFirstTemplateFromDir returns the first template name found in a directory
 func getFirstTemplateFromDir(dir string) (string, error) {
 	entries, err := os.ReadDir(dir)
@@ -425,8 +1150,22 @@ func main() {
 	rootCmd.SilenceErrors = true
 	
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		// Color and wrapping are only for a terminal; redirected errors stay plain
+		color, width := false, 0
+		if fd := int(os.Stderr.Fd()); term.IsTerminal(fd) {
+			color = !colorDisabled(rootCmd.PersistentFlags())
+			if columns, _, err := term.GetSize(fd); err == nil {
+				width = columns
+			}
+		}
+		fmt.Fprint(os.Stderr, orchestrator.RenderError(err, color, width))
+		os.Exit(orchestrator.ExitCode(err))
 	}
 }
 
+// colorDisabled reports whether --no-color was given, NO_COLOR is set, or TERM is dumb
+func colorDisabled(flags *pflag.FlagSet) bool {
+	noColor, _ := flags.GetBool("no-color")
+	return noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"slices"
 	"testing"
 
+	"github.com/imdevan/prompter/pkg/models"
 	"github.com/spf13/cobra"
-	"prompter-cli/pkg/models"
 )
 
 func TestBuildRequestFromFlags(t *testing.T) {
@@ -57,6 +58,73 @@ func TestBuildRequestFromFlags(t *testing.T) {
 				Files:               []string{},
 			},
 		},
+		{
+			name: "dash after the base prompt reads stdin",
+			args: []string{"review this", "-"},
+			expected: &models.PromptRequest{
+				BasePrompt:  "review this",
+				ReadStdin:   true,
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "dash as the base prompt reads stdin",
+			args: []string{"-"},
+			expected: &models.PromptRequest{
+				ReadStdin:   true,
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "named fix prompt",
+			flags: map[string]string{
+				"fix": "compile",
+			},
+			expected: &models.PromptRequest{
+				FixMode:     true,
+				FixTemplate: "compile",
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "fix command implies fix mode",
+			flags: map[string]string{
+				"fix-cmd": "go test ./...",
+			},
+			expected: &models.PromptRequest{
+				FixMode:     true,
+				FixCommand:  "go test ./...",
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "fix source implies fix mode",
+			flags: map[string]string{
+				"fix-source": "tmux",
+			},
+			expected: &models.PromptRequest{
+				FixMode:     true,
+				FixSource:   "tmux",
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "task implies fix mode",
+			flags: map[string]string{
+				"task": "test",
+			},
+			expected: &models.PromptRequest{
+				FixMode:     true,
+				FixTasks:    []string{"test"},
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
 		{
 			name: "number selection mode",
 			args: []string{"test prompt"},
@@ -107,6 +175,50 @@ func TestBuildRequestFromFlags(t *testing.T) {
 				Files:            []string{},
 			},
 		},
+		{
+			name: "inline templates",
+			args: []string{"test prompt"},
+			flags: map[string]string{
+				"pre-inline":  "You are a senior Go reviewer.",
+				"post-inline": "Answer with a unified diff only.",
+			},
+			expected: &models.PromptRequest{
+				BasePrompt:  "test prompt",
+				PreInline:   "You are a senior Go reviewer.",
+				PostInline:  "Answer with a unified diff only.",
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "pre-inline with pre should error",
+			flags: map[string]string{
+				"pre-inline": "You are a reviewer.",
+				"pre":        "review",
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipe-to sets a command target",
+			args: []string{"test prompt"},
+			flags: map[string]string{
+				"pipe-to": "claude -p",
+			},
+			expected: &models.PromptRequest{
+				BasePrompt:  "test prompt",
+				Target:      "cmd:claude -p",
+				Interactive: true,
+				Files:       []string{},
+			},
+		},
+		{
+			name: "pipe-to with target should error",
+			flags: map[string]string{
+				"pipe-to": "llm",
+				"target":  "stdout",
+			},
+			wantErr: true,
+		},
 		{
 			name: "conflicting interactive flags should error",
 			boolFlags: map[string]bool{
@@ -126,15 +238,54 @@ func TestBuildRequestFromFlags(t *testing.T) {
 			cmd.Flags().Bool("yes", false, "")
 			cmd.Flags().String("pre", "", "")
 			cmd.Flags().String("post", "", "")
+			cmd.Flags().String("pre-inline", "", "")
+			cmd.Flags().String("post-inline", "", "")
 			cmd.Flags().StringSlice("file", []string{}, "")
+			cmd.Flags().StringArray("url", []string{}, "")
+			cmd.Flags().StringArray("github", []string{}, "")
+			cmd.Flags().StringArray("jira", []string{}, "")
+			cmd.Flags().StringArray("pack", []string{}, "")
+			cmd.Flags().Bool("files-from-diff", false, "")
+			cmd.Flags().Bool("include-binary", false, "")
+			cmd.Flags().Bool("include-generated", false, "")
+			cmd.Flags().String("changed", "", "")
+			cmd.Flags().Lookup("changed").NoOptDefVal = "HEAD"
+			cmd.Flags().StringArray("symbol", []string{}, "")
+			cmd.Flags().StringArray("grep", []string{}, "")
+			cmd.Flags().Int("grep-context", 3, "")
+			cmd.Flags().Bool("grep-files", false, "")
+			cmd.Flags().Bool("map", false, "")
 			cmd.Flags().BoolP("directory", "d", false, "")
 			cmd.Flags().String("target", "", "")
+			cmd.Flags().String("pipe-to", "", "")
+			cmd.Flags().Bool("append", false, "")
 			cmd.Flags().String("editor", "", "")
-			cmd.Flags().Bool("fix", false, "")
+			cmd.Flags().String("fix", "", "")
+			cmd.Flags().Lookup("fix").NoOptDefVal = "true"
 			cmd.Flags().String("fix-file", "", "")
+			cmd.Flags().String("fix-cmd", "", "")
+			cmd.Flags().String("fix-source", "", "")
+			cmd.Flags().StringArray("task", []string{}, "")
+			cmd.Flags().Bool("no-editor-wait", false, "")
+			cmd.Flags().Bool("preview", false, "")
+			cmd.Flags().Bool("minimal", false, "")
+			cmd.Flags().Bool("no-minimal", false, "")
+			cmd.Flags().Bool("ci", false, "")
+			cmd.Flags().Bool("no-update-check", false, "")
+			cmd.Flags().Duration("timeout", 0, "")
+			cmd.Flags().Bool("no-fix-files", false, "")
+			cmd.Flags().Bool("no-redact", false, "")
 			cmd.Flags().BoolP("numbers", "n", false, "")
 			cmd.Flags().BoolP("clipboard", "b", false, "")
 			cmd.Flags().BoolP("interactive", "i", false, "")
+			cmd.Flags().Int("budget", 0, "")
+			cmd.Flags().Int("wrap", 0, "")
+			cmd.Flags().String("format", "", "")
+			cmd.Flags().String("schema", "", "")
+			cmd.Flags().Bool("debug", false, "")
+			cmd.Flags().BoolP("quiet", "q", false, "")
+			cmd.Flags().StringArray("set", []string{}, "")
+			cmd.Flags().Bool("cache", false, "")
 			
 			// Set flag values
 			for flag, value := range tt.flags {
@@ -164,6 +315,10 @@ func TestBuildRequestFromFlags(t *testing.T) {
 				t.Errorf("BasePrompt = %q, expected %q", result.BasePrompt, tt.expected.BasePrompt)
 			}
 			
+			if result.ReadStdin != tt.expected.ReadStdin {
+				t.Errorf("ReadStdin = %v, expected %v", result.ReadStdin, tt.expected.ReadStdin)
+			}
+
 			if result.PreTemplate != tt.expected.PreTemplate {
 				t.Errorf("PreTemplate = %q, expected %q", result.PreTemplate, tt.expected.PreTemplate)
 			}
@@ -180,6 +335,20 @@ func TestBuildRequestFromFlags(t *testing.T) {
 				t.Errorf("FixMode = %v, expected %v", result.FixMode, tt.expected.FixMode)
 			}
 			
+			if result.FixSource != tt.expected.FixSource {
+				t.Errorf("FixSource = %q, expected %q", result.FixSource, tt.expected.FixSource)
+			}
+			if result.FixTemplate != tt.expected.FixTemplate {
+				t.Errorf("FixTemplate = %q, expected %q", result.FixTemplate, tt.expected.FixTemplate)
+			}
+
+			if result.FixCommand != tt.expected.FixCommand {
+				t.Errorf("FixCommand = %q, expected %q", result.FixCommand, tt.expected.FixCommand)
+			}
+			if !slices.Equal(result.FixTasks, tt.expected.FixTasks) {
+				t.Errorf("FixTasks = %v, expected %v", result.FixTasks, tt.expected.FixTasks)
+			}
+			
 			if result.NumberSelect != tt.expected.NumberSelect {
 				t.Errorf("NumberSelect = %v, expected %v", result.NumberSelect, tt.expected.NumberSelect)
 			}
@@ -199,4 +368,33 @@ func TestBuildRequestFromFlags(t *testing.T) {
 	}
 }
 
-// TestValidateRequest removed - validation is now handled by the orchestrator
\ No newline at end of file
+// TestValidateRequest removed - validation is now handled by the orchestrator
+func TestRedactedCommandLine(t *testing.T) {
+	cmd := &cobra.Command{Use: "prompter"}
+	cmd.Flags().String("pre", "", "")
+	cmd.Flags().Bool("fix", false, "")
+	cmd.Flags().String("target", "", "")
+	cmd.Flags().StringArray("set", nil, "")
+	cmd.Flags().String("fix-cmd", "", "")
+	cmd.Flags().StringArray("task", nil, "")
+	cmd.Flags().String("pre-inline", "", "")
+	cmd.Flags().Set("pre", "review")
+	cmd.Flags().Set("fix", "true")
+	cmd.Flags().Set("target", "stdout")
+	cmd.Flags().Set("set", "github_token=ghp_secret")
+	cmd.Flags().Set("fix-cmd", "curl -H 'Authorization: Bearer secret' localhost")
+	cmd.Flags().Set("task", "deploy=TOKEN=secret make deploy")
+	cmd.Flags().Set("pre-inline", "do not log this either")
+
+	got := redactedCommandLine(cmd, []string{"do not log this"})
+	want := []string{"prompter", "--fix", "--fix-cmd=<redacted>", "--pre=review", "--pre-inline=<redacted>", "--set=<redacted>", "--target=stdout", "--task=<redacted>", "<prompt>"}
+	if len(got) != len(want) {
+		t.Fatalf("redactedCommandLine() = %v, expected %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("redactedCommandLine() = %v, expected %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// aliasTemplateFile is the template in the prompts location that replaces defaultAliases
+const aliasTemplateFile = "aliases.sh"
+
+// Lines around the aliases block in a shell rc file
+const (
+	aliasBlockStart = "# >>> prompter aliases >>>"
+	aliasBlockEnd   = "# <<< prompter aliases <<<"
+)
+
+// defaultAliases are the aliases installed when the prompts location has no aliases.sh. It is
+// a Go template; .Shell is zsh, bash, or fish. They're aliases rather than functions so zsh and
+// fish complete the flags that follow them with prompter's completion.
+const defaultAliases = `{{- $sep := "=" }}{{ if eq .Shell "fish" }}{{ $sep = " " }}{{ end -}}
+# Fix the last command's output without asking questions
+alias pf{{ $sep }}'prompter --fix -y'
+# Ask for a commit message for the staged changes
+alias pc{{ $sep }}'git diff --cached | prompter -y "Write a commit message for this change" -'
+`
+
+// aliasData is what an aliases template is rendered with
+type aliasData struct {
+	Shell string
+}
+
+// ShellAliases renders the aliases for shell from the prompts location's aliases.sh, or the
+// built-in aliases when there is none
+func ShellAliases(promptsLocation, shell string) (string, error) {
+	if !isHookShell(shell) {
+		return "", fmt.Errorf("unsupported shell %q: must be one of %s", shell, strings.Join(HookShells, ", "))
+	}
+
+	source := defaultAliases
+	path := filepath.Join(promptsLocation, aliasTemplateFile)
+	if content, err := os.ReadFile(path); err == nil {
+		source = string(content)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", contractPath(path), err)
+	}
+
+	tmpl, err := template.New(aliasTemplateFile).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", aliasTemplateFile, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, aliasData{Shell: shell}); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", aliasTemplateFile, err)
+	}
+	return strings.TrimSpace(out.String()) + "\n", nil
+}
+
+// isHookShell reports whether shell is one of HookShells
+func isHookShell(shell string) bool {
+	for _, name := range HookShells {
+		if shell == name {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasBlock wraps aliases in the markers that let uninstall find them again
+func aliasBlock(aliases string) string {
+	return aliasBlockStart + "\n# Managed by 'prompter alias install'; remove with 'prompter alias uninstall'\n" +
+		aliases + aliasBlockEnd + "\n"
+}
+
+// replaceAliasBlock returns rc with its aliases block replaced by block, or block appended
+// when there is none. An empty block removes it. found reports whether rc had one.
+func replaceAliasBlock(rc, block string) (updated string, found bool) {
+	start := strings.Index(rc, aliasBlockStart)
+	end := strings.Index(rc, aliasBlockEnd)
+	if start < 0 || end < start {
+		if block == "" {
+			return rc, false
+		}
+		if rc != "" && !strings.HasSuffix(rc, "\n") {
+			rc += "\n"
+		}
+		if rc != "" {
+			rc += "\n"
+		}
+		return rc + block, false
+	}
+
+	end += len(aliasBlockEnd)
+	if end < len(rc) && rc[end] == '\n' {
+		end++
+	}
+	before, after := rc[:start], rc[end:]
+	if block == "" {
+		// Drop the blank line install put before the block
+		before = strings.TrimSuffix(before, "\n")
+		if !strings.HasSuffix(before, "\n") && before != "" {
+			before += "\n"
+		}
+	}
+	return before + block + after, true
+}
+
+// defaultShell returns the shell named by $SHELL, or "" when it isn't one aliases support
+func defaultShell() string {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if isHookShell(shell) {
+		return shell
+	}
+	return ""
+}
+
+// shellRCFile returns the startup file the aliases go in for shell
+func shellRCFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	switch shell {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	default:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "config.fish"), nil
+	}
+}
+
+// resolveAliasShell returns shell, or the shell $SHELL names when it is empty
+func resolveAliasShell(shell string) (string, error) {
+	if shell == "" {
+		if shell = defaultShell(); shell == "" {
+			return "", fmt.Errorf("can't tell the shell from $SHELL; pass --shell (%s)", strings.Join(HookShells, ", "))
+		}
+	}
+	if !isHookShell(shell) {
+		return "", fmt.Errorf("unsupported shell %q: must be one of %s", shell, strings.Join(HookShells, ", "))
+	}
+	return shell, nil
+}
+
+// resolveAliasTarget fills in the shell from $SHELL and the rc file from the shell
+func resolveAliasTarget(shell, rcFile string) (string, string, error) {
+	shell, err := resolveAliasShell(shell)
+	if err != nil {
+		return "", "", err
+	}
+	if rcFile == "" {
+		if rcFile, err = shellRCFile(shell); err != nil {
+			return "", "", err
+		}
+	}
+	return shell, rcFile, nil
+}
+
+// PrintShellAliases prints the aliases install would write for shell
+func PrintShellAliases(request *models.PromptRequest, shell string) error {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	if shell, err = resolveAliasShell(shell); err != nil {
+		return err
+	}
+
+	aliases, err := ShellAliases(cfg.PromptsLocation, shell)
+	if err != nil {
+		return err
+	}
+	fmt.Print(aliases)
+	return nil
+}
+
+// InstallShellAliases writes the aliases into the shell's rc file, replacing ones installed
+// before. rcFile overrides the shell's usual startup file.
+func InstallShellAliases(request *models.PromptRequest, shell, rcFile string) error {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	if shell, rcFile, err = resolveAliasTarget(shell, rcFile); err != nil {
+		return err
+	}
+
+	aliases, err := ShellAliases(cfg.PromptsLocation, shell)
+	if err != nil {
+		return err
+	}
+
+	content, mode, err := readRCFile(rcFile)
+	if err != nil {
+		return err
+	}
+	updated, found := replaceAliasBlock(content, aliasBlock(aliases))
+	if err := os.MkdirAll(filepath.Dir(rcFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(rcFile, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", contractPath(rcFile), err)
+	}
+
+	verb := "Installed"
+	if found {
+		verb = "Updated"
+	}
+	fmt.Printf("%s prompter aliases in %s; open a new shell or source it to use them\n", verb, contractPath(rcFile))
+	return nil
+}
+
+// UninstallShellAliases removes the aliases block from the shell's rc file
+func UninstallShellAliases(shell, rcFile string) error {
+	_, rcFile, err := resolveAliasTarget(shell, rcFile)
+	if err != nil {
+		return err
+	}
+
+	content, mode, err := readRCFile(rcFile)
+	if err != nil {
+		return err
+	}
+	updated, found := replaceAliasBlock(content, "")
+	if !found {
+		fmt.Printf("No prompter aliases in %s\n", contractPath(rcFile))
+		return nil
+	}
+	if err := os.WriteFile(rcFile, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", contractPath(rcFile), err)
+	}
+	fmt.Printf("Removed prompter aliases from %s\n", contractPath(rcFile))
+	return nil
+}
+
+// readRCFile returns an rc file's content and permissions; a missing file is empty
+func readRCFile(path string) (string, os.FileMode, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", 0644, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", contractPath(path), err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", contractPath(path), err)
+	}
+	return string(content), info.Mode().Perm(), nil
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellAliases(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := map[string]string{
+		"zsh":  "alias pf='prompter --fix -y'",
+		"bash": "alias pf='prompter --fix -y'",
+		"fish": "alias pf 'prompter --fix -y'",
+	}
+	for shell, want := range tests {
+		aliases, err := ShellAliases(dir, shell)
+		if err != nil {
+			t.Fatalf("ShellAliases(%s) error: %v", shell, err)
+		}
+		if !strings.Contains(aliases, want) {
+			t.Errorf("ShellAliases(%s) = %q, want it to contain %q", shell, aliases, want)
+		}
+	}
+
+	if _, err := ShellAliases(dir, "tcsh"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+
+	// aliases.sh in the prompts location replaces the built-in aliases
+	custom := `alias pr{{ if eq .Shell "fish" }} {{ else }}={{ end }}'prompter -p review'`
+	if err := os.WriteFile(filepath.Join(dir, aliasTemplateFile), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := ShellAliases(dir, "bash")
+	if err != nil {
+		t.Fatalf("ShellAliases() error: %v", err)
+	}
+	if aliases != "alias pr='prompter -p review'\n" {
+		t.Errorf("ShellAliases() with aliases.sh = %q", aliases)
+	}
+}
+
+func TestReplaceAliasBlock(t *testing.T) {
+	block := aliasBlock("alias pf='prompter --fix -y'\n")
+	installed := "export A=1\n\n" + block
+
+	tests := []struct {
+		name      string
+		rc, block string
+		want      string
+		wantFound bool
+	}{
+		{name: "appends to an rc file", rc: "export A=1", block: block, want: installed},
+		{name: "writes an empty rc file", rc: "", block: block, want: block},
+		{name: "replaces an installed block", rc: installed + "export B=2\n", block: aliasBlock("alias x='y'\n"), want: "export A=1\n\n" + aliasBlock("alias x='y'\n") + "export B=2\n", wantFound: true},
+		{name: "removes an installed block", rc: installed, block: "", want: "export A=1\n", wantFound: true},
+		{name: "removing without a block", rc: "export A=1\n", block: "", want: "export A=1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := replaceAliasBlock(tt.rc, tt.block)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("replaceAliasBlock() = %q, %v; want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestUninstallShellAliases(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".bashrc")
+	content := "export A=1\n\n" + aliasBlock("alias pf='prompter --fix -y'\n")
+	if err := os.WriteFile(rc, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UninstallShellAliases("bash", rc); err != nil {
+		t.Fatalf("UninstallShellAliases() error: %v", err)
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "export A=1\n" {
+		t.Errorf("rc file after uninstall = %q", data)
+	}
+	if info, _ := os.Stat(rc); info.Mode().Perm() != 0600 {
+		t.Errorf("uninstall changed the rc file's mode to %v", info.Mode().Perm())
+	}
+}
@@ -1,53 +1,330 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
-	"prompter-cli/internal/interactive"
-	"prompter-cli/internal/interfaces"
-	"prompter-cli/internal/orchestrator"
-	"prompter-cli/internal/template"
-	"prompter-cli/pkg/models"
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/internal/update"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
 )
 
+// Version is the running binary's version, set by main from build flags
+var Version = "dev"
+
+// collectUpdateNotice forwards a finished update check to the warnings channel
+func collectUpdateNotice(notices <-chan string) {
+	select {
+	case notice, ok := <-notices:
+		if ok {
+			warnings.Notice("%s", notice)
+		}
+	default:
+		// Still running; skip rather than delay exit
+	}
+}
+
+// updateCachePath returns where the update check saves its result, "" if there is nowhere
+func updateCachePath() string {
+	dir, err := config.DataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "update-check.json")
+}
+
 // Run executes the main application logic
-func Run(request *models.PromptRequest) error {
+func Run(request *models.PromptRequest) (runErr error) {
+	// The first run from a terminal sets up a config rather than silently using defaults
+	if configPath, ok := shouldOnboard(request); ok {
+		if err := Setup(configPath, false); err != nil {
+			return fmt.Errorf("setup failed: %w", err)
+		}
+	}
+
 	// Create orchestrator first to load configuration
 	orch := orchestrator.New()
+	summary := &runSummary{}
+	orch.AddObserver(summary)
+	if err := orch.SetConfigOverrides(request.ConfigOverrides); err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
 
 	// Load configuration to get the correct prompts location
 	cfg, err := orch.LoadConfiguration(request.ConfigPath)
 	if err != nil {
-		return fmt.Errorf("configuration error: %w", err)
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	// Print collected warnings and notices after the output
+	defer warnings.Flush(os.Stderr)
+
+	// Ctrl-C and --timeout stop the commands and fetches the prompt is waiting on
+	ctx, stop := runContext(request.Timeout)
+	defer stop()
+
+	// Record how the prompt was assembled (never its content) once the run finishes
+	var prompt string
+	if cfg.InvocationLog {
+		defer func() {
+			entry := newInvocationEntry(request, cfg, prompt, runErr)
+			if err := appendInvocationLog(cfg.InvocationLogFile, entry); err != nil {
+				warnings.Add("failed to write invocation log: %v", err)
+			}
+		}()
 	}
 
 	// Resolve interactive mode based on flags and config
 	resolveInteractiveMode(request, cfg)
 
+	// Expand --target aliases from [targets] before the request is validated
+	if err := orch.ResolveTargetAlias(request, cfg); err != nil {
+		return err
+	}
+
+	// --ci never prompts; other runs only prompt when stdin and stdout are a terminal
+	if request.CI {
+		if err := applyCIMode(request, cfg); err != nil {
+			return err
+		}
+	} else {
+		stdinTerminal, stdoutTerminal := terminalAttached()
+		applyTerminalMode(request, cfg, stdinTerminal, stdoutTerminal)
+	}
+
+	// CI runners and containers usually lack a clipboard, an editor, and a usable terminal
+	if reason := minimalReason(request, cfg.MinimalAuto); reason != "" {
+		if err := applyMinimalMode(request, cfg, reason); err != nil {
+			return err
+		}
+	}
+
+	// Check for a newer release while the prompt is assembled; never waits for the result
+	if cfg.UpdateCheck && !request.NoUpdateCheck {
+		notices := update.Start(cfg.UpdateCheckURL, Version, updateCachePath())
+		defer collectUpdateNotice(notices)
+	}
+
+	// Piped input (git diff | prompter "review this" -) joins the prompt or becomes fix content
+	if stdinIsPiped() {
+		// stdin is no longer the terminal, so there is nothing to prompt with
+		request.Interactive = false
+		if !readsPipedStdin(request, os.Stdin) {
+			if request.Debug {
+				fmt.Fprintln(os.Stderr, "debug: stdin: not read with a base prompt given; pass - to read it")
+			}
+		} else if err := applyPipedStdin(request, os.Stdin); err != nil {
+			return err
+		}
+	}
+
+	// Project-local templates are only used once their directory is trusted
+	if err := orch.ApplyWorkspaceTrust(request, cfg); err != nil {
+		return fmt.Errorf("workspace trust: %w", err)
+	}
+
 	// Create interactive prompter with the configured prompts location
 	prompter := interactive.NewPrompter(cfg.PromptsLocation)
+	prompter.SetClipboardHistory(cfg.ClipboardHistoryCommand, cfg.ClipboardHistorySize)
+	prompter.SetBasePromptInput(cfg.BasePromptInput, func(text string) (string, error) {
+		return orch.ComposeInEditor(text, request, cfg)
+	})
+	if cfg.StateFile != "" {
+		prompter.SetTemplateOrder(cfg.TemplateOrder, templateUsageLoader(cfg))
+	}
 
 	// Collect missing inputs interactively if needed
 	if err := prompter.CollectMissingInputs(request); err != nil {
+		if interactive.IsCancelled(err) && request.BasePrompt != "" {
+			return offerPartialPrompt(prompter, assemblePartialPrompt(ctx, orch, request, cfg), err)
+		}
 		return fmt.Errorf("failed to collect inputs: %w", err)
 	}
 
+	// With --cache, an unchanged request reuses the prompt assembled last time
+	var cacheKey string
+	reused := false
+	if request.UseCache {
+		cacheKey, prompt, reused = cachedPrompt(orch, prompter, request, cfg)
+	}
+
 	// Generate the prompt
-	prompt, err := orch.GeneratePrompt(request)
-	if err != nil {
-		return fmt.Errorf("prompt generation failed: %w", err)
+	if !reused {
+		prompt, err = generatePrompt(ctx, orch, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return stoppedError(ctx, request.Timeout)
+			}
+			var prompterErr *orchestrator.PrompterError
+			if errors.As(err, &prompterErr) && prompterErr.Partial != "" {
+				return offerPartialPrompt(prompter, prompterErr.Partial, err)
+			}
+			return fmt.Errorf("prompt generation failed: %w", err)
+		}
+		if cacheKey != "" {
+			if err := orchestrator.SaveCachedPrompt(cfg, cacheKey, prompt); err != nil {
+				warnings.Add("failed to cache the prompt: %v", err)
+			}
+		}
+	}
+
+	// Show the prompt for a last look before it reaches the target
+	if request.Interactive && (request.Preview || cfg.PreviewOutput) {
+		if prompt, err = previewPrompt(ctx, orch, prompter, request, cfg, prompt); err != nil {
+			return err
+		}
 	}
 
 	// Output the prompt
-	if err := orch.OutputPrompt(prompt, request, cfg); err != nil {
+	if err := orch.OutputPrompt(ctx, prompt, request, cfg); err != nil {
+		if ctx.Err() != nil {
+			return stoppedError(ctx, request.Timeout)
+		}
 		return fmt.Errorf("output failed: %w", err)
 	}
 
+	// An active session keeps the prompt for the transcript later prompts include
+	recordSessionPrompt(request, cfg)
+	recordTemplateUsage(request, cfg)
+	recordHistory(orch, request, cfg, prompt)
+
+	if !request.Quiet {
+		summary.Print(os.Stderr, reused)
+	}
+	return nil
+}
+
+// runContext returns the context a run stops with: done on Ctrl-C, or once timeout has passed
+// when it is set. A second Ctrl-C exits at once, for a command that ignores being stopped.
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt)
+	cancel := stopSignals
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		cancel = func() {
+			cancelTimeout()
+			stopSignals()
+		}
+	}
+	context.AfterFunc(ctx, stopSignals)
+	return ctx, cancel
+}
+
+// stoppedError explains why ctx stopped the run: an interrupt, or --timeout passing
+func stoppedError(ctx context.Context, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s (--timeout); nothing was output", timeout)
+	}
+	return fmt.Errorf("interrupted; nothing was output")
+}
+
+// generatePrompt generates the prompt and serializes it in the requested --format
+func generatePrompt(ctx context.Context, orch *orchestrator.Orchestrator, request *models.PromptRequest) (string, error) {
+	result, err := orch.GeneratePrompt(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	return orchestrator.FormatResult(result, request.Format)
+}
+
+// previewPrompt shows prompt until the user sends it on, editing it or rebuilding it with
+// other templates along the way
+func previewPrompt(ctx context.Context, orch *orchestrator.Orchestrator, prompter *interactive.Prompter, request *models.PromptRequest, cfg *interfaces.Config, prompt string) (string, error) {
+	target := request.Target
+	if target == "" {
+		target = "stdout"
+	}
+
+	for {
+		choice, err := prompter.PreviewPrompt(prompt, orchestrator.EstimateTokens(prompt), target, request.NumberSelect)
+		if err != nil {
+			return "", fmt.Errorf("cancelled: %w", err)
+		}
+
+		switch choice {
+		case interactive.PreviewOutput:
+			return prompt, nil
+		case interactive.PreviewEdit:
+			if prompt, err = orch.EditPrompt(prompt, request, cfg); err != nil {
+				return "", err
+			}
+		case interactive.PreviewRepick:
+			if err := prompter.RepickTemplates(request); err != nil {
+				return "", err
+			}
+			if prompt, err = generatePrompt(ctx, orch, request); err != nil {
+				return "", fmt.Errorf("prompt generation failed: %w", err)
+			}
+		default:
+			return "", fmt.Errorf("cancelled: nothing was output")
+		}
+	}
+}
+
+// assemblePartialPrompt builds a prompt from the inputs collected before the user cancelled.
+// Assembling can't capture output, run commands, or fetch anything the user just cancelled:
+// fix mode offers nothing, and requests with such sources offer the base prompt alone.
+func assemblePartialPrompt(ctx context.Context, orch *orchestrator.Orchestrator, request *models.PromptRequest, cfg *interfaces.Config) string {
+	if request.FixMode {
+		return ""
+	}
+	if !assemblesOffline(request, cfg) {
+		return request.BasePrompt
+	}
+	partial := *request
+	partial.Interactive = false
+
+	result, err := orch.GeneratePrompt(ctx, &partial)
+	if err != nil {
+		// Fall back to the raw base prompt; it's what the user typed
+		return request.BasePrompt
+	}
+	return result.Text()
+}
+
+// assemblesOffline reports whether request is assembled from local inputs only, without
+// fetching context, running context pack or template commands, or reading remote files
+func assemblesOffline(request *models.PromptRequest, cfg *interfaces.Config) bool {
+	if len(request.URLs) > 0 || len(request.GitHubRefs) > 0 || len(request.JiraKeys) > 0 || len(request.ContextPacks) > 0 || cfg.AllowTemplateExec {
+		return false
+	}
+	for _, file := range request.Files {
+		if orchestrator.IsRemoteFile(file) {
+			return false
+		}
+	}
+	return true
+}
+
+// offerPartialPrompt asks whether to print partially assembled content after a cancellation
+func offerPartialPrompt(prompter *interactive.Prompter, partial string, cause error) error {
+	if strings.TrimSpace(partial) == "" {
+		return fmt.Errorf("cancelled: %w", cause)
+	}
+
+	confirmed, err := prompter.ConfirmPartialOutput()
+	if err != nil || !confirmed {
+		return fmt.Errorf("cancelled: %w", cause)
+	}
+
+	fmt.Println(partial)
 	return nil
 }
 
@@ -75,166 +352,218 @@ func getDefaultPromptsLocation() string {
 	}
 
 	// Fallback to home directory
-	if home, err := os.UserHomeDir(); err == nil {
-		return filepath.Join(home, ".config", "prompter", "prompts")
+	if dir, err := config.ConfigDir(); err == nil {
+		return filepath.Join(dir, "prompts")
 	}
 
 	// Final fallback
 	return "prompts"
 }
 
-// ListTemplates lists all available prompt templates
-func ListTemplates(request *models.PromptRequest) error {
+// ListOptions controls how ListTemplates prints templates
+type ListOptions struct {
+	Verbose bool // Show the file each name resolves to and the templates it shadows
+	JSON    bool   // Print every template file as JSON, for editor plugins and scripts
+	Type    string // Only pre or post templates; empty lists both
+	Filter  string // Only templates whose name contains this, ignoring case
+}
+
+// templateTypes are the kinds of template, in listing order
+var templateTypes = []string{"pre", "post"}
+
+// filterTemplateFiles keeps the files matching opts, sorted by type and name. Files sharing
+// a name keep their precedence order, so the first is the one the name resolves to.
+func filterTemplateFiles(files []template.TemplateFile, opts ListOptions) []template.TemplateFile {
+	filter := strings.ToLower(opts.Filter)
+	var kept []template.TemplateFile
+	for _, file := range files {
+		if opts.Type != "" && file.Type != opts.Type {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(file.Name), filter) {
+			continue
+		}
+		kept = append(kept, file)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Type != kept[j].Type {
+			return kept[i].Type == "pre"
+		}
+		return strings.ToLower(kept[i].Name) < strings.ToLower(kept[j].Name)
+	})
+	return kept
+}
+
+// listedTemplate is one template file in 'prompter list --json' output
+type listedTemplate struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Namespace   string    `json:"namespace"`
+	Path        string    `json:"path"`
+	Description string    `json:"description"`
+	Modified    time.Time `json:"modified"`
+	Shadowed    bool      `json:"shadowed"` // Another file with this name takes precedence; use namespace/name
+	Deprecated  bool      `json:"deprecated"`
+	ReplacedBy  string    `json:"replaced_by,omitempty"`
+}
+
+// ListTemplates lists all available prompt templates. A name found in several prompt
+// locations resolves to the first in precedence order; verbose shows each name's file and
+// the templates it shadows, which stay reachable as namespace/name.
+func ListTemplates(request *models.PromptRequest, opts ListOptions) error {
+	if opts.Type != "" && opts.Type != "pre" && opts.Type != "post" {
+		return fmt.Errorf("invalid template type: %s (must be 'pre' or 'post')", opts.Type)
+	}
+
 	// Create orchestrator to load configuration
 	orch := orchestrator.New()
 
 	// Load configuration to get the prompts location
 	cfg, err := orch.LoadConfiguration(request.ConfigPath)
 	if err != nil {
-		return fmt.Errorf("configuration error: %w", err)
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
 	}
 
-	// Create template processor to get all prompt locations
-	templateProcessor := orch.GetTemplateProcessor()
-	locations := templateProcessor.GetPromptLocations()
-	customTemplates := templateProcessor.GetCustomTemplates()
+	// Untrusted project-local templates aren't listed, since they won't be used; listing never asks
+	request.Interactive = false
+	if err := orch.ApplyWorkspaceTrust(request, cfg); err != nil {
+		return fmt.Errorf("workspace trust: %w", err)
+	}
+	defer warnings.Flush(os.Stderr)
+
+	processor, ok := orch.GetTemplateProcessor().(*template.Processor)
+	if !ok {
+		return fmt.Errorf("template processor does not support listing")
+	}
 
-	// Display all prompt locations
+	files := filterTemplateFiles(processor.TemplateFiles(), opts)
+	if opts.JSON {
+		return printTemplatesJSON(os.Stdout, files)
+	}
+
+	// Display all prompt locations in precedence order
+	namespaces := make(map[string]template.Namespace)
 	fmt.Printf("Prompt locations:\n")
-	localDisplayed := false
-	for i, location := range locations {
-		displayPath := contractPath(location)
-		
-		// Check if this is a local location
-		if !localDisplayed && i == 0 && len(locations) > 1 && location != cfg.PromptsLocation {
-			fmt.Printf("  - %s (local)\n", displayPath)
-			localDisplayed = true
-			continue
-		}
-		
-		// Check if this is a custom template location
-		isCustom := false
-		customName := ""
-		for name, customTemplate := range customTemplates {
-			if customTemplate.Location == location {
-				isCustom = true
-				customName = name
-				break
-			}
-		}
-		
-		if isCustom {
-			fmt.Printf("  - %s (custom: %s)\n", displayPath, customName)
-		} else {
-			fmt.Printf("  - %s\n", displayPath)
-		}
+	for _, ns := range processor.Namespaces() {
+		namespaces[ns.Name] = ns
+		fmt.Printf("  - %s%s\n", contractPath(ns.Location), namespaceLabel(ns))
 	}
 	fmt.Println()
 
-	// Collect all templates from all locations
-	allPreTemplates := make(map[string]string) // template name -> location
-	allPostTemplates := make(map[string]string)
-
-	for _, location := range locations {
-		// List pre-templates
-		preDir := filepath.Join(location, "pre")
-		preTemplates, err := listTemplatesInDir(preDir)
-		if err == nil {
-			for _, tmpl := range preTemplates {
-				if _, exists := allPreTemplates[tmpl]; !exists {
-					allPreTemplates[tmpl] = location
-				}
-			}
+	// Group the files of each type by name; they're sorted, so groups are in name order
+	byType := map[string][][]template.TemplateFile{}
+	for _, file := range files {
+		groups := byType[file.Type]
+		if n := len(groups); n > 0 && strings.EqualFold(groups[n-1][0].Name, file.Name) {
+			groups[n-1] = append(groups[n-1], file)
+		} else {
+			groups = append(groups, []template.TemplateFile{file})
 		}
+		byType[file.Type] = groups
+	}
 
-		// List post-templates
-		postDir := filepath.Join(location, "post")
-		postTemplates, err := listTemplatesInDir(postDir)
-		if err == nil {
-			for _, tmpl := range postTemplates {
-				if _, exists := allPostTemplates[tmpl]; !exists {
-					allPostTemplates[tmpl] = location
-				}
+	printed := 0
+	for _, templateType := range templateTypes {
+		if opts.Type != "" && templateType != opts.Type {
+			continue
+		}
+		if printed > 0 {
+			fmt.Println()
+		}
+		printed++
+		title := strings.ToUpper(templateType[:1]) + templateType[1:] + "-templates"
+
+		groups := byType[templateType]
+		if len(groups) == 0 {
+			if opts.Filter != "" {
+				fmt.Printf("%s: (none match %q)\n", title, opts.Filter)
+			} else {
+				fmt.Printf("%s: (none found)\n", title)
 			}
+			continue
 		}
-	}
 
-	// Helper function to get template label
-	getTemplateLabel := func(location string) string {
-		// Check if it's local
-		if len(locations) > 1 && location != cfg.PromptsLocation {
-			// Check if it's a custom template
-			for name, customTemplate := range customTemplates {
-				if customTemplate.Location == location {
-					return fmt.Sprintf(" (custom: %s)", name)
+		fmt.Printf("%s:\n", title)
+		for _, matches := range groups {
+			winner := matches[0]
+			if !opts.Verbose {
+				shadowed := ""
+				if len(matches) > 1 {
+					shadowed = fmt.Sprintf(" [shadows %d]", len(matches)-1)
 				}
+				fmt.Printf("  - %s%s%s%s\n", winner.Name, namespaceLabel(namespaces[winner.Namespace]), deprecatedLabel(winner.Path), shadowed)
+				continue
 			}
-			// Check if it's the local prompts location
-			if processor, ok := templateProcessor.(*template.Processor); ok {
-				if location == processor.GetPromptLocations()[0] && location != cfg.PromptsLocation {
-					return " (local)"
-				}
+
+			fmt.Printf("  - %s -> %s%s%s\n", winner.Name, contractPath(winner.Path), namespaceLabel(namespaces[winner.Namespace]), deprecatedLabel(winner.Path))
+			for _, shadowed := range matches[1:] {
+				fmt.Printf("      shadows %s/%s -> %s\n", shadowed.Namespace, shadowed.Name, contractPath(shadowed.Path))
 			}
 		}
-		return ""
 	}
 
-	// Display pre-templates
-	if len(allPreTemplates) == 0 {
-		fmt.Printf("Pre-templates: (none found)\n")
-	} else {
-		fmt.Printf("Pre-templates:\n")
-		for tmpl, location := range allPreTemplates {
-			label := getTemplateLabel(location)
-			fmt.Printf("  - %s%s\n", tmpl, label)
-		}
-	}
+	return nil
+}
 
-	fmt.Println()
+// printTemplatesJSON writes files, in precedence order, as a JSON array
+func printTemplatesJSON(w io.Writer, files []template.TemplateFile) error {
+	listed := make([]listedTemplate, 0, len(files))
+	seen := make(map[string]bool)
+	for _, file := range files {
+		key := file.Type + "/" + strings.ToLower(file.Name)
+		entry := listedTemplate{
+			Name:      file.Name,
+			Type:      file.Type,
+			Namespace: file.Namespace,
+			Path:      file.Path,
+			Shadowed:  seen[key],
+		}
+		seen[key] = true
 
-	// Display post-templates
-	if len(allPostTemplates) == 0 {
-		fmt.Printf("Post-templates: (none found)\n")
-	} else {
-		fmt.Printf("Post-templates:\n")
-		for tmpl, location := range allPostTemplates {
-			label := getTemplateLabel(location)
-			fmt.Printf("  - %s%s\n", tmpl, label)
+		if info, err := os.Stat(file.Path); err == nil {
+			entry.Modified = info.ModTime()
+		}
+		if fm, err := template.ReadFrontMatter(file.Path); err == nil {
+			entry.Description = fm.Description
+			entry.Deprecated = fm.IsDeprecated()
+			entry.ReplacedBy = fm.ReplacedBy
+		} else {
+			warnings.Add("%v", err)
 		}
+		listed = append(listed, entry)
 	}
 
-	return nil
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(listed)
 }
 
-// listTemplatesInDir lists all .md files in a directory
-func listTemplatesInDir(dir string) ([]string, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
+// deprecatedLabel marks a deprecated template in listings, naming its replacement
+func deprecatedLabel(path string) string {
+	fm, err := template.ReadFrontMatter(path)
+	switch {
+	case err != nil || !fm.IsDeprecated():
+		return ""
+	case fm.ReplacedBy != "":
+		return fmt.Sprintf(" [deprecated, use %s]", fm.ReplacedBy)
+	default:
+		return " [deprecated]"
 	}
+}
 
-	var templates []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		// Only include .md files
-		if filepath.Ext(name) == ".md" {
-			// Remove .md extension and .default. prefix if present
-			templateName := name[:len(name)-3] // Remove .md
-
-			// Remove .default. prefix if present
-			if len(templateName) > 9 && templateName[:9] == ".default." {
-				templateName = templateName[9:]
-			}
-
-			templates = append(templates, templateName)
-		}
+// namespaceLabel describes where a namespace comes from in template listings
+func namespaceLabel(ns template.Namespace) string {
+	switch {
+	case ns.Pack:
+		return fmt.Sprintf(" (pack: %s)", ns.Name)
+	case ns.Name == template.NamespaceLocal:
+		return " (local)"
+	case ns.Name == template.NamespaceGlobal:
+		return " (global)"
+	default:
+		return fmt.Sprintf(" (custom: %s)", ns.Name)
 	}
-
-	return templates, nil
 }
 
 // contractPath converts a full path back to use ~ for the home directory
@@ -261,19 +590,36 @@ func contractPath(path string) string {
 	return path
 }
 // AddTemplate adds a new prompt template
-func AddTemplate(request *models.PromptRequest, content, preName, postName string, fromClipboard, overwrite bool) error {
+func AddTemplate(request *models.PromptRequest, content, preName, postName, scaffold string, fromClipboard, overwrite, encrypt bool) error {
 	// Create orchestrator to load configuration
 	orch := orchestrator.New()
 
 	// Load configuration to get the prompts location
 	cfg, err := orch.LoadConfiguration(request.ConfigPath)
 	if err != nil {
-		return fmt.Errorf("configuration error: %w", err)
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
 	}
 
 	// Resolve interactive mode based on flags and config
 	resolveInteractiveMode(request, cfg)
 
+	// A scaffold replaces the content; fix.md lives at the prompts root, outside pre/ and post/
+	var scaffoldText string
+	if scaffold != "" {
+		if content != "" || fromClipboard {
+			return fmt.Errorf("cannot combine --scaffold with content or --clipboard")
+		}
+		if scaffold == ScaffoldFix {
+			if preName != "" || postName != "" || encrypt {
+				return fmt.Errorf("the fix scaffold writes fix.md and can't be combined with --pre, --post, or --encrypt")
+			}
+			return addFixScaffold(request, cfg, overwrite)
+		}
+		if scaffoldText, err = scaffoldContent(scaffold); err != nil {
+			return err
+		}
+	}
+
 	// Determine template type and name
 	var templateType, templateName string
 	
@@ -309,7 +655,9 @@ func AddTemplate(request *models.PromptRequest, content, preName, postName strin
 
 	// Get content
 	var templateContent string
-	if fromClipboard {
+	if scaffold != "" {
+		templateContent = scaffoldText
+	} else if fromClipboard {
 		// Get content from clipboard
 		templateContent, err = getClipboardContent()
 		if err != nil {
@@ -328,21 +676,14 @@ func AddTemplate(request *models.PromptRequest, content, preName, postName strin
 		}
 	}
 
-	// Create the template file
+	// Check if file already exists, plain or encrypted
 	templateDir := filepath.Join(cfg.PromptsLocation, templateType)
-	if err := os.MkdirAll(templateDir, 0755); err != nil {
-		return fmt.Errorf("failed to create template directory: %w", err)
-	}
-
-	templatePath := filepath.Join(templateDir, templateName+".md")
-	
-	// Check if file already exists
-	if _, err := os.Stat(templatePath); err == nil {
+	if existingPath := existingTemplatePath(templateDir, templateName); existingPath != "" {
 		if overwrite {
 			// --overwrite flag is set, proceed without prompting
 		} else if request.Interactive {
 			prompter := interactive.NewPrompter(cfg.PromptsLocation)
-			shouldOverwrite, err := prompter.ConfirmOverwrite(templatePath)
+			shouldOverwrite, err := prompter.ConfirmOverwrite(existingPath)
 			if err != nil {
 				return fmt.Errorf("failed to get overwrite confirmation: %w", err)
 			}
@@ -351,19 +692,69 @@ func AddTemplate(request *models.PromptRequest, content, preName, postName strin
 				return nil
 			}
 		} else {
-			return fmt.Errorf("template file already exists: %s", contractPath(templatePath))
+			return fmt.Errorf("template file already exists: %s", contractPath(existingPath))
 		}
 	}
 
-	// Write the template file
-	if err := os.WriteFile(templatePath, []byte(templateContent), 0644); err != nil {
-		return fmt.Errorf("failed to write template file: %w", err)
+	templatePath, err := writeTemplate(cfg, templateDir, templateName, templateContent, encrypt)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("Created %s template: %s\n", templateType, contractPath(templatePath))
 	return nil
 }
 
+// writeTemplate saves content as the template name in dir, encrypted with the configured tool
+// when encrypt is set, and returns its path. A plain or encrypted copy it replaces is removed.
+func writeTemplate(cfg *interfaces.Config, dir, name, content string, encrypt bool) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template directory: %w", err)
+	}
+
+	templatePath := filepath.Join(dir, name+".md")
+	tool := cfg.EncryptionTool
+	if tool == "" {
+		tool = template.EncryptionGPG
+	}
+	fileContent := []byte(content)
+	if encrypt {
+		ext, err := template.EncryptedExtension(tool)
+		if err != nil {
+			return "", err
+		}
+		templatePath += ext
+		if fileContent, err = template.EncryptTemplate(fileContent, tool, cfg.EncryptionRecipient); err != nil {
+			return "", fmt.Errorf("failed to encrypt template: %w", err)
+		}
+	}
+	existingPath := existingTemplatePath(dir, name)
+
+	// Write the template file
+	if err := os.WriteFile(templatePath, fileContent, 0644); err != nil {
+		return "", fmt.Errorf("failed to write template file: %w", err)
+	}
+
+	// Remove the replaced copy when switching between plain and encrypted, so it can't shadow this one
+	if existingPath != "" && existingPath != templatePath {
+		if err := os.Remove(existingPath); err != nil {
+			return "", fmt.Errorf("failed to remove replaced template: %w", err)
+		}
+	}
+	return templatePath, nil
+}
+
+// existingTemplatePath returns the path of a plain or encrypted template named name in dir, or ""
+func existingTemplatePath(dir, name string) string {
+	for _, suffix := range []string{".md", ".md.age", ".md.gpg", ".md.asc"} {
+		path := filepath.Join(dir, name+suffix)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 // getClipboardContent gets content from the system clipboard
 func getClipboardContent() (string, error) {
 	content, err := clipboard.ReadAll()
@@ -380,37 +771,58 @@ func getClipboardContent() (string, error) {
 }
 // OpenPromptsDirectory opens the prompts directory in the configured editor
 func OpenPromptsDirectory(request *models.PromptRequest) error {
+	return OpenInEditor(request, "")
+}
+
+// OpenInEditor opens the file templateName resolves to, or the prompts directory when it is
+// empty, in the editor chosen as for --editor
+func OpenInEditor(request *models.PromptRequest, templateName string) error {
 	// Create orchestrator to load configuration
 	orch := orchestrator.New()
 
 	// Load configuration to get the prompts location and editor
 	cfg, err := orch.LoadConfiguration(request.ConfigPath)
 	if err != nil {
-		return fmt.Errorf("configuration error: %w", err)
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
 	}
 
-	// Check if prompts directory exists
-	if _, err := os.Stat(cfg.PromptsLocation); os.IsNotExist(err) {
-		return fmt.Errorf("prompts directory does not exist: %s", contractPath(cfg.PromptsLocation))
-	}
+	path := cfg.PromptsLocation
+	description := "prompts directory"
+	if templateName != "" {
+		// Resolve the name as a run would, including the trust check for project-local templates
+		resolveInteractiveMode(request, cfg)
+		if err := orch.ApplyWorkspaceTrust(request, cfg); err != nil {
+			return fmt.Errorf("workspace trust: %w", err)
+		}
+		defer warnings.Flush(os.Stderr)
 
-	// Get the editor command
-	editor := cfg.Editor
-	if editor == "" {
-		// Fallback to environment variables
-		if envEditor := os.Getenv("EDITOR"); envEditor != "" {
-			editor = envEditor
-		} else if envEditor := os.Getenv("VISUAL"); envEditor != "" {
-			editor = envEditor
-		} else {
-			return fmt.Errorf("no editor configured. Set 'editor' in config file or EDITOR/VISUAL environment variable")
+		processor, ok := orch.GetTemplateProcessor().(*template.Processor)
+		if !ok {
+			return fmt.Errorf("template processor does not support resolving templates")
 		}
+		if path, err = processor.ResolveTemplate(templateName); err != nil {
+			return err
+		}
+		if template.IsEncrypted(path) {
+			return fmt.Errorf("template %s is encrypted (%s); decrypt it to edit, then re-add it with 'prompter add --encrypt'", templateName, contractPath(path))
+		}
+		description = "template " + templateName
+	}
+
+	// Check if the directory or template exists
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s does not exist: %s", description, contractPath(path))
+	}
+
+	args := strings.Fields(orch.ResolveEditor(request.Editor, cfg.Editor))
+	if len(args) == 0 {
+		return fmt.Errorf("no editor configured. Set 'editor' in config file or EDITOR/VISUAL environment variable")
 	}
 
-	fmt.Printf("Opening prompts directory in %s: %s\n", editor, contractPath(cfg.PromptsLocation))
+	fmt.Printf("Opening %s in %s: %s\n", description, args[0], contractPath(path))
 
 	// Execute the editor command
-	cmd := exec.Command(editor, cfg.PromptsLocation)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
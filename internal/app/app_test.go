@@ -0,0 +1,125 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestPrintTemplatesJSON(t *testing.T) {
+	dir := t.TempDir()
+	described := filepath.Join(dir, "review.md")
+	plain := filepath.Join(dir, "other-review.md")
+	if err := os.WriteFile(described, []byte("---\ndescription: Review a change\n---\nReview {{.Prompt}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(plain, []byte("Review"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []template.TemplateFile{
+		{Name: "review", Namespace: "local", Type: "pre", Path: described},
+		{Name: "Review", Namespace: "global", Type: "pre", Path: plain},
+		{Name: "review", Namespace: "global", Type: "post", Path: plain},
+	}
+
+	var out bytes.Buffer
+	if err := printTemplatesJSON(&out, files); err != nil {
+		t.Fatalf("printTemplatesJSON() error = %v", err)
+	}
+
+	var listed []listedTemplate
+	if err := json.Unmarshal(out.Bytes(), &listed); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(listed) != 3 {
+		t.Fatalf("listed %d templates, want 3", len(listed))
+	}
+	if listed[0].Description != "Review a change" || listed[0].Modified.IsZero() || listed[0].Shadowed {
+		t.Errorf("listed[0] = %+v, want the description, a modified time, and not shadowed", listed[0])
+	}
+	if !listed[1].Shadowed {
+		t.Errorf("listed[1] = %+v, want shadowed by the local pre-template", listed[1])
+	}
+	if listed[2].Shadowed {
+		t.Errorf("listed[2] = %+v, want a post-template not shadowed by a pre-template", listed[2])
+	}
+}
+
+func TestFilterTemplateFiles(t *testing.T) {
+	files := []template.TemplateFile{
+		{Name: "zeta", Namespace: "local", Type: "post"},
+		{Name: "Review", Namespace: "local", Type: "pre"},
+		{Name: "explain", Namespace: "local", Type: "pre"},
+		{Name: "review", Namespace: "global", Type: "pre"},
+		{Name: "alpha", Namespace: "global", Type: "post"},
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{name: "sorted by type then name, precedence kept", want: []string{"local/explain", "local/Review", "global/review", "global/alpha", "local/zeta"}},
+		{name: "type", opts: ListOptions{Type: "post"}, want: []string{"global/alpha", "local/zeta"}},
+		{name: "filter ignores case", opts: ListOptions{Filter: "REV"}, want: []string{"local/Review", "global/review"}},
+		{name: "no match", opts: ListOptions{Type: "post", Filter: "rev"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, file := range filterTemplateFiles(files, tt.opts) {
+				got = append(got, file.Namespace+"/"+file.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterTemplateFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssemblePartialPrompt_SkipsExternalSources(t *testing.T) {
+	cfg := &interfaces.Config{}
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		cfg     *interfaces.Config
+		want    string
+	}{
+		{name: "fix mode", request: &models.PromptRequest{BasePrompt: "fix it", FixMode: true}, want: ""},
+		{name: "urls", request: &models.PromptRequest{BasePrompt: "explain", URLs: []string{"https://example.com"}}, want: "explain"},
+		{name: "github refs", request: &models.PromptRequest{BasePrompt: "explain", GitHubRefs: []string{"org/repo#1"}}, want: "explain"},
+		{name: "jira keys", request: &models.PromptRequest{BasePrompt: "explain", JiraKeys: []string{"ABC-1"}}, want: "explain"},
+		{name: "context packs", request: &models.PromptRequest{BasePrompt: "explain", ContextPacks: []string{"backend"}}, want: "explain"},
+		{name: "remote file", request: &models.PromptRequest{BasePrompt: "explain", Files: []string{"ssh://host/etc/hosts"}}, want: "explain"},
+		{name: "template exec", request: &models.PromptRequest{BasePrompt: "explain"}, cfg: &interfaces.Config{AllowTemplateExec: true}, want: "explain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.cfg != nil {
+				c = tt.cfg
+			}
+			// A nil orchestrator panics if the prompt is generated at all
+			if got := assemblePartialPrompt(t.Context(), nil, tt.request, c); got != tt.want {
+				t.Errorf("assemblePartialPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssemblesOffline(t *testing.T) {
+	request := &models.PromptRequest{BasePrompt: "explain", Files: []string{"main.go"}}
+	if !assemblesOffline(request, &interfaces.Config{}) {
+		t.Error("assemblesOffline() = false for local files, want true")
+	}
+}
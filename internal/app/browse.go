@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// browseTimeout bounds the pack index request
+const browseTimeout = 10 * time.Second
+
+// BrowsePacks lists the packs in the pack_index_url index that match query, most installed
+// first. With install set it installs that pack instead; from a terminal it asks which of
+// the listed packs to install.
+func BrowsePacks(request *models.PromptRequest, query, install string, w io.Writer) error {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	if cfg.PackIndexURL == "" {
+		return fmt.Errorf("pack_index_url is not set; set it to the URL of a pack index to browse")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), browseTimeout)
+	defer cancel()
+	listings, err := template.FetchPackIndex(ctx, cfg.PackIndexURL)
+	if err != nil {
+		return err
+	}
+
+	if install != "" {
+		i := slices.IndexFunc(listings, func(listing template.PackListing) bool { return listing.Name == install })
+		if i < 0 {
+			return fmt.Errorf("pack %s is not in the index; run 'prompter browse' to see what is", install)
+		}
+		return InstallTemplatePack(request, listings[i].URL, listings[i].Name)
+	}
+
+	matches := template.SearchPacks(listings, query)
+	if len(matches) == 0 {
+		if query != "" {
+			fmt.Fprintf(w, "No packs match %q.\n", query)
+		} else {
+			fmt.Fprintln(w, "The index lists no packs.")
+		}
+		return nil
+	}
+
+	installed := template.InstalledPacks(cfg.PromptsLocation)
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, listing := range matches {
+		description := listing.Description
+		if slices.Contains(installed, listing.Name) {
+			description += " (installed)"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", listing.Name, formatInstalls(listing.Installs), description)
+	}
+	table.Flush()
+
+	if request.ForceNonInteractive || !term.IsTerminal(int(syscall.Stdin)) || !term.IsTerminal(int(syscall.Stdout)) {
+		fmt.Fprintln(w, "\nInstall one with 'prompter browse --install <name>'.")
+		return nil
+	}
+
+	var options []string
+	for _, listing := range matches {
+		if !slices.Contains(installed, listing.Name) {
+			options = append(options, listing.Name)
+		}
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	selected, err := interactive.NewPrompter(cfg.PromptsLocation).SelectOption(append(options, "None"), "Install a pack:", "", request.NumberSelect)
+	if err != nil || selected == "None" {
+		return err
+	}
+	listing := matches[slices.IndexFunc(matches, func(listing template.PackListing) bool { return listing.Name == selected })]
+	return InstallTemplatePack(request, listing.URL, listing.Name)
+}
+
+// formatInstalls describes an install count, e.g. "1 install" or "12.3k installs"
+func formatInstalls(n int) string {
+	switch {
+	case n == 1:
+		return "1 install"
+	case n >= 10000:
+		return fmt.Sprintf("%.1fk installs", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d installs", n)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestBrowsePacks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"packs": [
+			{"name": "review-kit", "description": "Code review prompts", "url": "https://github.com/acme/review-kit.git", "installs": 120},
+			{"name": "commits", "description": "Commit messages", "url": "https://github.com/acme/commits.git", "installs": 12500}
+		]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	prompts := filepath.Join(dir, "prompts")
+	if err := os.MkdirAll(filepath.Join(prompts, "packs", "review-kit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	request := models.NewPromptRequest()
+	request.ForceNonInteractive = true
+	request.ConfigPath = filepath.Join(dir, "config.toml")
+	content := "prompts_location = \"" + prompts + "\"\npack_index_url = \"" + server.URL + "/index.json\"\n"
+	if err := os.WriteFile(request.ConfigPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := BrowsePacks(request, "", "", &out); err != nil {
+		t.Fatalf("BrowsePacks() error = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if !strings.HasPrefix(lines[0], "commits") || !strings.Contains(lines[0], "12.5k installs") {
+		t.Errorf("first listing = %q, want commits, the most installed", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "review-kit") || !strings.HasSuffix(lines[1], "Code review prompts (installed)") {
+		t.Errorf("second listing = %q, want review-kit marked installed", lines[1])
+	}
+
+	out.Reset()
+	if err := BrowsePacks(request, "rust", "", &out); err != nil || out.String() != "No packs match \"rust\".\n" {
+		t.Errorf("BrowsePacks(rust) = %q, %v", out.String(), err)
+	}
+	if err := BrowsePacks(request, "", "missing", &out); err == nil || !strings.Contains(err.Error(), "not in the index") {
+		t.Errorf("BrowsePacks(--install missing) error = %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// cachedPrompt looks up the prompt --cache saved for an identical request. It returns the
+// request's cache key, "" when the prompt can't be cached, and the cached prompt when there
+// is one to reuse. Interactive runs ask first; others, such as watch loops, reuse it.
+func cachedPrompt(orch *orchestrator.Orchestrator, prompter *interactive.Prompter, request *models.PromptRequest, cfg *interfaces.Config) (string, string, bool) {
+	key, reason := orch.CacheKey(request, cfg)
+	if key == "" {
+		warnings.Add("--cache: not caching this prompt, it %s", reason)
+		return "", "", false
+	}
+
+	cached, ok := orchestrator.LoadCachedPrompt(cfg, key)
+	if !ok {
+		if request.Debug {
+			fmt.Fprintf(os.Stderr, "debug: cache: no prompt cached for key %s\n", key[:12])
+		}
+		return key, "", false
+	}
+	if request.Debug {
+		fmt.Fprintf(os.Stderr, "debug: cache: reusing the prompt cached at %s\n", cached.CreatedAt.Format(time.RFC3339))
+	}
+	if request.Interactive {
+		reuse, err := prompter.ConfirmCachedPrompt(time.Since(cached.CreatedAt))
+		if err != nil || !reuse {
+			return key, "", false
+		}
+	}
+	return key, cached.Prompt, true
+}
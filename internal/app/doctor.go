@@ -0,0 +1,256 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Results of a doctor check
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "FAIL"
+)
+
+// doctorCheck is one line of 'prompter config doctor' output
+type doctorCheck struct {
+	Status string
+	Name   string
+	Detail string
+	Fix    string // What to do about a warning or failure
+}
+
+// ConfigDoctor checks the config file and the environment prompter runs in, printing a
+// result per check. It fails when any check does.
+func ConfigDoctor(request *models.PromptRequest, w io.Writer) error {
+	checks := doctorChecks(orchestrator.New(), request.ConfigPath)
+
+	failed := 0
+	for _, check := range checks {
+		// Continuation lines, such as one per template error, line up under the first
+		fmt.Fprintf(w, "%-5s %s: %s\n", check.Status, check.Name, strings.ReplaceAll(check.Detail, "\n", "\n      "))
+		if check.Fix != "" {
+			fmt.Fprintf(w, "      %s\n", check.Fix)
+		}
+		if check.Status == checkFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// doctorChecks runs every check against the config at configPath (the default when empty)
+func doctorChecks(orch *orchestrator.Orchestrator, configPath string) []doctorCheck {
+	checks := checkConfigFile(configPath)
+	if configPath == "" {
+		checks = append(checks, checkLegacyDir()...)
+	}
+	cfg, err := orch.LoadConfiguration(configPath)
+	if err != nil {
+		// The other checks need a configuration to look at
+		return append(checks, doctorCheck{checkFail, "configuration", err.Error(), "Fix the value named above; see example-config.toml for what each key accepts."})
+	}
+	detail := "valid"
+	if cfg.Profile != "" {
+		detail = fmt.Sprintf("valid, with profile %s", cfg.Profile)
+	}
+	checks = append(checks, doctorCheck{checkOK, "configuration", detail, ""})
+
+	checks = append(checks, checkPromptLocations(orch, cfg)...)
+	checks = append(checks, checkTemplates(orch, cfg)...)
+	checks = append(checks, checkEditor(orch, cfg))
+	checks = append(checks, checkClipboard(cfg))
+	checks = append(checks, checkFixFile(cfg.FixFile))
+	return checks
+}
+
+// checkConfigFile reports the config file in use and any keys in it prompter doesn't read
+func checkConfigFile(configPath string) []doctorCheck {
+	if configPath == "" {
+		defaultPath, err := config.DefaultConfigPath()
+		if err != nil {
+			return []doctorCheck{{checkFail, "config file", err.Error(), ""}}
+		}
+		configPath = defaultPath
+	}
+	configPath = config.ExpandPath(configPath)
+
+	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+		return []doctorCheck{{checkWarn, "config file", contractPath(configPath) + " not found, using defaults", "Run 'prompter setup' to create one."}}
+	}
+
+	unknown, err := config.UnknownKeys(configPath)
+	if err != nil {
+		return []doctorCheck{{checkFail, "config file", err.Error(), "Fix the TOML syntax error above."}}
+	}
+
+	checks := []doctorCheck{{checkOK, "config file", contractPath(configPath), ""}}
+	for _, key := range unknown {
+		checks = append(checks, doctorCheck{checkWarn, "config key", fmt.Sprintf("unknown key %q isn't a prompter setting; templates can still read it as .Config.%s", key, key),
+			"If it's meant to be a setting, check its spelling against example-config.toml."})
+	}
+	return checks
+}
+
+// checkLegacyDir warns about files left in ~/.config/prompter once the config directory is
+// elsewhere, such as under XDG_CONFIG_HOME
+func checkLegacyDir() []doctorCheck {
+	moves, err := config.LegacyMoves()
+	if err != nil || len(moves) == 0 {
+		return nil
+	}
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = filepath.Base(move.From)
+	}
+	verb := "is"
+	if len(names) > 1 {
+		names[len(names)-1] = "and " + names[len(names)-1]
+		verb = "are"
+	}
+	detail := fmt.Sprintf("%s %s still in %s, the old location", strings.Join(names, ", "), verb, contractPath(filepath.Dir(moves[0].From)))
+	_, configInUse := config.LegacyDirInUse()
+	_, dataInUse := config.LegacyDataInUse()
+	if !configInUse && !dataInUse {
+		detail += ", and not read from there"
+	}
+	return []doctorCheck{{checkWarn, "config location", detail, "Run 'prompter config migrate' to move them to the new location."}}
+}
+
+// checkPromptLocations checks that each prompts location is a readable directory
+func checkPromptLocations(orch *orchestrator.Orchestrator, cfg *interfaces.Config) []doctorCheck {
+	locations := []string{cfg.PromptsLocation}
+	if processor, ok := orch.GetTemplateProcessor().(*template.Processor); ok {
+		locations = processor.GetPromptLocations()
+	}
+
+	var checks []doctorCheck
+	for _, location := range locations {
+		name := "prompts location"
+		entries, err := os.ReadDir(location)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			checks = append(checks, doctorCheck{checkFail, name, contractPath(location) + " does not exist", "Create it, or correct the location in the config."})
+		case err != nil:
+			checks = append(checks, doctorCheck{checkFail, name, err.Error(), "Check the directory's permissions."})
+		default:
+			checks = append(checks, doctorCheck{checkOK, name, fmt.Sprintf("%s (%d entries)", contractPath(location), len(entries)), ""})
+		}
+	}
+	return checks
+}
+
+// checkTemplates parses every template, reporting syntax errors and fields the template data
+// doesn't have. Encrypted templates are skipped, since checking them would ask to decrypt.
+func checkTemplates(orch *orchestrator.Orchestrator, cfg *interfaces.Config) []doctorCheck {
+	processor, ok := orch.GetTemplateProcessor().(*template.Processor)
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, file := range processor.TemplateFiles() {
+		paths = append(paths, file.Path)
+	}
+	fixPrompts, _ := filepath.Glob(filepath.Join(cfg.PromptsLocation, "fix", "*.md"))
+	paths = append(paths, filepath.Join(cfg.PromptsLocation, "fix.md"))
+	paths = append(paths, fixPrompts...)
+
+	var checks []doctorCheck
+	parsed := 0
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil || template.IsEncrypted(path) {
+			continue
+		}
+		if _, err := processor.LoadTemplate(path); err != nil {
+			checks = append(checks, doctorCheck{checkFail, "template", err.Error(), "Fix the template, or run 'prompter open' to edit it."})
+			continue
+		}
+		parsed++
+	}
+	return append(checks, doctorCheck{checkOK, "templates", fmt.Sprintf("%d parsed without errors", parsed), ""})
+}
+
+// checkEditor checks that the editor prompter would open is on PATH
+func checkEditor(orch *orchestrator.Orchestrator, cfg *interfaces.Config) doctorCheck {
+	editor := orch.ResolveEditor("", cfg.Editor)
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return doctorCheck{checkFail, "editor", "no editor configured", "Set editor in the config, or $VISUAL or $EDITOR."}
+	}
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return doctorCheck{checkFail, "editor", fmt.Sprintf("%q not found in PATH", fields[0]), "Install it, or set editor in the config, or $VISUAL or $EDITOR."}
+	}
+	return doctorCheck{checkOK, "editor", fmt.Sprintf("%s (%s)", editor, path), ""}
+}
+
+// checkClipboard reports how a clipboard target would be written here
+func checkClipboard(cfg *interfaces.Config) doctorCheck {
+	backends := orchestrator.AvailableClipboardBackends()
+	switch {
+	case !clipboard.Unsupported:
+		return doctorCheck{checkOK, "clipboard", "system clipboard available", ""}
+	case len(backends) > 0:
+		return doctorCheck{checkOK, "clipboard", "no system clipboard tool, using " + strings.Join(backends, ", "), ""}
+	}
+
+	status := checkWarn
+	if cfg.Target != "clipboard" {
+		// Only matters for runs that ask for the clipboard
+		status = checkOK
+	}
+	return doctorCheck{status, "clipboard", "no clipboard backend available; clipboard output falls back to stdout",
+		"Install wl-copy, xclip, or xsel, run inside tmux, or set target to stdout or a file: target."}
+}
+
+// checkFixFile checks that the shell hook can write captures to fix_file
+func checkFixFile(fixFile string) doctorCheck {
+	if fixFile == "" {
+		return doctorCheck{checkOK, "fix_file", "not set", ""}
+	}
+
+	fix := "Point fix_file at a writable location, or fix the directory's permissions."
+	if file, err := os.OpenFile(fixFile, os.O_WRONLY|os.O_APPEND, 0); err == nil {
+		file.Close()
+		if err := orchestrator.CheckPrivateFile(fixFile); err != nil {
+			return doctorCheck{checkWarn, "fix_file", err.Error() + "; --fix ignores the shell hook's captures in it",
+				"Remove it so the shell hook creates it again with mode 0600, or point fix_file at a directory only you can write."}
+		}
+		return doctorCheck{checkOK, "fix_file", contractPath(fixFile) + " is writable", ""}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return doctorCheck{checkFail, "fix_file", err.Error(), fix}
+	}
+
+	// Not written yet: check that it can be created, without leaving a file behind. The hook
+	// creates missing directories, so probe the nearest one that exists.
+	dir := filepath.Dir(fixFile)
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	probe, err := os.CreateTemp(dir, ".prompter-doctor-*")
+	if err != nil {
+		return doctorCheck{checkFail, "fix_file", fmt.Sprintf("can't create %s: %v", contractPath(fixFile), err), fix}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return doctorCheck{checkOK, "fix_file", contractPath(fixFile) + " can be created", ""}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	dir := t.TempDir()
+	promptsDir := filepath.Join(dir, "prompts")
+	if err := os.MkdirAll(filepath.Join(promptsDir, "pre"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(promptsDir, "pre", "typo.md"), []byte("{{.Filez}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	content := `prompts_location = "` + promptsDir + `"
+editor = "prompter-no-such-editor"
+fix_file = "` + filepath.Join(dir, "state", "fix.txt") + `"
+state_file = "` + filepath.Join(dir, "state.db") + `"
+defualt_pre = "review"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]string{}
+	for _, check := range doctorChecks(orchestrator.New(), configPath) {
+		if check.Status != checkOK || statuses[check.Name] == "" {
+			statuses[check.Name] = check.Status
+		}
+	}
+	want := map[string]string{
+		"config file":      checkOK,
+		"config key":       checkWarn,
+		"configuration":    checkOK,
+		"prompts location": checkOK,
+		"template":         checkFail,
+		"editor":           checkFail,
+		"fix_file":         checkOK,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("check %q = %q, want %q", name, statuses[name], status)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "state")); !os.IsNotExist(err) {
+		t.Error("the fix_file check left a file behind")
+	}
+}
+
+func TestDoctorChecks_InvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("directory_strategy = \"everything\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := doctorChecks(orchestrator.New(), configPath)
+	last := checks[len(checks)-1]
+	if last.Name != "configuration" || last.Status != checkFail {
+		t.Errorf("last check = %+v, want a failed configuration check", last)
+	}
+}
+
+func TestConfigDoctor(t *testing.T) {
+	dir := t.TempDir()
+	request := models.NewPromptRequest()
+	request.ConfigPath = filepath.Join(dir, "config.toml")
+	// prompts_location is created when missing; other locations aren't
+	content := "prompts_location = \"" + filepath.Join(dir, "prompts") + "\"\n\n[custom_template.team]\nlocation = \"" + filepath.Join(dir, "missing") + "\"\n"
+	if err := os.WriteFile(request.ConfigPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := ConfigDoctor(request, &out); err == nil {
+		t.Error("expected an error when a prompts location is missing")
+	}
+	if !strings.Contains(out.String(), "FAIL  prompts location:") {
+		t.Errorf("output missing the failed check:\n%s", out.String())
+	}
+}
+
+func TestCheckFixFile_Shared(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes")
+	}
+	fixFile := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ make\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if check := checkFixFile(fixFile); check.Status != checkWarn {
+		t.Errorf("checkFixFile() of a shared file = %+v, want a warning", check)
+	}
+	if err := os.Chmod(fixFile, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if check := checkFixFile(fixFile); check.Status != checkOK {
+		t.Errorf("checkFixFile() of a private file = %+v, want ok", check)
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// secretKeys are config keys whose values config explain doesn't print
+var secretKeys = map[string]bool{"github_token": true, "jira_token": true}
+
+// keyNotes name what replaces a config key outside the config layers: a run's own flags, or
+// environment variables read in its place
+var keyNotes = map[string]string{
+	"target":              "A run's --target or --pipe-to replaces it.",
+	"editor":              "A run's --editor replaces it.",
+	"editor_wait":         "A run's --no-editor-wait turns it off.",
+	"default_pre":         "A run's --pre or --pre-inline replaces it.",
+	"default_post":        "A run's --post or --post-inline replaces it.",
+	"fix_file":            "A run's --fix-file replaces it.",
+	"fix_source":          "A run's --fix-source or --fix-cmd replaces it.",
+	"fix_embed_files":     "A run's --no-fix-files turns off attaching files.",
+	"preview_output":      "A run's --preview turns it on.",
+	"interactive_default": "A run's -i or -y replaces it.",
+	"minimal_auto":        "A run's --minimal or --no-minimal replaces it.",
+	"token_budget":        "A run's --budget replaces it.",
+	"github_token":        "GITHUB_TOKEN or GH_TOKEN, when set, is used instead.",
+	"jira_token":          "JIRA_API_TOKEN, when set, is used instead.",
+}
+
+// ConfigExplain writes the resolved value of a config key to w, with the layer that supplied
+// it and the lower layers it overrides
+func ConfigExplain(request *models.PromptRequest, key string, w io.Writer) error {
+	orch := orchestrator.New()
+	if err := orch.SetConfigOverrides(request.ConfigOverrides); err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	if _, err := orch.LoadConfiguration(request.ConfigPath); err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	value, layers, err := orch.ExplainConfig(key)
+	if err != nil {
+		return err
+	}
+
+	key = strings.ToLower(strings.TrimSpace(key))
+	secret := secretKeys[key]
+	fmt.Fprintf(w, "%s = %s\n", key, explainValue(value, secret))
+	for i, layer := range layers {
+		if i == 0 {
+			fmt.Fprintf(w, "  from %s\n", layerName(layer))
+			continue
+		}
+		fmt.Fprintf(w, "  overrides %s: %s\n", layerName(layer), explainValue(layer.Value, secret))
+	}
+	if len(layers) == 0 {
+		fmt.Fprintln(w, "  not set by any layer")
+	}
+	if note := keyNotes[key]; note != "" {
+		fmt.Fprintln(w, note)
+	}
+	return nil
+}
+
+// layerName describes a layer, e.g. "environment PROMPTER_TARGET"
+func layerName(layer config.Layer) string {
+	if layer.Detail == "" {
+		return layer.Source
+	}
+	if layer.Source == config.SourceFile {
+		return layer.Source + " " + contractPath(layer.Detail)
+	}
+	return layer.Source + " " + layer.Detail
+}
+
+// explainValue formats a config value, hiding secrets
+func explainValue(value interface{}, secret bool) string {
+	if s, ok := value.(string); ok {
+		if secret && s != "" {
+			return "(set, hidden)"
+		}
+		return fmt.Sprintf("%q", s)
+	}
+	if value == nil {
+		return "(unset)"
+	}
+	return fmt.Sprintf("%v", value)
+}
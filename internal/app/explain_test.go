@@ -0,0 +1,39 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestConfigExplain(t *testing.T) {
+	dir := t.TempDir()
+	request := models.NewPromptRequest()
+	request.ConfigPath = filepath.Join(dir, "config.toml")
+	request.ConfigOverrides = []string{"target=stdout"}
+	content := "prompts_location = \"" + filepath.Join(dir, "prompts") + "\"\ntarget = \"tmux\"\ngithub_token = \"ghp_secret\"\n"
+	if err := os.WriteFile(request.ConfigPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := ConfigExplain(request, "target", &out); err != nil {
+		t.Fatalf("ConfigExplain() error = %v", err)
+	}
+	want := "target = \"stdout\"\n  from flag --set target\n  overrides config file " + request.ConfigPath + ": \"tmux\"\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("ConfigExplain() = %q, want it to start with %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := ConfigExplain(request, "github_token", &out); err != nil {
+		t.Fatalf("ConfigExplain() error = %v", err)
+	}
+	if strings.Contains(out.String(), "ghp_secret") {
+		t.Errorf("ConfigExplain() printed a token:\n%s", out.String())
+	}
+}
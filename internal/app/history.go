@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// historyPromptWidth is how much of a base prompt 'prompter history' shows, in characters
+const historyPromptWidth = 60
+
+// recordHistory adds a prompt that was output, and the fix capture it was assembled from, to
+// the history in state_file
+func recordHistory(orch *orchestrator.Orchestrator, request *models.PromptRequest, cfg *interfaces.Config, prompt string) {
+	if !cfg.History || cfg.StateFile == "" {
+		return
+	}
+	cwd, _ := os.Getwd()
+	entry := interfaces.HistoryEntry{
+		Time:   time.Now(),
+		Dir:    cwd,
+		Prompt: request.BasePrompt,
+		Pre:    request.PreTemplate,
+		Post:   request.PostTemplate,
+		Files:  request.Files,
+		Fix:    request.FixMode,
+		Target: request.Target,
+		Tokens: orchestrator.EstimateTokens(prompt),
+	}
+	if err := orchestrator.RecordHistory(cfg, entry); err != nil {
+		warnings.Add("failed to record history: %v", err)
+	}
+
+	// A capture reused with --fix-source last is already logged
+	if capture, ok := orch.LastCapture(); ok && capture.Source != orchestrator.FixSourceLast {
+		if err := orchestrator.RecordCapture(cfg, capture); err != nil {
+			warnings.Add("failed to log the fix capture: %v", err)
+		}
+	}
+}
+
+// ShowHistory writes the last limit prompts output, newest first, or with captures the last
+// limit fix captures
+func ShowHistory(request *models.PromptRequest, captures bool, limit int, w io.Writer) error {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	if captures {
+		entries, err := orchestrator.LoadCaptures(cfg)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(w, historyEmptyMessage(cfg, "No fix captures logged yet."))
+			return nil
+		}
+		for i := len(entries) - 1; i >= max(len(entries)-limit, 0); i-- {
+			entry := entries[i]
+			fmt.Fprintf(w, "%s  %s  %s  %s  (exit %d)\n", entry.Time.Format("2006-01-02 15:04"), contractPath(entry.Dir), entry.Source, historyText(entry.Command), entry.ExitCode)
+		}
+		return nil
+	}
+
+	entries, err := orchestrator.LoadHistory(cfg)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, historyEmptyMessage(cfg, "No prompts in the history yet."))
+		return nil
+	}
+	for i := len(entries) - 1; i >= max(len(entries)-limit, 0); i-- {
+		entry := entries[i]
+		var parts []string
+		if entry.Fix {
+			parts = append(parts, "fix")
+		}
+		if entry.Pre != "" {
+			parts = append(parts, "pre:"+entry.Pre)
+		}
+		if entry.Post != "" {
+			parts = append(parts, "post:"+entry.Post)
+		}
+		if entry.Prompt != "" {
+			parts = append(parts, historyText(entry.Prompt))
+		}
+		if len(entry.Files) > 0 {
+			parts = append(parts, fmt.Sprintf("+%d files", len(entry.Files)))
+		}
+		fmt.Fprintf(w, "%s  %s  %s  (%d tokens)\n", entry.Time.Format("2006-01-02 15:04"), contractPath(entry.Dir), strings.Join(parts, "  "), entry.Tokens)
+	}
+	return nil
+}
+
+// historyEmptyMessage returns message, noting when history is turned off
+func historyEmptyMessage(cfg *interfaces.Config, message string) string {
+	if !cfg.History {
+		return message + " History is off; set history = true to keep it."
+	}
+	return message
+}
+
+// historyText returns the first line of text, cut to historyPromptWidth characters
+func historyText(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(line); len(runes) > historyPromptWidth {
+		return string(runes[:historyPromptWidth-3]) + "..."
+	}
+	return line
+}
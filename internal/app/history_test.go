@@ -0,0 +1,71 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestShowHistory(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "state.db")
+	configPath := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configPath, []byte("state_file = \""+stateFile+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	request := models.NewPromptRequest()
+	request.ConfigPath = configPath
+
+	var out bytes.Buffer
+	if err := ShowHistory(request, false, 20, &out); err != nil {
+		t.Fatalf("ShowHistory() error: %v", err)
+	}
+	if !strings.Contains(out.String(), "No prompts") {
+		t.Errorf("ShowHistory() with no history = %q", out.String())
+	}
+
+	cfg := &interfaces.Config{StateFile: stateFile}
+	for _, entry := range []interfaces.HistoryEntry{
+		{Dir: dir, Prompt: "first", Tokens: 3},
+		{Dir: dir, Pre: "review", Prompt: "explain the race\nin detail", Files: []string{"a.go", "b.go"}, Tokens: 120},
+	} {
+		if err := orchestrator.RecordHistory(cfg, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := orchestrator.RecordCapture(cfg, interfaces.CaptureEntry{Dir: dir, Source: "command", Command: "go vet", ExitCode: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := ShowHistory(request, false, 1, &out); err != nil {
+		t.Fatalf("ShowHistory() error: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "pre:review  explain the race  +2 files  (120 tokens)") || strings.Contains(got, "first") {
+		t.Errorf("ShowHistory(limit 1) = %q, want only the newest prompt", got)
+	}
+
+	out.Reset()
+	if err := ShowHistory(request, true, 20, &out); err != nil {
+		t.Fatalf("ShowHistory(captures) error: %v", err)
+	}
+	if !strings.Contains(out.String(), "command  go vet  (exit 1)") {
+		t.Errorf("ShowHistory(captures) = %q", out.String())
+	}
+}
+
+func TestHistoryText(t *testing.T) {
+	long := strings.Repeat("é", historyPromptWidth+10)
+	if got := historyText(long); len([]rune(got)) != historyPromptWidth || !strings.HasSuffix(got, "...") {
+		t.Errorf("historyText() = %q, want %d characters ending in ...", got, historyPromptWidth)
+	}
+	if got := historyText("  one\ntwo"); got != "one" {
+		t.Errorf("historyText() = %q, want the first line", got)
+	}
+}
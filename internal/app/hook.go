@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// hookMaxLines caps how much of a command's output the hook keeps in the fix file
+const hookMaxLines = 2000
+
+// hookSkipCommands are full-screen or interactive programs whose output shouldn't be teed:
+// editors, pagers, and REPLs, and tools that open them, such as git for commit messages and
+// rebase todo lists
+const hookSkipCommands = "prompter|vi|vim|nvim|nano|emacs|less|more|man|top|htop|btop|ssh|mosh|tmux|screen|fzf|watch|" +
+	"git|tig|lazygit|sudo|su|bash|zsh|fish|sh|python|python3|ipython|node|irb|psql|mysql|sqlite3|gdb|docker|kubectl"
+
+// hookLimitations is the note at the top of the zsh and bash hooks about what teeing changes
+const hookLimitations = `# Captured commands write to a pipe rather than the terminal, so some drop colors or skip
+# their pager. Programs that need the terminal (editors, pagers, git, sudo, REPLs, and the
+# others listed in _prompter_preexec) run uncaptured.`
+
+// HookShells lists the shells `prompter hook` can generate a snippet for
+var HookShells = []string{"zsh", "bash", "fish"}
+
+// zshHook tees each command's output through a process substitution between preexec and precmd
+const zshHook = `# prompter shell hook for zsh: captures each command's output for 'prompter --fix'
+# Install: add 'eval "$(prompter hook zsh)"' to ~/.zshrc
+%LIMITATIONS%
+export PROMPTER_HOOK=zsh
+_prompter_fix_file=%FIX_FILE%
+_prompter_capture="${_prompter_fix_file}.capture"
+_prompter_cmd=
+# Captures are private: prompter ignores a fix file other users can read or write
+command mkdir -p -m 700 -- "${_prompter_fix_file:h}"
+
+_prompter_preexec() {
+  case "${${1%% *}:t}" in
+    %SKIP%) _prompter_cmd=; return ;;
+  esac
+  _prompter_cmd=$1
+  command rm -f "$_prompter_capture.done"
+  exec {_prompter_stdout}>&1 {_prompter_stderr}>&2
+  exec > >(umask 077; tee "$_prompter_capture"; : >| "$_prompter_capture.done") 2>&1
+}
+
+_prompter_precmd() {
+  local exit_code=$? i
+  [[ -z $_prompter_cmd ]] && return
+  exec 1>&$_prompter_stdout 2>&$_prompter_stderr {_prompter_stdout}>&- {_prompter_stderr}>&-
+  # Give tee a moment to flush after its input closes
+  for i in {1..20}; do [[ -e $_prompter_capture.done ]] && break; sleep 0.05; done
+  (umask 077; {
+    print -r -- "\$ $_prompter_cmd"
+    print
+    tail -n %MAX_LINES% "$_prompter_capture"
+    print
+    print -r -- "# Exit code: $exit_code"
+  } >| "$_prompter_fix_file")
+  command rm -f "$_prompter_capture" "$_prompter_capture.done"
+  _prompter_cmd=
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec _prompter_preexec
+add-zsh-hook precmd _prompter_precmd
+`
+
+// bashHook emulates preexec with a DEBUG trap armed once per prompt
+const bashHook = `# prompter shell hook for bash: captures each command's output for 'prompter --fix'
+# Install: add 'eval "$(prompter hook bash)"' to ~/.bashrc
+%LIMITATIONS%
+export PROMPTER_HOOK=bash
+_prompter_fix_file=%FIX_FILE%
+_prompter_capture="${_prompter_fix_file}.capture"
+_prompter_cmd=
+_prompter_armed=
+# Captures are private: prompter ignores a fix file other users can read or write
+command mkdir -p -m 700 -- "$(dirname -- "$_prompter_fix_file")"
+
+_prompter_preexec() {
+  # DEBUG fires for every simple command; only act on the first one after a prompt
+  [[ -n $COMP_LINE || -z $_prompter_armed ]] && return
+  _prompter_armed=
+  # An empty Enter runs only PROMPT_COMMAND, which mustn't replace the last capture
+  [[ $BASH_COMMAND == '_prompter_status=$?' ]] && return
+  local cmd
+  cmd=$(HISTTIMEFORMAT= builtin history 1 | sed 's/^ *[0-9]* *//')
+  local name=${cmd%% *}
+  case "${name##*/}" in
+    %SKIP%) return ;;
+  esac
+  _prompter_cmd=$cmd
+  exec {_prompter_stdout}>&1 {_prompter_stderr}>&2
+  exec > >(umask 077; exec tee "$_prompter_capture") 2>&1
+  _prompter_tee=$!
+}
+
+_prompter_precmd() {
+  local exit_code=$_prompter_status
+  if [[ -n $_prompter_cmd ]]; then
+    exec 1>&$_prompter_stdout 2>&$_prompter_stderr {_prompter_stdout}>&- {_prompter_stderr}>&-
+    wait "$_prompter_tee" 2>/dev/null
+    (umask 077; {
+      printf '$ %s\n\n' "$_prompter_cmd"
+      tail -n %MAX_LINES% "$_prompter_capture"
+      printf '\n# Exit code: %s\n' "$exit_code"
+    } > "$_prompter_fix_file")
+    command rm -f "$_prompter_capture"
+    _prompter_cmd=
+  fi
+  _prompter_armed=1
+}
+
+trap '_prompter_preexec' DEBUG
+PROMPT_COMMAND="_prompter_status=\$?;${PROMPT_COMMAND:+$PROMPT_COMMAND;}_prompter_precmd"
+`
+
+// fishHook records the command and status only: fish can't redirect its own output,
+// so prompter re-runs the recorded command to capture output, once the user confirms
+const fishHook = `# prompter shell hook for fish: records each command for 'prompter --fix'
+# fish can't tee its own output, so prompter re-runs the recorded command to capture it,
+# after asking. Install: add 'prompter hook fish | source' to ~/.config/fish/config.fish
+set -gx PROMPTER_HOOK fish
+set -g _prompter_fix_file %FIX_FILE%
+# prompter only re-runs a command from a fix file that no other user can read or write
+mkdir -p -m 700 -- (path dirname -- $_prompter_fix_file)
+
+function _prompter_postexec --on-event fish_postexec
+    set -l exit_code $status
+    set -l name (string split -m1 ' ' -- $argv[1])[1]
+    if string match -qr '^(%SKIP%)$' -- (path basename -- $name)
+        return
+    end
+    set -l mask (umask)
+    umask 077
+    printf '$ %s\n\n# Exit code: %s\n' $argv[1] $exit_code > $_prompter_fix_file
+    umask $mask
+end
+`
+
+// ShellHook returns the hook snippet for shell, writing captures to fixFile
+func ShellHook(shell, fixFile string) (string, error) {
+	var script string
+	switch shell {
+	case "zsh":
+		script = zshHook
+	case "bash":
+		script = bashHook
+	case "fish":
+		script = fishHook
+	default:
+		return "", fmt.Errorf("unsupported shell %q: must be one of %s", shell, strings.Join(HookShells, ", "))
+	}
+
+	replacer := strings.NewReplacer(
+		"%LIMITATIONS%", hookLimitations,
+		"%FIX_FILE%", quoteShellPath(shell, fixFile),
+		"%MAX_LINES%", strconv.Itoa(hookMaxLines),
+		"%SKIP%", hookSkipCommands,
+	)
+	return replacer.Replace(script), nil
+}
+
+// quoteShellPath single-quotes a path for the target shell
+func quoteShellPath(shell, path string) string {
+	if shell == "fish" {
+		// fish allows \' and \\ inside single quotes
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(path) + "'"
+	}
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// PrintShellHook prints the hook snippet for shell using the configured fix_file
+func PrintShellHook(request *models.PromptRequest, shell string) error {
+	orch := orchestrator.New()
+
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	if cfg.FixFile == "" {
+		return fmt.Errorf("fix_file must be set in the config to use the shell hook")
+	}
+
+	script, err := ShellHook(shell, cfg.FixFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(script)
+	return nil
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellHook(t *testing.T) {
+	for _, shell := range HookShells {
+		t.Run(shell, func(t *testing.T) {
+			script, err := ShellHook(shell, "/tmp/it's fix.txt")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(script, "%FIX_FILE%") || strings.Contains(script, "%SKIP%") || strings.Contains(script, "%MAX_LINES%") {
+				t.Errorf("unreplaced placeholder in %s hook", shell)
+			}
+			if !strings.Contains(script, "PROMPTER_HOOK") {
+				t.Errorf("%s hook should export PROMPTER_HOOK", shell)
+			}
+		})
+	}
+
+	if script, _ := ShellHook("bash", "/tmp/it's fix.txt"); !strings.Contains(script, `_prompter_fix_file='/tmp/it'\''s fix.txt'`) {
+		t.Errorf("bash hook should single-quote the fix file path")
+	}
+
+	// An empty Enter runs only PROMPT_COMMAND, which mustn't count as a command
+	if script, _ := ShellHook("bash", "/tmp/fix.txt"); !strings.Contains(script, `[[ $BASH_COMMAND == '_prompter_status=$?' ]] && return`) {
+		t.Errorf("bash hook should ignore the DEBUG trap for PROMPT_COMMAND")
+	}
+	for _, shell := range []string{"zsh", "bash"} {
+		script, _ := ShellHook(shell, "/tmp/fix.txt")
+		if !strings.Contains(script, "|git|") || !strings.Contains(script, "Captured commands write to a pipe") {
+			t.Errorf("%s hook should skip git and note what teeing changes", shell)
+		}
+	}
+
+	if _, err := ShellHook("tcsh", "/tmp/fix.txt"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// ImportOptions selects the files 'prompter add --from-file/--from-dir' turns into templates
+type ImportOptions struct {
+	Files     []string // Markdown files to import (--from-file)
+	Dir       string   // Directory searched recursively for markdown files (--from-dir)
+	Type      string   // pre or post for every file; otherwise inferred per file
+	Name      string   // Template name, only with a single file
+	Overwrite bool
+	Encrypt   bool
+}
+
+// importFile is a markdown file to import and the template it becomes
+type importFile struct {
+	Path string
+	Name string
+	Type string // Empty when it couldn't be inferred
+}
+
+// ImportTemplates copies markdown files into the prompts location as templates. Each file's
+// type comes from opts.Type, a pre/ or post/ parent directory, or a type in its front matter.
+// Existing templates are replaced with opts.Overwrite or after asking; noninteractive runs
+// skip them. Files that aren't imported are reported, and make the import fail at the end.
+func ImportTemplates(request *models.PromptRequest, opts ImportOptions) error {
+	if opts.Type != "" && opts.Type != "pre" && opts.Type != "post" {
+		return fmt.Errorf("invalid --type %q (use pre or post)", opts.Type)
+	}
+	if opts.Dir != "" && opts.Name != "" {
+		return fmt.Errorf("a template name can't be given with --from-dir; names come from the file names")
+	}
+	if len(opts.Files) > 1 && opts.Name != "" {
+		return fmt.Errorf("a template name can only be given when importing a single file")
+	}
+
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	resolveInteractiveMode(request, cfg)
+
+	files, err := importCandidates(opts)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no markdown files found in %s", opts.Dir)
+	}
+
+	imported, failed := 0, 0
+	for _, file := range files {
+		ok, err := importTemplate(request, cfg, file, opts)
+		if err != nil {
+			fmt.Printf("Skipped %s: %v\n", contractPath(file.Path), err)
+			failed++
+		} else if ok {
+			imported++
+		}
+	}
+
+	fmt.Printf("Imported %d of %d files\n", imported, len(files))
+	if failed > 0 {
+		return fmt.Errorf("%d files were not imported", failed)
+	}
+	return nil
+}
+
+// importCandidates lists the files opts names, inferring each template's name and type
+func importCandidates(opts ImportOptions) ([]importFile, error) {
+	var paths []string
+	for _, path := range opts.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot import %s: %w", path, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory; use --from-dir", path)
+		}
+		paths = append(paths, path)
+	}
+
+	if opts.Dir != "" {
+		err := filepath.WalkDir(opts.Dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != opts.Dir && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(path), ".md") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot import %s: %w", opts.Dir, err)
+		}
+	}
+
+	files := make([]importFile, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if opts.Name != "" {
+			name = strings.TrimSuffix(opts.Name, ".md")
+		}
+		files = append(files, importFile{Path: path, Name: name, Type: importType(path, opts.Type)})
+	}
+	return files, nil
+}
+
+// importType infers whether path is a pre or post template, or returns ""
+func importType(path, flagType string) string {
+	if flagType != "" {
+		return flagType
+	}
+	if parent := filepath.Base(filepath.Dir(path)); parent == "pre" || parent == "post" {
+		return parent
+	}
+	if fm, err := template.ReadFrontMatter(path); err == nil {
+		return fm.Type
+	}
+	return ""
+}
+
+// importTemplate writes one file as a template, reporting whether it did. It returns an
+// error for files that can't be imported; declining to overwrite isn't one.
+func importTemplate(request *models.PromptRequest, cfg *interfaces.Config, file importFile, opts ImportOptions) (bool, error) {
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return false, err
+	}
+	if _, err := template.ReadFrontMatter(file.Path); err != nil {
+		return false, err
+	}
+	if file.Type == "" {
+		return false, fmt.Errorf("unknown template type (use --type, a pre/ or post/ directory, or type in front matter)")
+	}
+	if file.Name == "" || strings.ContainsAny(file.Name, `/\`) {
+		return false, fmt.Errorf("invalid template name %q", file.Name)
+	}
+
+	templateDir := filepath.Join(cfg.PromptsLocation, file.Type)
+	if existingPath := existingTemplatePath(templateDir, file.Name); existingPath != "" {
+		if sameFile(existingPath, file.Path) {
+			return false, fmt.Errorf("already in the prompts location")
+		}
+		if !opts.Overwrite {
+			if !request.Interactive {
+				return false, fmt.Errorf("template already exists: %s (use --overwrite)", contractPath(existingPath))
+			}
+			prompter := interactive.NewPrompter(cfg.PromptsLocation)
+			shouldOverwrite, err := prompter.ConfirmOverwrite(existingPath)
+			if err != nil {
+				return false, fmt.Errorf("failed to get overwrite confirmation: %w", err)
+			}
+			if !shouldOverwrite {
+				fmt.Printf("Kept %s\n", contractPath(existingPath))
+				return false, nil
+			}
+		}
+	}
+
+	templatePath, err := writeTemplate(cfg, templateDir, file.Name, string(content), opts.Encrypt)
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("Imported %s template %s: %s\n", file.Type, file.Name, contractPath(templatePath))
+	return true, nil
+}
+
+// sameFile reports whether a and b are the same file on disk
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	return err == nil && os.SameFile(aInfo, bInfo)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// writeTree writes files, keyed by path relative to root, creating their directories
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImportTemplates(t *testing.T) {
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"pre/review.md":       "review",
+		"post/concise.md":     "concise",
+		"typed.md":            "---\ntype: post\n---\ntyped",
+		"untyped.md":          "untyped",
+		"bad.md":              "---\ntype: middle\n---\nbad",
+		"notes.txt":           "not markdown",
+		".git/pre/ignored.md": "ignored",
+	})
+
+	promptsDir := t.TempDir()
+	writeTree(t, promptsDir, map[string]string{"pre/review.md": "old review"})
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("prompts_location = \""+promptsDir+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	request := func() *models.PromptRequest {
+		return &models.PromptRequest{ConfigPath: configPath, ForceNonInteractive: true}
+	}
+	readTemplate := func(name string) string {
+		content, _ := os.ReadFile(filepath.Join(promptsDir, name))
+		return string(content)
+	}
+
+	// untyped.md and bad.md have no usable type, and review.md exists without --overwrite
+	err := ImportTemplates(request(), ImportOptions{Dir: source})
+	if err == nil || !strings.Contains(err.Error(), "3 files") {
+		t.Errorf("ImportTemplates(dir) error = %v, want 3 files not imported", err)
+	}
+	if got := readTemplate("post/concise.md"); got != "concise" {
+		t.Errorf("post/concise.md = %q", got)
+	}
+	if got := readTemplate("post/typed.md"); !strings.HasSuffix(got, "typed") {
+		t.Errorf("post/typed.md = %q, want the front matter type used", got)
+	}
+	if got := readTemplate("pre/review.md"); got != "old review" {
+		t.Errorf("pre/review.md = %q, want it kept without --overwrite", got)
+	}
+	if _, err := os.Stat(filepath.Join(promptsDir, "pre", "ignored.md")); err == nil {
+		t.Error("files under hidden directories were imported")
+	}
+
+	if err := ImportTemplates(request(), ImportOptions{Dir: filepath.Join(source, "pre"), Overwrite: true}); err != nil {
+		t.Fatalf("ImportTemplates(overwrite) error = %v", err)
+	}
+	if got := readTemplate("pre/review.md"); got != "review" {
+		t.Errorf("pre/review.md = %q, want it replaced with --overwrite", got)
+	}
+
+	// A single file can be named, and --type covers files without one
+	opts := ImportOptions{Files: []string{filepath.Join(source, "untyped.md")}, Type: "pre", Name: "renamed"}
+	if err := ImportTemplates(request(), opts); err != nil {
+		t.Fatalf("ImportTemplates(file) error = %v", err)
+	}
+	if got := readTemplate("pre/renamed.md"); got != "untyped" {
+		t.Errorf("pre/renamed.md = %q", got)
+	}
+
+	for _, opts := range []ImportOptions{
+		{Dir: source, Name: "x"},
+		{Files: []string{"a.md", "b.md"}, Name: "x"},
+		{Files: []string{source}},
+		{Files: []string{filepath.Join(source, "typed.md")}, Type: "middle"},
+	} {
+		if err := ImportTemplates(request(), opts); err == nil {
+			t.Errorf("ImportTemplates(%+v) expected error", opts)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// invocationEntry is one line of the invocation log. It records how a prompt was
+// assembled, never what it contains.
+type invocationEntry struct {
+	Time         string   `json:"time"`
+	Version      string   `json:"version"`
+	CommandLine  []string `json:"command_line"`
+	ConfigHash   string   `json:"config_hash"`
+	Mode         string   `json:"mode"`
+	FixSource    string   `json:"fix_source,omitempty"`
+	PreTemplate  string   `json:"pre_template,omitempty"`
+	PostTemplate string   `json:"post_template,omitempty"`
+	Target       string   `json:"target,omitempty"`
+	Bytes        int      `json:"bytes"`
+	Tokens       int      `json:"tokens"`
+	Status       string   `json:"status"`
+}
+
+// newInvocationEntry summarizes a finished run
+func newInvocationEntry(request *models.PromptRequest, cfg *interfaces.Config, prompt string, runErr error) invocationEntry {
+	entry := invocationEntry{
+		Time:         time.Now().UTC().Format(time.RFC3339),
+		Version:      Version,
+		CommandLine:  request.CommandLine,
+		ConfigHash:   configHash(cfg),
+		Mode:         "normal",
+		PreTemplate:  request.PreTemplate,
+		PostTemplate: request.PostTemplate,
+		Target:       request.Target,
+		Bytes:        len(prompt),
+		Tokens:       orchestrator.EstimateTokens(prompt),
+		Status:       "ok",
+	}
+
+	if request.FixMode {
+		entry.Mode = "fix"
+		entry.FixSource = request.FixSource
+	}
+
+	switch {
+	case runErr == nil:
+	case interactive.IsCancelled(runErr):
+		entry.Status = "cancelled"
+	default:
+		entry.Status = "error"
+	}
+
+	return entry
+}
+
+// configHash fingerprints the resolved configuration so runs can be grouped by setup
+func configHash(cfg *interfaces.Config) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// appendInvocationLog writes entry as a JSON line to path
+func appendInvocationLog(path string, entry invocationEntry) error {
+	// Keep <prompt> readable rather than \u003cprompt\u003e
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	// A single write keeps lines from concurrent invocations whole
+	if _, err := file.Write(line.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
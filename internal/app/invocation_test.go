@@ -0,0 +1,93 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestNewInvocationEntry(t *testing.T) {
+	request := &models.PromptRequest{
+		BasePrompt:   "secret roadmap details",
+		PreTemplate:  "review",
+		Target:       "stdout",
+		CommandLine:  []string{"prompter", "--pre=review", "<prompt>"},
+		FixMode:      true,
+		FixSource:    "tmux",
+		PostTemplate: "strict",
+	}
+	cfg := &interfaces.Config{Target: "clipboard"}
+	prompt := "secret roadmap details\n\nplease review"
+
+	tests := []struct {
+		name   string
+		err    error
+		status string
+	}{
+		{name: "success", status: "ok"},
+		{name: "cancelled", err: interactive.ErrCancelled, status: "cancelled"},
+		{name: "failure", err: errors.New("boom"), status: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newInvocationEntry(request, cfg, prompt, tt.err)
+			if entry.Status != tt.status {
+				t.Errorf("Status = %q, want %q", entry.Status, tt.status)
+			}
+			if entry.Mode != "fix" || entry.FixSource != "tmux" || entry.PreTemplate != "review" || entry.PostTemplate != "strict" {
+				t.Errorf("entry = %+v", entry)
+			}
+			if entry.Bytes != len(prompt) || entry.Tokens == 0 {
+				t.Errorf("Bytes = %d, Tokens = %d", entry.Bytes, entry.Tokens)
+			}
+
+			data, _ := json.Marshal(entry)
+			if strings.Contains(string(data), "secret") {
+				t.Errorf("entry contains prompt text: %s", data)
+			}
+		})
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	a := configHash(&interfaces.Config{Target: "clipboard"})
+	b := configHash(&interfaces.Config{Target: "stdout"})
+	if a == "" || a == b {
+		t.Errorf("configHash() = %q and %q, want distinct non-empty hashes", a, b)
+	}
+	if again := configHash(&interfaces.Config{Target: "clipboard"}); again != a {
+		t.Errorf("configHash() not stable: %q vs %q", a, again)
+	}
+}
+
+func TestAppendInvocationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "invocations.jsonl")
+
+	for _, status := range []string{"ok", "error"} {
+		if err := appendInvocationLog(path, invocationEntry{Status: status}); err != nil {
+			t.Fatalf("appendInvocationLog() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log has %d lines, want 2", len(lines))
+	}
+
+	var entry invocationEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil || entry.Status != "error" {
+		t.Errorf("second line = %q, %v", lines[1], err)
+	}
+}
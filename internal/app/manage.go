@@ -0,0 +1,242 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// defaultTemplateLine matches a top-level default_pre or default_post setting, or their fix_
+// counterparts, in a config file
+var defaultTemplateLine = regexp.MustCompile(`(?m)^(\s*((?:fix_)?default_(?:pre|post))\s*=\s*)("[^"\n]*"|'[^'\n]*')`)
+
+// tableHeader matches the first line of a TOML table, where top-level keys end
+var tableHeader = regexp.MustCompile(`(?m)^\s*\[`)
+
+// MoveTemplate renames a template, updating default_pre/default_post in the config file when
+// they referenced it
+func MoveTemplate(request *models.PromptRequest, src, dst string) error {
+	return transferTemplate(request, src, dst, true)
+}
+
+// CopyTemplate copies a template to a new name, or into another namespace with namespace/name
+func CopyTemplate(request *models.PromptRequest, src, dst string) error {
+	return transferTemplate(request, src, dst, false)
+}
+
+// transferTemplate moves or copies the template src resolves to. dst is a bare name, kept in
+// the source's directory, or namespace/name to place it in that namespace with the same type.
+func transferTemplate(request *models.PromptRequest, src, dst string, move bool) error {
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	// Resolve names as a run would, including the trust check for project-local templates
+	resolveInteractiveMode(request, cfg)
+	if err := orch.ApplyWorkspaceTrust(request, cfg); err != nil {
+		return fmt.Errorf("workspace trust: %w", err)
+	}
+	defer warnings.Flush(os.Stderr)
+
+	processor, ok := orch.GetTemplateProcessor().(*template.Processor)
+	if !ok {
+		return fmt.Errorf("template processor does not support resolving templates")
+	}
+
+	srcPath, err := processor.ResolveTemplate(src)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(srcPath); err != nil {
+		return fmt.Errorf("template not found: %s", src)
+	}
+	dstPath, err := destinationPath(processor, srcPath, dst, move)
+	if err != nil {
+		return err
+	}
+	if move {
+		if pack := packOf(processor, srcPath); pack != "" {
+			return fmt.Errorf("%s belongs to pack %s; copy it with 'prompter cp' instead", src, pack)
+		}
+	}
+
+	// Work out the config change before the move, while the old name still resolves
+	var configPath, updatedConfig string
+	var keys []string
+	if move {
+		if configPath, err = movedConfigPath(request.ConfigPath); err != nil {
+			return err
+		}
+		if data, err := os.ReadFile(configPath); err == nil {
+			updatedConfig, keys = renameDefaultTemplates(string(data), srcPath, dst, processor.ResolveTemplate)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+
+	if move {
+		err = moveFile(srcPath, dstPath)
+	} else {
+		err = copyFile(srcPath, dstPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "Copied"
+	if move {
+		verb = "Moved"
+	}
+	fmt.Printf("%s %s -> %s\n", verb, contractPath(srcPath), contractPath(dstPath))
+
+	if len(keys) == 0 {
+		return nil
+	}
+	info, err := os.Stat(configPath)
+	if err == nil {
+		err = os.WriteFile(configPath, []byte(updatedConfig), info.Mode().Perm())
+	}
+	if err != nil {
+		return fmt.Errorf("template moved, but updating the config failed: %w", err)
+	}
+	for _, key := range keys {
+		fmt.Printf("Updated %s in %s\n", key, contractPath(configPath))
+	}
+	return nil
+}
+
+// movedConfigPath returns the config file whose defaults a move updates
+func movedConfigPath(configPath string) (string, error) {
+	if configPath == "" {
+		return config.DefaultConfigPath()
+	}
+	return config.ExpandPath(configPath), nil
+}
+
+// destinationPath returns where dst puts the template at srcPath, keeping its type and encryption
+// suffix. A move keeps the .default marker; a copy drops it so one default stays the default.
+func destinationPath(processor *template.Processor, srcPath, dst string, move bool) (string, error) {
+	templateType := filepath.Base(filepath.Dir(srcPath))
+	if templateType != "pre" && templateType != "post" {
+		return "", fmt.Errorf("%s is not in a pre or post template directory", contractPath(srcPath))
+	}
+
+	dir := filepath.Dir(srcPath)
+	name := dst
+	if nsName, rest, ok := strings.Cut(dst, "/"); ok {
+		var found bool
+		for _, ns := range processor.Namespaces() {
+			if strings.EqualFold(ns.Name, nsName) {
+				if ns.Pack {
+					return "", fmt.Errorf("cannot write into pack %s; 'prompter templates update' would overwrite it", ns.Name)
+				}
+				dir = filepath.Join(ns.Location, templateType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("unknown namespace %q", nsName)
+		}
+		name = rest
+	}
+	name = strings.TrimSuffix(name, ".md")
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", dst)
+	}
+
+	base := filepath.Base(srcPath)
+	stem, _ := template.TemplateName(base)
+	suffix := strings.TrimPrefix(base, stem)
+	if move && strings.HasSuffix(stem, ".default") {
+		suffix = ".default" + suffix
+	}
+
+	if existing := existingTemplatePath(dir, name); existing != "" {
+		return "", fmt.Errorf("template already exists: %s", contractPath(existing))
+	}
+	return filepath.Join(dir, name+suffix), nil
+}
+
+// packOf returns the pack that path belongs to, or ""
+func packOf(processor *template.Processor, path string) string {
+	for _, ns := range processor.Namespaces() {
+		if ns.Pack && strings.HasPrefix(path, ns.Location+string(filepath.Separator)) {
+			return ns.Name
+		}
+	}
+	return ""
+}
+
+// moveFile renames src to dst, copying across filesystems when a rename isn't possible
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile copies src to dst, failing if dst exists
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	return out.Close()
+}
+
+// renameDefaultTemplates rewrites default_pre/default_post in config content to newRef when
+// they resolve to oldPath, returning the new content and the keys it changed. Settings inside
+// tables and values that don't resolve (e.g. template expressions) are left alone.
+func renameDefaultTemplates(content, oldPath, newRef string, resolve func(string) (string, error)) (string, []string) {
+	// Only top-level keys set the defaults; stop at the first table header
+	topLevel := content
+	if loc := tableHeader.FindStringIndex(content); loc != nil {
+		topLevel = content[:loc[0]]
+	}
+
+	var keys []string
+	updated := defaultTemplateLine.ReplaceAllStringFunc(topLevel, func(line string) string {
+		match := defaultTemplateLine.FindStringSubmatch(line)
+		value := match[3][1 : len(match[3])-1]
+		if value == "" {
+			return line
+		}
+		if resolved, err := resolve(value); err != nil || resolved != oldPath {
+			return line
+		}
+		keys = append(keys, match[2])
+		return match[1] + tomlString(newRef)
+	})
+	return updated + content[len(topLevel):], keys
+}
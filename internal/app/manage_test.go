@@ -0,0 +1,114 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/template"
+)
+
+func TestRenameDefaultTemplates(t *testing.T) {
+	oldPath := "/prompts/pre/review.md"
+	resolve := func(name string) (string, error) {
+		switch name {
+		case "review", "global/review":
+			return oldPath, nil
+		case "other":
+			return "/prompts/pre/other.md", nil
+		}
+		return "", os.ErrNotExist
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		want     string
+		wantKeys []string
+	}{
+		{
+			name:     "bare name",
+			content:  "default_pre = \"review\"\ndefault_post = \"other\"\n",
+			want:     "default_pre = \"code-review\"\ndefault_post = \"other\"\n",
+			wantKeys: []string{"default_pre"},
+		},
+		{
+			name:     "namespaced and single-quoted",
+			content:  "default_post = 'global/review' # keep\n",
+			want:     "default_post = \"code-review\" # keep\n",
+			wantKeys: []string{"default_post"},
+		},
+		{
+			name:     "fix mode default",
+			content:  "fix_default_pre = \"review\"\n",
+			want:     "fix_default_pre = \"code-review\"\n",
+			wantKeys: []string{"fix_default_pre"},
+		},
+		{
+			name:    "template expression left alone",
+			content: "default_pre = \"{{.Project.Type}}-style\"\n",
+			want:    "default_pre = \"{{.Project.Type}}-style\"\n",
+		},
+		{
+			name:    "inside a table left alone",
+			content: "editor = \"vim\"\n\n[custom_template.team]\ndefault_pre = \"review\"\n",
+			want:    "editor = \"vim\"\n\n[custom_template.team]\ndefault_pre = \"review\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, keys := renameDefaultTemplates(tt.content, oldPath, "code-review", resolve)
+			if got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestDestinationPath(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"global/pre/review.default.md":    "x",
+		"global/post/secret.md.age":       "x",
+		"local/pre/taken.md":              "x",
+		"global/packs/acme/pre/review.md": "x",
+	})
+	processor := template.NewProcessor(filepath.Join(root, "global"))
+	processor.SetLocalPromptsLocation(filepath.Join(root, "local"))
+
+	review := filepath.Join(root, "global/pre/review.default.md")
+	tests := []struct {
+		name      string
+		src       string
+		dst       string
+		move      bool
+		want      string
+		wantError bool
+	}{
+		{name: "move keeps default marker", src: review, dst: "code-review", move: true, want: "global/pre/code-review.default.md"},
+		{name: "copy drops default marker", src: review, dst: "code-review.md", want: "global/pre/code-review.md"},
+		{name: "into another namespace", src: review, dst: "local/review", want: "local/pre/review.md"},
+		{name: "keeps encryption suffix", src: filepath.Join(root, "global/post/secret.md.age"), dst: "hidden", want: "global/post/hidden.md.age"},
+		{name: "existing template", src: review, dst: "local/taken", wantError: true},
+		{name: "unknown namespace", src: review, dst: "nope/review", wantError: true},
+		{name: "into a pack", src: review, dst: "acme/mine", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := destinationPath(processor, tt.src, tt.dst, tt.move)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("destinationPath(%q) = %q, expected error", tt.dst, got)
+				}
+				return
+			}
+			if err != nil || got != filepath.Join(root, tt.want) {
+				t.Errorf("destinationPath(%q) = %q, %v; want %q", tt.dst, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/imdevan/prompter/internal/config"
+)
+
+// MigrateConfig moves the config file, prompts, state file, and invocation log out of
+// ~/.config/prompter to the directories prompter now uses, such as under XDG_CONFIG_HOME or
+// %APPDATA%, and writes what it moved to w
+func MigrateConfig(w io.Writer) error {
+	legacy, err := config.LegacyDir()
+	if err != nil {
+		return err
+	}
+	moves, err := config.MigrateLegacyDir()
+	for _, move := range moves {
+		fmt.Fprintf(w, "Moved %s to %s\n", contractPath(move.From), contractPath(move.To))
+	}
+	if err != nil {
+		return fmt.Errorf("migration stopped: %w", err)
+	}
+	if len(moves) == 0 {
+		fmt.Fprintf(w, "Nothing to migrate from %s.\n", contractPath(legacy))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// ciVariables are environment variables CI systems set on their runners
+var ciVariables = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "CIRCLECI", "JENKINS_URL", "TF_BUILD"}
+
+// containerMarkers are files container runtimes create inside their containers
+var containerMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+
+// minimalReason reports why a run uses minimal mode, or "" when it doesn't. Without
+// --minimal or --no-minimal, CI and containers turn it on when autoDetect is set.
+func minimalReason(request *models.PromptRequest, autoDetect bool) string {
+	switch {
+	case request.NoMinimal:
+		return ""
+	case request.Minimal:
+		return "--minimal"
+	case !autoDetect:
+		return ""
+	}
+	return constrainedEnvironment()
+}
+
+// constrainedEnvironment names the CI system or container prompter runs in, or ""
+func constrainedEnvironment() string {
+	for _, name := range ciVariables {
+		if value := os.Getenv(name); value != "" && value != "false" && value != "0" {
+			return "running in CI"
+		}
+	}
+	if os.Getenv("container") != "" {
+		return "running in a container"
+	}
+	for _, marker := range containerMarkers {
+		if _, err := os.Stat(marker); err == nil {
+			return "running in a container"
+		}
+	}
+	return ""
+}
+
+// applyMinimalMode turns off what constrained environments tend to lack: the clipboard, the
+// editor, interactive prompts (they need a raw-mode terminal), color, and network access.
+// Features given on the command line are skipped with a warning; -i still forces prompts.
+func applyMinimalMode(request *models.PromptRequest, cfg *interfaces.Config, reason string) error {
+	if request.FromClipboard {
+		return fmt.Errorf("--clipboard needs the system clipboard, which minimal mode (%s) turns off; pipe the text in instead", reason)
+	}
+
+	if !request.ForceInteractive {
+		request.Interactive = false
+	}
+	interactive.DisableColor()
+
+	if request.Target == "clipboard" || (request.Target == "" && cfg.Target == "clipboard") {
+		if request.Target == "clipboard" {
+			warnings.Add("minimal mode (%s): writing to stdout instead of the clipboard", reason)
+		}
+		request.Target = "stdout"
+	}
+	cfg.ClipboardVerify = false
+
+	if request.EditorRequested {
+		warnings.Add("minimal mode (%s): --editor is ignored", reason)
+		request.EditorRequested = false
+	}
+	request.Preview = false
+	cfg.PreviewOutput = false
+
+	// Network access: fetched context, remote files, and the update check
+	var skipped []string
+	if len(request.URLs) > 0 {
+		skipped = append(skipped, "--url")
+		request.URLs = nil
+	}
+	if len(request.GitHubRefs) > 0 {
+		skipped = append(skipped, "--github")
+		request.GitHubRefs = nil
+	}
+	if len(request.JiraKeys) > 0 {
+		skipped = append(skipped, "--jira")
+		request.JiraKeys = nil
+	}
+	local := []string{}
+	for _, file := range request.Files {
+		if strings.HasPrefix(file, "ssh://") {
+			skipped = append(skipped, file)
+			continue
+		}
+		local = append(local, file)
+	}
+	request.Files = local
+	if len(skipped) > 0 {
+		warnings.Add("minimal mode (%s): network access is off; skipped %s", reason, strings.Join(skipped, ", "))
+	}
+	cfg.UpdateCheck = false
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// clearEnvironment makes constrainedEnvironment see neither CI nor a container
+func clearEnvironment(t *testing.T) {
+	t.Helper()
+	for _, name := range append(ciVariables, "container") {
+		t.Setenv(name, "")
+	}
+	saved := containerMarkers
+	containerMarkers = []string{filepath.Join(t.TempDir(), "missing")}
+	t.Cleanup(func() { containerMarkers = saved })
+}
+
+func TestMinimalReason(t *testing.T) {
+	clearEnvironment(t)
+
+	request := models.NewPromptRequest()
+	if got := minimalReason(request, true); got != "" {
+		t.Errorf("minimalReason() outside CI = %q, want none", got)
+	}
+
+	t.Setenv("CI", "true")
+	if got := minimalReason(request, true); got != "running in CI" {
+		t.Errorf("minimalReason() in CI = %q", got)
+	}
+	if got := minimalReason(request, false); got != "" {
+		t.Errorf("minimalReason() with minimal_auto off = %q, want none", got)
+	}
+
+	request.NoMinimal = true
+	if got := minimalReason(request, true); got != "" {
+		t.Errorf("minimalReason() with --no-minimal = %q, want none", got)
+	}
+
+	t.Setenv("CI", "false")
+	request.NoMinimal, request.Minimal = false, true
+	if got := minimalReason(request, true); got != "--minimal" {
+		t.Errorf("minimalReason() with --minimal = %q", got)
+	}
+}
+
+func TestApplyMinimalMode(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.EditorRequested = true
+	request.Preview = true
+	request.URLs = []string{"https://example.com"}
+	request.Files = []string{"main.go", "ssh://prod/var/log/app.log"}
+	cfg := &interfaces.Config{Target: "clipboard", UpdateCheck: true, ClipboardVerify: true}
+
+	if err := applyMinimalMode(request, cfg, "--minimal"); err != nil {
+		t.Fatalf("applyMinimalMode() error = %v", err)
+	}
+	warnings.Flush(io.Discard)
+
+	if request.Interactive || request.EditorRequested || request.Preview || request.Target != "stdout" {
+		t.Errorf("applyMinimalMode() left request = %+v", request)
+	}
+	if request.URLs != nil || !reflect.DeepEqual(request.Files, []string{"main.go"}) {
+		t.Errorf("applyMinimalMode() kept network sources: urls %v, files %v", request.URLs, request.Files)
+	}
+	if cfg.UpdateCheck || cfg.ClipboardVerify {
+		t.Errorf("applyMinimalMode() left config = %+v", cfg)
+	}
+
+	request = models.NewPromptRequest()
+	request.FromClipboard = true
+	if err := applyMinimalMode(request, cfg, "--minimal"); err == nil {
+		t.Error("applyMinimalMode() with --clipboard should fail")
+	}
+}
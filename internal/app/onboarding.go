@@ -0,0 +1,205 @@
+package app
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/pkg/models"
+	"golang.org/x/term"
+)
+
+// starterTemplates are the templates offered during setup and installed by 'prompter
+// templates bootstrap', laid out as in the prompts location: pre/, post/, fix/, and fix.md
+//
+//go:embed starter
+var starterTemplates embed.FS
+
+// shouldOnboard reports whether this run should start the first-run setup: the default config
+// file is missing and prompter was started from a terminal without -y or --config
+func shouldOnboard(request *models.PromptRequest) (string, bool) {
+	if request.ConfigPath != "" || request.ForceNonInteractive || request.CI || os.Getenv("PROMPTER_NO_SETUP") != "" {
+		return "", false
+	}
+	if minimalReason(request, true) != "" {
+		return "", false
+	}
+	if !term.IsTerminal(int(syscall.Stdin)) || !term.IsTerminal(int(syscall.Stdout)) {
+		return "", false
+	}
+
+	path, err := config.DefaultConfigPath()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return "", false
+	}
+	return path, true
+}
+
+// onboardingDefaults are the answers offered when setup starts, matching the config defaults
+func onboardingDefaults() interactive.Onboarding {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "nvim"
+	}
+	return interactive.Onboarding{
+		PromptsLocation:    config.DefaultPromptsLocation(),
+		Editor:             editor,
+		Target:             "clipboard",
+		InteractiveDefault: true,
+		StarterTemplates:   true,
+	}
+}
+
+// Setup runs the guided setup and writes its answers to configPath (the default config when
+// empty). Unless confirmed, it first asks whether to run it at all; declining writes the
+// defaults so the question isn't asked again.
+func Setup(configPath string, confirmed bool) error {
+	if configPath == "" {
+		defaultPath, err := config.DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = defaultPath
+	}
+	configPath = config.ExpandPath(configPath)
+
+	prompter := interactive.NewPrompter("")
+	answers := onboardingDefaults()
+
+	if !confirmed {
+		var err error
+		confirmed, err = prompter.ConfirmOnboarding(contractPath(configPath))
+		if err != nil {
+			return err
+		}
+	}
+
+	if confirmed {
+		if _, err := os.Stat(configPath); err == nil {
+			overwrite, err := prompter.ConfirmOverwrite(configPath)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				return fmt.Errorf("setup cancelled: %s already exists", configPath)
+			}
+		}
+
+		var err error
+		answers, err = prompter.CollectOnboarding(answers)
+		if err != nil {
+			return err
+		}
+	} else {
+		answers.StarterTemplates = false
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(configPath, []byte(onboardingConfig(answers)), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Printf("Config written to %s\n", contractPath(configPath))
+
+	promptsLocation := config.ExpandPath(answers.PromptsLocation)
+	if err := os.MkdirAll(promptsLocation, 0755); err != nil {
+		return fmt.Errorf("failed to create prompts location: %w", err)
+	}
+	if answers.StarterTemplates {
+		installed, err := installStarterTemplates(promptsLocation, false)
+		if err != nil {
+			return err
+		}
+		if len(installed) > 0 {
+			fmt.Printf("Starter templates installed: %s\n", strings.Join(installed, ", "))
+		}
+	}
+
+	return nil
+}
+
+// onboardingConfig renders the setup answers as a config file
+func onboardingConfig(answers interactive.Onboarding) string {
+	var b strings.Builder
+	b.WriteString("# Written by prompter setup. See example-config.toml in the prompter repository for\n")
+	b.WriteString("# every option.\n\n")
+	fmt.Fprintf(&b, "prompts_location = %s\n", tomlString(answers.PromptsLocation))
+	fmt.Fprintf(&b, "editor = %s\n", tomlString(answers.Editor))
+	fmt.Fprintf(&b, "target = %s\n", tomlString(answers.Target))
+	fmt.Fprintf(&b, "interactive_default = %t\n", answers.InteractiveDefault)
+	return b.String()
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// installStarterTemplates copies the starter templates into promptsLocation, keeping any
+// existing template with the same name, and returns the ones it added as type/name. With
+// asDefault, pre- and post-templates are written as name.default.md so pickers list them first.
+func installStarterTemplates(promptsLocation string, asDefault bool) ([]string, error) {
+	var installed []string
+	err := fs.WalkDir(starterTemplates, "starter", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		rel := strings.TrimPrefix(path, "starter/")
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		target := filepath.Join(promptsLocation, filepath.FromSlash(rel))
+		if existingTemplatePath(filepath.Dir(target), name) != "" || existingTemplatePath(filepath.Dir(target), name+".default") != "" {
+			return nil
+		}
+		// fix/ prompts are loaded by file name, so only pickers' templates can be defaults
+		if asDefault && (strings.HasPrefix(rel, "pre/") || strings.HasPrefix(rel, "post/")) {
+			target = filepath.Join(filepath.Dir(target), name+".default.md")
+		}
+
+		content, err := starterTemplates.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create template directory: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write starter template: %w", err)
+		}
+		installed = append(installed, strings.TrimSuffix(rel, ".md"))
+		return nil
+	})
+	return installed, err
+}
+
+// BootstrapTemplates installs the starter templates into the prompts location, keeping the
+// templates already there
+func BootstrapTemplates(request *models.PromptRequest, asDefault bool) error {
+	promptsLocation, err := loadPromptsLocation(request)
+	if err != nil {
+		return err
+	}
+
+	installed, err := installStarterTemplates(promptsLocation, asDefault)
+	if err != nil {
+		return err
+	}
+	if len(installed) == 0 {
+		fmt.Printf("Every starter template is already in %s\n", contractPath(promptsLocation))
+		return nil
+	}
+	fmt.Printf("Starter templates installed in %s: %s\n", contractPath(promptsLocation), strings.Join(installed, ", "))
+	return nil
+}
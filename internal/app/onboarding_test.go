@@ -0,0 +1,79 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interactive"
+)
+
+func TestOnboardingConfig_Loads(t *testing.T) {
+	answers := interactive.Onboarding{
+		PromptsLocation:    filepath.Join(t.TempDir(), `my "prompts"`),
+		Editor:             "code",
+		Target:             "stdout",
+		InteractiveDefault: false,
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(onboardingConfig(answers)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.NewManager().Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.PromptsLocation != answers.PromptsLocation || cfg.Editor != "code" || cfg.Target != "stdout" || cfg.InteractiveDefault {
+		t.Errorf("Load() = prompts %q, editor %q, target %q, interactive %v; want the setup answers",
+			cfg.PromptsLocation, cfg.Editor, cfg.Target, cfg.InteractiveDefault)
+	}
+}
+
+func TestInstallStarterTemplates(t *testing.T) {
+	promptsDir := t.TempDir()
+	existing := filepath.Join(promptsDir, "pre", "review.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("my own review"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installed, err := installStarterTemplates(promptsDir, false)
+	if err != nil {
+		t.Fatalf("installStarterTemplates() error = %v", err)
+	}
+	want := "fix/tests,fix,post/concise,post/steps,pre/explain,pre/refactor,pre/tests"
+	if got := strings.Join(installed, ","); got != want {
+		t.Errorf("installStarterTemplates() = %s, want %s", got, want)
+	}
+
+	if data, _ := os.ReadFile(existing); string(data) != "my own review" {
+		t.Errorf("existing template was replaced: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(promptsDir, "post", "concise.md")); err != nil {
+		t.Errorf("starter template not installed: %v", err)
+	}
+}
+
+func TestInstallStarterTemplatesAsDefault(t *testing.T) {
+	promptsDir := t.TempDir()
+	if _, err := installStarterTemplates(promptsDir, true); err != nil {
+		t.Fatalf("installStarterTemplates() error = %v", err)
+	}
+	for _, rel := range []string{"pre/review.default.md", "post/concise.default.md", "fix/tests.md", "fix.md"} {
+		if _, err := os.Stat(filepath.Join(promptsDir, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("starter template %s not installed: %v", rel, err)
+		}
+	}
+
+	// A second run finds the defaults and leaves them alone
+	installed, err := installStarterTemplates(promptsDir, false)
+	if err != nil || len(installed) != 0 {
+		t.Errorf("installStarterTemplates() again = %v, %v; want nothing installed", installed, err)
+	}
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// InstallTemplatePack clones a git repository of pre/ and post/ templates into the packs
+// directory. The pack is named after the repository unless name is set.
+func InstallTemplatePack(request *models.PromptRequest, gitURL, name string) error {
+	promptsLocation, err := loadPromptsLocation(request)
+	if err != nil {
+		return err
+	}
+	packsDir := template.PacksDir(promptsLocation)
+
+	// A local repository is cloned as is; anything else, such as a URL from the pack index,
+	// has to be one git fetches over the network
+	if info, err := os.Stat(gitURL); err != nil || !info.IsDir() {
+		if err := template.ValidatePackURL(gitURL); err != nil {
+			return err
+		}
+	}
+
+	if name == "" {
+		name = template.PackNameFromURL(gitURL)
+	}
+	if err := template.ValidatePackName(name); err != nil {
+		return err
+	}
+	if template.IsReservedNamespace(name) {
+		return fmt.Errorf("pack name %s is reserved for a built-in prompt location; choose another with --name", name)
+	}
+
+	packDir := filepath.Join(packsDir, name)
+	if _, err := os.Stat(packDir); err == nil {
+		return fmt.Errorf("pack %s is already installed; use 'prompter templates update %s'", name, name)
+	}
+	if err := os.MkdirAll(packsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create packs directory: %w", err)
+	}
+
+	if err := runGit("clone", "--depth", "1", "--", gitURL, packDir); err != nil {
+		os.RemoveAll(packDir) // Don't leave a partial clone that looks installed
+		return fmt.Errorf("failed to install pack %s: %w", name, err)
+	}
+
+	if !hasTemplateDirs(packDir) {
+		fmt.Fprintf(os.Stderr, "Warning: pack %s has no pre/ or post/ directory, so it provides no templates\n", name)
+	}
+
+	fmt.Printf("Installed pack %s: %s\n", name, contractPath(packDir))
+	return nil
+}
+
+// UpdateTemplatePacks pulls the named packs, or every installed pack when none are named
+func UpdateTemplatePacks(request *models.PromptRequest, names []string) error {
+	promptsLocation, err := loadPromptsLocation(request)
+	if err != nil {
+		return err
+	}
+	packsDir := template.PacksDir(promptsLocation)
+
+	if len(names) == 0 {
+		names = template.InstalledPacks(promptsLocation)
+		if len(names) == 0 {
+			fmt.Println("No packs installed.")
+			return nil
+		}
+	}
+
+	var failed []string
+	for _, name := range names {
+		packDir, err := installedPackDir(packsDir, name)
+		if err != nil {
+			return err
+		}
+		if err := runGit("-C", packDir, "pull", "--ff-only"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update pack %s: %v\n", name, err)
+			failed = append(failed, name)
+			continue
+		}
+		fmt.Printf("Updated pack %s\n", name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update %d pack(s): %v", len(failed), failed)
+	}
+	return nil
+}
+
+// UninstallTemplatePack removes an installed pack
+func UninstallTemplatePack(request *models.PromptRequest, name string) error {
+	promptsLocation, err := loadPromptsLocation(request)
+	if err != nil {
+		return err
+	}
+	packsDir := template.PacksDir(promptsLocation)
+
+	packDir, err := installedPackDir(packsDir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(packDir); err != nil {
+		return fmt.Errorf("failed to uninstall pack %s: %w", name, err)
+	}
+
+	fmt.Printf("Uninstalled pack %s\n", name)
+	return nil
+}
+
+// loadPromptsLocation loads the configuration and returns prompts_location, which holds the packs
+func loadPromptsLocation(request *models.PromptRequest) (string, error) {
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	return cfg.PromptsLocation, nil
+}
+
+// installedPackDir returns the directory of an installed pack
+func installedPackDir(packsDir, name string) (string, error) {
+	if err := template.ValidatePackName(name); err != nil {
+		return "", err
+	}
+	packDir := filepath.Join(packsDir, name)
+	if info, err := os.Stat(packDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("pack %s is not installed", name)
+	}
+	return packDir, nil
+}
+
+// hasTemplateDirs reports whether dir has a pre/ or post/ template directory
+func hasTemplateDirs(dir string) bool {
+	for _, sub := range []string{"pre", "post"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// runGit runs git with its progress and errors shown on stderr
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestTemplatePackLifecycle(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	// A local repository stands in for the remote pack
+	repo := filepath.Join(t.TempDir(), "team-prompts")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	writeTree(t, repo, map[string]string{"pre/review.md": "v1"})
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+
+	promptsDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("prompts_location = \""+promptsDir+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	request := &models.PromptRequest{ConfigPath: configPath}
+	installed := filepath.Join(promptsDir, "packs", "team-prompts", "pre", "review.md")
+
+	if err := InstallTemplatePack(request, repo, ""); err != nil {
+		t.Fatalf("InstallTemplatePack() error = %v", err)
+	}
+	if content, err := os.ReadFile(installed); err != nil || string(content) != "v1" {
+		t.Fatalf("installed template = %q, %v; want v1", content, err)
+	}
+	if err := InstallTemplatePack(request, repo, ""); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("second InstallTemplatePack() error = %v, want already installed", err)
+	}
+	if err := InstallTemplatePack(request, "--upload-pack=touch "+filepath.Join(promptsDir, "pwned"), "evil"); err == nil || !strings.Contains(err.Error(), "invalid pack URL") {
+		t.Errorf("InstallTemplatePack() with an option for a URL error = %v, want invalid pack URL", err)
+	}
+
+	writeTree(t, repo, map[string]string{"pre/review.md": "v2"})
+	git("commit", "-q", "-am", "v2")
+	if err := UpdateTemplatePacks(request, nil); err != nil {
+		t.Fatalf("UpdateTemplatePacks() error = %v", err)
+	}
+	if content, _ := os.ReadFile(installed); string(content) != "v2" {
+		t.Errorf("updated template = %q, want v2", content)
+	}
+
+	if err := UninstallTemplatePack(request, "team-prompts"); err != nil {
+		t.Fatalf("UninstallTemplatePack() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(filepath.Dir(installed))); !os.IsNotExist(err) {
+		t.Errorf("pack directory still exists after uninstall: %v", err)
+	}
+	if err := UninstallTemplatePack(request, "../escape"); err == nil {
+		t.Errorf("UninstallTemplatePack() accepted a path as a pack name")
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// scaffoldTemplates are the skeletons 'add --scaffold' starts a template from
+//
+//go:embed scaffolds
+var scaffoldTemplates embed.FS
+
+// ScaffoldFix is the scaffold for fix.md, the prompt fix mode starts with. fix.md isn't
+// rendered as a template, so unlike the others it has no variables.
+const ScaffoldFix = "fix"
+
+// Scaffolds returns the names accepted by 'add --scaffold', sorted
+func Scaffolds() []string {
+	entries, _ := scaffoldTemplates.ReadDir("scaffolds")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scaffoldContent returns the skeleton called name
+func scaffoldContent(name string) (string, error) {
+	content, err := scaffoldTemplates.ReadFile("scaffolds/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("unknown scaffold %q (use %s)", name, strings.Join(Scaffolds(), ", "))
+	}
+	return string(content), nil
+}
+
+// addFixScaffold writes the fix scaffold to fix.md in the prompts location
+func addFixScaffold(request *models.PromptRequest, cfg *interfaces.Config, overwrite bool) error {
+	content, err := scaffoldContent(ScaffoldFix)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(cfg.PromptsLocation, "fix.md")
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		if !request.Interactive {
+			return fmt.Errorf("template file already exists: %s", contractPath(path))
+		}
+		prompter := interactive.NewPrompter(cfg.PromptsLocation)
+		shouldOverwrite, err := prompter.ConfirmOverwrite(path)
+		if err != nil {
+			return fmt.Errorf("failed to get overwrite confirmation: %w", err)
+		}
+		if !shouldOverwrite {
+			fmt.Println("Template creation cancelled.")
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(cfg.PromptsLocation, 0755); err != nil {
+		return fmt.Errorf("failed to create prompts directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write template file: %w", err)
+	}
+
+	fmt.Printf("Created fix template: %s\n", contractPath(path))
+	return nil
+}
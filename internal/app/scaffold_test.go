@@ -0,0 +1,89 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestScaffolds_Render(t *testing.T) {
+	want := []string{"constraints", "fix", "output-format", "persona"}
+	if got := Scaffolds(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Scaffolds() = %v, want %v", got, want)
+	}
+
+	dir := t.TempDir()
+	processor := template.NewProcessor(dir)
+	data := interfaces.TemplateData{
+		Prompt:  "add retries to the client",
+		Project: interfaces.ProjectInfo{Name: "prompter", Type: "go"},
+		Git:     interfaces.GitInfo{Branch: "feature/retries"},
+		Files:   []interfaces.FileInfo{{RelPath: "client.go", Language: "go", Content: "package client"}},
+	}
+
+	for _, name := range Scaffolds() {
+		t.Run(name, func(t *testing.T) {
+			content, err := scaffoldContent(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name == ScaffoldFix {
+				// fix.md is used as written, so template markup would show up in the prompt
+				if strings.Contains(content, "{{") || strings.HasPrefix(content, "---") {
+					t.Errorf("fix scaffold has template markup or front matter:\n%s", content)
+				}
+				return
+			}
+
+			path := filepath.Join(dir, name+".md")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if fm, err := template.ReadFrontMatter(path); err != nil || fm.Description == "" {
+				t.Errorf("scaffold front matter = %+v, %v; want a description", fm, err)
+			}
+			tmpl, err := processor.LoadTemplate(path)
+			if err != nil {
+				t.Fatalf("LoadTemplate() error = %v", err)
+			}
+			rendered, err := processor.Execute(tmpl, data)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if strings.Contains(rendered, "<no value>") {
+				t.Errorf("rendered scaffold has missing values:\n%s", rendered)
+			}
+		})
+	}
+
+	if _, err := scaffoldContent("nope"); err == nil {
+		t.Error("scaffoldContent(nope) expected error")
+	}
+}
+
+func TestAddFixScaffold(t *testing.T) {
+	cfg := &interfaces.Config{PromptsLocation: filepath.Join(t.TempDir(), "prompts")}
+	request := models.NewPromptRequest()
+	request.Interactive = false
+
+	if err := addFixScaffold(request, cfg, false); err != nil {
+		t.Fatalf("addFixScaffold() error = %v", err)
+	}
+	path := filepath.Join(cfg.PromptsLocation, "fix.md")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("fix.md not written: %v", err)
+	}
+
+	// An existing fix.md is only replaced with --overwrite
+	if err := addFixScaffold(request, cfg, false); err == nil {
+		t.Error("addFixScaffold() over an existing fix.md expected error")
+	}
+	if err := addFixScaffold(request, cfg, true); err != nil {
+		t.Errorf("addFixScaffold() with overwrite error = %v", err)
+	}
+}
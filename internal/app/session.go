@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// loadSessionConfig loads the configuration and the scope of the current project's session
+func loadSessionConfig(request *models.PromptRequest) (*interfaces.Config, string, error) {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	scope, err := orchestrator.ProjectSessionScope()
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, scope, nil
+}
+
+// StartSession starts a session for the current project. Until it ends, each prompt includes
+// a condensed transcript of the ones before it and of the responses added with AddSessionResponse.
+func StartSession(request *models.PromptRequest, name string, w io.Writer) error {
+	cfg, scope, err := loadSessionConfig(request)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = time.Now().Format("2006-01-02 15:04")
+	}
+	if _, err := orchestrator.StartSession(cfg, scope, name); err != nil {
+		return fmt.Errorf("failed to start the session: %w", err)
+	}
+
+	fmt.Fprintf(w, "Started session %q for %s\n", name, contractPath(scope))
+	fmt.Fprintln(w, "Add a model's response with 'prompter session add', and end it with 'prompter session end'.")
+	return nil
+}
+
+// AddSessionResponse adds a model's response to the active session: text, or the clipboard
+// when fromClipboard is set, or piped input
+func AddSessionResponse(request *models.PromptRequest, text string, fromClipboard bool, w io.Writer) error {
+	cfg, scope, err := loadSessionConfig(request)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case fromClipboard:
+		if text, err = getClipboardContent(); err != nil {
+			return err
+		}
+	case text == "" && stdinIsPiped():
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read the response: %w", err)
+		}
+		text = string(data)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("no response given: pass it as an argument, pipe it in, or use --clipboard")
+	}
+
+	session, err := orchestrator.AddSessionTurn(cfg, scope, orchestrator.SessionTurn{Role: orchestrator.SessionResponse, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to add the response: %w", err)
+	}
+	fmt.Fprintf(w, "Added a response to session %q (%s)\n", session.Name, formatTurns(len(session.Turns)))
+	return nil
+}
+
+// ShowSession writes the active session's full transcript to w
+func ShowSession(request *models.PromptRequest, w io.Writer) error {
+	cfg, scope, err := loadSessionConfig(request)
+	if err != nil {
+		return err
+	}
+	session, err := orchestrator.LoadSession(cfg, scope)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		fmt.Fprintln(w, "No session is active. Start one with 'prompter session start [name]'.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Session %q, started %s\n", session.Name, session.StartedAt.Format("2006-01-02 15:04"))
+	if len(session.Turns) == 0 {
+		fmt.Fprintln(w, "\nNo prompts yet.")
+	}
+	for i, turn := range session.Turns {
+		fmt.Fprintf(w, "\n--- %d. %s (%s)\n", i+1, turn.Role, turn.At.Format("15:04"))
+		if len(turn.Context) > 0 {
+			fmt.Fprintf(w, "included: %s\n", strings.Join(turn.Context, ", "))
+		}
+		if turn.Text != "" {
+			fmt.Fprintln(w, turn.Text)
+		}
+	}
+	return nil
+}
+
+// EndSession ends the active session
+func EndSession(request *models.PromptRequest, w io.Writer) error {
+	cfg, scope, err := loadSessionConfig(request)
+	if err != nil {
+		return err
+	}
+	session, err := orchestrator.EndSession(cfg, scope)
+	if err != nil {
+		return fmt.Errorf("failed to end the session: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("no session is active")
+	}
+
+	fmt.Fprintf(w, "Ended session %q (%s)\n", session.Name, formatTurns(len(session.Turns)))
+	return nil
+}
+
+// formatTurns describes a number of session turns, e.g. "1 turn" or "4 turns"
+func formatTurns(n int) string {
+	if n == 1 {
+		return "1 turn"
+	}
+	return fmt.Sprintf("%d turns", n)
+}
+
+// recordSessionPrompt adds the prompt just output to the active session, if there is one
+func recordSessionPrompt(request *models.PromptRequest, cfg *interfaces.Config) {
+	if cfg.StateFile == "" {
+		return
+	}
+	scope, err := orchestrator.ProjectSessionScope()
+	if err != nil {
+		return
+	}
+	session, err := orchestrator.LoadSession(cfg, scope)
+	if err != nil || session == nil {
+		return
+	}
+	if _, err := orchestrator.AddSessionTurn(cfg, scope, orchestrator.SessionPromptTurn(request)); err != nil {
+		warnings.Add("failed to add the prompt to session %q: %v", session.Name, err)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestSessionCommands(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+	request := models.NewPromptRequest()
+	request.ConfigPath = filepath.Join(dir, "config.toml")
+	content := "prompts_location = \"" + filepath.Join(dir, "prompts") + "\"\nstate_file = \"" + filepath.Join(dir, "state.db") + "\"\n"
+	if err := os.WriteFile(request.ConfigPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := ShowSession(request, &out); err != nil || !strings.HasPrefix(out.String(), "No session is active.") {
+		t.Errorf("ShowSession() with no session = %q, %v", out.String(), err)
+	}
+	if err := StartSession(request, "auth", &out); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if err := AddSessionResponse(request, "", false, &out); err == nil {
+		t.Error("expected an error adding an empty response")
+	}
+	out.Reset()
+	if err := AddSessionResponse(request, "Use a middleware.", false, &out); err != nil {
+		t.Fatalf("AddSessionResponse() error = %v", err)
+	}
+	if out.String() != "Added a response to session \"auth\" (1 turn)\n" {
+		t.Errorf("AddSessionResponse() output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := ShowSession(request, &out); err != nil {
+		t.Fatalf("ShowSession() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Session \"auth\", started ") || !strings.Contains(out.String(), "1. response") || !strings.HasSuffix(out.String(), "Use a middleware.\n") {
+		t.Errorf("ShowSession() output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := EndSession(request, &out); err != nil || out.String() != "Ended session \"auth\" (1 turn)\n" {
+		t.Errorf("EndSession() = %q, %v", out.String(), err)
+	}
+	if err := EndSession(request, &out); err == nil {
+		t.Error("expected an error ending a session that isn't active")
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// stdinIsPiped reports whether stdin is a pipe or redirected file rather than a terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	mode := info.Mode()
+	return mode&os.ModeNamedPipe != 0 || mode.IsRegular()
+}
+
+// readsPipedStdin reports whether piped stdin is input to the request: when there's no base
+// prompt, "-" asks for it, or it's a redirected file. With a prompt given on its own a pipe
+// is left alone, since it may never close, e.g. under a CI runner or an editor.
+func readsPipedStdin(request *models.PromptRequest, stdin *os.File) bool {
+	if request.BasePrompt == "" || request.ReadStdin {
+		return true
+	}
+	info, err := stdin.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
+
+// applyPipedStdin routes piped input into the request: fix mode captures it as the
+// output to fix, otherwise it is fenced and appended to the base prompt
+func applyPipedStdin(request *models.PromptRequest, stdin io.Reader) error {
+	if request.FixMode {
+		if request.FixSource == "" && request.FixCommand == "" && request.FixFile == "" && len(request.FixTasks) == 0 {
+			request.FixSource = orchestrator.FixSourceStdin
+		}
+		return nil
+	}
+
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	request.BasePrompt = appendPipedInput(request.BasePrompt, string(content))
+	return nil
+}
+
+// appendPipedInput adds piped content to prompt as a fenced block
+func appendPipedInput(prompt, content string) string {
+	content = strings.Trim(content, "\n")
+	if strings.TrimSpace(content) == "" {
+		return prompt
+	}
+
+	fence := orchestrator.Fence(content)
+	block := fmt.Sprintf("%s%s\n%s\n%s", fence, pipedInputLanguage(content), content, fence)
+	if prompt == "" {
+		return block
+	}
+	return prompt + "\n\n" + block
+}
+
+// pipedInputLanguage guesses a fence language for common piped input
+func pipedInputLanguage(content string) string {
+	if strings.HasPrefix(content, "diff --git ") || strings.HasPrefix(content, "--- ") {
+		return "diff"
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestAppendPipedInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		content string
+		want    string
+	}{
+		{
+			name:    "appends fenced diff",
+			prompt:  "review this",
+			content: "diff --git a/x.go b/x.go\n+foo\n",
+			want:    "review this\n\n```diff\ndiff --git a/x.go b/x.go\n+foo\n```",
+		},
+		{
+			name:    "becomes the prompt when none given",
+			content: "some log output\n",
+			want:    "```\nsome log output\n```",
+		},
+		{
+			name:    "longer fence around backticks",
+			prompt:  "explain",
+			content: "```go\nfmt.Println()\n```",
+			want:    "explain\n\n````\n```go\nfmt.Println()\n```\n````",
+		},
+		{
+			name:    "empty input leaves prompt alone",
+			prompt:  "review this",
+			content: "\n\n",
+			want:    "review this",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendPipedInput(tt.prompt, tt.content); got != tt.want {
+				t.Errorf("appendPipedInput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPipedStdin(t *testing.T) {
+	t.Run("fix mode reads stdin as fix content", func(t *testing.T) {
+		request := &models.PromptRequest{FixMode: true}
+		if err := applyPipedStdin(request, strings.NewReader("error")); err != nil {
+			t.Fatal(err)
+		}
+		if request.FixSource != orchestrator.FixSourceStdin {
+			t.Errorf("FixSource = %q, want %q", request.FixSource, orchestrator.FixSourceStdin)
+		}
+	})
+
+	t.Run("fix mode keeps an explicit command", func(t *testing.T) {
+		request := &models.PromptRequest{FixMode: true, FixCommand: "make"}
+		if err := applyPipedStdin(request, strings.NewReader("error")); err != nil {
+			t.Fatal(err)
+		}
+		if request.FixSource != "" {
+			t.Errorf("FixSource = %q, want empty", request.FixSource)
+		}
+	})
+
+	t.Run("prompt mode appends to base prompt", func(t *testing.T) {
+		request := &models.PromptRequest{BasePrompt: "summarize"}
+		if err := applyPipedStdin(request, strings.NewReader("notes")); err != nil {
+			t.Fatal(err)
+		}
+		if request.BasePrompt != "summarize\n\n```\nnotes\n```" {
+			t.Errorf("BasePrompt = %q", request.BasePrompt)
+		}
+	})
+}
+
+func TestReadsPipedStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	redirected, err := os.Create(filepath.Join(t.TempDir(), "input.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redirected.Close()
+
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		stdin   *os.File
+		want    bool
+	}{
+		{"no base prompt", &models.PromptRequest{}, r, true},
+		{"base prompt and -", &models.PromptRequest{BasePrompt: "review this", ReadStdin: true}, r, true},
+		{"base prompt leaves a pipe alone", &models.PromptRequest{BasePrompt: "review this"}, r, false},
+		{"base prompt and a redirected file", &models.PromptRequest{BasePrompt: "review this"}, redirected, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readsPipedStdin(tt.request, tt.stdin); got != tt.want {
+				t.Errorf("readsPipedStdin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
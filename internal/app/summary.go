@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// runSummary collects what went into the prompt from observer events, for the line printed
+// once it is output
+type runSummary struct {
+	interfaces.NopObserver
+	Templates  int
+	Embedded   int // Files whose content is in the prompt
+	Referenced int // Files named by path only
+	Skipped    int // Files listed rather than embedded as binary, lock, minified, or generated
+	Output     *interfaces.OutputEvent
+}
+
+// OnTemplateResolved counts a template
+func (s *runSummary) OnTemplateResolved(interfaces.TemplateEvent) {
+	s.Templates++
+}
+
+// OnFileIncluded counts a file by how it was included
+func (s *runSummary) OnFileIncluded(event interfaces.FileEvent) {
+	switch {
+	case event.Skipped != "":
+		s.Skipped++
+	case event.Embedded:
+		s.Embedded++
+	default:
+		s.Referenced++
+	}
+}
+
+// OnOutputWritten records the prompt's size and target
+func (s *runSummary) OnOutputWritten(event interfaces.OutputEvent) {
+	s.Output = &event
+}
+
+// Print writes the summary, e.g. "2 templates, 3 files (1 skipped), 12480 bytes, ~3120
+// tokens to clipboard", or nothing when no prompt was output. cached marks a prompt reused
+// with --cache, whose templates and files weren't read this time.
+func (s *runSummary) Print(w io.Writer, cached bool) {
+	if s.Output == nil {
+		return
+	}
+
+	var parts []string
+	if cached {
+		parts = append(parts, "cached prompt")
+	} else {
+		parts = append(parts, plural(s.Templates, "template"))
+		if files := s.Embedded + s.Referenced + s.Skipped; files > 0 {
+			var detail []string
+			if s.Referenced > 0 {
+				detail = append(detail, fmt.Sprintf("%d by path", s.Referenced))
+			}
+			if s.Skipped > 0 {
+				detail = append(detail, fmt.Sprintf("%d skipped", s.Skipped))
+			}
+			label := plural(files, "file")
+			if len(detail) > 0 {
+				label += " (" + strings.Join(detail, ", ") + ")"
+			}
+			parts = append(parts, label)
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d bytes", s.Output.Bytes), fmt.Sprintf("~%d tokens to %s", s.Output.Tokens, s.Output.Target))
+	fmt.Fprintln(w, strings.Join(parts, ", "))
+}
+
+// plural formats n and noun, adding an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestRunSummaryPrint(t *testing.T) {
+	output := interfaces.OutputEvent{Target: "clipboard", Bytes: 12480, Tokens: 3120}
+	tests := []struct {
+		name   string
+		events func(s *runSummary)
+		cached bool
+		want   string
+	}{
+		{
+			name:   "nothing output",
+			events: func(s *runSummary) { s.OnTemplateResolved(interfaces.TemplateEvent{}) },
+			want:   "",
+		},
+		{
+			name: "templates and files",
+			events: func(s *runSummary) {
+				s.OnTemplateResolved(interfaces.TemplateEvent{Type: "pre"})
+				s.OnTemplateResolved(interfaces.TemplateEvent{Type: "post"})
+				s.OnFileIncluded(interfaces.FileEvent{Path: "main.go", Embedded: true})
+				s.OnFileIncluded(interfaces.FileEvent{Path: "docs"})
+				s.OnFileIncluded(interfaces.FileEvent{Path: "go.sum", Skipped: "lock file"})
+				s.OnOutputWritten(output)
+			},
+			want: "2 templates, 3 files (1 by path, 1 skipped), 12480 bytes, ~3120 tokens to clipboard\n",
+		},
+		{
+			name: "one embedded file",
+			events: func(s *runSummary) {
+				s.OnTemplateResolved(interfaces.TemplateEvent{Type: "pre"})
+				s.OnFileIncluded(interfaces.FileEvent{Path: "main.go", Embedded: true})
+				s.OnOutputWritten(output)
+			},
+			want: "1 template, 1 file, 12480 bytes, ~3120 tokens to clipboard\n",
+		},
+		{
+			name:   "no files",
+			events: func(s *runSummary) { s.OnOutputWritten(output) },
+			want:   "0 templates, 12480 bytes, ~3120 tokens to clipboard\n",
+		},
+		{
+			name:   "cached",
+			events: func(s *runSummary) { s.OnOutputWritten(output) },
+			cached: true,
+			want:   "cached prompt, 12480 bytes, ~3120 tokens to clipboard\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &runSummary{}
+			tt.events(summary)
+			var buf bytes.Buffer
+			summary.Print(&buf, tt.cached)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Print() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
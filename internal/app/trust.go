@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// TrustWorkspace records whether project-local templates in the current directory may be used
+func TrustWorkspace(request *models.PromptRequest, trusted bool) error {
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	dir, err := orchestrator.WorkspaceDir()
+	if err != nil {
+		return err
+	}
+	if err := orchestrator.RecordWorkspaceTrust(cfg, dir, trusted); err != nil {
+		return fmt.Errorf("failed to record workspace trust: %w", err)
+	}
+
+	if trusted {
+		fmt.Printf("Trusted %s\n", contractPath(dir))
+	} else {
+		fmt.Printf("Project-local templates in %s will be ignored\n", contractPath(dir))
+	}
+	return nil
+}
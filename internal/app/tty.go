@@ -0,0 +1,59 @@
+package app
+
+import (
+	"slices"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// terminalTargets are output targets meant for someone at a terminal rather than a pipe
+var terminalTargets = []string{"clipboard", "tmux", "osc52"}
+
+// applyTerminalMode turns off prompts and color unless stdin and stdout are both terminals,
+// since raw-mode selection needs one and escape codes would end up in the pipe. When stdout
+// is redirected, a configured clipboard, tmux, or osc52 target becomes stdout, so
+// `prompter "x" | llm` works; -i and an explicit --target are kept.
+func applyTerminalMode(request *models.PromptRequest, cfg *interfaces.Config, stdinTerminal, stdoutTerminal bool) {
+	if stdinTerminal && stdoutTerminal {
+		return
+	}
+	if !request.ForceInteractive {
+		request.Interactive = false
+	}
+	interactive.DisableColor()
+
+	if !stdoutTerminal && request.Target == "" && slices.Contains(terminalTargets, cfg.Target) {
+		request.Target = "stdout"
+	}
+}
+
+// applyCIMode makes a --ci run behave as if neither stdin nor stdout were a terminal, and
+// fails up front on flags that can only work by asking. Prompts reached later, such as
+// workspace trust or a missing base prompt, fail with ErrInteractionRequired.
+func applyCIMode(request *models.PromptRequest, cfg *interfaces.Config) error {
+	if request.ForceInteractive {
+		return orchestrator.NewValidationError("ci", "-i", "can't be combined with --ci")
+	}
+	if request.EditorRequested {
+		return orchestrator.NewInteractionRequiredError("edits in --editor", "Drop --editor, or review the prompt in a later step.")
+	}
+	if request.Preview {
+		return orchestrator.NewInteractionRequiredError("confirmation in --preview", "Drop --preview; the prompt goes straight to the target.")
+	}
+
+	request.ForceNonInteractive = true
+	applyTerminalMode(request, cfg, false, false)
+	cfg.PreviewOutput = false
+	return nil
+}
+
+// terminalAttached reports whether stdin and stdout are terminals
+func terminalAttached() (stdin, stdout bool) {
+	return term.IsTerminal(int(syscall.Stdin)), term.IsTerminal(int(syscall.Stdout))
+}
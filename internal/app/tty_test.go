@@ -0,0 +1,68 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestApplyTerminalMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		stdin, stdout   bool
+		force           bool
+		target          string
+		configTarget    string
+		wantInteractive bool
+		wantTarget      string
+	}{
+		{name: "terminal", stdin: true, stdout: true, configTarget: "clipboard", wantInteractive: true},
+		{name: "piped stdin keeps the clipboard", stdin: false, stdout: true, configTarget: "clipboard"},
+		{name: "redirected stdout", stdin: true, stdout: false, configTarget: "clipboard", wantTarget: "stdout"},
+		{name: "redirected stdout keeps a file target", stdin: true, stdout: false, configTarget: "file:/tmp/prompt.md"},
+		{name: "explicit target", stdin: true, stdout: false, target: "tmux", configTarget: "clipboard", wantTarget: "tmux"},
+		{name: "-i", stdin: true, stdout: false, force: true, configTarget: "osc52", wantInteractive: true, wantTarget: "stdout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &models.PromptRequest{Interactive: true, ForceInteractive: tt.force, Target: tt.target}
+			applyTerminalMode(request, &interfaces.Config{Target: tt.configTarget}, tt.stdin, tt.stdout)
+			if request.Interactive != tt.wantInteractive || request.Target != tt.wantTarget {
+				t.Errorf("applyTerminalMode() = interactive %t, target %q, want %t, %q",
+					request.Interactive, request.Target, tt.wantInteractive, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestApplyCIMode(t *testing.T) {
+	request := &models.PromptRequest{Interactive: true, CI: true}
+	cfg := &interfaces.Config{Target: "clipboard", PreviewOutput: true}
+	if err := applyCIMode(request, cfg); err != nil {
+		t.Fatalf("applyCIMode() error = %v", err)
+	}
+	if request.Interactive || !request.ForceNonInteractive || request.Target != "stdout" || cfg.PreviewOutput {
+		t.Errorf("applyCIMode() left request %+v, config %+v", request, cfg)
+	}
+
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		want    int
+	}{
+		{name: "-i", request: &models.PromptRequest{ForceInteractive: true}, want: orchestrator.ExitValidation},
+		{name: "--editor", request: &models.PromptRequest{EditorRequested: true}, want: orchestrator.ExitInteraction},
+		{name: "--preview", request: &models.PromptRequest{Preview: true}, want: orchestrator.ExitInteraction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyCIMode(tt.request, &interfaces.Config{})
+			if got := orchestrator.ExitCode(err); got != tt.want {
+				t.Errorf("applyCIMode() error = %v, exit code %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"time"
+
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// templateUsageLoader reads template usage from the state store for the pickers, which fall
+// back to ordering by name, with a warning, when it can't be read
+func templateUsageLoader(cfg *interfaces.Config) interactive.UsageLoader {
+	return func(templateType string) map[string]interfaces.TemplateUsage {
+		usage, err := orchestrator.LoadTemplateUsage(cfg, templateType)
+		if err != nil {
+			warnings.Add("template_order: can't read template usage (%v); ordering by name", err)
+		}
+		return usage
+	}
+}
+
+// recordTemplateUsage counts the templates of a prompt that was output, for template_order
+func recordTemplateUsage(request *models.PromptRequest, cfg *interfaces.Config) {
+	if cfg.StateFile == "" {
+		return
+	}
+	if err := orchestrator.RecordTemplateUsage(cfg, request, time.Now()); err != nil {
+		warnings.Add("failed to record template usage: %v", err)
+	}
+}
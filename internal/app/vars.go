@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// varsScope returns the scope the vars commands work on, global unless project is set, and
+// how to describe it
+func varsScope(project bool) (scope, label string, err error) {
+	if !project {
+		return orchestrator.VarsGlobalScope, "globally", nil
+	}
+	scope, err = orchestrator.ProjectVarsScope()
+	if err != nil {
+		return "", "", err
+	}
+	return scope, "for " + contractPath(scope), nil
+}
+
+// loadVarsConfig loads the configuration the vars commands read the state file from
+func loadVarsConfig(request *models.PromptRequest) (*interfaces.Config, error) {
+	cfg, err := orchestrator.New().LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+	return cfg, nil
+}
+
+// SetVar stores a variable templates read as .Vars.<name>, for the current project or globally
+func SetVar(request *models.PromptRequest, name, value string, project bool) error {
+	cfg, err := loadVarsConfig(request)
+	if err != nil {
+		return err
+	}
+	scope, label, err := varsScope(project)
+	if err != nil {
+		return err
+	}
+	if err := orchestrator.SetVar(cfg, scope, name, value); err != nil {
+		return fmt.Errorf("failed to set %s: %w", name, err)
+	}
+
+	fmt.Printf("Set %s %s\n", name, label)
+	return nil
+}
+
+// UnsetVar removes a variable set with SetVar
+func UnsetVar(request *models.PromptRequest, name string, project bool) error {
+	cfg, err := loadVarsConfig(request)
+	if err != nil {
+		return err
+	}
+	scope, label, err := varsScope(project)
+	if err != nil {
+		return err
+	}
+	removed, err := orchestrator.UnsetVar(cfg, scope, name)
+	if err != nil {
+		return fmt.Errorf("failed to unset %s: %w", name, err)
+	}
+	if !removed {
+		return fmt.Errorf("%s is not set %s", name, label)
+	}
+
+	fmt.Printf("Unset %s %s\n", name, label)
+	return nil
+}
+
+// ListVars writes the global variables and the current project's to w. A project variable
+// replaces a global one with the same name.
+func ListVars(request *models.PromptRequest, w io.Writer) error {
+	cfg, err := loadVarsConfig(request)
+	if err != nil {
+		return err
+	}
+	project, err := orchestrator.ProjectVarsScope()
+	if err != nil {
+		return err
+	}
+	global, err := orchestrator.LoadVars(cfg, orchestrator.VarsGlobalScope)
+	if err != nil {
+		return err
+	}
+	local, err := orchestrator.LoadVars(cfg, project)
+	if err != nil {
+		return err
+	}
+
+	if len(global) == 0 && len(local) == 0 {
+		fmt.Fprintln(w, "No variables set. Add one with 'prompter vars set <name> <value>' (--project for this project only).")
+		return nil
+	}
+	writeVars(w, "Global:", global, local)
+	writeVars(w, fmt.Sprintf("Project (%s):", contractPath(project)), local, nil)
+	return nil
+}
+
+// writeVars writes vars under heading, marking the ones override replaces
+func writeVars(w io.Writer, heading string, vars, override map[string]string) {
+	if len(vars) == 0 {
+		return
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fmt.Fprintln(w, heading)
+	for _, name := range names {
+		line := fmt.Sprintf("  %s = %q", name, vars[name])
+		if _, replaced := override[name]; replaced {
+			line += " (replaced by the project's)"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestListVars(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+	request := models.NewPromptRequest()
+	request.ConfigPath = filepath.Join(dir, "config.toml")
+	content := "prompts_location = \"" + filepath.Join(dir, "prompts") + "\"\nstate_file = \"" + filepath.Join(dir, "state.db") + "\"\n"
+	if err := os.WriteFile(request.ConfigPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := ListVars(request, &out); err != nil {
+		t.Fatalf("ListVars() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "No variables set.") {
+		t.Errorf("ListVars() with nothing set = %q", out.String())
+	}
+
+	if err := SetVar(request, "team", "payments", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVar(request, "team", "billing", true); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := ListVars(request, &out); err != nil {
+		t.Fatalf("ListVars() error = %v", err)
+	}
+	for _, want := range []string{"Global:\n  team = \"payments\" (replaced by the project's)\n", "  team = \"billing\"\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("ListVars() output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := UnsetVar(request, "service", false); err == nil {
+		t.Error("expected an error unsetting a variable that isn't set")
+	}
+}
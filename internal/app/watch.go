@@ -0,0 +1,224 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/imdevan/prompter/internal/diagnostics"
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// DefaultWatchInterval is how often watch reads the clipboard
+const DefaultWatchInterval = time.Second
+
+// watchDebounce is how long a watched file must be quiet before its prompt is assembled
+// again; build logs are written in bursts
+const watchDebounce = 200 * time.Millisecond
+
+// errorPattern matches the start of common stack traces and error reports, for copied content
+// the diagnostics parser doesn't recognize
+var errorPattern = regexp.MustCompile(`(?m)^(panic: |fatal error: |Traceback \(most recent call last\):|Exception in thread |Caused by: |\s+at [\w$.<>]+ ?\(.*:\d+|(Uncaught )?\w*(Error|Exception)\b.*: )`)
+
+// WatchClipboard watches the clipboard until interrupted. Each time new content matching
+// pattern is copied (a stack trace or compiler error when pattern is empty), it assembles a fix
+// prompt from it with the configured templates and outputs it to request's target, the
+// clipboard unless set.
+func WatchClipboard(request *models.PromptRequest, pattern string, interval time.Duration, w io.Writer) error {
+	match := looksLikeError
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid --match pattern: %w", err)
+		}
+		match = re.MatchString
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	// The prompt replaces the error that was copied, unless the command line sends it elsewhere
+	if request.Target == "" {
+		request.Target = "clipboard"
+	}
+
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return watchClipboard(ctx, w, getClipboardContent, interval, match, func(content string) (string, error) {
+		fix := *request
+		fix.FixMode = true
+		fix.FixSource = orchestrator.FixSourceClipboard
+		fix.Interactive = false
+
+		prompt, err := generatePrompt(ctx, orch, &fix)
+		if err != nil {
+			return "", err
+		}
+		if err := orch.OutputPrompt(ctx, prompt, &fix, cfg); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(w, "%s  fix prompt for %s assembled\n", time.Now().Format("15:04:05"), describeCopied(content))
+		return prompt, nil
+	})
+}
+
+// watchClipboard polls read every interval until ctx is done, calling handle with each new
+// content match accepts. handle returns what it wrote back, which isn't handled again.
+// Content already on the clipboard when watching starts is left alone.
+func watchClipboard(ctx context.Context, w io.Writer, read func() (string, error), interval time.Duration, match func(string) bool, handle func(content string) (string, error)) error {
+	last, err := read()
+	if err != nil && !strings.Contains(err.Error(), "empty") {
+		return err
+	}
+	fmt.Fprintln(w, "Watching the clipboard for errors; press Ctrl-C to stop.")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var written string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		content, err := read()
+		if err != nil || content == last {
+			continue
+		}
+		last = content
+		if content == written || !match(content) {
+			continue
+		}
+
+		if written, err = handle(content); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		written = strings.TrimSpace(written)
+	}
+}
+
+// WatchFile assembles a fix prompt from path, as --fix-file would, and outputs it to
+// request's target, then again each time path changes, until interrupted
+func WatchFile(request *models.PromptRequest, path string, w io.Writer) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	orch := orchestrator.New()
+	cfg, err := orch.LoadConfiguration(request.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", orchestrator.ErrConfigurationInvalid, err)
+	}
+
+	// The file is a log, not a shell hook capture: a one-line log mustn't be re-run as a command
+	os.Unsetenv("PROMPTER_HOOK")
+
+	// Watch the directory, so the file is still followed when it's replaced or created later
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", contractPath(path), err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", contractPath(path), err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(w, "Watching %s; press Ctrl-C to stop.\n", contractPath(path))
+	return watchFile(ctx, watcher.Events, watcher.Errors, path, watchDebounce, func() error {
+		fix := *request
+		fix.FixMode = true
+		fix.FixSource = orchestrator.FixSourceFile
+		fix.FixFile = path
+		fix.Interactive = false
+
+		prompt, err := generatePrompt(ctx, orch, &fix)
+		if err != nil {
+			return err
+		}
+		if err := orch.OutputPrompt(ctx, prompt, &fix, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s  fix prompt for %s assembled\n", time.Now().Format("15:04:05"), filepath.Base(path))
+		return nil
+	})
+}
+
+// watchFile calls handle for path's content as it is now, then each time events show path
+// changed and it has been quiet for debounce. Empty and unchanged content is skipped.
+func watchFile(ctx context.Context, events <-chan fsnotify.Event, errs <-chan error, path string, debounce time.Duration, handle func() error) error {
+	var last []byte
+	update := func() {
+		content, err := os.ReadFile(path)
+		if err != nil || len(bytes.TrimSpace(content)) == 0 || bytes.Equal(content, last) {
+			return
+		}
+		last = content
+		if err := handle(); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	update()
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: watching %s: %v\n", contractPath(path), err)
+		case <-timer.C:
+			update()
+		}
+	}
+}
+
+// looksLikeError reports whether copied content is compiler, test, or linter output or a
+// stack trace
+func looksLikeError(content string) bool {
+	return errorPattern.MatchString(content) || len(diagnostics.Parse(content)) > 0
+}
+
+// describeCopied summarizes copied content by its first line and length
+func describeCopied(content string) string {
+	first, _, _ := strings.Cut(content, "\n")
+	if runes := []rune(first); len(runes) > 60 {
+		first = string(runes[:60]) + "..."
+	}
+	lines := strings.Count(content, "\n") + 1
+	if lines == 1 {
+		return fmt.Sprintf("%q", first)
+	}
+	return fmt.Sprintf("%q (%d lines)", first, lines)
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatchClipboard(t *testing.T) {
+	// Each read returns the next copy, then the last one again
+	copies := []string{
+		"already there: panic: old",
+		"panic: runtime error: index out of range",
+		"FIX PROMPT", // The handler's own output
+		"just some notes",
+		"panic: runtime error: index out of range", // Copied again after the prompt replaced it
+	}
+	reads := 0
+	read := func() (string, error) {
+		content := copies[min(reads, len(copies)-1)]
+		reads++
+		return content, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var handled []string
+	handle := func(content string) (string, error) {
+		handled = append(handled, content)
+		if len(handled) == 2 {
+			cancel()
+		}
+		return "FIX PROMPT", nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- watchClipboard(ctx, io.Discard, read, time.Millisecond, looksLikeError, handle) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watchClipboard() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchClipboard() did not stop")
+	}
+
+	want := []string{copies[1], copies[4]}
+	if !slices.Equal(handled, want) {
+		t.Errorf("handled %q, want %q", handled, want)
+	}
+}
+
+func TestWatchClipboardUnavailable(t *testing.T) {
+	read := func() (string, error) { return "", fmt.Errorf("failed to read from clipboard: no xclip") }
+	err := watchClipboard(context.Background(), io.Discard, read, time.Millisecond, looksLikeError, nil)
+	if err == nil {
+		t.Error("expected an error when the clipboard can't be read")
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build.log")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go:3: undefined: x\n")
+
+	events := make(chan fsnotify.Event)
+	handled := make(chan string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watchFile(ctx, events, nil, path, time.Millisecond, func() error {
+			content, _ := os.ReadFile(path)
+			handled <- string(content)
+			return nil
+		})
+	}()
+	next := func() string {
+		select {
+		case content := <-handled:
+			return content
+		case <-time.After(5 * time.Second):
+			t.Fatal("watchFile() did not assemble a prompt")
+			return ""
+		}
+	}
+
+	if got := next(); got != "main.go:3: undefined: x\n" {
+		t.Errorf("first prompt from %q, want the file as watching starts", got)
+	}
+
+	// A truncated log and other files in the directory don't count as changes
+	write("")
+	events <- fsnotify.Event{Name: path, Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: filepath.Join(filepath.Dir(path), "other.log"), Op: fsnotify.Write}
+	write("main.go:4: undefined: y\n")
+	events <- fsnotify.Event{Name: path, Op: fsnotify.Write}
+	if got := next(); got != "main.go:4: undefined: y\n" {
+		t.Errorf("prompt after a change from %q", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watchFile() error = %v", err)
+	}
+	if len(handled) != 0 {
+		t.Errorf("handled content %q more than once", <-handled)
+	}
+}
+
+func TestLooksLikeError(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"panic: runtime error: invalid memory address", true},
+		{"Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>", true},
+		{"TypeError: Cannot read properties of undefined (reading 'id')\n    at render (app.js:12:5)", true},
+		{"Exception in thread \"main\" java.lang.NullPointerException", true},
+		{"./main.go:12:5: undefined: foo", true},
+		{"meeting notes: ship on friday", false},
+		{"https://example.com/error", false},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeError(tt.content); got != tt.want {
+			t.Errorf("looksLikeError(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package config
 
 import (
+	"github.com/imdevan/prompter/internal/interfaces"
 	"testing"
-	"prompter-cli/internal/interfaces"
 )
 
 // TestManagerImplementsInterface verifies that Manager implements ConfigManager interface
@@ -4,16 +4,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/update"
+	"github.com/imdevan/prompter/internal/warnings"
 	"github.com/spf13/viper"
-	"prompter-cli/internal/interfaces"
 )
 
 // Manager implements the ConfigManager interface
 type Manager struct {
-	v     *viper.Viper
-	flags map[string]interface{} // Store flag values for precedence
+	v        *viper.Viper
+	flags    map[string]interface{} // Store flag values for precedence
+	profile  *viper.Viper           // The applied [profiles.<name>] table, for Explain
+	warnings *warnings.Channel      // Where the old config location is reported
 }
 
 // NewManager creates a new configuration manager
@@ -28,11 +36,17 @@ func NewManager() *Manager {
 	setDefaults(v)
 
 	return &Manager{
-		v:     v,
-		flags: make(map[string]interface{}),
+		v:        v,
+		flags:    make(map[string]interface{}),
+		warnings: warnings.Default,
 	}
 }
 
+// SetWarnings sets where warnings raised while loading go, in place of warnings.Default
+func (m *Manager) SetWarnings(channel *warnings.Channel) {
+	m.warnings = channel
+}
+
 // SetConfigPath sets the configuration file path
 func (m *Manager) SetConfigPath(path string) {
 	if path != "" {
@@ -42,26 +56,90 @@ func (m *Manager) SetConfigPath(path string) {
 
 // setDefaults sets the default configuration values
 func setDefaults(v *viper.Viper) {
-	v.SetDefault("prompts_location", "~/.config/prompter/prompts")
+	v.SetDefault("prompts_location", DefaultPromptsLocation())
 	v.SetDefault("local_prompts_location", "")
 	v.SetDefault("editor", "nvim")
+	v.SetDefault("editor_wait", true)
+	v.SetDefault("base_prompt_input", "line")
+	v.SetDefault("template_order", "name")
+	v.SetDefault("clipboard_verify", false)
+	v.SetDefault("clipboard_history_command", "")
+	v.SetDefault("clipboard_history_size", 10)
 	v.SetDefault("default_pre", "")
 	v.SetDefault("default_post", "")
-	v.SetDefault("fix_file", "/tmp/prompter-fix.txt")
+	v.SetDefault("fix_default_pre", "")
+	v.SetDefault("fix_default_post", "")
+	v.SetDefault("fix_file", defaultLocation(StateDir, "last-command"))
+	v.SetDefault("fix_source", "")
+	v.SetDefault("script_file", "")
+	v.SetDefault("fix_review", true)
+	v.SetDefault("preview_output", false)
+	v.SetDefault("fix_embed_files", true)
+	v.SetDefault("large_files", "path")
+	v.SetDefault("strip_ansi", true)
+	v.SetDefault("noise_default_filters", true)
+	v.SetDefault("noise_filters", []string{})
+	v.SetDefault("redact", true)
+	v.SetDefault("redact_patterns", []string{})
 	v.SetDefault("directory_strategy", "git")
 	v.SetDefault("target", "clipboard")
 	v.SetDefault("interactive_default", true)
+	v.SetDefault("minimal_auto", true)
+	v.SetDefault("invocation_log", false)
+	v.SetDefault("invocation_log_file", defaultLocation(DataDir, "invocations.jsonl"))
+	v.SetDefault("state_file", defaultLocation(DataDir, "state.db"))
+	v.SetDefault("history", true)
+	v.SetDefault("workspace_trust", true)
+	v.SetDefault("update_check", false)
+	v.SetDefault("update_check_url", update.DefaultReleaseURL)
+	v.SetDefault("pack_index_url", "")
+	v.SetDefault("url_markdown", true)
+	v.SetDefault("github_token", "")
+	v.SetDefault("github_api_url", "https://api.github.com")
+	v.SetDefault("jira_url", "")
+	v.SetDefault("jira_email", "")
+	v.SetDefault("jira_token", "")
+	v.SetDefault("jira_acceptance_field", "")
+	v.SetDefault("encryption_tool", "gpg")
+	v.SetDefault("encryption_recipient", "")
+	v.SetDefault("age_identity", "")
+	v.SetDefault("allow_template_exec", false)
+	v.SetDefault("template_exec_timeout", "10s")
+	v.SetDefault("token_budget", 0)
+	v.SetDefault("template_token_limit", "warn")
+	v.SetDefault("redirect_deprecated", false)
+	v.SetDefault("profile", "")
+}
+
+// DefaultConfigPath returns the config file used when no --config is given, config.toml in
+// ConfigDir
+func DefaultConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
 }
 
 // Load loads configuration from the specified path
 func (m *Manager) Load(path string) (*interfaces.Config, error) {
 	if path == "" {
 		// Use default config path
-		homeDir, err := os.UserHomeDir()
+		defaultPath, err := DefaultConfigPath()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+			return nil, err
+		}
+		path = defaultPath
+
+		if legacy, ok := LegacyDirInUse(); ok {
+			if dir, err := configHome(); err == nil {
+				m.warnings.Add("using %s, the old config location; run 'prompter config migrate' to move it to %s", homePath(legacy), homePath(dir))
+			}
+		} else if legacy, ok := LegacyDataInUse(); ok {
+			if dir, err := dataHome(); err == nil {
+				m.warnings.Add("using the state in %s, the old data location; run 'prompter config migrate' to move it to %s", homePath(legacy), homePath(dir))
+			}
 		}
-		path = filepath.Join(homeDir, ".config", "prompter", "config.toml")
 	}
 
 	// Expand tilde in path
@@ -76,6 +154,9 @@ func (m *Manager) Load(path string) (*interfaces.Config, error) {
 	// Check if config file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		// Config file doesn't exist, use defaults
+		if err := m.applyProfile(); err != nil {
+			return nil, err
+		}
 		return m.getConfigFromViper(), nil
 	}
 
@@ -85,16 +166,192 @@ func (m *Manager) Load(path string) (*interfaces.Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 	}
 
+	if err := m.applyProfile(); err != nil {
+		return nil, err
+	}
+
 	return m.getConfigFromViper(), nil
 }
 
+// applyProfile layers the selected [profiles.<name>] table over the config file, so its keys
+// still give way to environment variables and flags. The profile is chosen with the profile
+// key or PROMPTER_PROFILE, which --profile sets.
+func (m *Manager) applyProfile() error {
+	name := strings.ToLower(m.v.GetString("profile"))
+	if name == "" {
+		return nil
+	}
+
+	profiles := m.v.GetStringMap("profiles")
+	settings, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for profile := range profiles {
+			names = append(names, profile)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: the config file has no [profiles] tables", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	if err := m.v.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to apply profile %q: %w", name, err)
+	}
+	m.profile = viper.New()
+	return m.profile.MergeConfigMap(settings)
+}
+
+// namedTables are config tables whose keys are names the user picks, e.g. [targets]
+var namedTables = []string{"custom_template", "template_group", "budget_weights", "targets", "tasks"}
+
+// isKnownKey reports whether prompter reads the config key. Keys in a profile, such as
+// profiles.work.target, are checked as the key they set.
+func isKnownKey(key string, known []string) bool {
+	if profileKey, ok := strings.CutPrefix(key, "profiles."); ok {
+		if _, key, ok = strings.Cut(profileKey, "."); !ok || key == "profile" {
+			return false
+		}
+	}
+	table, _, nested := strings.Cut(key, ".")
+	return slices.Contains(known, key) || (nested && slices.Contains(namedTables, table))
+}
+
+// UnknownKeys returns the keys set in the config file at path that prompter doesn't read,
+// such as misspellings, sorted
+func UnknownKeys(path string) ([]string, error) {
+	file := viper.New()
+	file.SetConfigType("toml")
+	file.SetConfigFile(expandPath(path))
+	if err := file.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	defaults := viper.New()
+	setDefaults(defaults)
+	known := defaults.AllKeys()
+
+	var unknown []string
+	for _, key := range file.AllKeys() {
+		if isKnownKey(key, known) {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
 // SetFlag sets a flag value for precedence resolution
 func (m *Manager) SetFlag(key string, value interface{}) {
 	m.flags[key] = value
 }
 
+// ParseOverride splits a key=value config override, as --set gives it, converting the value
+// to the key's type. Only top-level keys with a default can be set.
+func ParseOverride(assignment string) (string, interface{}, error) {
+	key, value, ok := strings.Cut(assignment, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("invalid override %q: must be key=value", assignment)
+	}
+
+	defaults := viper.New()
+	setDefaults(defaults)
+	if !slices.Contains(defaults.AllKeys(), key) {
+		return "", nil, fmt.Errorf("invalid override %q: unknown config key %s", assignment, key)
+	}
+	if key == "profile" {
+		// The profile is applied while the config file loads, before overrides
+		return "", nil, fmt.Errorf("invalid override %q: use --profile to pick a profile", assignment)
+	}
+
+	switch defaults.Get(key).(type) {
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid override %q: %s must be true or false", assignment, key)
+		}
+		return key, b, nil
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid override %q: %s must be a whole number", assignment, key)
+		}
+		return key, n, nil
+	}
+	return key, value, nil
+}
+
+// Sources a config value can come from, highest precedence first
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "environment"
+	SourceProfile = "profile"
+	SourceFile    = "config file"
+	SourceDefault = "default"
+)
+
+// Layer is one place that sets a config key
+type Layer struct {
+	Source string      // One of the Source constants
+	Detail string      // The flag, variable, profile, or file that sets it
+	Value  interface{} // The value as that layer gives it
+}
+
+// Explain returns the resolved value of key and every layer that sets it, highest precedence
+// first, so the first layer supplied the value. It asks viper for the value and checks the
+// layers in viper's order; call it after Load and Resolve.
+func (m *Manager) Explain(key string) (interface{}, []Layer, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if strings.HasPrefix(key, "profiles") {
+		return nil, nil, fmt.Errorf("profiles are a layer, not a key: explain a key a profile sets, e.g. target")
+	}
+	defaults := viper.New()
+	setDefaults(defaults)
+	if !isKnownKey(key, defaults.AllKeys()) && !slices.Contains(namedTables, key) {
+		return nil, nil, fmt.Errorf("unknown config key %s", key)
+	}
+
+	var layers []Layer
+	if val, ok := m.flags[key]; ok && val != nil {
+		layers = append(layers, Layer{SourceFlag, "--set " + key, val})
+	}
+	env := "PROMPTER_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if val := os.Getenv(env); val != "" {
+		// Like viper, an empty variable counts as unset
+		layers = append(layers, Layer{SourceEnv, env, val})
+	}
+	if m.profile != nil && m.profile.IsSet(key) {
+		layers = append(layers, Layer{SourceProfile, "profiles." + strings.ToLower(m.v.GetString("profile")), m.profile.Get(key)})
+	}
+	if path := m.v.ConfigFileUsed(); path != "" {
+		// The profile was merged into the file's values, so read the file on its own
+		file := viper.New()
+		file.SetConfigType("toml")
+		file.SetConfigFile(path)
+		if err := file.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if file.IsSet(key) {
+			layers = append(layers, Layer{SourceFile, path, file.Get(key)})
+		}
+	}
+	if defaults.IsSet(key) {
+		layers = append(layers, Layer{SourceDefault, "", defaults.Get(key)})
+	}
+	return m.v.Get(key), layers, nil
+}
+
 // Resolve applies precedence rules (flags > env > config > defaults)
 func (m *Manager) Resolve() (*interfaces.Config, error) {
+	// Flags go through viper first, so every key can be overridden (e.g. with --set) and is
+	// read like a config file value; the typed overrides below then apply on top
+	for key, val := range m.flags {
+		if val != nil {
+			m.v.Set(key, val)
+		}
+	}
 	config := m.getConfigFromViper()
 
 	// Apply flag overrides (highest precedence)
@@ -158,6 +415,12 @@ func (m *Manager) applyFlagOverrides(config *interfaces.Config) {
 			config.InteractiveDefault = b
 		}
 	}
+
+	if val, exists := m.flags["token_budget"]; exists && val != nil {
+		if n, ok := val.(int); ok && n > 0 {
+			config.TokenBudget = n
+		}
+	}
 }
 
 // Validate validates the configuration values
@@ -175,14 +438,120 @@ func (m *Manager) Validate(config *interfaces.Config) error {
 		return fmt.Errorf("invalid directory_strategy: %s (must be 'git' or 'filesystem')", config.DirectoryStrategy)
 	}
 
-	// Validate target
-	validTargets := map[string]bool{
-		"clipboard": true,
-		"stdout":    true,
+	// Validate template encryption tool (empty uses gpg)
+	validEncryptionTools := map[string]bool{
+		"":    true,
+		"age": true,
+		"gpg": true,
+	}
+	if !validEncryptionTools[config.EncryptionTool] {
+		return fmt.Errorf("invalid encryption_tool: %s (must be 'age' or 'gpg')", config.EncryptionTool)
+	}
+
+	// Validate target, which may name an alias
+	target := config.Target
+	if spec, ok := config.Targets[target]; ok {
+		target = spec
+	}
+	if !IsTargetSpec(target) {
+		return fmt.Errorf("invalid target: %s (must be 'clipboard', 'tmux', 'osc52', 'stdout', 'file:/path', 'file+:/path', 'file+prepend:/path', 'cmd:command', or a [targets] alias)", config.Target)
+	}
+	for name, spec := range config.Targets {
+		if IsTargetSpec(name) {
+			return fmt.Errorf("invalid target alias: %s (shadows a built-in target)", name)
+		}
+		if !IsTargetSpec(spec) {
+			return fmt.Errorf("invalid target alias %s: %s (must be 'clipboard', 'tmux', 'osc52', 'stdout', 'file:/path', 'file+:/path', 'file+prepend:/path', or 'cmd:command')", name, spec)
+		}
+	}
+
+	// Tasks run through the shell, so each needs a command
+	for name, command := range config.Tasks {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("invalid tasks.%s: the command is empty", name)
+		}
+	}
+
+	// Validate token budget and weights
+	if config.TokenBudget < 0 {
+		return fmt.Errorf("invalid token_budget: %d (must be 0 or greater)", config.TokenBudget)
+	}
+	for class, weight := range config.BudgetWeights {
+		if !validBudgetClasses[class] {
+			return fmt.Errorf("invalid budget_weights class: %s (must be one of base, fix, diff, context, files, tree)", class)
+		}
+		if weight < 0 {
+			return fmt.Errorf("invalid budget_weights.%s: %v (must be 0 or greater)", class, weight)
+		}
+	}
+
+	// Validate how template max_tokens caps are enforced (empty warns)
+	validTokenLimits := map[string]bool{
+		"":      true,
+		"warn":  true,
+		"error": true,
+		"off":   true,
+	}
+	if !validTokenLimits[config.TemplateTokenLimit] {
+		return fmt.Errorf("invalid template_token_limit: %s (must be 'warn', 'error', or 'off')", config.TemplateTokenLimit)
+	}
+
+	// Validate how interactive runs ask for the base prompt (empty asks for one line)
+	switch config.BasePromptInput {
+	case "", "line", "multiline", "editor":
+	default:
+		return fmt.Errorf("invalid base_prompt_input: %s (must be 'line', 'multiline', or 'editor')", config.BasePromptInput)
+	}
+
+	// Validate how the template pickers are ordered (empty orders them by name)
+	switch config.TemplateOrder {
+	case "", "name", "recent", "frequent":
+	default:
+		return fmt.Errorf("invalid template_order: %s (must be 'name', 'recent', or 'frequent')", config.TemplateOrder)
+	}
+
+	// Validate how files too large to embed whole are included (empty lists them by path)
+	if config.LargeFiles != "" && config.LargeFiles != "path" && config.LargeFiles != "sample" {
+		return fmt.Errorf("invalid large_files: %s (must be 'path' or 'sample')", config.LargeFiles)
+	}
+
+	// Template groups need members and a known way to pick among them
+	for name, group := range config.TemplateGroups {
+		if group.Tag == "" && len(group.Templates) == 0 {
+			return fmt.Errorf("invalid template_group.%s: set tag or templates", name)
+		}
+		if group.Select != "" && group.Select != "random" && group.Select != "rotate" {
+			return fmt.Errorf("invalid template_group.%s.select: %s (must be 'random' or 'rotate')", name, group.Select)
+		}
 	}
-	// Also allow file: prefix
-	if !validTargets[config.Target] && !strings.HasPrefix(config.Target, "file:") {
-		return fmt.Errorf("invalid target: %s (must be 'clipboard', 'stdout', or 'file:/path')", config.Target)
+
+	// Custom template names are namespaces (name/template), so they can't take the built-in ones
+	for name := range config.CustomTemplates {
+		if strings.EqualFold(name, "local") || strings.EqualFold(name, "global") {
+			return fmt.Errorf("invalid custom_template name: %s (reserved for the built-in prompt locations)", name)
+		}
+	}
+
+	// Validate noise filter patterns
+	for _, pattern := range config.NoiseFilters {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid noise_filters pattern %q: %w", pattern, err)
+		}
+	}
+
+	if config.ClipboardHistoryCommand != "" && config.ClipboardHistorySize < 2 {
+		return fmt.Errorf("invalid clipboard_history_size: %d (must be at least 2 to pick among entries)", config.ClipboardHistorySize)
+	}
+
+	if config.AllowTemplateExec && config.TemplateExecTimeout <= 0 {
+		return fmt.Errorf("invalid template_exec_timeout: %s (must be a positive duration, e.g. \"10s\")", config.TemplateExecTimeout)
+	}
+
+	// Validate redaction patterns
+	for _, pattern := range config.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redact_patterns pattern %q: %w", pattern, err)
+		}
 	}
 
 	// Validate prompts location exists or can be created
@@ -199,6 +568,16 @@ func (m *Manager) Validate(config *interfaces.Config) error {
 	return nil
 }
 
+// validBudgetClasses lists the section classes accepted in budget_weights
+var validBudgetClasses = map[string]bool{
+	"base":    true,
+	"fix":     true,
+	"diff":    true,
+	"context": true,
+	"files":   true,
+	"tree":    true,
+}
+
 // getConfigFromViper converts viper configuration to Config struct
 // This handles env > config > defaults precedence (flags are applied separately)
 func (m *Manager) getConfigFromViper() *interfaces.Config {
@@ -240,18 +619,101 @@ func (m *Manager) getConfigFromViper() *interfaces.Config {
 		}
 	}
 	
+	// Parse template groups
+	templateGroups := make(map[string]interfaces.TemplateGroup)
+	for name := range m.v.GetStringMap("template_group") {
+		templateGroups[name] = interfaces.TemplateGroup{
+			Tag:       m.v.GetString(fmt.Sprintf("template_group.%s.tag", name)),
+			Templates: m.v.GetStringSlice(fmt.Sprintf("template_group.%s.templates", name)),
+			Select:    m.v.GetString(fmt.Sprintf("template_group.%s.select", name)),
+		}
+	}
+	
+	// Parse budget weights
+	budgetWeights := make(map[string]float64)
+	if m.v.IsSet("budget_weights") {
+		for class := range m.v.GetStringMap("budget_weights") {
+			budgetWeights[class] = m.v.GetFloat64(fmt.Sprintf("budget_weights.%s", class))
+		}
+	}
+	
+	targets := make(map[string]string)
+	for name, spec := range m.v.GetStringMapString("targets") {
+		targets[name] = expandTargetPath(spec)
+	}
+
 	return &interfaces.Config{
 		PromptsLocation:      expandPath(m.v.GetString("prompts_location")),
 		LocalPromptsLocation: expandPath(m.v.GetString("local_prompts_location")),
 		Editor:               m.v.GetString("editor"),
+		EditorWait:           m.v.GetBool("editor_wait"),
+		BasePromptInput:      strings.ToLower(m.v.GetString("base_prompt_input")),
+		TemplateOrder:        strings.ToLower(m.v.GetString("template_order")),
+		ClipboardVerify:      m.v.GetBool("clipboard_verify"),
+		ClipboardHistoryCommand: m.v.GetString("clipboard_history_command"),
+		ClipboardHistorySize:    m.v.GetInt("clipboard_history_size"),
 		DefaultPre:           m.v.GetString("default_pre"),
 		DefaultPost:          m.v.GetString("default_post"),
+		FixDefaultPre:        m.v.GetString("fix_default_pre"),
+		FixDefaultPost:       m.v.GetString("fix_default_post"),
 		FixFile:              expandPath(m.v.GetString("fix_file")),
+		FixSource:            m.v.GetString("fix_source"),
+		ScriptFile:           expandPath(m.v.GetString("script_file")),
+		FixReview:            m.v.GetBool("fix_review"),
+		PreviewOutput:        m.v.GetBool("preview_output"),
+		FixEmbedFiles:        m.v.GetBool("fix_embed_files"),
+		LargeFiles:           strings.ToLower(m.v.GetString("large_files")),
+		StripANSI:            m.v.GetBool("strip_ansi"),
+		NoiseDefaultFilters:  m.v.GetBool("noise_default_filters"),
+		NoiseFilters:         m.v.GetStringSlice("noise_filters"),
+		Redact:               m.v.GetBool("redact"),
+		RedactPatterns:       m.v.GetStringSlice("redact_patterns"),
 		DirectoryStrategy:    m.v.GetString("directory_strategy"),
-		Target:               m.v.GetString("target"),
+		Target:               expandTargetPath(m.v.GetString("target")),
+		Targets:              targets,
+		Tasks:                m.v.GetStringMapString("tasks"),
 		InteractiveDefault:   m.v.GetBool("interactive_default"),
+		MinimalAuto:          m.v.GetBool("minimal_auto"),
+		InvocationLog:        m.v.GetBool("invocation_log"),
+		InvocationLogFile:    expandPath(m.v.GetString("invocation_log_file")),
+		StateFile:            expandPath(m.v.GetString("state_file")),
+		History:              m.v.GetBool("history"),
+		WorkspaceTrust:       m.v.GetBool("workspace_trust"),
+		UpdateCheck:          m.v.GetBool("update_check"),
+		UpdateCheckURL:       m.v.GetString("update_check_url"),
+		PackIndexURL:         m.v.GetString("pack_index_url"),
+		URLMarkdown:          m.v.GetBool("url_markdown"),
+		GitHubToken:          m.v.GetString("github_token"),
+		GitHubAPIURL:         m.v.GetString("github_api_url"),
+		JiraURL:              m.v.GetString("jira_url"),
+		JiraEmail:            m.v.GetString("jira_email"),
+		JiraToken:            m.v.GetString("jira_token"),
+		JiraAcceptanceField:  m.v.GetString("jira_acceptance_field"),
+		EncryptionTool:       m.v.GetString("encryption_tool"),
+		EncryptionRecipient:  m.v.GetString("encryption_recipient"),
+		AgeIdentity:          expandPath(m.v.GetString("age_identity")),
+		AllowTemplateExec:    m.v.GetBool("allow_template_exec"),
+		TemplateExecTimeout:  m.v.GetDuration("template_exec_timeout"),
 		CustomTemplates:      customTemplates,
+		TemplateGroups:       templateGroups,
+		TokenBudget:          m.v.GetInt("token_budget"),
+		TemplateTokenLimit:   m.v.GetString("template_token_limit"),
+		RedirectDeprecated:   m.v.GetBool("redirect_deprecated"),
+		BudgetWeights:        budgetWeights,
+		Profile:              strings.ToLower(m.v.GetString("profile")),
+		Settings:             templateSettings(m.v),
+	}
+}
+
+// templateSettings returns the resolved config as templates see it in .Config: every key,
+// including ones prompter doesn't read, such as a team's product name. Tokens stay out of
+// prompts, and the [profiles] tables are already merged in.
+func templateSettings(v *viper.Viper) map[string]interface{} {
+	settings := v.AllSettings()
+	for _, key := range []string{"github_token", "jira_token", "profiles"} {
+		delete(settings, key)
 	}
+	return settings
 }
 
 // MergeConfig merges another configuration into this manager
@@ -286,10 +748,78 @@ func (m *Manager) MergeConfig(other *interfaces.Config) {
 		m.v.Set("target", other.Target)
 	}
 
+	if other.TokenBudget != 0 {
+		m.v.Set("token_budget", other.TokenBudget)
+	}
+
 	// Note: InteractiveDefault is a boolean, so we always set it
 	m.v.Set("interactive_default", other.InteractiveDefault)
 }
 
+// Ways a file target writes its file
+const (
+	FileReplace = "replace"
+	FileAppend  = "append"
+	FilePrepend = "prepend"
+)
+
+// fileTargets are the file target prefixes and how each writes the file
+var fileTargets = []struct {
+	prefix, mode string
+}{
+	{"file+append:", FileAppend},
+	{"file+prepend:", FilePrepend},
+	{"file+:", FileAppend},
+	{"file:", FileReplace},
+}
+
+// ParseFileTarget splits a file target into its path and how it writes the file. ok is false
+// for targets that aren't files.
+func ParseFileTarget(target string) (path, mode string, ok bool) {
+	for _, file := range fileTargets {
+		if strings.HasPrefix(target, file.prefix) {
+			return strings.TrimPrefix(target, file.prefix), file.mode, true
+		}
+	}
+	return "", "", false
+}
+
+// ParseCommandTarget returns the command a cmd: target pipes the prompt into. ok is false for
+// targets that aren't commands or name none.
+func ParseCommandTarget(target string) (command string, ok bool) {
+	if !strings.HasPrefix(target, "cmd:") {
+		return "", false
+	}
+	command = strings.TrimSpace(strings.TrimPrefix(target, "cmd:"))
+	return command, command != ""
+}
+
+// IsTargetSpec reports whether target is a built-in output target rather than an alias.
+// file: replaces the file; file+: (or file+append:) appends to it and file+prepend: prepends.
+// cmd: pipes the prompt into a command.
+func IsTargetSpec(target string) bool {
+	_, _, isFile := ParseFileTarget(target)
+	_, isCommand := ParseCommandTarget(target)
+	switch target {
+	case "clipboard", "tmux", "osc52", "stdout":
+		return true
+	}
+	return isFile || isCommand
+}
+
+// expandTargetPath expands ~ in the path of a file target
+func expandTargetPath(target string) string {
+	if path, _, ok := ParseFileTarget(target); ok {
+		return strings.TrimSuffix(target, path) + expandPath(path)
+	}
+	return target
+}
+
+// ExpandPath expands a leading ~/ to the user's home directory, as config paths are
+func ExpandPath(path string) string {
+	return expandPath(path)
+}
+
 // expandPath expands ~ to user home directory
 func expandPath(path string) string {
 	if !strings.HasPrefix(path, "~/") {
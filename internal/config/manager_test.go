@@ -3,9 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
-	"prompter-cli/internal/interfaces"
+	"github.com/imdevan/prompter/internal/interfaces"
 )
 
 func TestNewManager(t *testing.T) {
@@ -103,6 +104,15 @@ func TestManager_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid encryption tool",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				EncryptionTool:    "rot13",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid target",
 			config: &interfaces.Config{
@@ -111,6 +121,43 @@ func TestManager_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "template exec without a timeout",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				AllowTemplateExec: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "clipboard history with too few entries",
+			config: &interfaces.Config{
+				DirectoryStrategy:       "git",
+				Target:                  "clipboard",
+				ClipboardHistoryCommand: "cliphist list",
+				ClipboardHistorySize:    1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown base prompt input",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				BasePromptInput:   "form",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown template order",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				TemplateOrder:     "alphabetical",
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid file target",
 			config: &interfaces.Config{
@@ -119,6 +166,69 @@ func TestManager_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "target alias",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "notes",
+				Targets:           map[string]string{"notes": "file+:/tmp/notes.md"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "alias with invalid spec",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				Targets:           map[string]string{"notes": "notes.md"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "task without a command",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				Tasks:             map[string]string{"build": " "},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid large_files",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				LargeFiles:        "truncate",
+			},
+			wantErr: true,
+		},
+		{
+			name: "template group without members",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				TemplateGroups:    map[string]interfaces.TemplateGroup{"any": {Select: "rotate"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "template group with unknown select",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				TemplateGroups:    map[string]interfaces.TemplateGroup{"any": {Tag: "brainstorm", Select: "shuffle"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "alias shadowing built-in target",
+			config: &interfaces.Config{
+				DirectoryStrategy: "git",
+				Target:            "clipboard",
+				Targets:           map[string]string{"stdout": "file:/tmp/out.md"},
+			},
+			wantErr: true,
+		},
 	}
 	
 	for _, tt := range tests {
@@ -269,4 +379,318 @@ func TestExpandPath(t *testing.T) {
 			t.Errorf("expandPath(~/test/path) = %s, expected %s", result, expected)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestManager_Load_TargetAliases(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "target = \"notes\"\n\n[targets]\nnotes = \"file+:~/notes/prompts.md\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := NewManager().Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	expected := "file+:" + filepath.Join(homeDir, "notes/prompts.md")
+	if config.Targets["notes"] != expected {
+		t.Errorf("Targets[notes] = %q, expected %q", config.Targets["notes"], expected)
+	}
+}
+
+func TestManager_Load_TemplateGroups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "[template_group.brainstorm-any]\ntag = \"brainstorm\"\nselect = \"rotate\"\n\n[template_group.pair]\ntemplates = [\"a\", \"team/b\"]\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := NewManager().Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if group := config.TemplateGroups["brainstorm-any"]; group.Tag != "brainstorm" || group.Select != "rotate" {
+		t.Errorf("TemplateGroups[brainstorm-any] = %+v", group)
+	}
+	if group := config.TemplateGroups["pair"]; len(group.Templates) != 2 || group.Templates[1] != "team/b" {
+		t.Errorf("TemplateGroups[pair] = %+v", group)
+	}
+}
+
+func TestManager_Load_Profile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+default_pre = "review"
+target = "clipboard"
+
+[targets]
+notes = "file:/tmp/notes.md"
+
+[profiles.work]
+prompts_location = "/tmp/work-prompts"
+default_pre = "client-review"
+
+[profiles.work.targets]
+tickets = "file:/tmp/tickets.md"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		target     string // PROMPTER_TARGET
+		defaultPre string
+		wantTarget string
+		wantErr    bool
+	}{
+		{name: "no profile", defaultPre: "review", wantTarget: "clipboard"},
+		{name: "profile", profile: "work", defaultPre: "client-review", wantTarget: "clipboard"},
+		{name: "case-insensitive", profile: "Work", defaultPre: "client-review", wantTarget: "clipboard"},
+		{name: "environment beats the profile", profile: "work", target: "stdout", defaultPre: "client-review", wantTarget: "stdout"},
+		{name: "unknown profile", profile: "personal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PROMPTER_PROFILE", tt.profile)
+			t.Setenv("PROMPTER_TARGET", tt.target)
+
+			config, err := NewManager().Load(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.DefaultPre != tt.defaultPre {
+				t.Errorf("DefaultPre = %q, expected %q", config.DefaultPre, tt.defaultPre)
+			}
+			if config.Target != tt.wantTarget {
+				t.Errorf("Target = %q, expected %q", config.Target, tt.wantTarget)
+			}
+			if tt.profile == "" {
+				return
+			}
+			if config.PromptsLocation != "/tmp/work-prompts" {
+				t.Errorf("PromptsLocation = %q, expected the profile's", config.PromptsLocation)
+			}
+			if config.Targets["notes"] == "" || config.Targets["tickets"] == "" {
+				t.Errorf("Targets = %v, expected the file's and the profile's", config.Targets)
+			}
+		})
+	}
+}
+
+func TestParseCommandTarget(t *testing.T) {
+	tests := []struct {
+		target, command string
+		ok              bool
+	}{
+		{"cmd:claude -p", "claude -p", true},
+		{"cmd: llm ", "llm", true},
+		{"cmd:", "", false},
+		{"stdout", "", false},
+	}
+
+	for _, tt := range tests {
+		command, ok := ParseCommandTarget(tt.target)
+		if command != tt.command || ok != tt.ok {
+			t.Errorf("ParseCommandTarget(%q) = %q, %v; expected %q, %v", tt.target, command, ok, tt.command, tt.ok)
+		}
+	}
+}
+
+func TestParseFileTarget(t *testing.T) {
+	tests := []struct {
+		target, path, mode string
+		ok                 bool
+	}{
+		{"file:/tmp/p.md", "/tmp/p.md", FileReplace, true},
+		{"file+:/tmp/p.md", "/tmp/p.md", FileAppend, true},
+		{"file+append:/tmp/p.md", "/tmp/p.md", FileAppend, true},
+		{"file+prepend:/tmp/p.md", "/tmp/p.md", FilePrepend, true},
+		{"clipboard", "", "", false},
+		{"notes", "", "", false},
+	}
+
+	for _, tt := range tests {
+		path, mode, ok := ParseFileTarget(tt.target)
+		if path != tt.path || mode != tt.mode || ok != tt.ok {
+			t.Errorf("ParseFileTarget(%q) = %q, %q, %v; expected %q, %q, %v", tt.target, path, mode, ok, tt.path, tt.mode, tt.ok)
+		}
+	}
+}
+
+func TestParseOverride(t *testing.T) {
+	tests := []struct {
+		assignment string
+		key        string
+		value      interface{}
+		wantErr    bool
+	}{
+		{"directory_strategy=filesystem", "directory_strategy", "filesystem", false},
+		{"Default_Pre=", "default_pre", "", false},
+		{"redirect_deprecated=true", "redirect_deprecated", true, false},
+		{"redirect_deprecated=maybe", "", nil, true},
+		{"no_such_key=1", "", nil, true},
+		{"directory_strategy", "", nil, true},
+		{"=git", "", nil, true},
+		{"profile=work", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		key, value, err := ParseOverride(tt.assignment)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseOverride(%q) error = %v, wantErr %v", tt.assignment, err, tt.wantErr)
+			continue
+		}
+		if key != tt.key || value != tt.value {
+			t.Errorf("ParseOverride(%q) = %q, %v; expected %q, %v", tt.assignment, key, value, tt.key, tt.value)
+		}
+	}
+}
+
+func TestManager_Resolve_Overrides(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("redirect_deprecated = false\ndefault_pre = \"review\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	manager := NewManager()
+	if _, err := manager.Load(configPath); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	for _, assignment := range []string{"redirect_deprecated=true", "default_pre="} {
+		key, value, err := ParseOverride(assignment)
+		if err != nil {
+			t.Fatalf("ParseOverride(%q) error: %v", assignment, err)
+		}
+		manager.SetFlag(key, value)
+	}
+
+	config, err := manager.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if !config.RedirectDeprecated {
+		t.Error("Expected RedirectDeprecated to be true (from override)")
+	}
+	if config.DefaultPre != "" {
+		t.Errorf("Expected DefaultPre to be cleared by override, got %q", config.DefaultPre)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+editor = "vim"
+edtor = "nano"
+
+[targets]
+notes = "file:~/notes.md"
+
+[custom_template.work]
+location = "~/work-prompts"
+
+[jira]
+url = "https://example.atlassian.net"
+
+[profiles.work]
+target = "stdout"
+targt = "stdout"
+
+[profiles.work.targets]
+notes = "file:~/work-notes.md"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	unknown, err := UnknownKeys(configPath)
+	if err != nil {
+		t.Fatalf("UnknownKeys() error: %v", err)
+	}
+	if want := []string{"edtor", "jira.url", "profiles.work.targt"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("UnknownKeys() = %v, expected %v", unknown, want)
+	}
+}
+
+func TestManager_Explain(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "target = \"stdout\"\neditor = \"vim\"\n\n[profiles.work]\ntarget = \"cmd:cat\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROMPTER_PROFILE", "work")
+	t.Setenv("PROMPTER_EDITOR", "nano")
+
+	manager := NewManager()
+	manager.SetFlag("target", "tmux")
+	if _, err := manager.Load(configPath); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := manager.Resolve(); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		value   interface{}
+		sources []string
+	}{
+		{"target", "tmux", []string{SourceFlag, SourceProfile, SourceFile, SourceDefault}},
+		{"Editor", "nano", []string{SourceEnv, SourceFile, SourceDefault}},
+		{"directory_strategy", "git", []string{SourceDefault}},
+	}
+	for _, tt := range tests {
+		value, layers, err := manager.Explain(tt.key)
+		if err != nil {
+			t.Fatalf("Explain(%q) error = %v", tt.key, err)
+		}
+		if value != tt.value {
+			t.Errorf("Explain(%q) value = %v, expected %v", tt.key, value, tt.value)
+		}
+		var sources []string
+		for _, layer := range layers {
+			sources = append(sources, layer.Source)
+		}
+		if !reflect.DeepEqual(sources, tt.sources) {
+			t.Errorf("Explain(%q) layers = %v, expected %v", tt.key, sources, tt.sources)
+		}
+	}
+
+	for _, key := range []string{"no_such_key", "profiles.work.target"} {
+		if _, _, err := manager.Explain(key); err == nil {
+			t.Errorf("Explain(%q) expected an error", key)
+		}
+	}
+}
+
+func TestManager_Load_TemplateSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "product_name = \"Ledger\"\ngithub_token = \"ghp_secret\"\n\n[style]\nguide = \"https://example.com/style\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := NewManager().Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := config.Settings["product_name"]; got != "Ledger" {
+		t.Errorf("Settings[product_name] = %v, expected the custom key", got)
+	}
+	if style, _ := config.Settings["style"].(map[string]interface{}); style["guide"] != "https://example.com/style" {
+		t.Errorf("Settings[style] = %v, expected the custom table", config.Settings["style"])
+	}
+	if got := config.Settings["editor"]; got != "nvim" {
+		t.Errorf("Settings[editor] = %v, expected the default", got)
+	}
+	if _, ok := config.Settings["github_token"]; ok {
+		t.Error("Settings includes github_token; tokens must stay out of templates")
+	}
+}
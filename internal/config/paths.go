@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// appName is the directory prompter's files live in under the config and data homes
+const appName = "prompter"
+
+// Move is a file or directory the migration moves from the old location to the new one
+type Move struct {
+	From string
+	To   string
+}
+
+// ConfigDir returns the directory the config file and prompts live in:
+// $XDG_CONFIG_HOME/prompter, %APPDATA%\prompter on Windows, or ~/.config/prompter. Until
+// MigrateLegacyDir moves them, files still in ~/.config/prompter are used where they are.
+func ConfigDir() (string, error) {
+	if legacy, ok := LegacyDirInUse(); ok {
+		return legacy, nil
+	}
+	return configHome()
+}
+
+// DataDir returns the directory the state file and invocation log live in:
+// $XDG_DATA_HOME/prompter when set, otherwise ConfigDir, where they've always been. Until
+// MigrateLegacyDir moves them, the ones still in ~/.config/prompter are used where they are.
+func DataDir() (string, error) {
+	if legacy, ok := LegacyDirInUse(); ok {
+		return legacy, nil
+	}
+	if legacy, ok := LegacyDataInUse(); ok {
+		return legacy, nil
+	}
+	return dataHome()
+}
+
+// StateDir returns the directory for per-user state that isn't worth keeping, such as the
+// shell hook's last capture: $XDG_STATE_HOME/prompter, %LOCALAPPDATA%\prompter on Windows,
+// or ~/.local/state/prompter
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", appName), nil
+}
+
+// LegacyDir returns ~/.config/prompter, where prompter kept everything before it read
+// XDG_CONFIG_HOME, XDG_DATA_HOME, and %APPDATA%
+func LegacyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", appName), nil
+}
+
+// LegacyDirInUse reports whether prompter still reads from LegacyDir: the new config
+// directory is elsewhere and doesn't exist yet, and the old one does
+func LegacyDirInUse() (string, bool) {
+	legacy, err := LegacyDir()
+	if err != nil {
+		return "", false
+	}
+	dir, err := configHome()
+	if err != nil || dir == legacy {
+		return "", false
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		return "", false
+	}
+	if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return legacy, true
+}
+
+// legacyDataFiles are the files in DataDir that MigrateLegacyDir moves
+var legacyDataFiles = []string{"state.db", "invocations.jsonl"}
+
+// LegacyDataInUse reports whether prompter still reads its data from LegacyDir while the
+// config is read from the new location, as when only XDG_DATA_HOME is set: the data
+// directory is elsewhere and holds none of the data files yet, and the old one holds some
+func LegacyDataInUse() (string, bool) {
+	legacy, err := LegacyDir()
+	if err != nil {
+		return "", false
+	}
+	dir, err := dataHome()
+	if err != nil || dir == legacy {
+		return "", false
+	}
+	inLegacy := false
+	for _, name := range legacyDataFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, os.ErrNotExist) {
+			return "", false
+		}
+		if info, err := os.Stat(filepath.Join(legacy, name)); err == nil && info.Mode().IsRegular() {
+			inLegacy = true
+		}
+	}
+	return legacy, inLegacy
+}
+
+// configHome returns the config directory prompter moves to, ignoring LegacyDir
+func configHome() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+	}
+	return LegacyDir()
+}
+
+// dataHome returns the data directory prompter moves to, ignoring LegacyDir
+func dataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	return configHome()
+}
+
+// defaultLocation returns name in dir for a config default, with the home directory as ~,
+// falling back to the legacy location when dir can't be found
+func defaultLocation(dir func() (string, error), name string) string {
+	base, err := dir()
+	if err != nil {
+		return "~/.config/prompter/" + name
+	}
+	return homePath(filepath.Join(base, name))
+}
+
+// DefaultPromptsLocation returns the prompts_location default, prompts in ConfigDir
+func DefaultPromptsLocation() string {
+	return defaultLocation(ConfigDir, "prompts")
+}
+
+// homePath writes a path under the home directory as ~/..., with forward slashes, as config
+// files do
+func homePath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(homeDir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// LegacyMoves returns what MigrateLegacyDir would move out of LegacyDir. Destinations that
+// already exist are left out, so nothing is overwritten.
+func LegacyMoves() ([]Move, error) {
+	legacy, err := LegacyDir()
+	if err != nil {
+		return nil, err
+	}
+	configDir, err := configHome()
+	if err != nil {
+		return nil, err
+	}
+	dataDir, err := dataHome()
+	if err != nil {
+		return nil, err
+	}
+
+	var moves []Move
+	for _, entry := range []struct{ name, dir string }{
+		{"config.toml", configDir},
+		{"prompts", configDir},
+		{"state.db", dataDir},
+		{"invocations.jsonl", dataDir},
+	} {
+		move := Move{From: filepath.Join(legacy, entry.name), To: filepath.Join(entry.dir, entry.name)}
+		if move.From == move.To {
+			continue
+		}
+		if _, err := os.Stat(move.From); err != nil {
+			continue
+		}
+		if _, err := os.Stat(move.To); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// MigrateLegacyDir moves the config file, prompts, state file, and invocation log out of
+// LegacyDir to ConfigDir and DataDir, and points the moved config file's paths at their new
+// locations. It returns what it moved.
+func MigrateLegacyDir() ([]Move, error) {
+	moves, err := LegacyMoves()
+	if err != nil {
+		return nil, err
+	}
+	for i, move := range moves {
+		if err := os.MkdirAll(filepath.Dir(move.To), 0755); err != nil {
+			return moves[:i], fmt.Errorf("failed to create %s: %w", filepath.Dir(move.To), err)
+		}
+		if err := os.Rename(move.From, move.To); err != nil {
+			return moves[:i], fmt.Errorf("failed to move %s to %s: %w", move.From, move.To, err)
+		}
+	}
+
+	for _, move := range moves {
+		if filepath.Base(move.To) == "config.toml" {
+			if err := rewriteMovedPaths(move.To, moves); err != nil {
+				return moves, err
+			}
+		}
+	}
+	return moves, nil
+}
+
+// rewriteMovedPaths replaces the old paths of moves in a config file with their new ones, as
+// setup writes prompts_location out in full
+func rewriteMovedPaths(configPath string, moves []Move) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	updated := string(content)
+	for _, move := range moves {
+		for _, from := range []string{homePath(move.From), filepath.ToSlash(move.From)} {
+			updated = strings.ReplaceAll(updated, `"`+from+`"`, `"`+homePath(move.To)+`"`)
+		}
+	}
+	if updated == string(content) {
+		return nil
+	}
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", configPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	legacy := filepath.Join(home, ".config", "prompter")
+	xdgConfig := filepath.Join(home, "xdg-config")
+	xdgData := filepath.Join(home, "xdg-data")
+
+	tests := []struct {
+		name       string
+		configHome string
+		dataHome   string
+		legacyDir  bool
+		wantConfig string
+		wantData   string
+	}{
+		{"defaults", "", "", false, legacy, legacy},
+		{"XDG_CONFIG_HOME", xdgConfig, "", false, filepath.Join(xdgConfig, "prompter"), filepath.Join(xdgConfig, "prompter")},
+		{"XDG_DATA_HOME", "", xdgData, false, legacy, filepath.Join(xdgData, "prompter")},
+		{"relative XDG_CONFIG_HOME is ignored", "xdg-config", "", false, legacy, legacy},
+		{"old location still in use", xdgConfig, xdgData, true, legacy, legacy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			t.Setenv("XDG_DATA_HOME", tt.dataHome)
+			os.RemoveAll(legacy)
+			if tt.legacyDir {
+				if err := os.MkdirAll(legacy, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got, err := ConfigDir(); err != nil || got != tt.wantConfig {
+				t.Errorf("ConfigDir() = %q, %v; want %q", got, err, tt.wantConfig)
+			}
+			if got, err := DataDir(); err != nil || got != tt.wantData {
+				t.Errorf("DataDir() = %q, %v; want %q", got, err, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestDataDirLegacyState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	xdgData := filepath.Join(home, "xdg-data")
+	t.Setenv("XDG_DATA_HOME", xdgData)
+
+	legacy := filepath.Join(home, ".config", "prompter")
+	newDir := filepath.Join(xdgData, "prompter")
+
+	tests := []struct {
+		name        string
+		legacyState bool
+		newState    bool
+		want        string
+	}{
+		{"no state yet", false, false, newDir},
+		{"state only in the old location", true, false, legacy},
+		{"state migrated", false, true, newDir},
+		{"state in both", true, true, newDir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.RemoveAll(legacy)
+			os.RemoveAll(newDir)
+			for dir, want := range map[string]bool{legacy: tt.legacyState, newDir: tt.newState} {
+				if !want {
+					continue
+				}
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "state.db"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got, err := DataDir(); err != nil || got != tt.want {
+				t.Errorf("DataDir() = %q, %v; want %q", got, err, tt.want)
+			}
+			if _, inUse := LegacyDataInUse(); inUse != (tt.want == legacy) {
+				t.Errorf("LegacyDataInUse() = %v; want %v", inUse, tt.want == legacy)
+			}
+			if got, err := ConfigDir(); err != nil || got != legacy {
+				t.Errorf("ConfigDir() = %q, %v; want %q", got, err, legacy)
+			}
+		})
+	}
+}
+
+func TestStateDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	if got, err := StateDir(); err != nil || got != filepath.Join(home, ".local", "state", "prompter") {
+		t.Errorf("StateDir() = %q, %v; want ~/.local/state/prompter", got, err)
+	}
+
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "xdg-state"))
+	if got, err := StateDir(); err != nil || got != filepath.Join(home, "xdg-state", "prompter") {
+		t.Errorf("StateDir() = %q, %v; want $XDG_STATE_HOME/prompter", got, err)
+	}
+}
+
+func TestMigrateLegacyDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "xdg-data"))
+
+	legacy := filepath.Join(home, ".config", "prompter")
+	if err := os.MkdirAll(filepath.Join(legacy, "prompts", "pre"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "prompts_location = \"~/.config/prompter/prompts\"\nstate_file = \"" + filepath.ToSlash(filepath.Join(legacy, "state.db")) + "\"\nfix_file = \"~/.config/prompter/fix.txt\"\n"
+	for name, data := range map[string]string{"config.toml": content, "state.db": "state", "prompts/pre/review.md": "review"} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, inUse := LegacyDirInUse(); !inUse {
+		t.Fatal("LegacyDirInUse() = false before migrating")
+	}
+
+	moves, err := MigrateLegacyDir()
+	if err != nil {
+		t.Fatalf("MigrateLegacyDir() error = %v", err)
+	}
+	if len(moves) != 3 {
+		t.Errorf("MigrateLegacyDir() moved %+v, want config.toml, prompts, and state.db", moves)
+	}
+	for _, path := range []string{"xdg-config/prompter/config.toml", "xdg-config/prompter/prompts/pre/review.md", "xdg-data/prompter/state.db"} {
+		if _, err := os.Stat(filepath.Join(home, path)); err != nil {
+			t.Errorf("expected %s after migrating: %v", path, err)
+		}
+	}
+
+	migrated, err := os.ReadFile(filepath.Join(home, "xdg-config", "prompter", "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "prompts_location = \"~/xdg-config/prompter/prompts\"\nstate_file = \"~/xdg-data/prompter/state.db\"\nfix_file = \"~/.config/prompter/fix.txt\"\n"
+	if string(migrated) != want {
+		t.Errorf("migrated config = %q, want %q", migrated, want)
+	}
+
+	if _, inUse := LegacyDirInUse(); inUse {
+		t.Error("LegacyDirInUse() = true after migrating")
+	}
+	if moves, err := MigrateLegacyDir(); err != nil || len(moves) != 0 {
+		t.Errorf("second MigrateLegacyDir() = %+v, %v; want nothing to move", moves, err)
+	}
+	if path, err := DefaultConfigPath(); err != nil || !strings.HasPrefix(path, filepath.Join(home, "xdg-config")) {
+		t.Errorf("DefaultConfigPath() = %q, %v; want the migrated file", path, err)
+	}
+}
@@ -0,0 +1,152 @@
+package diagnostics
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// Tool names reported on parsed diagnostics
+const (
+	ToolGo         = "go"
+	ToolGoTest     = "go test"
+	ToolTypeScript = "tsc"
+	ToolPython     = "python"
+	ToolGeneric    = "lint"
+	ToolReference  = "reference" // A file:line mention outside a recognized error line
+)
+
+// matcher recognizes one output format and converts a match into a diagnostic
+type matcher struct {
+	tool    string
+	pattern *regexp.Regexp
+	convert func(tool string, m []string) interfaces.Diagnostic
+}
+
+// fileLineColMessage converts matches of the form (file, line, column, message)
+func fileLineColMessage(tool string, m []string) interfaces.Diagnostic {
+	return interfaces.Diagnostic{
+		File:    m[1],
+		Line:    atoi(m[2]),
+		Column:  atoi(m[3]),
+		Message: strings.TrimSpace(m[4]),
+		Tool:    tool,
+	}
+}
+
+// matchers are tried in order; the first match for a line wins
+var matchers = []matcher{
+	// go test assertion failures: "    handler_test.go:42: expected 200, got 500"
+	{
+		tool:    ToolGoTest,
+		pattern: regexp.MustCompile(`^\s+([\w.\-/]+_test\.go):(\d+)():? (.+)$`),
+		convert: fileLineColMessage,
+	},
+	// go build, go vet, and golangci-lint: "./main.go:12:5: undefined: foo (typecheck)"
+	{
+		tool:    ToolGo,
+		pattern: regexp.MustCompile(`^(?:vet: )?((?:\.{0,2}/)?[\w.\-/]+\.go):(\d+)(?::(\d+))?: (.+)$`),
+		convert: fileLineColMessage,
+	},
+	// TypeScript compiler: "src/app.ts(12,5): error TS2322: Type 'string' is not assignable"
+	{
+		tool:    ToolTypeScript,
+		pattern: regexp.MustCompile(`^([\w.\-/]+\.tsx?)\((\d+),(\d+)\): (.+)$`),
+		convert: fileLineColMessage,
+	},
+	// Python tracebacks: `  File "app/views.py", line 12, in index`
+	{
+		tool:    ToolPython,
+		pattern: regexp.MustCompile(`^\s*File "([^"]+)", line (\d+)(), in (.+)$`),
+		convert: func(tool string, m []string) interfaces.Diagnostic {
+			d := fileLineColMessage(tool, m)
+			d.Message = "in " + d.Message
+			return d
+		},
+	},
+	// Generic compiler/linter style (gcc, eslint unix, flake8, rustc short): "src/a.c:3:1: error: ..."
+	{
+		tool:    ToolGeneric,
+		pattern: regexp.MustCompile(`^((?:\.{0,2}/)?[\w.\-/]+\.\w+):(\d+):(?:(\d+):)? (.+)$`),
+		convert: fileLineColMessage,
+	},
+}
+
+// Parse extracts file/line diagnostics from captured command output.
+// Duplicate entries (same file, line, and message) are reported once, in first-seen order.
+func Parse(output string) []interfaces.Diagnostic {
+	var result []interfaces.Diagnostic
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		d, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		key := d.File + ":" + strconv.Itoa(d.Line) + ":" + d.Message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, d)
+	}
+
+	return result
+}
+
+// parseLine converts a single output line into a diagnostic when a matcher recognizes it
+func parseLine(line string) (interfaces.Diagnostic, bool) {
+	for _, m := range matchers {
+		if match := m.pattern.FindStringSubmatch(line); match != nil {
+			return m.convert(m.tool, match), true
+		}
+	}
+	return interfaces.Diagnostic{}, false
+}
+
+// referencePattern matches "path/to/file.ext:line" mentions anywhere in a line (stack traces, panics, logs)
+var referencePattern = regexp.MustCompile(`(?:^|[\s("'=])((?:\.{0,2}/)?[\w.\-/]*\w\.[A-Za-z]\w*):(\d+)\b`)
+
+// References extracts file:line mentions anywhere in output, for files named outside
+// recognized diagnostics (e.g. "panic: ... at internal/foo/bar.go:42 +0x1d").
+func References(output string) []interfaces.Diagnostic {
+	var result []interfaces.Diagnostic
+	seen := make(map[string]bool)
+
+	for _, match := range referencePattern.FindAllStringSubmatch(output, -1) {
+		key := match[1] + ":" + match[2]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, interfaces.Diagnostic{
+			File: match[1],
+			Line: atoi(match[2]),
+			Tool: ToolReference,
+		})
+	}
+
+	return result
+}
+
+// Files returns the distinct files referenced by diagnostics, in first-seen order
+func Files(diagnostics []interfaces.Diagnostic) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, d := range diagnostics {
+		if !seen[d.File] {
+			seen[d.File] = true
+			files = append(files, d.File)
+		}
+	}
+	return files
+}
+
+// atoi parses a decimal number, returning 0 for empty or invalid input
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
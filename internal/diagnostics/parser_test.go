@@ -0,0 +1,106 @@
+package diagnostics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []interfaces.Diagnostic
+	}{
+		{
+			name:   "go build errors",
+			output: "# prompter-cli/internal/app\ninternal/app/app.go:42:9: undefined: foo\n./main.go:7:2: \"os\" imported and not used",
+			expected: []interfaces.Diagnostic{
+				{File: "internal/app/app.go", Line: 42, Column: 9, Message: "undefined: foo", Tool: ToolGo},
+				{File: "./main.go", Line: 7, Column: 2, Message: "\"os\" imported and not used", Tool: ToolGo},
+			},
+		},
+		{
+			name:   "go test failure",
+			output: "--- FAIL: TestHandler (0.00s)\n    handler_test.go:31: expected 200, got 500\nFAIL\nFAIL\tprompter-cli/internal/app\t0.004s",
+			expected: []interfaces.Diagnostic{
+				{File: "handler_test.go", Line: 31, Message: "expected 200, got 500", Tool: ToolGoTest},
+			},
+		},
+		{
+			name:   "golangci-lint",
+			output: "internal/config/manager.go:88:2: ineffectual assignment to err (ineffassign)",
+			expected: []interfaces.Diagnostic{
+				{File: "internal/config/manager.go", Line: 88, Column: 2, Message: "ineffectual assignment to err (ineffassign)", Tool: ToolGo},
+			},
+		},
+		{
+			name:   "typescript",
+			output: "src/app.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'.",
+			expected: []interfaces.Diagnostic{
+				{File: "src/app.ts", Line: 12, Column: 5, Message: "error TS2322: Type 'string' is not assignable to type 'number'.", Tool: ToolTypeScript},
+			},
+		},
+		{
+			name:   "python traceback",
+			output: "Traceback (most recent call last):\n  File \"app/views.py\", line 12, in index\n    return render(x)\nNameError: name 'x' is not defined",
+			expected: []interfaces.Diagnostic{
+				{File: "app/views.py", Line: 12, Message: "in index", Tool: ToolPython},
+			},
+		},
+		{
+			name:   "generic linter",
+			output: "src/util.js:3:10: 'x' is defined but never used.\nsetup.py:1: E302 expected 2 blank lines",
+			expected: []interfaces.Diagnostic{
+				{File: "src/util.js", Line: 3, Column: 10, Message: "'x' is defined but never used.", Tool: ToolGeneric},
+				{File: "setup.py", Line: 1, Message: "E302 expected 2 blank lines", Tool: ToolGeneric},
+			},
+		},
+		{
+			name:     "duplicates reported once",
+			output:   "main.go:1:1: bad\nmain.go:1:1: bad",
+			expected: []interfaces.Diagnostic{{File: "main.go", Line: 1, Column: 1, Message: "bad", Tool: ToolGo}},
+		},
+		{
+			name:     "no diagnostics",
+			output:   "ok  \tprompter-cli/internal/app\t0.004s\nDone at 12:30:45: all good",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Parse(tt.output)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Parse() = %+v, expected %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	diags := []interfaces.Diagnostic{
+		{File: "a.go", Line: 1},
+		{File: "b.go", Line: 2},
+		{File: "a.go", Line: 3},
+	}
+	expected := []string{"a.go", "b.go"}
+	if result := Files(diags); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Files() = %v, expected %v", result, expected)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	output := "panic: runtime error: index out of range\n\ngoroutine 1 [running]:\nmain.run()\n\t/home/dev/api/internal/foo/bar.go:42 +0x1d\n" +
+		"see (cmd/api/main.go:7) and internal/foo/bar.go:42 again\nserver listening on localhost:8080 at 12:30:45"
+
+	expected := []interfaces.Diagnostic{
+		{File: "/home/dev/api/internal/foo/bar.go", Line: 42, Tool: ToolReference},
+		{File: "cmd/api/main.go", Line: 7, Tool: ToolReference},
+		{File: "internal/foo/bar.go", Line: 42, Tool: ToolReference},
+	}
+	if result := References(output); !reflect.DeepEqual(result, expected) {
+		t.Errorf("References() = %+v, expected %+v", result, expected)
+	}
+}
@@ -0,0 +1,189 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the file listing paths, in gitignore syntax, that are never included in prompts
+const FileName = ".prompterignore"
+
+// rule is one pattern line of an ignore file
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool // The line started with !, re-including what earlier lines ignored
+	dirOnly bool // The line ended with /, so it only matches directories
+}
+
+// Matcher reports which paths under its root an ignore file excludes. A nil Matcher
+// ignores nothing.
+type Matcher struct {
+	root  string
+	rules []rule
+}
+
+// Find loads the nearest ignore file in dir or a parent, stopping at the repository root,
+// and returns nil when there isn't one
+func Find(dir string) (*Matcher, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil || filepath.Dir(current) == current {
+			return nil, nil
+		}
+	}
+}
+
+// Load parses the ignore file at path; its patterns are relative to the file's directory
+func Load(path string) (*Matcher, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	root, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	m := &Matcher{root: root}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text()); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	return m, scanner.Err()
+}
+
+// Match reports whether path, absolute or relative to the working directory, is ignored,
+// either itself or because a directory it is in is. Paths outside the root never are.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	// As with git, nothing under an ignored directory can be re-included
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matches(rel, isDir)
+}
+
+// matches applies the rules to rel, a slash-separated path under the root; the last rule
+// that matches decides
+func (m *Matcher) matches(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.pattern.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// parseRule parses one line of an ignore file, reporting false for blank lines and comments
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimRight(strings.TrimSuffix(line, "\r"), " \t")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// A pattern with a slash is relative to the root; one without matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(.*/)?" + expr
+	}
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.pattern = pattern
+	return r, true
+}
+
+// globToRegexp translates a gitignore glob to a regular expression: * and ? stay within a
+// path segment, and ** spans any number of them
+func globToRegexp(glob string) string {
+	var out strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") {
+				i++
+				if strings.HasPrefix(glob[i+1:], "/") {
+					// "**/" matches zero or more directories
+					out.WriteString("(.*/)?")
+					i++
+				} else {
+					out.WriteString(".*")
+				}
+				continue
+			}
+			out.WriteString("[^/]*")
+		case '?':
+			out.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				out.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			out.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				out.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			out.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return out.String()
+}
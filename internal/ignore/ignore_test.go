@@ -0,0 +1,121 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeIgnoreFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	m, err := Load(writeIgnoreFile(t, root, `# vendored and generated code
+node_modules/
+vendor
+/fixtures/*.json
+!fixtures/keep.json
+*.log
+docs/**/draft-*.md
+build/**
+\#notes
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"node_modules", true, true},
+		{"web/node_modules/react/index.js", false, true},
+		{"node_modules", false, false}, // A file named like a directory-only pattern
+		{"vendor/github.com/x/y.go", false, true},
+		{"internal/vendor", false, true},
+		{"fixtures/users.json", false, true},
+		{"fixtures/keep.json", false, false},
+		{"internal/fixtures/users.json", false, false}, // Anchored to the root
+		{"fixtures/nested/users.json", false, false},   // * stays within a segment
+		{"debug.log", false, true},
+		{"logs/app.log", false, true},
+		{"docs/draft-intro.md", false, true},
+		{"docs/guides/v2/draft-intro.md", false, true},
+		{"docs/guides/intro.md", false, false},
+		{"build/out/app", false, true},
+		{"#notes", false, true},
+		{"README.md", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchIgnoredDirectoryCannotBeReincluded(t *testing.T) {
+	root := t.TempDir()
+	m, err := Load(writeIgnoreFile(t, root, "vendor/\n!vendor/keep.go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Match(filepath.Join(root, "vendor", "keep.go"), false) {
+		t.Error("a file under an ignored directory should stay ignored")
+	}
+}
+
+func TestMatchOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	m, err := Load(writeIgnoreFile(t, root, "*\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Match(filepath.Join(filepath.Dir(root), "other.go"), false) {
+		t.Error("paths outside the root should never be ignored")
+	}
+	if m.Match(root, true) {
+		t.Error("the root itself should never be ignored")
+	}
+
+	var none *Matcher
+	if none.Match(filepath.Join(root, "main.go"), false) {
+		t.Error("a nil Matcher should ignore nothing")
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(root, "internal", "app")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Find(nested)
+	if err != nil || m != nil {
+		t.Fatalf("Find() without an ignore file = %v, %v, want nil", m, err)
+	}
+
+	writeIgnoreFile(t, root, "*.log\n")
+	m, err = Find(nested)
+	if err != nil || m == nil {
+		t.Fatalf("Find() = %v, %v, want the root's ignore file", m, err)
+	}
+	if !m.Match(filepath.Join(nested, "debug.log"), false) {
+		t.Error("patterns should apply below the ignore file's directory")
+	}
+}
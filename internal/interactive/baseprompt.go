@@ -0,0 +1,101 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// How interactive runs ask for the base prompt, for base_prompt_input
+const (
+	BasePromptLine      = "line"      // A single line, with :m and :e to switch
+	BasePromptMultiline = "multiline" // Several lines, finished with two empty ones
+	BasePromptEditor    = "editor"    // Composed in the editor, like a git commit message
+)
+
+// Answers to the single-line prompt that switch to the other ways of entering it
+const (
+	switchToMultiline = ":m"
+	switchToEditor    = ":e"
+)
+
+// SetBasePromptInput sets how the base prompt is asked for, and compose, which opens text in
+// the editor and returns it as saved, for composing it there
+func (p *Prompter) SetBasePromptInput(mode string, compose func(text string) (string, error)) {
+	p.basePromptInput = mode
+	p.compose = compose
+}
+
+// promptForBasePrompt asks the user to enter a base prompt
+func (p *Prompter) promptForBasePrompt(request *models.PromptRequest) error {
+	var basePrompt string
+	var err error
+	switch p.basePromptInput {
+	case BasePromptMultiline:
+		basePrompt, err = askMultilineBasePrompt()
+	case BasePromptEditor:
+		basePrompt, err = p.composeBasePrompt()
+	default:
+		basePrompt, err = p.askBasePromptLine()
+	}
+	if err != nil {
+		return err
+	}
+
+	request.BasePrompt = strings.TrimSpace(basePrompt)
+	return nil
+}
+
+// askBasePromptLine asks for the base prompt on one line. Answering :m or :e switches to
+// multiline entry or the editor, for prompts that don't fit on a line.
+func (p *Prompter) askBasePromptLine() (string, error) {
+	prompt := &survey.Input{
+		Message: "Enter your base prompt:",
+		Help:    fmt.Sprintf("This is the main prompt text that will be sent to the AI. Enter %s to type several lines, or %s to compose it in your editor", switchToMultiline, switchToEditor),
+	}
+
+	var basePrompt string
+	if err := survey.AskOne(prompt, &basePrompt, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+
+	switch strings.TrimSpace(basePrompt) {
+	case switchToMultiline:
+		return askMultilineBasePrompt()
+	case switchToEditor:
+		return p.composeBasePrompt()
+	}
+	return basePrompt, nil
+}
+
+// askMultilineBasePrompt asks for a base prompt spanning several lines
+func askMultilineBasePrompt() (string, error) {
+	prompt := &survey.Multiline{
+		Message: "Enter your base prompt:",
+		Help:    "This is the main prompt text that will be sent to the AI",
+	}
+
+	var basePrompt string
+	if err := survey.AskOne(prompt, &basePrompt, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	return basePrompt, nil
+}
+
+// composeBasePrompt opens an empty file in the editor for the base prompt. Saving it empty
+// cancels, as with a git commit message.
+func (p *Prompter) composeBasePrompt() (string, error) {
+	if p.compose == nil {
+		return "", fmt.Errorf("no editor to compose the base prompt in")
+	}
+	basePrompt, err := p.compose("")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(basePrompt) == "" {
+		return "", fmt.Errorf("%w: the base prompt is empty", ErrCancelled)
+	}
+	return basePrompt, nil
+}
@@ -0,0 +1,43 @@
+package interactive
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestPromptForBasePrompt_Editor(t *testing.T) {
+	tests := []struct {
+		name      string
+		compose   func(string) (string, error)
+		want      string
+		cancelled bool
+		wantErr   bool
+	}{
+		{name: "composed", compose: func(string) (string, error) { return "\nexplain this\nin detail\n\n", nil }, want: "explain this\nin detail"},
+		{name: "saved empty", compose: func(string) (string, error) { return " \n", nil }, cancelled: true},
+		{name: "editor fails", compose: func(string) (string, error) { return "", errors.New("no such editor") }, wantErr: true},
+		{name: "no editor", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrompter(t.TempDir())
+			p.SetBasePromptInput(BasePromptEditor, tt.compose)
+			request := &models.PromptRequest{}
+			err := p.promptForBasePrompt(request)
+			switch {
+			case tt.cancelled:
+				if !IsCancelled(err) {
+					t.Errorf("promptForBasePrompt() error = %v, want it cancelled", err)
+				}
+			case tt.wantErr:
+				if err == nil || IsCancelled(err) {
+					t.Errorf("promptForBasePrompt() error = %v, want a failure", err)
+				}
+			case err != nil || request.BasePrompt != tt.want:
+				t.Errorf("promptForBasePrompt() = %q, %v; want %q", request.BasePrompt, err, tt.want)
+			}
+		})
+	}
+}
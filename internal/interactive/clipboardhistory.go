@@ -0,0 +1,117 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+// clipboardHistoryTimeout bounds how long the clipboard manager may take to list its entries
+const clipboardHistoryTimeout = 5 * time.Second
+
+// clipboardPreviewLength is how much of an entry the picker shows
+const clipboardPreviewLength = 70
+
+// SetClipboardHistory sets the command listing recent clipboard entries, for picking the one
+// --clipboard reads in interactive runs, and how many of them to offer. An empty command reads
+// only the current clipboard.
+func (p *Prompter) SetClipboardHistory(command string, size int) {
+	p.clipboardHistoryCommand = command
+	p.clipboardHistorySize = size
+}
+
+// clipboardHistory runs the history command and returns up to size distinct entries, newest
+// first. Entries are separated by NUL bytes when the output has any, so they can span lines,
+// and by newlines otherwise.
+func clipboardHistory(command string, size int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardHistoryTimeout)
+	defer cancel()
+	shell := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		shell = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	shell.Stderr = os.Stderr
+	output, err := shell.Output()
+	if err != nil {
+		return nil, fmt.Errorf("clipboard_history_command %q: %w", command, err)
+	}
+	return parseClipboardHistory(string(output), size), nil
+}
+
+// parseClipboardHistory splits the output of a history command into up to size distinct,
+// non-empty entries
+func parseClipboardHistory(output string, size int) []string {
+	separator := "\n"
+	if strings.Contains(output, "\x00") {
+		separator = "\x00"
+	}
+	var entries []string
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(output, separator) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || seen[entry] {
+			continue
+		}
+		if len(entries) == size {
+			break
+		}
+		seen[entry] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// clipboardOptions labels each entry for the picker with its first line, squeezed and
+// truncated, noting how many more lines it has. Labels are unique so the choice maps back.
+func clipboardOptions(entries []string) []string {
+	options := make([]string, len(entries))
+	used := map[string]bool{}
+	for i, entry := range entries {
+		lines := strings.Split(entry, "\n")
+		label := truncateString(strings.Join(strings.Fields(lines[0]), " "), clipboardPreviewLength)
+		if len(lines) > 1 {
+			label += fmt.Sprintf(" (+%d lines)", len(lines)-1)
+		}
+		for base, n := label, 2; used[label]; n++ {
+			label = fmt.Sprintf("%s [%d]", base, n)
+		}
+		used[label] = true
+		options[i] = label
+	}
+	return options
+}
+
+// pickClipboardEntry offers the recent clipboard entries and returns the chosen one. It returns
+// current, the clipboard as it is, when there is no history command or it lists one entry at
+// most, and, with a warning, when the command fails.
+func (p *Prompter) pickClipboardEntry(current string, numberSelect bool) (string, error) {
+	if p.clipboardHistoryCommand == "" {
+		return current, nil
+	}
+	entries, err := clipboardHistory(p.clipboardHistoryCommand, p.clipboardHistorySize)
+	if err != nil {
+		warnings.Add("%v; using the current clipboard", err)
+		return current, nil
+	}
+	if len(entries) <= 1 {
+		return current, nil
+	}
+
+	options := clipboardOptions(entries)
+	selected, err := p.selectTemplate(options, "Select a clipboard entry:", "Recent entries from clipboard_history_command, newest first", numberSelect)
+	if err != nil {
+		return "", err
+	}
+	for i, option := range options {
+		if option == selected {
+			return entries[i], nil
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,61 @@
+package interactive
+
+import (
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+func TestParseClipboardHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		size   int
+		want   []string
+	}{
+		{name: "one entry per line", output: "newest\n\nolder\nnewest\noldest\n", size: 10, want: []string{"newest", "older", "oldest"}},
+		{name: "NUL separated entries span lines", output: "line 1\nline 2\x00older\x00", size: 10, want: []string{"line 1\nline 2", "older"}},
+		{name: "capped at size", output: "a\nb\nc\n", size: 2, want: []string{"a", "b"}},
+		{name: "empty", output: "\n", size: 10, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseClipboardHistory(tt.output, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseClipboardHistory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClipboardOptions(t *testing.T) {
+	got := clipboardOptions([]string{"fix   the\tbuild", "fix the build", "explain this\nstack trace\nplease"})
+	want := []string{"fix the build", "fix the build [2]", "explain this (+2 lines)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clipboardOptions() = %q, want %q", got, want)
+	}
+}
+
+func TestPickClipboardEntry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	defer warnings.Flush(io.Discard)
+	p := NewPrompter(t.TempDir())
+	if got, err := p.pickClipboardEntry("current", false); err != nil || got != "current" {
+		t.Errorf("pickClipboardEntry() without a history command = %q, %v; want the current clipboard", got, err)
+	}
+
+	// A single entry is nothing to pick from
+	p.SetClipboardHistory("echo only", 10)
+	if got, err := p.pickClipboardEntry("current", false); err != nil || got != "current" {
+		t.Errorf("pickClipboardEntry() with one entry = %q, %v; want the current clipboard", got, err)
+	}
+
+	p.SetClipboardHistory("exit 3", 10)
+	if got, err := p.pickClipboardEntry("current", false); err != nil || got != "current" {
+		t.Errorf("pickClipboardEntry() with a failing command = %q, %v; want the current clipboard", got, err)
+	}
+}
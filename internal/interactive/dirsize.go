@@ -0,0 +1,143 @@
+package interactive
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/ignore"
+)
+
+// Limits on the directory scan, so the question isn't held up by a huge tree
+const (
+	estimateMaxFiles = 50000
+	estimateTimeout  = 500 * time.Millisecond
+)
+
+// skippedDirs are directories the scan doesn't descend into outside git repositories
+var skippedDirs = map[string]bool{".git": true, "node_modules": true, ".venv": true, "__pycache__": true}
+
+// dirEstimate is the size of a directory from a metadata-only scan
+type dirEstimate struct {
+	Files   int
+	Bytes   int64
+	Partial bool // The scan stopped at estimateMaxFiles or estimateTimeout
+}
+
+// Tokens returns the rough token count of the files (about four bytes per token)
+func (e dirEstimate) Tokens() int64 {
+	return (e.Bytes + 3) / 4
+}
+
+// String describes the estimate, e.g. "120 files, 1.5 MB, ~367k tokens"
+func (e dirEstimate) String() string {
+	more := ""
+	if e.Partial {
+		more = "at least "
+	}
+	files := "files"
+	if e.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%s%d %s, %s, ~%s tokens", more, e.Files, files, formatBytes(e.Bytes), formatCount(e.Tokens()))
+}
+
+// estimateDirectory sizes dir from file metadata without reading any files. In a git
+// repository it counts tracked and untracked files that aren't ignored; elsewhere it walks
+// the tree, skipping skippedDirs. Paths .prompterignore excludes aren't counted either way.
+func estimateDirectory(dir string) (dirEstimate, error) {
+	deadline := time.Now().Add(estimateTimeout)
+	ignored, _ := ignore.Find(dir) // An unreadable ignore file only makes the estimate larger
+	if files, err := gitFiles(dir); err == nil {
+		return estimateFiles(dir, files, ignored, deadline), nil
+	}
+
+	var estimate dirEstimate
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries don't change the estimate
+		}
+		if entry.IsDir() {
+			if path != dir && (skippedDirs[entry.Name()] || ignored.Match(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignored.Match(path, false) {
+			return nil
+		}
+		if estimate.Files >= estimateMaxFiles || time.Now().After(deadline) {
+			estimate.Partial = true
+			return filepath.SkipAll
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			estimate.Files++
+			estimate.Bytes += info.Size()
+		}
+		return nil
+	})
+	return estimate, err
+}
+
+// gitFiles lists the files in dir git knows about or would, relative to dir
+func gitFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(string(output), func(r rune) bool { return r == 0 }), nil
+}
+
+// estimateFiles adds up the sizes of files, which are relative to dir, leaving out ignored ones
+func estimateFiles(dir string, files []string, ignored *ignore.Matcher, deadline time.Time) dirEstimate {
+	var estimate dirEstimate
+	for _, file := range files {
+		if estimate.Files >= estimateMaxFiles || time.Now().After(deadline) {
+			estimate.Partial = true
+			break
+		}
+		path := filepath.Join(dir, file)
+		if ignored.Match(path, false) {
+			continue
+		}
+		// ls-files lists deleted files too; they're no longer there to include
+		if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+			estimate.Files++
+			estimate.Bytes += info.Size()
+		}
+	}
+	return estimate
+}
+
+// formatBytes formats n bytes as B, KB, MB, or GB
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return ""
+}
+
+// formatCount shortens large counts, e.g. 367412 to 367k
+func formatCount(n int64) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", n/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,58 @@
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/ignore"
+)
+
+func TestEstimateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "package main\n",
+		"docs/guide.md":           "# Guide\n",
+		"node_modules/x/index.js": "module.exports = {}\n",
+		".git/HEAD":               "ref: refs/heads/main\n",
+		"fixtures/users.json":     "[]\n",
+		ignore.FileName:           "fixtures/\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A .git directory without a repository isn't one git recognizes, so the tree is walked,
+	estimate, err := estimateDirectory(dir)
+	if err != nil {
+		t.Fatalf("estimateDirectory() error: %v", err)
+	}
+	// and what .prompterignore excludes isn't counted
+	want := dirEstimate{Files: 3, Bytes: int64(len("package main\n") + len("# Guide\n") + len("fixtures/\n"))}
+	if estimate != want {
+		t.Errorf("estimateDirectory() = %+v, want %+v", estimate, want)
+	}
+}
+
+func TestDirEstimateString(t *testing.T) {
+	tests := []struct {
+		estimate dirEstimate
+		want     string
+	}{
+		{dirEstimate{Files: 1, Bytes: 400}, "1 file, 400 B, ~100 tokens"},
+		{dirEstimate{Files: 120, Bytes: 1468000}, "120 files, 1.5 MB, ~367k tokens"},
+		{dirEstimate{Files: 50000, Bytes: 8000000, Partial: true}, "at least 50000 files, 8.0 MB, ~2.0M tokens"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.estimate.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
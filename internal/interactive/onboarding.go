@@ -0,0 +1,81 @@
+package interactive
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Onboarding holds the answers to the first-run setup questions
+type Onboarding struct {
+	PromptsLocation    string
+	Editor             string
+	Target             string
+	InteractiveDefault bool
+	StarterTemplates   bool
+}
+
+// onboardingTargets are the default targets offered during setup
+var onboardingTargets = []string{"clipboard", "stdout"}
+
+// ConfirmOnboarding asks whether to run the first-run setup
+func (p *Prompter) ConfirmOnboarding(configPath string) (bool, error) {
+	confirmPrompt := &survey.Confirm{
+		Message: "No config found at " + configPath + ". Set up prompter now?",
+		Help:    "Answering no writes a config with the defaults, so this isn't asked again. Rerun it with 'prompter setup'.",
+		Default: true,
+	}
+
+	var confirmed bool
+	if err := survey.AskOne(confirmPrompt, &confirmed); err != nil {
+		return false, err
+	}
+
+	return confirmed, nil
+}
+
+// CollectOnboarding asks the first-run setup questions, offering defaults as the answers
+func (p *Prompter) CollectOnboarding(defaults Onboarding) (Onboarding, error) {
+	answers := defaults
+
+	if err := survey.AskOne(&survey.Input{
+		Message: "Where should your prompt templates live?",
+		Help:    "Templates go in pre/ and post/ subdirectories of this location",
+		Default: defaults.PromptsLocation,
+	}, &answers.PromptsLocation, survey.WithValidator(survey.Required)); err != nil {
+		return defaults, err
+	}
+
+	if err := survey.AskOne(&survey.Input{
+		Message: "Editor for --editor:",
+		Help:    "A command such as nvim, code, or subl; GUI editors are waited on automatically",
+		Default: defaults.Editor,
+	}, &answers.Editor, survey.WithValidator(survey.Required)); err != nil {
+		return defaults, err
+	}
+
+	if err := survey.AskOne(&survey.Select{
+		Message: "Where should prompts go by default?",
+		Help:    "Override per run with --target; file targets can be set in the config later",
+		Options: onboardingTargets,
+		Default: defaults.Target,
+	}, &answers.Target); err != nil {
+		return defaults, err
+	}
+
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Ask for missing inputs (templates, prompt) interactively by default?",
+		Help:    "Override per run with -i or -y",
+		Default: defaults.InteractiveDefault,
+	}, &answers.InteractiveDefault); err != nil {
+		return defaults, err
+	}
+
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Install a few starter templates?",
+		Help:    "Existing templates with the same names are left alone",
+		Default: defaults.StarterTemplates,
+	}, &answers.StarterTemplates); err != nil {
+		return defaults, err
+	}
+
+	return answers, nil
+}
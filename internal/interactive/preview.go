@@ -0,0 +1,81 @@
+package interactive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/imdevan/prompter/pkg/models"
+	"golang.org/x/term"
+)
+
+// Choices offered after previewing the assembled prompt
+const (
+	PreviewOutput = "Output"
+	PreviewEdit   = "Edit in editor"
+	PreviewRepick = "Pick templates again"
+	PreviewCancel = "Cancel"
+)
+
+// previewChoices are the preview choices in the order they're offered
+var previewChoices = []string{PreviewOutput, PreviewEdit, PreviewRepick, PreviewCancel}
+
+// PreviewPrompt shows the assembled prompt and its estimated token count, then asks what to
+// do with it. The preview goes to stderr, through a pager when it doesn't fit the terminal.
+func (p *Prompter) PreviewPrompt(prompt string, tokens int, target string, numberSelect bool) (string, error) {
+	showPaged(os.Stderr, prompt)
+	fmt.Fprintf(os.Stderr, "\n(about %d tokens, %d lines)\n", tokens, strings.Count(prompt, "\n")+1)
+
+	choice, err := p.selectTemplate(previewChoices, "Send this prompt to "+target+"?", "Edit it, pick different templates, or cancel without output", numberSelect)
+	if err != nil {
+		return "", err
+	}
+	return choice, nil
+}
+
+// RepickTemplates asks for the pre- and post-templates again
+func (p *Prompter) RepickTemplates(request *models.PromptRequest) error {
+	request.PreTemplate, request.PostTemplate = "", ""
+	request.PreInline, request.PostInline = "", ""
+	if err := p.promptForPreTemplate(request); err != nil {
+		return fmt.Errorf("failed to collect pre-template: %w", err)
+	}
+	if err := p.promptForPostTemplate(request); err != nil {
+		return fmt.Errorf("failed to collect post-template: %w", err)
+	}
+	return nil
+}
+
+// showPaged writes text to w, piping it through $PAGER (or less) when w is a terminal too
+// short to show it
+func showPaged(w *os.File, text string) {
+	fd := int(w.Fd())
+	if term.IsTerminal(fd) {
+		if _, height, err := term.GetSize(fd); err == nil && strings.Count(text, "\n")+4 > height {
+			if pager := pagerCommand(); len(pager) > 0 {
+				cmd := exec.Command(pager[0], pager[1:]...)
+				cmd.Stdin = strings.NewReader(text)
+				cmd.Stdout = w
+				cmd.Stderr = w
+				if err := cmd.Run(); err == nil {
+					return
+				}
+			}
+		}
+	}
+	io.WriteString(w, text+"\n")
+}
+
+// pagerCommand returns the pager to run, or nil when none is installed
+func pagerCommand() []string {
+	if args := strings.Fields(os.Getenv("PAGER")); len(args) > 0 {
+		return args
+	}
+	if _, err := exec.LookPath("less"); err == nil {
+		// Quit without waiting when the text fits after all; keep colors
+		return []string{"less", "-FRX"}
+	}
+	return nil
+}
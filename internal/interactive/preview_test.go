@@ -0,0 +1,34 @@
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestShowPaged(t *testing.T) {
+	// Files aren't terminals, so the text is written as is
+	path := filepath.Join(t.TempDir(), "preview.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	showPaged(f, "line one\nline two")
+	f.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "line one\nline two\n" {
+		t.Errorf("showPaged() wrote %q", data)
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "most -s")
+	if got := pagerCommand(); !reflect.DeepEqual(got, []string{"most", "-s"}) {
+		t.Errorf("pagerCommand() = %v, want [most -s]", got)
+	}
+}
@@ -2,22 +2,49 @@ package interactive
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/atotto/clipboard"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
 	"golang.org/x/term"
-	"prompter-cli/pkg/models"
 )
 
+// defaultFixOption is the fix prompt choice for fix.md
+const defaultFixOption = "fix.md"
+
+// ErrCancelled is returned when the user aborts a selection with Esc or Ctrl+C
+var ErrCancelled = errors.New("selection cancelled")
+
+// IsCancelled reports whether err came from the user aborting an interactive prompt
+func IsCancelled(err error) bool {
+	return errors.Is(err, ErrCancelled) || errors.Is(err, terminal.InterruptErr)
+}
+
+// DisableColor makes prompts plain text, for terminals that don't render color
+func DisableColor() {
+	core.DisableColor = true
+}
+
 // Prompter handles interactive user input collection
 type Prompter struct {
-	promptsLocation string
+	promptsLocation         string
+	clipboardHistoryCommand string                            // Lists recent clipboard entries to pick from (clipboard_history_command)
+	clipboardHistorySize    int                               // How many entries the picker offers
+	basePromptInput         string                            // How the base prompt is asked for (base_prompt_input)
+	compose                 func(text string) (string, error) // Opens text in the editor and returns it as saved
+	templateOrder           string                            // How the pickers order templates (template_order)
+	templateUsage           UsageLoader                       // Loads how templates have been used
 }
 
 // NewPrompter creates a new interactive prompter
@@ -48,15 +75,22 @@ func (p *Prompter) CollectMissingInputs(request *models.PromptRequest) error {
 		}
 	}
 
+	// Collect the fix prompt if fix/ has named ones and none was specified
+	if request.FixMode && request.FixTemplate == "" {
+		if err := p.promptForFixTemplate(request); err != nil {
+			return fmt.Errorf("failed to collect fix prompt: %w", err)
+		}
+	}
+
 	// Collect pre-template if not specified
-	if request.PreTemplate == "" && !request.FixMode {
+	if request.PreTemplate == "" && request.PreInline == "" && !request.FixMode {
 		if err := p.promptForPreTemplate(request); err != nil {
 			return fmt.Errorf("failed to collect pre-template: %w", err)
 		}
 	}
 
 	// Collect post-template if not specified
-	if request.PostTemplate == "" && !request.FixMode {
+	if request.PostTemplate == "" && request.PostInline == "" && !request.FixMode {
 		if err := p.promptForPostTemplate(request); err != nil {
 			return fmt.Errorf("failed to collect post-template: %w", err)
 		}
@@ -69,11 +103,6 @@ func (p *Prompter) CollectMissingInputs(request *models.PromptRequest) error {
 		}
 	}
 
-	// Show confirmation summary
-	if err := p.showConfirmationSummary(request); err != nil {
-		return fmt.Errorf("user cancelled operation: %w", err)
-	}
-
 	return nil
 }
 
@@ -85,6 +114,11 @@ func (p *Prompter) appendClipboardToPrompt(request *models.PromptRequest) error
 	}
 
 	clipboardContent = strings.TrimSpace(clipboardContent)
+	if request.Interactive {
+		if clipboardContent, err = p.pickClipboardEntry(clipboardContent, request.NumberSelect); err != nil {
+			return err
+		}
+	}
 	if clipboardContent == "" {
 		return fmt.Errorf("clipboard is empty")
 	}
@@ -106,28 +140,13 @@ func (p *Prompter) appendClipboardToPrompt(request *models.PromptRequest) error
 	return nil
 }
 
-// promptForBasePrompt asks the user to enter a base prompt
-func (p *Prompter) promptForBasePrompt(request *models.PromptRequest) error {
-	prompt := &survey.Input{
-		Message: "Enter your base prompt:",
-		Help:    "This is the main prompt text that will be sent to the AI",
-	}
-
-	var basePrompt string
-	if err := survey.AskOne(prompt, &basePrompt, survey.WithValidator(survey.Required)); err != nil {
-		return err
-	}
-
-	request.BasePrompt = strings.TrimSpace(basePrompt)
-	return nil
-}
-
 // promptForPreTemplate asks the user to select a pre-template
 func (p *Prompter) promptForPreTemplate(request *models.PromptRequest) error {
 	templates, err := p.findTemplates("pre")
 	if err != nil {
 		return fmt.Errorf("failed to find pre templates: %w", err)
 	}
+	templates = p.orderTemplates("pre", templates)
 
 	// Build options with proper ordering: defaults first, then "None", then regulars
 	options := p.buildOptionsWithNone(templates, "pre")
@@ -150,6 +169,7 @@ func (p *Prompter) promptForPostTemplate(request *models.PromptRequest) error {
 	if err != nil {
 		return fmt.Errorf("failed to find post templates: %w", err)
 	}
+	templates = p.orderTemplates("post", templates)
 
 	// Build options with proper ordering: defaults first, then "None", then regulars
 	options := p.buildOptionsWithNone(templates, "post")
@@ -166,10 +186,45 @@ func (p *Prompter) promptForPostTemplate(request *models.PromptRequest) error {
 	return nil
 }
 
+// promptForFixTemplate asks the user to pick a fix prompt from fix/, with fix.md as the
+// default. It asks nothing when fix/ has no templates.
+func (p *Prompter) promptForFixTemplate(request *models.PromptRequest) error {
+	templates, err := p.findTemplates("fix")
+	if err != nil {
+		return fmt.Errorf("failed to find fix templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+
+	options := append([]string{defaultFixOption}, templates...)
+	selected, err := p.selectTemplate(options, "Select a fix prompt:", "Named fix prompts live in fix/ in the prompts location", request.NumberSelect)
+	if err != nil {
+		return err
+	}
+
+	if selected != defaultFixOption {
+		request.FixTemplate = selected
+	}
+
+	return nil
+}
+
 // promptForDirectoryInclusion asks whether to include directory context
 func (p *Prompter) promptForDirectoryInclusion(request *models.PromptRequest) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Size the directory first so the answer is an informed one
+	message := "Include current directory context in the prompt?"
+	if estimate, err := estimateDirectory(cwd); err == nil {
+		message = fmt.Sprintf("Include current directory context in the prompt? (%s)", estimate)
+	}
+
 	includeDirectory, err := p.selectYesNo(
-		"Include current directory context in the prompt?",
+		message,
 		"This will include relevant files from the current directory",
 		false, // default to No
 		request.NumberSelect,
@@ -179,23 +234,12 @@ func (p *Prompter) promptForDirectoryInclusion(request *models.PromptRequest) er
 	}
 
 	if includeDirectory {
-		// Get current working directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
 		request.Directory = cwd
 	}
 
 	return nil
 }
 
-// showConfirmationSummary displays a summary and asks for confirmation
-func (p *Prompter) showConfirmationSummary(request *models.PromptRequest) error {
-	// Skip confirmation prompt entirely
-	return nil
-}
-
 // findTemplates discovers available templates in the specified subdirectory
 func (p *Prompter) findTemplates(subdir string) ([]string, error) {
 	templateDir := filepath.Join(p.promptsLocation, subdir)
@@ -214,9 +258,8 @@ func (p *Prompter) findTemplates(subdir string) ([]string, error) {
 	var regularTemplates []string
 	
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			// Remove .md extension for processing
-			name := strings.TrimSuffix(entry.Name(), ".md")
+		// Template names drop the .md extension and any encryption suffix
+		if name, ok := template.TemplateName(entry.Name()); ok && !entry.IsDir() {
 			
 			// Check if this is a default template
 			if strings.Contains(name, ".default.") {
@@ -261,8 +304,7 @@ func (p *Prompter) buildOptionsWithNone(templates []string, subdir string) []str
 		defaultNames := make(map[string]bool)
 		
 		for _, entry := range entries {
-			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-				name := strings.TrimSuffix(entry.Name(), ".md")
+			if name, ok := template.TemplateName(entry.Name()); ok && !entry.IsDir() {
 				
 				// Check if this is a default template
 				if strings.Contains(name, ".default.") || strings.HasSuffix(name, ".default") {
@@ -300,6 +342,11 @@ func (p *Prompter) buildOptionsWithNone(templates []string, subdir string) []str
 	return options
 }
 
+// SelectOption asks the user to pick one of options, using number keys when numberSelect is set
+func (p *Prompter) SelectOption(options []string, message, help string, numberSelect bool) (string, error) {
+	return p.selectTemplate(options, message, help, numberSelect)
+}
+
 // selectTemplate handles template selection with optional number key support
 func (p *Prompter) selectTemplate(options []string, message, help string, numberSelect bool) (string, error) {
 	if len(options) == 0 {
@@ -386,7 +433,7 @@ func (p *Prompter) selectTemplateWithNumbers(options []string, message, help str
 		// Handle Escape or Ctrl+C
 		if char == 27 || char == 3 {
 			fmt.Println()
-			return "", fmt.Errorf("selection cancelled")
+			return "", ErrCancelled
 		}
 
 		// For any other key, continue waiting
@@ -509,7 +556,7 @@ func (p *Prompter) selectYesNoWithNumbers(message, help string, defaultValue boo
 		// Handle Escape or Ctrl+C
 		if char == 27 || char == 3 {
 			fmt.Println()
-			return false, fmt.Errorf("selection cancelled")
+			return false, ErrCancelled
 		}
 
 		// For any other key, continue waiting
@@ -613,4 +660,29 @@ func (p *Prompter) ConfirmOverwrite(filePath string) (bool, error) {
 	}
 
 	return overwrite, nil
-}
\ No newline at end of file
+}
+
+// ConfirmPartialOutput asks whether to print what was assembled before the user cancelled
+func (p *Prompter) ConfirmPartialOutput() (bool, error) {
+	return confirm("Cancelled. Print the prompt assembled so far to stdout?")
+}
+
+// ConfirmCachedPrompt asks whether to reuse the prompt cached age ago for the same request
+func (p *Prompter) ConfirmCachedPrompt(age time.Duration) (bool, error) {
+	return confirm(fmt.Sprintf("Nothing changed since the prompt cached %s ago. Reuse it?", age.Round(time.Second)))
+}
+
+// confirm asks a yes/no question that defaults to yes
+func confirm(message string) (bool, error) {
+	confirmPrompt := &survey.Confirm{
+		Message: message,
+		Default: true,
+	}
+
+	var confirmed bool
+	if err := survey.AskOne(confirmPrompt, &confirmed); err != nil {
+		return false, err
+	}
+
+	return confirmed, nil
+}
@@ -1,11 +1,14 @@
 package interactive
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
-	"prompter-cli/pkg/models"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/imdevan/prompter/pkg/models"
 )
 
 func TestNewPrompter(t *testing.T) {
@@ -301,4 +304,25 @@ func TestTruncateString(t *testing.T) {
 				test.input, test.maxLen, result, test.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestIsCancelled(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"number selection cancelled", ErrCancelled, true},
+		{"survey interrupt", terminal.InterruptErr, true},
+		{"wrapped interrupt", fmt.Errorf("failed to collect pre-template: %w", terminal.InterruptErr), true},
+		{"other error", errors.New("clipboard is empty"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsCancelled(tt.err); result != tt.expected {
+				t.Errorf("IsCancelled(%v) = %v, expected %v", tt.err, result, tt.expected)
+			}
+		})
+	}
+}
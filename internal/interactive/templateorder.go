@@ -0,0 +1,47 @@
+package interactive
+
+import (
+	"slices"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// How the template pickers order templates, for template_order
+const (
+	TemplateOrderName     = "name"     // Defaults first, then by file name
+	TemplateOrderRecent   = "recent"   // Most recently used first
+	TemplateOrderFrequent = "frequent" // Most used first, the most recent breaking ties
+)
+
+// UsageLoader returns how the templates of templateType, pre or post, have been used, by name
+type UsageLoader func(templateType string) map[string]interfaces.TemplateUsage
+
+// SetTemplateOrder sets how the pickers order templates, and for recent and frequent, where
+// their usage comes from
+func (p *Prompter) SetTemplateOrder(order string, usage UsageLoader) {
+	p.templateOrder = order
+	p.templateUsage = usage
+}
+
+// orderTemplates sorts templates of templateType by usage when template_order asks for it.
+// Templates never used keep their order after the others, and default templates still come
+// before "None".
+func (p *Prompter) orderTemplates(templateType string, templates []string) []string {
+	if p.templateUsage == nil || (p.templateOrder != TemplateOrderRecent && p.templateOrder != TemplateOrderFrequent) {
+		return templates
+	}
+	return orderByUsage(templates, p.templateUsage(templateType), p.templateOrder)
+}
+
+// orderByUsage returns templates sorted by usage for order, recent or frequent
+func orderByUsage(templates []string, usage map[string]interfaces.TemplateUsage, order string) []string {
+	ordered := slices.Clone(templates)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		ua, ub := usage[a], usage[b]
+		if order == TemplateOrderFrequent && ua.Count != ub.Count {
+			return ub.Count - ua.Count
+		}
+		return ub.LastUsed.Compare(ua.LastUsed)
+	})
+	return ordered
+}
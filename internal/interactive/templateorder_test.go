@@ -0,0 +1,41 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestOrderTemplates(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	usage := map[string]interfaces.TemplateUsage{
+		"daily":  {Count: 40, LastUsed: now.Add(-time.Hour)},
+		"review": {Count: 3, LastUsed: now},
+		"tests":  {Count: 40, LastUsed: now.Add(-48 * time.Hour)},
+	}
+	templates := []string{"api", "daily", "docs", "review", "tests"}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: TemplateOrderName, want: templates},
+		{order: TemplateOrderRecent, want: []string{"review", "daily", "tests", "api", "docs"}},
+		{order: TemplateOrderFrequent, want: []string{"daily", "tests", "review", "api", "docs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			p := NewPrompter(t.TempDir())
+			p.SetTemplateOrder(tt.order, func(string) map[string]interfaces.TemplateUsage { return usage })
+			if got := p.orderTemplates("pre", templates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderTemplates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := NewPrompter(t.TempDir()).orderTemplates("pre", templates); !reflect.DeepEqual(got, templates) {
+		t.Errorf("orderTemplates() without usage = %v, want %v", got, templates)
+	}
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import "context"
+
+// CaptureRequest carries the options a capture provider may use
+type CaptureRequest struct {
+	Context      context.Context // Done when the run is interrupted or times out; stop commands then
+	Command      string          // Command to run (--fix-cmd)
+	Tasks        []Task          // Commands to run one after another (--task)
+	File         string          // Saved capture to read (--fix-file or fix_file)
+	ScriptFile   string          // script(1) typescript to read (script_file)
+	StateFile    string          // State database holding the capture log (state_file)
+	Lines        int             // How much scrollback terminal captures keep
+	Interactive  bool            // Whether the provider may prompt the user
+	NumberSelect bool            // Use number key selection when prompting
+}
+
+// Task is a named command --task runs
+type Task struct {
+	Name    string
+	Command string
+}
+
+// CaptureProvider supplies the content fix mode works on
+type CaptureProvider interface {
+	// Name is the --fix-source value that selects the provider
+	Name() string
+
+	// Capture returns the captured command and output
+	Capture(request CaptureRequest) (FixInfo, error)
+}
@@ -1,5 +1,7 @@
 package interfaces
 
+import "time"
+
 // CustomTemplate represents a custom template configuration
 type CustomTemplate struct {
 	Location    string `toml:"location"`
@@ -10,18 +12,74 @@ type CustomTemplate struct {
 	Description string `toml:"description"` // Custom help description
 }
 
+// TemplateGroup stands for one of several templates, picked each run: -p brainstorm-any
+type TemplateGroup struct {
+	Tag       string   `toml:"tag"`       // Members include templates whose front matter tags have this
+	Templates []string `toml:"templates"` // Members named outright
+	Select    string   `toml:"select"`    // "random" (by front matter weight, the default) or "rotate"
+}
+
 // Config represents the application configuration
 type Config struct {
 	PromptsLocation      string                     `toml:"prompts_location"`
 	LocalPromptsLocation string                     `toml:"local_prompts_location"`
 	Editor               string                     `toml:"editor"`
+	EditorWait           bool                       `toml:"editor_wait"` // Wait for GUI editors (code, subl, ...) to close the prompt
+	BasePromptInput      string                     `toml:"base_prompt_input"` // line, multiline, or editor: how interactive runs ask for the base prompt
+	TemplateOrder        string                     `toml:"template_order"`    // name, recent, or frequent: how the template pickers are ordered
+	ClipboardVerify      bool                       `toml:"clipboard_verify"` // Read the clipboard back after copying to catch truncation
+	ClipboardHistoryCommand string                  `toml:"clipboard_history_command"` // Lists recent clipboard entries for --clipboard to pick from
+	ClipboardHistorySize    int                     `toml:"clipboard_history_size"`    // How many recent entries the picker offers
 	DefaultPre           string                     `toml:"default_pre"`
 	DefaultPost          string                     `toml:"default_post"`
+	FixDefaultPre        string                     `toml:"fix_default_pre"`  // Pre-template wrapping fix prompts; default_pre doesn't apply to them
+	FixDefaultPost       string                     `toml:"fix_default_post"` // Post-template wrapping fix prompts
 	FixFile              string                     `toml:"fix_file"`
+	FixSource            string                     `toml:"fix_source"`  // Default --fix-source when no command or file is given
+	ScriptFile           string                     `toml:"script_file"` // script(1) typescript read by the "script" fix source
+	FixReview            bool                       `toml:"fix_review"` // Review and trim captured output interactively
+	PreviewOutput        bool                       `toml:"preview_output"` // Show the prompt and confirm before it reaches the target
+	FixEmbedFiles        bool                       `toml:"fix_embed_files"`       // Embed referenced files in fix prompts, not just their paths
+	LargeFiles           string                     `toml:"large_files"`           // path or sample, for files too large to embed whole
+	StripANSI            bool                       `toml:"strip_ansi"`            // Remove terminal escape sequences from captured output
+	NoiseDefaultFilters  bool                       `toml:"noise_default_filters"` // Drop progress bars, docker layer lines, etc.
+	NoiseFilters         []string                   `toml:"noise_filters"`         // Extra regexes; matching lines are dropped
+	Redact               bool                       `toml:"redact"`                // Replace secrets in included content with placeholders
+	RedactPatterns       []string                   `toml:"redact_patterns"`       // Extra regexes for secrets; a group named secret limits what is replaced
 	DirectoryStrategy    string                     `toml:"directory_strategy"`
 	Target               string                     `toml:"target"`
+	Targets              map[string]string          `toml:"targets"` // Named target specs, e.g. notes = "file+:~/notes/prompts.md"
+	Tasks                map[string]string          `toml:"tasks"`   // Named commands for --task, e.g. test = "go test ./..."
 	InteractiveDefault   bool                       `toml:"interactive_default"`
+	MinimalAuto          bool                       `toml:"minimal_auto"` // Use minimal mode automatically in CI and containers
+	InvocationLog        bool                       `toml:"invocation_log"`      // Append a content-free summary of each run to invocation_log_file
+	InvocationLogFile    string                     `toml:"invocation_log_file"` // JSON lines file written when invocation_log is set
+	StateFile            string                     `toml:"state_file"`     // Database for history, captures, template usage, sessions, trust, cached prompts, and variables
+	History              bool                       `toml:"history"`        // Keep recent prompts and fix captures in state_file
+	WorkspaceTrust       bool                       `toml:"workspace_trust"` // Ask before using project-local templates in a new directory
+	UpdateCheck          bool                       `toml:"update_check"`     // Check for a newer release in the background
+	UpdateCheckURL       string                     `toml:"update_check_url"` // Release endpoint queried by the update check
+	PackIndexURL         string                     `toml:"pack_index_url"`   // JSON index of template packs for prompter browse
+	GitHubToken          string                     `toml:"github_token"`   // Token for --github; GITHUB_TOKEN or GH_TOKEN take precedence
+	GitHubAPIURL         string                     `toml:"github_api_url"` // REST API root, for GitHub Enterprise
+	JiraURL              string                     `toml:"jira_url"`              // Site root for --jira, e.g. https://example.atlassian.net
+	JiraEmail            string                     `toml:"jira_email"`            // Account for Jira Cloud; empty sends the token as a bearer token
+	JiraToken            string                     `toml:"jira_token"`            // API token; JIRA_API_TOKEN takes precedence
+	JiraAcceptanceField  string                     `toml:"jira_acceptance_field"` // Custom field holding acceptance criteria
+	EncryptionTool       string                     `toml:"encryption_tool"`       // age or gpg, for prompter add --encrypt
+	EncryptionRecipient  string                     `toml:"encryption_recipient"`  // Key to encrypt to; empty asks for a passphrase
+	AgeIdentity          string                     `toml:"age_identity"`          // Identity file for decrypting age templates
+	AllowTemplateExec    bool                       `toml:"allow_template_exec"`   // Let templates run commands with {{exec "..."}}
+	TemplateExecTimeout  time.Duration              `toml:"template_exec_timeout"` // How long a template's exec command may run
+	URLMarkdown          bool                       `toml:"url_markdown"` // Convert HTML pages fetched with --url to markdown
+	TokenBudget          int                        `toml:"token_budget"`   // Maximum estimated tokens, 0 for unlimited
+	TemplateTokenLimit   string                     `toml:"template_token_limit"` // warn, error, or off when a template exceeds its max_tokens
+	RedirectDeprecated   bool                       `toml:"redirect_deprecated"` // Use a deprecated template's replaced_by instead of only warning
+	BudgetWeights        map[string]float64         `toml:"budget_weights"` // Relative share per section class when fitting the budget
+	Profile              string                     `toml:"profile"` // [profiles.<name>] table layered over the config file
+	Settings             map[string]interface{}     `toml:"-"`       // Every resolved key, custom ones included, for templates' .Config
 	CustomTemplates      map[string]CustomTemplate `toml:"custom_template"`
+	TemplateGroups       map[string]TemplateGroup  `toml:"template_group"` // Names that pick among templates, for -p/-o
 }
 
 // ConfigManager handles configuration loading and resolution
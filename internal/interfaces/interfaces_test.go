@@ -69,6 +69,14 @@ func (m *mockTemplateProcessor) RegisterHelpers() error {
 	return nil
 }
 
+func (m *mockTemplateProcessor) GetPromptLocations() []string {
+	return []string{}
+}
+
+func (m *mockTemplateProcessor) GetCustomTemplates() map[string]CustomTemplate {
+	return map[string]CustomTemplate{}
+}
+
 
 
 type mockOutputHandler struct{}
@@ -77,6 +85,10 @@ func (m *mockOutputHandler) WriteToClipboard(content string) error {
 	return nil
 }
 
+func (m *mockOutputHandler) ReadFromClipboard() (string, error) {
+	return "", nil
+}
+
 func (m *mockOutputHandler) WriteToStdout(content string) error {
 	return nil
 }
@@ -85,8 +97,8 @@ func (m *mockOutputHandler) WriteToFile(content string, path string) error {
 	return nil
 }
 
-func (m *mockOutputHandler) OpenInEditor(content string, editor string) error {
-	return nil
+func (m *mockOutputHandler) OpenInEditor(content string, editor string, wait bool) (string, error) {
+	return content, nil
 }
 
 // Test that mock implementations satisfy interfaces
@@ -0,0 +1,50 @@
+package interfaces
+
+// Observer receives progress events while a prompt is generated and output, so embedders
+// and the TUI can show live progress or collect metrics. Methods are called synchronously
+// from the generating goroutine and should return quickly.
+type Observer interface {
+	// OnTemplateResolved is called after a pre or post template is loaded and rendered
+	OnTemplateResolved(event TemplateEvent)
+
+	// OnFileIncluded is called for each file added to the prompt, by reference or content
+	OnFileIncluded(event FileEvent)
+
+	// OnOutputWritten is called once the prompt has been written to its target
+	OnOutputWritten(event OutputEvent)
+}
+
+// TemplateEvent describes a rendered template
+type TemplateEvent struct {
+	Name   string // Name or path as requested
+	Type   string // pre or post
+	Path   string // File the name resolved to
+	Tokens int    // Estimated tokens of the rendered template
+}
+
+// FileEvent describes a file included in the prompt
+type FileEvent struct {
+	Path     string
+	Embedded bool   // The content is in the prompt, not just the path
+	Bytes    int    // Embedded content size, 0 for references
+	Skipped  string // Why a file was referenced rather than embedded: binary, lock file, minified, or generated
+}
+
+// OutputEvent describes where the prompt was written
+type OutputEvent struct {
+	Target string // clipboard, stdout, file:/path, or file+:/path
+	Bytes  int
+	Tokens int // Estimated tokens
+}
+
+// NopObserver ignores every event; embed it to implement only some Observer methods
+type NopObserver struct{}
+
+// OnTemplateResolved does nothing
+func (NopObserver) OnTemplateResolved(TemplateEvent) {}
+
+// OnFileIncluded does nothing
+func (NopObserver) OnFileIncluded(FileEvent) {}
+
+// OnOutputWritten does nothing
+func (NopObserver) OnOutputWritten(OutputEvent) {}
@@ -5,12 +5,16 @@ type OutputHandler interface {
 	// WriteToClipboard copies content to the system clipboard
 	WriteToClipboard(content string) error
 	
+	// ReadFromClipboard returns the system clipboard's content
+	ReadFromClipboard() (string, error)
+	
 	// WriteToStdout writes content to standard output
 	WriteToStdout(content string) error
 	
 	// WriteToFile writes content to the specified file path
 	WriteToFile(content string, path string) error
 	
-	// OpenInEditor opens content in the specified editor
-	OpenInEditor(content string, editor string) error
+	// OpenInEditor opens content in the specified editor, waiting for GUI editors when wait is
+	// set, and returns the content as edited
+	OpenInEditor(content string, editor string, wait bool) (string, error)
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package interfaces
+
+import "time"
+
+// Buckets group related records in the state store
+const (
+	BucketHistory  = "history"
+	BucketCaptures = "captures"
+	BucketStats    = "stats"
+	BucketSessions = "sessions"
+	BucketTrust    = "trust"
+	BucketRotation = "rotation"
+	BucketCache    = "cache"
+	BucketVars     = "vars"
+)
+
+// TemplateUsage is how often and how recently a template was used, kept in BucketStats
+type TemplateUsage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// HistoryEntry is a prompt that was output, kept in BucketHistory
+type HistoryEntry struct {
+	Time   time.Time `json:"time"`
+	Dir    string    `json:"dir"`              // Working directory of the run
+	Prompt string    `json:"prompt,omitempty"` // Base prompt, with secrets redacted
+	Pre    string    `json:"pre,omitempty"`
+	Post   string    `json:"post,omitempty"`
+	Files  []string  `json:"files,omitempty"`
+	Fix    bool      `json:"fix,omitempty"`
+	Target string    `json:"target,omitempty"`
+	Tokens int       `json:"tokens"` // Estimated tokens of the prompt
+}
+
+// CaptureEntry is output fix mode captured, kept in BucketCaptures
+type CaptureEntry struct {
+	Time     time.Time `json:"time"`
+	Dir      string    `json:"dir"`    // Working directory of the run
+	Source   string    `json:"source"` // Fix source it came from, e.g. command or rerun
+	Command  string    `json:"command,omitempty"`
+	ExitCode int       `json:"exit_code"`
+	Raw      string    `json:"raw"` // The capture as fix mode read it, with secrets redacted
+}
+
+// Store persists prompter state (prompt history, fix captures, template usage stats,
+// sessions, trust, template group rotation, cached prompts, template variables).
+// Implementations must be safe for concurrent use by multiple prompter processes.
+type Store interface {
+	// Put stores value as JSON under key in bucket, replacing any existing value
+	Put(bucket, key string, value interface{}) error
+
+	// Get decodes the value under key into value, reporting whether it was found
+	Get(bucket, key string, value interface{}) (bool, error)
+
+	// Modify atomically decodes key into value, calls fn to change it, and stores the result.
+	// Use it instead of Get followed by Put whenever other invocations may write the same key.
+	Modify(bucket, key string, value interface{}, fn func(found bool) error) error
+
+	// Delete removes key from bucket; deleting a missing key is not an error
+	Delete(bucket, key string) error
+
+	// Append stores value under the next sequential key in bucket and returns that key
+	Append(bucket string, value interface{}) (string, error)
+
+	// Keys returns the keys in bucket in ascending order
+	Keys(bucket string) ([]string, error)
+
+	// Close releases the underlying database
+	Close() error
+}
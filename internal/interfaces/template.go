@@ -7,14 +7,16 @@ import (
 
 // TemplateData contains all variables available to templates
 type TemplateData struct {
-	Prompt string                 `json:"prompt"`
-	Now    time.Time              `json:"now"`
-	CWD    string                 `json:"cwd"`
-	Files  []FileInfo             `json:"files"`
-	Git    GitInfo                `json:"git"`
-	Config map[string]interface{} `json:"config"`
-	Env    map[string]string      `json:"env"`
-	Fix    FixInfo                `json:"fix"`
+	Prompt  string                 `json:"prompt"`
+	Now     time.Time              `json:"now"`
+	CWD     string                 `json:"cwd"`
+	Files   []FileInfo             `json:"files"`
+	Git     GitInfo                `json:"git"`
+	Project ProjectInfo            `json:"project"`
+	Config  map[string]interface{} `json:"config"`
+	Env     map[string]string      `json:"env"`
+	Vars    map[string]string      `json:"vars"` // Set with prompter vars set, global with the project's on top
+	Fix     FixInfo                `json:"fix"`
 }
 
 // FileInfo represents information about a file for templates
@@ -23,22 +25,60 @@ type FileInfo struct {
 	RelPath  string `json:"rel_path"`
 	Language string `json:"language"`
 	Content  string `json:"content"`
+	Sampled  bool   `json:"sampled"` // Content is an excerpt of a file too large to include whole
+}
+
+// ProjectInfo describes the project being worked on
+type ProjectInfo struct {
+	Root string `json:"root"`
+	Name string `json:"name"`
+	Type string `json:"type"` // e.g. "go", "node", "python"; empty when unknown
 }
 
 // GitInfo represents git repository information
 type GitInfo struct {
-	Root   string `json:"root"`
-	Branch string `json:"branch"`
-	Commit string `json:"commit"`
-	Dirty  bool   `json:"dirty"`
+	Root              string `json:"root"`
+	Branch            string `json:"branch"`              // Empty with a detached HEAD
+	Commit            string `json:"commit"`              // Hash of HEAD, empty before the first commit
+	Dirty             bool   `json:"dirty"`
+	LastCommitMessage string `json:"last_commit_message"` // Full message of HEAD
+	AuthorName        string `json:"author_name"`         // Configured user.name
+	AuthorEmail       string `json:"author_email"`        // Configured user.email
 }
 
 // FixInfo represents fix mode data
 type FixInfo struct {
-	Enabled bool   `json:"enabled"`
-	Raw     string `json:"raw"`
-	Command string `json:"command"`
-	Output  string `json:"output"`
+	Enabled  bool          `json:"enabled"`
+	Raw      string        `json:"raw"`
+	Command  string        `json:"command"`
+	Output   string        `json:"output"`
+	Stdout   string        `json:"stdout"`    // Standard output when the command was run by prompter
+	Stderr   string        `json:"stderr"`    // Standard error when the command was run by prompter
+	ExitCode int           `json:"exit_code"` // Exit code when the command was run by prompter
+	Duration time.Duration `json:"duration"`  // Run time when the command was run by prompter
+
+	Diagnostics []Diagnostic `json:"diagnostics"` // Compiler, test, and linter errors found in the output
+	Tasks       []TaskResult `json:"tasks"`       // Each task's capture when --task ran several commands
+}
+
+// TaskResult is the capture of one task a composite fix ran
+type TaskResult struct {
+	Name     string        `json:"name"`
+	Command  string        `json:"command"`
+	Output   string        `json:"output"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Diagnostic is a file/line error parsed from command output
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"` // 0 when the tool doesn't report one
+	Message string `json:"message"`
+	Tool    string `json:"tool"` // e.g. "go", "go test", "tsc", "python", "lint"
 }
 
 // TemplateProcessor handles template loading and execution
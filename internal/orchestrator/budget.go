@@ -0,0 +1,238 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+)
+
+// Section classes used for budget allocation, listed from highest to lowest priority
+const (
+	SectionBase    = "base"
+	SectionFix     = "fix"
+	SectionDiff    = "diff"
+	SectionContext = "context"
+	SectionFiles   = "files"
+	SectionTree    = "tree"
+)
+
+// sectionPriority is the order in which classes are served when fitting a budget
+var sectionPriority = []string{SectionBase, SectionFix, SectionDiff, SectionContext, SectionFiles, SectionTree}
+
+// DefaultBudgetWeights are the relative shares each class receives when the budget is contended
+var DefaultBudgetWeights = map[string]float64{
+	SectionBase:    5,
+	SectionFix:     4,
+	SectionDiff:    3,
+	SectionContext: 2,
+	SectionFiles:   2,
+	SectionTree:    1,
+}
+
+// promptSection is a single part of the assembled prompt tagged with its budget class
+type promptSection struct {
+	Class   string
+	Source  string // What produced it, e.g. pre:review or url:https://...
+	Content string
+}
+
+// EstimateTokens returns a rough token count for text (exported for app layer)
+func EstimateTokens(text string) int {
+	return estimateTokens(text)
+}
+
+// checkTemplateTokens compares a rendered template with its max_tokens front matter. Oversized
+// templates raise a warning, or an error when mode (template_token_limit) is "error".
+func (o *Orchestrator) checkTemplateTokens(name, rendered string, maxTokens int, mode string) error {
+	if maxTokens <= 0 || mode == "off" {
+		return nil
+	}
+
+	tokens := estimateTokens(rendered)
+	if tokens <= maxTokens {
+		return nil
+	}
+
+	if mode == "error" {
+		return fmt.Errorf("template %s renders to about %d tokens, over its max_tokens of %d", name, tokens, maxTokens)
+	}
+	o.warnings.Add("template %s renders to about %d tokens, over its max_tokens of %d", name, tokens, maxTokens)
+	return nil
+}
+
+// estimateTokens returns a rough token count for text (about four characters per token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// fitToBudget trims sections so their combined size stays within budget tokens.
+//
+// Classes are served in priority order. Each class is offered a share of the
+// remaining budget proportional to its weight among the classes not yet served;
+// a class that needs less than its share passes the surplus down to lower
+// priority classes. A budget of zero or less disables fitting.
+func fitToBudget(sections []promptSection, budget int, weights map[string]float64) []promptSection {
+	if budget <= 0 {
+		return sections
+	}
+
+	// Measure how many tokens each class needs
+	needs := make(map[string]int)
+	for _, section := range sections {
+		needs[section.Class] += estimateTokens(section.Content)
+	}
+
+	total := 0
+	for _, need := range needs {
+		total += need
+	}
+	if total <= budget {
+		return sections
+	}
+
+	allocations := allocateBudget(needs, budget, weights)
+
+	// Distribute each class allocation across its sections in order
+	fitted := make([]promptSection, 0, len(sections))
+	for _, section := range sections {
+		remaining := allocations[section.Class]
+		tokens := estimateTokens(section.Content)
+
+		if tokens <= remaining {
+			allocations[section.Class] -= tokens
+			fitted = append(fitted, section)
+			continue
+		}
+
+		allocations[section.Class] = 0
+		if remaining <= 0 {
+			continue
+		}
+
+		section.Content = truncateSection(section, remaining)
+		fitted = append(fitted, section)
+	}
+
+	return fitted
+}
+
+// allocateBudget splits budget across classes by priority and weight
+func allocateBudget(needs map[string]int, budget int, weights map[string]float64) map[string]int {
+	allocations := make(map[string]int)
+
+	// Only classes that actually have content take part, in priority order
+	var classes []string
+	for _, class := range sectionPriority {
+		if needs[class] > 0 {
+			classes = append(classes, class)
+		}
+	}
+	var extra []string
+	for class, need := range needs {
+		if need > 0 && !isKnownClass(class) {
+			extra = append(extra, class)
+		}
+	}
+	sort.Strings(extra)
+	classes = append(classes, extra...)
+
+	remaining := budget
+	for i, class := range classes {
+		weightSum := 0.0
+		for _, c := range classes[i:] {
+			weightSum += classWeight(c, weights)
+		}
+
+		share := remaining
+		if weightSum > 0 {
+			share = int(float64(remaining) * classWeight(class, weights) / weightSum)
+		}
+
+		// Never hand a class more than it needs; the surplus flows downward
+		if share > needs[class] {
+			share = needs[class]
+		}
+
+		allocations[class] = share
+		remaining -= share
+	}
+
+	// Hand any rounding leftovers back to classes in priority order
+	for _, class := range classes {
+		if remaining <= 0 {
+			break
+		}
+		missing := needs[class] - allocations[class]
+		if missing <= 0 {
+			continue
+		}
+		if missing > remaining {
+			missing = remaining
+		}
+		allocations[class] += missing
+		remaining -= missing
+	}
+
+	return allocations
+}
+
+// classWeight returns the configured weight for a class, falling back to the defaults
+func classWeight(class string, weights map[string]float64) float64 {
+	if weight, ok := weights[class]; ok && weight >= 0 {
+		return weight
+	}
+	if weight, ok := DefaultBudgetWeights[class]; ok {
+		return weight
+	}
+	return 1
+}
+
+// isKnownClass reports whether class is one of the built-in section classes
+func isKnownClass(class string) bool {
+	for _, known := range sectionPriority {
+		if class == known {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateSection shortens a section to roughly tokens tokens with a marker.
+// Fix output keeps its tail since errors usually appear at the end.
+func truncateSection(section promptSection, tokens int) string {
+	limit := tokens * 4
+	content := section.Content
+	if limit >= len(content) {
+		return content
+	}
+
+	dropped := estimateTokens(content) - tokens
+	marker := fmt.Sprintf("[... truncated %d tokens to fit budget ...]", dropped)
+
+	if section.Class == SectionFix {
+		start := len(content) - limit
+		for start < len(content) && !utf8.RuneStart(content[start]) {
+			start++
+		}
+		return marker + "\n" + strings.TrimLeft(content[start:], "\n")
+	}
+
+	end := limit
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return strings.TrimRight(content[:end], "\n") + "\n" + marker
+}
+
+// joinSections joins section contents into the final prompt text
+func joinSections(sections []promptSection) string {
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		if section.Content != "" {
+			parts = append(parts, section.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,150 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitToBudget_UnderBudgetUnchanged(t *testing.T) {
+	sections := []promptSection{
+		{Class: SectionBase, Content: "short prompt"},
+		{Class: SectionFiles, Content: "main.go"},
+	}
+
+	fitted := fitToBudget(sections, 1000, nil)
+	if joinSections(fitted) != joinSections(sections) {
+		t.Errorf("expected sections to be unchanged, got %q", joinSections(fitted))
+	}
+}
+
+func TestFitToBudget_ZeroBudgetDisabled(t *testing.T) {
+	sections := []promptSection{
+		{Class: SectionBase, Content: strings.Repeat("a", 4000)},
+	}
+
+	fitted := fitToBudget(sections, 0, nil)
+	if len(fitted[0].Content) != 4000 {
+		t.Errorf("expected no truncation with zero budget, got %d chars", len(fitted[0].Content))
+	}
+}
+
+func TestFitToBudget_PriorityOrder(t *testing.T) {
+	base := strings.Repeat("b", 40)   // 10 tokens
+	fix := strings.Repeat("f", 400)   // 100 tokens
+	files := strings.Repeat("x", 400) // 100 tokens
+	sections := []promptSection{
+		{Class: SectionBase, Content: base},
+		{Class: SectionFix, Content: fix},
+		{Class: SectionFiles, Content: files},
+	}
+
+	fitted := fitToBudget(sections, 110, nil)
+
+	if fitted[0].Content != base {
+		t.Errorf("expected base prompt to be kept whole")
+	}
+
+	total := 0
+	for _, section := range fitted {
+		total += estimateTokens(section.Content)
+	}
+	// Allow for truncation markers on top of the allocated budget
+	if total > 110+40 {
+		t.Errorf("expected fitted prompt near budget, got %d tokens", total)
+	}
+
+	var fixTokens, fileTokens int
+	for _, section := range fitted {
+		switch section.Class {
+		case SectionFix:
+			fixTokens = estimateTokens(section.Content)
+		case SectionFiles:
+			fileTokens = estimateTokens(section.Content)
+		}
+	}
+	if fixTokens <= fileTokens {
+		t.Errorf("expected fix output (%d tokens) to get more budget than files (%d tokens)", fixTokens, fileTokens)
+	}
+}
+
+func TestFitToBudget_DiffBeforeFiles(t *testing.T) {
+	changed := strings.Repeat("d", 400) // 100 tokens
+	other := strings.Repeat("x", 400)   // 100 tokens
+	sections := []promptSection{
+		{Class: SectionFiles, Source: "files", Content: other},
+		{Class: SectionDiff, Source: "files-from-diff", Content: changed},
+	}
+
+	fitted := fitToBudget(sections, 100, nil)
+
+	// Diff sections outweigh other files, so changed files keep more of a contended budget
+	diffTokens, fileTokens := estimateTokens(fitted[1].Content), estimateTokens(fitted[0].Content)
+	if diffTokens <= fileTokens {
+		t.Errorf("expected changed files (%d tokens) to get more budget than other files (%d tokens)", diffTokens, fileTokens)
+	}
+}
+
+func TestFitToBudget_FixKeepsTail(t *testing.T) {
+	fix := strings.Repeat("noise\n", 100) + "error: the real failure"
+	sections := []promptSection{
+		{Class: SectionFix, Content: fix},
+	}
+
+	fitted := fitToBudget(sections, 20, nil)
+	if !strings.HasSuffix(fitted[0].Content, "error: the real failure") {
+		t.Errorf("expected fix output tail to be preserved, got %q", fitted[0].Content)
+	}
+	if !strings.Contains(fitted[0].Content, "truncated") {
+		t.Errorf("expected truncation marker in %q", fitted[0].Content)
+	}
+}
+
+func TestAllocateBudget_Weights(t *testing.T) {
+	needs := map[string]int{
+		SectionDiff:  100,
+		SectionFiles: 100,
+	}
+
+	// Equal weights split the budget evenly
+	allocations := allocateBudget(needs, 100, map[string]float64{SectionDiff: 1, SectionFiles: 1})
+	if allocations[SectionDiff] != 50 || allocations[SectionFiles] != 50 {
+		t.Errorf("expected even split, got %v", allocations)
+	}
+
+	// Surplus from a small class flows to lower priority classes
+	needs = map[string]int{
+		SectionBase:  10,
+		SectionFiles: 100,
+	}
+	allocations = allocateBudget(needs, 100, nil)
+	if allocations[SectionBase] != 10 || allocations[SectionFiles] != 90 {
+		t.Errorf("expected surplus to flow down, got %v", allocations)
+	}
+}
+
+func TestCheckTemplateTokens(t *testing.T) {
+	rendered := strings.Repeat("word ", 100) // About 125 tokens
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		mode      string
+		wantErr   bool
+	}{
+		{name: "no cap", maxTokens: 0, mode: "error"},
+		{name: "under cap", maxTokens: 500, mode: "error"},
+		{name: "over cap warns", maxTokens: 50, mode: "warn"},
+		{name: "over cap with default mode warns", maxTokens: 50, mode: ""},
+		{name: "over cap fails when strict", maxTokens: 50, mode: "error", wantErr: true},
+		{name: "over cap ignored when off", maxTokens: 50, mode: "off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().checkTemplateTokens("review", rendered, tt.maxTokens, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkTemplateTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// cacheMaxFiles bounds how many files a cache key covers; bigger inputs aren't cached, since
+// fingerprinting them would cost about as much as assembling the prompt
+const cacheMaxFiles = 20000
+
+// CachedPrompt is the last prompt assembled with --cache in a workspace directory
+type CachedPrompt struct {
+	Key       string    `json:"key"`
+	Prompt    string    `json:"prompt"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// uncacheableReason returns why the prompt for request depends on something a cache key
+// can't fingerprint, or shouldn't be kept in the state file, or "" when it can be cached.
+// processor may be nil when templates aren't loaded from disk.
+func uncacheableReason(request *models.PromptRequest, cfg *interfaces.Config, processor *template.Processor) string {
+	switch {
+	case len(request.URLs) > 0 || len(request.GitHubRefs) > 0 || len(request.JiraKeys) > 0:
+		return "fetches remote context"
+	case len(request.ContextPacks) > 0:
+		return "runs context pack commands"
+	case request.FilesFromDiff:
+		return "includes changed files"
+	case request.RepoMap:
+		return "maps the repository"
+	case len(request.Symbols) > 0:
+		return "extracts Go symbols"
+	case len(request.GrepPatterns) > 0:
+		return "searches the workspace"
+	case request.FixMode && (request.FixFile == "" || request.FixCommand != "" || len(request.FixTasks) > 0 || (request.FixSource != "" && request.FixSource != FixSourceFile)):
+		return "captures command output (use --fix-file to cache fix prompts)"
+	case request.FixMode && request.Interactive && cfg.FixReview:
+		return "is trimmed interactively (fix_review)"
+	case cfg.AllowTemplateExec:
+		return "lets templates run commands (allow_template_exec)"
+	}
+	for _, file := range request.Files {
+		if isRemoteFile(file) {
+			return "includes remote files"
+		}
+	}
+	if session, err := activeSession(cfg); err == nil && session != nil {
+		return "continues a session (end it with 'prompter session end')"
+	}
+	// The cache would keep the decrypted prompt in plain text
+	if processor != nil && resolvesEncryptedTemplate(processor, request, cfg) {
+		return "uses encrypted templates"
+	}
+	return ""
+}
+
+// resolvesEncryptedTemplate reports whether assembling request decrypts a template: one of
+// its pre or post templates, a member of their group, a template a deprecated one redirects
+// to, or a partial, since every render parses all of them
+func resolvesEncryptedTemplate(processor *template.Processor, request *models.PromptRequest, cfg *interfaces.Config) bool {
+	var paths []string
+	for _, ref := range []struct{ name, templateType string }{{request.PreTemplate, "pre"}, {request.PostTemplate, "post"}} {
+		if ref.name == "" {
+			continue
+		}
+		if group, ok := cfg.TemplateGroups[strings.ToLower(ref.name)]; ok {
+			members, _ := groupMembers(processor, group, ref.templateType)
+			for _, member := range members {
+				paths = append(paths, member.Path)
+			}
+			continue
+		}
+		if path, err := processor.ResolveTemplate(ref.name); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for len(paths) > 0 {
+		path := paths[0]
+		paths = paths[1:]
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if template.IsEncrypted(path) {
+			return true
+		}
+		if !cfg.RedirectDeprecated {
+			continue
+		}
+		if fm, err := template.ReadFrontMatter(path); err == nil && fm.IsDeprecated() && fm.ReplacedBy != "" {
+			if replacement, err := processor.ResolveTemplate(fm.ReplacedBy); err == nil {
+				paths = append(paths, replacement)
+			}
+		}
+	}
+
+	encrypted := false
+	for _, dir := range processor.PartialDirs() {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && template.IsEncrypted(path) {
+				encrypted = true
+				return filepath.SkipAll
+			}
+			return nil
+		})
+	}
+	return encrypted
+}
+
+// CacheKey fingerprints everything the prompt for request is assembled from: the request,
+// the resolved configuration, the template files, and the files it includes. It returns ""
+// and the reason when the prompt can't be cached (exported for app layer).
+func (o *Orchestrator) CacheKey(request *models.PromptRequest, cfg *interfaces.Config) (string, string) {
+	processor, _ := o.templateProcessor.(*template.Processor)
+	if reason := uncacheableReason(request, cfg, processor); reason != "" {
+		return "", reason
+	}
+
+	// Where and how the prompt is delivered doesn't change what is assembled
+	assembled := *request
+	assembled.Target, assembled.AppendOutput = "", false
+	assembled.Editor, assembled.EditorRequested, assembled.NoEditorWait, assembled.Preview = "", false, false, false
+	assembled.Interactive, assembled.ForceInteractive, assembled.ForceNonInteractive = false, false, false
+	assembled.NumberSelect, assembled.Debug, assembled.UseCache, assembled.CommandLine = false, false, false, nil
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err.Error()
+	}
+	// Templates see the commit and branch as .Git, and checking out another one changes them
+	h := sha256.New()
+	for _, value := range []interface{}{assembled, cfg, cwd, o.templateVars(cfg), readGitInfo(cwd)} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err.Error()
+		}
+		h.Write(data)
+	}
+
+	// Template files change often and are small, so their metadata stands in for content
+	files := 0
+	var paths []string
+	if processor != nil {
+		for _, file := range processor.TemplateFiles() {
+			paths = append(paths, file.Path)
+		}
+		paths = append(paths, processor.PartialDirs()...)
+	}
+	paths = append(paths, filepath.Join(cfg.PromptsLocation, "fix.md"), filepath.Join(cfg.PromptsLocation, "fix"))
+	// Which workspace files a template includes is only known once it renders
+	if includesWorkspaceFiles(paths, request.PreInline, request.PostInline) {
+		return "", "includes workspace files from templates (includeFile, includeDir)"
+	}
+	if request.Directory != "" {
+		paths = append(paths, request.Directory)
+	}
+	for _, path := range paths {
+		if err := fingerprintMetadata(h, path, &files); err != nil {
+			return "", err.Error()
+		}
+	}
+
+	// Named files are the heart of the prompt; hash what they hold
+	for _, file := range append(request.Files, request.FixFile, request.SchemaFile) {
+		if file == "" {
+			continue
+		}
+		if err := fingerprintContent(h, file, &files); err != nil {
+			return "", err.Error()
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), ""
+}
+
+// fingerprintMetadata adds the path, size, and modification time of path, or of every file
+// under it, to h. A missing path counts too, so creating it changes the key.
+func fingerprintMetadata(h hash.Hash, path string, files *int) error {
+	err := filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && current == path {
+				fmt.Fprintf(h, "%s missing\n", current)
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if current != path && entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if *files++; *files > cacheMaxFiles {
+			return fmt.Errorf("includes more than %d files", cacheMaxFiles)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d %d\n", current, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return err
+}
+
+// fileIncludeHelper matches a call of the template helpers that read workspace files
+var fileIncludeHelper = regexp.MustCompile(`\binclude(File|Dir)\b`)
+
+// includesWorkspaceFiles reports whether any of texts, or of the template files at or under
+// paths, calls includeFile or includeDir
+func includesWorkspaceFiles(paths []string, texts ...string) bool {
+	for _, text := range texts {
+		if fileIncludeHelper.MatchString(text) {
+			return true
+		}
+	}
+	found := false
+	for _, path := range paths {
+		filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+			if err != nil || found || entry.IsDir() {
+				return nil
+			}
+			if content, err := os.ReadFile(current); err == nil && fileIncludeHelper.Match(content) {
+				found = true
+			}
+			return nil
+		})
+	}
+	return found
+}
+
+// fingerprintContent adds the path and contents of path to h, or the metadata of the files
+// under it when it is a directory
+func fingerprintContent(h hash.Hash, path string, files *int) error {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		// Missing files are reported when the prompt is assembled; the key just notes them
+		return fingerprintMetadata(h, path, files)
+	}
+	*files++
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	fmt.Fprintf(h, "%s\n", path)
+	_, err = io.Copy(h, file)
+	return err
+}
+
+// LoadCachedPrompt returns the prompt cached for the current directory when it was saved
+// under key (exported for app layer)
+func LoadCachedPrompt(cfg *interfaces.Config, key string) (CachedPrompt, bool) {
+	dir, err := WorkspaceDir()
+	if err != nil {
+		return CachedPrompt{}, false
+	}
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return CachedPrompt{}, false
+	}
+	defer st.Close()
+
+	var cached CachedPrompt
+	found, err := st.Get(interfaces.BucketCache, dir, &cached)
+	if err != nil || !found || cached.Key != key {
+		return CachedPrompt{}, false
+	}
+	return cached, true
+}
+
+// SaveCachedPrompt records prompt under key for the current directory, replacing the prompt
+// cached before it (exported for app layer)
+func SaveCachedPrompt(cfg *interfaces.Config, key, prompt string) error {
+	dir, err := WorkspaceDir()
+	if err != nil {
+		return err
+	}
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	return st.Put(interfaces.BucketCache, dir, CachedPrompt{Key: key, Prompt: prompt, CreatedAt: time.Now()})
+}
@@ -0,0 +1,191 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestOrchestrator_CacheKey(t *testing.T) {
+	promptsDir := t.TempDir()
+	templatePath := filepath.Join(promptsDir, "pre", "review.md")
+	if err := os.MkdirAll(filepath.Dir(templatePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(templatePath, []byte("Review this."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &interfaces.Config{PromptsLocation: promptsDir}
+	o := New()
+	o.templateProcessor.(*template.Processor).SetPromptsLocation(promptsDir)
+
+	newRequest := func() *models.PromptRequest {
+		request := models.NewPromptRequest()
+		request.BasePrompt = "explain"
+		request.PreTemplate = "review"
+		request.Files = []string{file}
+		return request
+	}
+	key, reason := o.CacheKey(newRequest(), cfg)
+	if key == "" {
+		t.Fatalf("CacheKey() uncacheable: %s", reason)
+	}
+
+	// Delivery settings don't change the prompt
+	request := newRequest()
+	request.Target = "stdout"
+	request.Preview = true
+	if got, _ := o.CacheKey(request, cfg); got != key {
+		t.Error("CacheKey() changed with the target")
+	}
+
+	request = newRequest()
+	request.BasePrompt = "summarize"
+	if got, _ := o.CacheKey(request, cfg); got == key {
+		t.Error("CacheKey() unchanged with a different base prompt")
+	}
+
+	if err := os.WriteFile(file, []byte("package other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := o.CacheKey(newRequest(), cfg); got == key {
+		t.Error("CacheKey() unchanged after the included file changed")
+	}
+	key, _ = o.CacheKey(newRequest(), cfg)
+
+	if err := os.WriteFile(templatePath, []byte("Review this carefully."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := o.CacheKey(newRequest(), cfg); got == key {
+		t.Error("CacheKey() unchanged after the template changed")
+	}
+	key, _ = o.CacheKey(newRequest(), cfg)
+
+	if err := os.WriteFile(filepath.Join(promptsDir, "fix.md"), []byte("Please fix"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := o.CacheKey(newRequest(), cfg); got == key {
+		t.Error("CacheKey() unchanged after a fix prompt was added")
+	}
+}
+
+func TestUncacheableReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   models.PromptRequest
+		cacheable bool
+	}{
+		{name: "base prompt and files", request: models.PromptRequest{BasePrompt: "x", Files: []string{"main.go"}}, cacheable: true},
+		{name: "url", request: models.PromptRequest{URLs: []string{"https://example.com"}}},
+		{name: "remote file", request: models.PromptRequest{Files: []string{"ssh://host/etc/hosts"}}},
+		{name: "fix rerun", request: models.PromptRequest{FixMode: true}},
+		{name: "fix file", request: models.PromptRequest{FixMode: true, FixFile: "out.txt"}, cacheable: true},
+		{name: "fix command", request: models.PromptRequest{FixMode: true, FixFile: "out.txt", FixCommand: "go test"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := uncacheableReason(&tt.request, &interfaces.Config{}, nil)
+			if (reason == "") != tt.cacheable {
+				t.Errorf("uncacheableReason() = %q, cacheable %v", reason, tt.cacheable)
+			}
+		})
+	}
+}
+
+func TestCachedPromptRoundTrip(t *testing.T) {
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+
+	if _, ok := LoadCachedPrompt(cfg, "abc"); ok {
+		t.Fatal("LoadCachedPrompt() found a prompt in an empty store")
+	}
+	if err := SaveCachedPrompt(cfg, "abc", "the prompt"); err != nil {
+		t.Fatalf("SaveCachedPrompt() error: %v", err)
+	}
+	cached, ok := LoadCachedPrompt(cfg, "abc")
+	if !ok || cached.Prompt != "the prompt" {
+		t.Errorf("LoadCachedPrompt() = %+v, %v; want the saved prompt", cached, ok)
+	}
+	if _, ok := LoadCachedPrompt(cfg, "def"); ok {
+		t.Error("LoadCachedPrompt() returned a prompt saved under another key")
+	}
+}
+
+func TestOrchestrator_CacheKeyGitHead(t *testing.T) {
+	repo, git, writeFile := newTestRepo(t)
+	writeFile("main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add main")
+	t.Chdir(repo)
+
+	cfg := &interfaces.Config{PromptsLocation: t.TempDir()}
+	o := New()
+	request := models.NewPromptRequest()
+	request.BasePrompt = "explain"
+	key, reason := o.CacheKey(request, cfg)
+	if key == "" {
+		t.Fatalf("CacheKey() uncacheable: %s", reason)
+	}
+
+	git("checkout", "-q", "-b", "feature")
+	if got, _ := o.CacheKey(request, cfg); got == key {
+		t.Error("CacheKey() unchanged on another branch")
+	}
+	key, _ = o.CacheKey(request, cfg)
+
+	git("commit", "-q", "--allow-empty", "-m", "Empty")
+	if got, _ := o.CacheKey(request, cfg); got == key {
+		t.Error("CacheKey() unchanged after a commit")
+	}
+}
+
+func TestOrchestrator_CacheKeyEncryptedTemplates(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		cfg   interfaces.Config
+		pre   string
+	}{
+		{name: "pre template", files: map[string]string{"pre/secret.md.age": "ciphertext"}, pre: "secret"},
+		{name: "partial", files: map[string]string{"pre/review.md": "Review this.", "partials/footer.md.gpg": "ciphertext"}, pre: "review"},
+		{
+			name:  "group member",
+			files: map[string]string{"pre/review.md": "Review this.", "pre/secret.md.age": "ciphertext"},
+			cfg:   interfaces.Config{TemplateGroups: map[string]interfaces.TemplateGroup{"reviews": {Templates: []string{"review", "secret"}}}},
+			pre:   "reviews",
+		},
+		{
+			name:  "deprecated redirect",
+			files: map[string]string{"pre/review.md": "---\ndeprecated: true\nreplaced_by: secret\n---\nReview this.", "pre/secret.md.age": "ciphertext"},
+			cfg:   interfaces.Config{RedirectDeprecated: true},
+			pre:   "review",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptsDir := t.TempDir()
+			writeTree(t, promptsDir, tt.files)
+
+			cfg := tt.cfg
+			cfg.PromptsLocation = promptsDir
+			o := New()
+			o.templateProcessor.(*template.Processor).SetPromptsLocation(promptsDir)
+			request := models.NewPromptRequest()
+			request.BasePrompt = "explain"
+			request.PreTemplate = tt.pre
+			if key, reason := o.CacheKey(request, &cfg); key != "" || reason != "uses encrypted templates" {
+				t.Errorf("CacheKey() = %q, %q; want it uncacheable for encrypted templates", key, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Built-in fix sources selectable with --fix-source or fix_source
+const (
+	FixSourceCommand   = "command"   // Run the --fix-cmd command
+	FixSourceFile      = "file"      // Read a saved capture (--fix-file, or fix_file with the shell hook)
+	FixSourceStdin     = "stdin"     // Read output piped into prompter
+	FixSourceRerun     = "rerun"     // Re-run the last command from shell history (default)
+	FixSourceTmux      = "tmux"      // Capture recent scrollback from the current tmux pane
+	FixSourceScript    = "script"    // Read the end of a script(1) session log (script_file)
+	FixSourceTasks     = "tasks"     // Run the --task commands one after another
+	FixSourceClipboard = "clipboard" // Read output copied to the clipboard
+	FixSourceLast      = "last"      // Reuse the latest capture logged in this directory
+)
+
+// defaultCaptureLines is how much scrollback terminal captures keep
+const defaultCaptureLines = 200
+
+var (
+	captureProvidersMu         sync.RWMutex
+	registeredCaptureProviders = map[string]interfaces.CaptureProvider{}
+)
+
+// RegisterCaptureProvider adds a fix source selectable with --fix-source. Builds that ship
+// their own sources call it from an init function; a provider named like a built-in replaces it.
+func RegisterCaptureProvider(provider interfaces.CaptureProvider) {
+	captureProvidersMu.Lock()
+	defer captureProvidersMu.Unlock()
+	registeredCaptureProviders[provider.Name()] = provider
+}
+
+// captureFunc adapts a function to the CaptureProvider interface
+type captureFunc struct {
+	name    string
+	capture func(request interfaces.CaptureRequest) (interfaces.FixInfo, error)
+}
+
+// Name returns the fix source name
+func (c captureFunc) Name() string {
+	return c.name
+}
+
+// Capture runs the capture function
+func (c captureFunc) Capture(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	return c.capture(request)
+}
+
+// captureProviders returns the built-in fix sources overlaid with registered ones
+func (o *Orchestrator) captureProviders() map[string]interfaces.CaptureProvider {
+	providers := map[string]interfaces.CaptureProvider{}
+	for _, provider := range []interfaces.CaptureProvider{
+		captureFunc{FixSourceCommand, o.captureCommand},
+		captureFunc{FixSourceFile, o.captureFile},
+		captureFunc{FixSourceStdin, o.captureStdin},
+		captureFunc{FixSourceRerun, o.captureRerun},
+		captureFunc{FixSourceTasks, o.captureTasks},
+		captureFunc{FixSourceClipboard, o.captureClipboard},
+		captureFunc{FixSourceLast, o.captureLast},
+		captureFunc{FixSourceTmux, func(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+			return captureTmuxPane(request.Lines)
+		}},
+		captureFunc{FixSourceScript, func(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+			return captureScriptSession(request.ScriptFile, request.Lines)
+		}},
+	} {
+		providers[provider.Name()] = provider
+	}
+
+	captureProvidersMu.RLock()
+	defer captureProvidersMu.RUnlock()
+	for name, provider := range registeredCaptureProviders {
+		providers[name] = provider
+	}
+
+	return providers
+}
+
+// captureSourceNames returns the available fix source names in sorted order
+func (o *Orchestrator) captureSourceNames() []string {
+	var names []string
+	for name := range o.captureProviders() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveFixSource picks the fix source for request: an explicit --fix-source,
+// then --task, then --fix-cmd, then a fix file, falling back to re-running from history
+func resolveFixSource(request *models.PromptRequest) string {
+	switch {
+	case request.FixSource != "":
+		return request.FixSource
+	case len(request.FixTasks) > 0:
+		return FixSourceTasks
+	case request.FixCommand != "":
+		return FixSourceCommand
+	case request.FixFile != "":
+		return FixSourceFile
+	default:
+		return FixSourceRerun
+	}
+}
+
+// captureContext returns the context request's commands run under
+func captureContext(request interfaces.CaptureRequest) context.Context {
+	if request.Context == nil {
+		return context.Background()
+	}
+	return request.Context
+}
+
+// captureCommand runs the command the user named explicitly
+func (o *Orchestrator) captureCommand(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	if request.Command == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("no command given; use --fix-cmd")
+	}
+	fmt.Fprintf(o.progress, "Running: %s\n", request.Command)
+	return o.executeAndCaptureCommand(captureContext(request), request.Command, "")
+}
+
+// captureFile reads a capture saved by prompter, the shell hook, or the user
+func (o *Orchestrator) captureFile(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	if request.File == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("no fix file given; use --fix-file or set fix_file")
+	}
+
+	content, err := os.ReadFile(request.File)
+	if err != nil {
+		return interfaces.FixInfo{}, err // Let the caller wrap this with appropriate error type
+	}
+
+	trimmedContent := strings.TrimSpace(string(content))
+	if trimmedContent == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("fix file is empty")
+	}
+
+	fixInfo := parseFixContent(trimmedContent)
+
+	// The fish hook records commands without output; re-run to capture it
+	if fixInfo.Output == "" && fixInfo.Command != "" && os.Getenv(hookEnvVar) == shellFish {
+		return o.rerunHookCommand(request, fixInfo)
+	}
+
+	return fixInfo, nil
+}
+
+// rerunHookCommand re-runs the command the fish hook recorded in request.File to capture its
+// output. Whatever is in the file gets run, so it has to be the user's own private file, and
+// the user confirms first; noninteractive runs refuse.
+func (o *Orchestrator) rerunHookCommand(request interfaces.CaptureRequest, fixInfo interfaces.FixInfo) (interfaces.FixInfo, error) {
+	if err := checkPrivateFile(request.File); err != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("not re-running the command recorded in %s: %w", request.File, err)
+	}
+	if !request.Interactive {
+		return interfaces.FixInfo{}, fmt.Errorf("the fish hook recorded %q without its output; re-running it needs confirmation, so run interactively or pass it with --fix-cmd", fixInfo.Command)
+	}
+
+	confirm := o.confirm
+	if confirm == nil {
+		confirm = o.selectYesNo
+	}
+	rerun, err := confirm(fmt.Sprintf("Re-run %q to capture its output?", fixInfo.Command), "The fish hook records commands without their output", true, request.NumberSelect)
+	if err != nil {
+		return interfaces.FixInfo{}, err
+	}
+	if !rerun {
+		// The recorded command and exit code are still worth a prompt
+		return fixInfo, nil
+	}
+
+	fmt.Fprintf(o.progress, "Running: %s\n", fixInfo.Command)
+	return o.executeAndCaptureCommand(captureContext(request), fixInfo.Command, shellFish)
+}
+
+// captureStdin reads output piped into prompter
+func (o *Orchestrator) captureStdin(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	if term.IsTerminal(int(syscall.Stdin)) {
+		return interfaces.FixInfo{}, fmt.Errorf("nothing piped to stdin; try: command 2>&1 | prompter --fix-source stdin")
+	}
+
+	content, err := o.readFromStdin()
+	if err != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	trimmedContent := strings.TrimSpace(string(content))
+	if trimmedContent == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("stdin is empty")
+	}
+
+	return interfaces.FixInfo{
+		Enabled: true,
+		Raw:     trimmedContent,
+		Output:  trimmedContent,
+	}, nil
+}
+
+// captureClipboard reads output the user copied, such as a stack trace from a browser console
+func (o *Orchestrator) captureClipboard(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	content, err := o.outputHandler.ReadFromClipboard()
+	if err != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("failed to read the clipboard: %w", err)
+	}
+
+	trimmedContent := strings.TrimSpace(content)
+	if trimmedContent == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("clipboard is empty")
+	}
+
+	return interfaces.FixInfo{
+		Enabled: true,
+		Raw:     trimmedContent,
+		Output:  trimmedContent,
+	}, nil
+}
+
+// captureRerun re-runs the last command from shell history, asking first in interactive mode
+func (o *Orchestrator) captureRerun(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	if request.Interactive {
+		return o.promptAndRerunLastCommand(captureContext(request), request.NumberSelect)
+	}
+	return o.rerunLastCommand(captureContext(request))
+}
+
+// captureScriptSession reads the last lines of a session recorded with `script -f path`
+func captureScriptSession(path string, lines int) (interfaces.FixInfo, error) {
+	if path == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("script_file is not set; record a session with 'script -f <file>' and point script_file at it")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return interfaces.FixInfo{}, err
+	}
+
+	trimmedContent := trimScriptLog(string(content), lines)
+	if trimmedContent == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("script session is empty")
+	}
+
+	return interfaces.FixInfo{
+		Enabled: true,
+		Raw:     trimmedContent,
+		Output:  trimmedContent,
+	}, nil
+}
+
+// trimScriptLog keeps the last lines of a typescript, without script(1)'s start and end banners
+func trimScriptLog(content string, lines int) string {
+	var kept []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "Script started on ") || strings.HasPrefix(line, "Script done on ") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	// Trim the trailing prompt first so it doesn't count against the line limit
+	kept = strings.Split(trimTerminalCapture(strings.Join(kept, "\n")), "\n")
+	if lines > 0 && len(kept) > lines {
+		kept = kept[len(kept)-lines:]
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,190 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestResolveFixSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		want    string
+	}{
+		{name: "explicit source", request: &models.PromptRequest{FixSource: FixSourceTmux}, want: FixSourceTmux},
+		{name: "fix command", request: &models.PromptRequest{FixCommand: "make"}, want: FixSourceCommand},
+		{name: "fix file", request: &models.PromptRequest{FixFile: "/tmp/out.txt"}, want: FixSourceFile},
+		{name: "nothing given", request: &models.PromptRequest{}, want: FixSourceRerun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveFixSource(tt.request); got != tt.want {
+				t.Errorf("resolveFixSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimScriptLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lines   int
+		want    string
+	}{
+		{
+			name:    "drops banners, CRs, and prompter prompt",
+			content: "Script started on 2026-01-02 10:00:00+00:00 [TERM=\"xterm\"]\r\n$ go vet ./...\r\n./a.go:1:1: bad\r\n$ prompter --fix-source script\r\n",
+			lines:   200,
+			want:    "$ go vet ./...\n./a.go:1:1: bad",
+		},
+		{
+			name:    "keeps only the last lines",
+			content: "one\ntwo\nthree\nfour\n",
+			lines:   2,
+			want:    "three\nfour",
+		},
+		{
+			name:    "finished session",
+			content: "$ make\nError 1\n\nScript done on 2026-01-02 10:05:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n",
+			lines:   200,
+			want:    "$ make\nError 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimScriptLog(tt.content, tt.lines); got != tt.want {
+				t.Errorf("trimScriptLog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureScriptSession_Unset(t *testing.T) {
+	if _, err := captureScriptSession("", defaultCaptureLines); err == nil {
+		t.Error("captureScriptSession() expected error when script_file is unset")
+	}
+}
+
+func TestOrchestrator_CaptureFile(t *testing.T) {
+	t.Setenv(hookEnvVar, "")
+	path := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(path, []byte("$ go build\n\nmain.go:1: oops\n\n# Exit code: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fixInfo, err := New().captureFile(interfaces.CaptureRequest{File: path})
+	if err != nil {
+		t.Fatalf("captureFile() error = %v", err)
+	}
+	if fixInfo.Command != "go build" || fixInfo.Output != "main.go:1: oops" || fixInfo.ExitCode != 1 {
+		t.Errorf("captureFile() = %+v", fixInfo)
+	}
+}
+
+func TestOrchestrator_CaptureFileFishHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and file modes")
+	}
+	t.Setenv(hookEnvVar, shellFish)
+	dir := t.TempDir()
+	ran := filepath.Join(dir, "ran.txt")
+	path := filepath.Join(dir, "fix.txt")
+	if err := os.WriteFile(path, []byte("$ echo ran > "+ran+"\n\n# Exit code: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var asked int
+	o := New()
+	o.confirm = func(string, string, bool, bool) (bool, error) {
+		asked++
+		return false, nil
+	}
+
+	// Noninteractive runs refuse to re-run the recorded command
+	if _, err := o.captureFile(interfaces.CaptureRequest{File: path}); err == nil || !strings.Contains(err.Error(), "--fix-cmd") {
+		t.Errorf("captureFile() noninteractive error = %v, want a refusal pointing to --fix-cmd", err)
+	}
+
+	// Declining keeps the recorded command without running it
+	fixInfo, err := o.captureFile(interfaces.CaptureRequest{File: path, Interactive: true})
+	if err != nil || asked != 1 || fixInfo.Command != "echo ran > "+ran || fixInfo.ExitCode != 1 {
+		t.Errorf("captureFile() declined = %+v, %v after %d questions", fixInfo, err, asked)
+	}
+
+	// A fix file other users can write isn't trusted at all
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.captureFile(interfaces.CaptureRequest{File: path, Interactive: true}); err == nil || asked != 1 {
+		t.Errorf("captureFile() of a shared file error = %v after %d questions, want a refusal without asking", err, asked)
+	}
+
+	// Other hooks record the output themselves, so nothing is re-run
+	t.Setenv(hookEnvVar, shellZsh)
+	if _, err := o.captureFile(interfaces.CaptureRequest{File: path, Interactive: true}); err != nil || asked != 1 {
+		t.Errorf("captureFile() with the zsh hook = %v after %d questions", err, asked)
+	}
+	if _, err := os.Stat(ran); !os.IsNotExist(err) {
+		t.Error("the recorded command ran")
+	}
+}
+
+func TestOrchestrator_CaptureClipboard(t *testing.T) {
+	o := New()
+	stub := &clipboardStub{}
+	o.outputHandler = stub
+
+	if _, err := o.captureClipboard(interfaces.CaptureRequest{}); err == nil {
+		t.Error("expected an error for an empty clipboard")
+	}
+	stub.WriteToClipboard("  TypeError: x is undefined\n    at render (app.js:12:5)\n")
+	fixInfo, err := o.captureClipboard(interfaces.CaptureRequest{})
+	if err != nil {
+		t.Fatalf("captureClipboard() error = %v", err)
+	}
+	if want := "TypeError: x is undefined\n    at render (app.js:12:5)"; fixInfo.Raw != want || fixInfo.Output != want || fixInfo.Command != "" {
+		t.Errorf("captureClipboard() = %+v", fixInfo)
+	}
+}
+
+// stubCapture is a fix source registered by tests
+type stubCapture struct{}
+
+func (stubCapture) Name() string { return "stub" }
+
+func (stubCapture) Capture(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	return interfaces.FixInfo{Enabled: true, Raw: "stub output", Output: "stub output"}, nil
+}
+
+func TestRegisterCaptureProvider(t *testing.T) {
+	RegisterCaptureProvider(stubCapture{})
+	defer func() {
+		captureProvidersMu.Lock()
+		delete(registeredCaptureProviders, "stub")
+		captureProvidersMu.Unlock()
+	}()
+
+	o := New()
+	request := &models.PromptRequest{FixMode: true, FixSource: "stub"}
+	if err := o.validateRequest(request); err != nil {
+		t.Fatalf("validateRequest() error = %v", err)
+	}
+
+	fixInfo, err := o.loadFixContent(context.Background(), request, &interfaces.Config{})
+	if err != nil {
+		t.Fatalf("loadFixContent() error = %v", err)
+	}
+	if fixInfo.Raw != "stub output" {
+		t.Errorf("loadFixContent() Raw = %q, want %q", fixInfo.Raw, "stub output")
+	}
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// osc52Limit is roughly the largest OSC 52 sequence terminals accept; some drop bigger copies
+const osc52Limit = 100000
+
+// clipboardBackend copies text somewhere a paste can reach when the system clipboard can't
+type clipboardBackend struct {
+	name      string
+	available func() bool
+	write     func(content string) error
+}
+
+// clipboardBackends are tried in order when the system clipboard fails. Linux desktops get
+// wl-copy, xclip, and xsel directly, since the clipboard library gives up when the one it
+// picks can't reach a display. Then tmux's paste buffer inside tmux, and OSC 52 over SSH,
+// where a local terminal can set its own clipboard. A var so tests can replace them.
+var clipboardBackends = []clipboardBackend{
+	{name: "wl-copy", available: hasDisplay("WAYLAND_DISPLAY", "wl-copy"), write: clipboardCommand("wl-copy")},
+	{name: "xclip", available: hasDisplay("DISPLAY", "xclip"), write: clipboardCommand("xclip", "-selection", "clipboard")},
+	{name: "xsel", available: hasDisplay("DISPLAY", "xsel"), write: clipboardCommand("xsel", "--clipboard", "--input")},
+	{name: "tmux", available: inTmux, write: writeTmuxBuffer},
+	{name: "osc52", available: inSSHSession, write: writeOSC52},
+}
+
+// hasDisplay returns a check that the display variable is set and tool is installed
+func hasDisplay(variable, tool string) func() bool {
+	return func() bool {
+		if os.Getenv(variable) == "" {
+			return false
+		}
+		_, err := exec.LookPath(tool)
+		return err == nil
+	}
+}
+
+// clipboardCommand returns a backend write that pipes content to a clipboard tool. Its output
+// isn't captured: the tools fork a process that serves the selection, and it would keep the
+// pipes open.
+func clipboardCommand(name string, args ...string) func(string) error {
+	return func(content string) error {
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = strings.NewReader(content)
+		return cmd.Run()
+	}
+}
+
+// inTmux reports whether prompter runs inside tmux
+func inTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// inSSHSession reports whether prompter runs over SSH
+func inSSHSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// AvailableClipboardBackends returns the fallback backends that can be used here, in the order
+// they are tried
+func AvailableClipboardBackends() []string {
+	var names []string
+	for _, backend := range clipboardBackends {
+		if backend.available() {
+			names = append(names, backend.name)
+		}
+	}
+	return names
+}
+
+// copyWithBackend writes content with the first available fallback backend and returns its
+// name. It fails when none is available or all of them fail. debugf reports each attempt.
+func copyWithBackend(content string, debugf func(format string, args ...any)) (string, error) {
+	var failures []string
+	for _, backend := range clipboardBackends {
+		if !backend.available() {
+			debugf("clipboard backend %s: not available", backend.name)
+			continue
+		}
+		if err := backend.write(content); err != nil {
+			debugf("clipboard backend %s: %v", backend.name, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", backend.name, err))
+			continue
+		}
+		return backend.name, nil
+	}
+	if len(failures) == 0 {
+		return "", fmt.Errorf("no clipboard backend available")
+	}
+	return "", fmt.Errorf("%s", strings.Join(failures, "; "))
+}
+
+// writeTmuxBuffer loads content into tmux's paste buffer. -w also sends it to the terminal's
+// clipboard (tmux 3.2+); older versions only set the buffer.
+func writeTmuxBuffer(content string) error {
+	if !inTmux() {
+		return fmt.Errorf("not running inside tmux")
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found in PATH")
+	}
+
+	// load-buffer reads stdin, so large prompts don't hit the argument size limit set-buffer has
+	load := func(args ...string) error {
+		cmd := exec.Command("tmux", append([]string{"load-buffer"}, args...)...)
+		cmd.Stdin = strings.NewReader(content)
+		return cmd.Run()
+	}
+	if err := load("-w", "-"); err != nil {
+		if err := load("-"); err != nil {
+			return fmt.Errorf("tmux load-buffer failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// osc52Sequence returns the escape sequence that asks the terminal to put content on its
+// clipboard. Inside tmux it is wrapped to pass through to the outer terminal.
+func osc52Sequence(content string, tmux bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(content)) + "\x07"
+	if tmux {
+		// tmux drops unknown sequences unless they're wrapped and their ESCs doubled
+		return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return sequence
+}
+
+// writeOSC52 sends content to the terminal's clipboard with an OSC 52 escape sequence. It
+// writes to the controlling terminal, so it works while stdout is redirected.
+func writeOSC52(content string) error {
+	sequence := osc52Sequence(content, inTmux())
+	if len(sequence) > osc52Limit {
+		return fmt.Errorf("the prompt is too large for OSC 52 (%d KB encoded, terminals accept about %d KB)",
+			len(sequence)/1000, osc52Limit/1000)
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to send the OSC 52 sequence to: %w", err)
+	}
+	defer tty.Close()
+	if _, err := tty.WriteString(sequence); err != nil {
+		return fmt.Errorf("failed to write to the terminal: %w", err)
+	}
+	return nil
+}
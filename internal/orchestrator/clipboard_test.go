@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestOsc52Sequence(t *testing.T) {
+	tests := []struct {
+		name string
+		tmux bool
+		want string
+	}{
+		{name: "terminal", want: "\x1b]52;c;aGk=\x07"},
+		{name: "tmux passthrough", tmux: true, want: "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := osc52Sequence("hi", tt.tmux); got != tt.want {
+				t.Errorf("osc52Sequence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeBackend returns a clipboard backend that records what it was given
+func fakeBackend(name string, available bool, err error, got *string) clipboardBackend {
+	return clipboardBackend{
+		name:      name,
+		available: func() bool { return available },
+		write: func(content string) error {
+			if err == nil {
+				*got = content
+			}
+			return err
+		},
+	}
+}
+
+func TestCopyWithBackend(t *testing.T) {
+	defer func(backends []clipboardBackend) { clipboardBackends = backends }(clipboardBackends)
+
+	tests := []struct {
+		name    string
+		tmux    bool
+		tmuxErr error
+		ssh     bool
+		want    string
+		wantErr bool
+	}{
+		{name: "tmux first", tmux: true, ssh: true, want: "tmux"},
+		{name: "osc52 over ssh", ssh: true, want: "osc52"},
+		{name: "osc52 after tmux fails", tmux: true, tmuxErr: errors.New("no server"), ssh: true, want: "osc52"},
+		{name: "nothing available", wantErr: true},
+		{name: "all fail", tmux: true, tmuxErr: errors.New("no server"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			clipboardBackends = []clipboardBackend{
+				fakeBackend("tmux", tt.tmux, tt.tmuxErr, &got),
+				fakeBackend("osc52", tt.ssh, nil, &got),
+			}
+
+			backend, err := copyWithBackend("prompt", func(string, ...any) {})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("copyWithBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if backend != tt.want {
+				t.Errorf("copyWithBackend() = %q, want %q", backend, tt.want)
+			}
+			if !tt.wantErr && got != "prompt" {
+				t.Errorf("backend got %q, want the prompt", got)
+			}
+		})
+	}
+}
+
+// failingClipboard is an output handler without a system clipboard
+type failingClipboard struct {
+	OutputHandler
+}
+
+func (h *failingClipboard) WriteToClipboard(content string) error {
+	return errors.New("no clipboard utilities available")
+}
+
+func TestOrchestrator_OutputPromptClipboardBackend(t *testing.T) {
+	defer func(backends []clipboardBackend) { clipboardBackends = backends }(clipboardBackends)
+	var got string
+	clipboardBackends = []clipboardBackend{fakeBackend("osc52", true, nil, &got)}
+
+	request := models.NewPromptRequest()
+	request.Interactive = false
+	request.Target = "clipboard"
+
+	o := New()
+	o.outputHandler = &failingClipboard{}
+	if err := o.OutputPrompt(context.Background(), "the prompt", request, &interfaces.Config{ClipboardVerify: true}); err != nil {
+		t.Fatalf("OutputPrompt() error = %v", err)
+	}
+	if got != "the prompt" {
+		t.Errorf("backend got %q, want the prompt", got)
+	}
+}
+
+func TestClipboardCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clipboard")
+	write := clipboardCommand("sh", "-c", "cat > "+path)
+	if err := write("copied prompt"); err != nil {
+		t.Fatalf("clipboardCommand() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "copied prompt" {
+		t.Errorf("tool got %q, want the prompt", data)
+	}
+
+	if err := clipboardCommand("sh", "-c", "exit 1")("prompt"); err == nil {
+		t.Error("expected error when the tool fails")
+	}
+}
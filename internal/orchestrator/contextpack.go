@@ -0,0 +1,183 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ContextPackFile defines a repository's context packs: named bundles of files and commands
+// included together with --pack
+const ContextPackFile = ".prompter-pack.toml"
+
+// contextPack is one bundle in a context pack file:
+//
+//	[auth-flow]
+//	description = "Login and session handling"
+//	files = ["internal/auth/*.go", "cmd/login.go"]
+//	commands = ["go test ./internal/auth/..."]
+type contextPack struct {
+	Description string   `toml:"description"`
+	Files       []string `toml:"files"`    // Paths or globs, relative to the pack file
+	Commands    []string `toml:"commands"` // Run from the current directory; their output is included
+}
+
+// findContextPackFile looks for a context pack file from dir up to the project root
+func findContextPackFile(dir string) (string, bool) {
+	root := detectProject(dir).Root
+	for current := dir; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, ContextPackFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		if current == root || filepath.Dir(current) == current {
+			return "", false
+		}
+	}
+}
+
+// loadContextPacks parses the context packs defined in path
+func loadContextPacks(path string) (map[string]contextPack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var packs map[string]contextPack
+	if err := toml.Unmarshal(data, &packs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return packs, nil
+}
+
+// contextPackFiles expands a pack's file patterns, relative to baseDir, in order and without
+// duplicates. Paths inside cwd are made relative to it, like --file arguments. Glob matches
+// that ignored excludes are left out; paths named outright are kept. The pack file comes from
+// the repository, so matches outside root are left out with a warning.
+func (o *Orchestrator) contextPackFiles(pack contextPack, baseDir, cwd, root string, ignored *ignore.Matcher) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range pack.Files {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil || len(matches) == 0 {
+			o.warnings.Add("context pack file %s matches nothing", pattern)
+			continue
+		}
+		sort.Strings(matches)
+		isGlob := strings.ContainsAny(pattern, "*?[")
+		for _, match := range matches {
+			if isGlob && ignored.Match(match, isDirectory(match)) {
+				continue
+			}
+			if _, ok := resolveProjectFile(match, cwd, root); !ok {
+				o.warnings.Add("context pack file %s left out: outside the project (%s)", match, root)
+				continue
+			}
+			if rel, err := filepath.Rel(cwd, match); err == nil && !strings.HasPrefix(rel, "..") {
+				match = rel
+			}
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files
+}
+
+// isDirectory reports whether path is a directory
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// workspaceTrusted reports whether dir was trusted with 'prompter trust'; everything is
+// trusted when workspace_trust is off
+func workspaceTrusted(cfg *interfaces.Config, dir string) bool {
+	if !cfg.WorkspaceTrust {
+		return true
+	}
+
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return false
+	}
+	defer st.Close()
+
+	var decision trustDecision
+	found, err := st.Get(interfaces.BucketTrust, dir, &decision)
+	return err == nil && found && decision.Trusted
+}
+
+// contextPackSections resolves the packs named with --pack. Their files are returned for the
+// files section; each command's output becomes a context section. A pack file comes from the
+// repository, so its commands only run once the directory is trusted.
+func (o *Orchestrator) contextPackSections(ctx context.Context, names []string, cfg *interfaces.Config) ([]string, []promptSection, error) {
+	cwd, err := WorkspaceDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	path, ok := findContextPackFile(cwd)
+	if !ok {
+		return nil, nil, NewValidationError("pack", strings.Join(names, ", "), "no "+ContextPackFile+" found in this project")
+	}
+	packs, err := loadContextPacks(path)
+	if err != nil {
+		return nil, nil, NewConfigurationError(fmt.Sprintf("failed to load context packs from %s", path), err)
+	}
+
+	ignored, err := ignore.Find(cwd)
+	if err != nil {
+		o.warnings.Add("failed to read %s: %v", ignore.FileName, err)
+	}
+	root := detectProject(cwd).Root
+
+	var files []string
+	var sections []promptSection
+	for _, name := range names {
+		pack, ok := packs[name]
+		if !ok {
+			available := make([]string, 0, len(packs))
+			for packName := range packs {
+				available = append(available, packName)
+			}
+			sort.Strings(available)
+			return nil, nil, NewValidationError("pack", name, "not defined in "+path+" (available: "+strings.Join(available, ", ")+")")
+		}
+
+		files = append(files, o.contextPackFiles(pack, filepath.Dir(path), cwd, root, ignored)...)
+
+		if len(pack.Commands) > 0 && !workspaceTrusted(cfg, cwd) {
+			o.warnings.Add("context pack %s: commands skipped until %s is trusted (run 'prompter trust')", name, cwd)
+			continue
+		}
+		for _, command := range pack.Commands {
+			fmt.Fprintf(o.progress, "Running: %s\n", command)
+			info, err := o.executeAndCaptureCommand(ctx, command, "")
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			if err != nil {
+				o.warnings.Add("context pack %s: %v", name, err)
+				continue
+			}
+			sections = append(sections, promptSection{
+				Class:   SectionContext,
+				Source:  "pack:" + name,
+				Content: fmt.Sprintf("Context pack %s:\n```\n%s\n```", name, info.Raw),
+			})
+		}
+	}
+	return files, sections, nil
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestContextPackSections(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "id_rsa"), []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	files := map[string]string{
+		".git/HEAD":       "ref: refs/heads/main",
+		"auth/login.go":   "package auth",
+		"auth/session.go": "package auth",
+		"auth/auth.pb.go": "package auth",
+		"cmd/main.go":     "package main",
+		ignore.FileName:   "*.pb.go\n",
+		ContextPackFile:   "[auth-flow]\ndescription = \"Login\"\nfiles = [\"auth/*.go\", \"cmd/main.go\", \"auth/login.go\", \"missing/*.go\", \"../*/id_rsa\", \"" + filepath.ToSlash(outside) + "/id_rsa\", \"link/*\"]\ncommands = [\"echo checking auth\"]\n",
+	}
+	writeTree(t, root, files)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(root, "cmd"))
+
+	o := New()
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+
+	included, sections, err := o.contextPackSections(context.Background(), []string{"auth-flow"}, cfg)
+	if err != nil {
+		t.Fatalf("contextPackSections() error = %v", err)
+	}
+	// Files outside the current directory stay absolute; duplicates, empty globs, ignored
+	// matches, and files outside the project, through .. or a symlink, are dropped
+	want := []string{filepath.Join(root, "auth", "login.go"), filepath.Join(root, "auth", "session.go"), "main.go"}
+	if strings.Join(included, ",") != strings.Join(want, ",") {
+		t.Errorf("contextPackSections() files = %v, want %v", included, want)
+	}
+	if len(sections) != 1 || !strings.Contains(sections[0].Content, "$ echo checking auth") || !strings.Contains(sections[0].Content, "checking auth") {
+		t.Errorf("contextPackSections() sections = %+v, want the command output", sections)
+	}
+
+	// Repository commands wait for the directory to be trusted
+	cfg.WorkspaceTrust = true
+	if _, sections, err := o.contextPackSections(context.Background(), []string{"auth-flow"}, cfg); err != nil || len(sections) != 0 {
+		t.Errorf("contextPackSections() untrusted = %d sections, %v; want commands skipped", len(sections), err)
+	}
+
+	if _, _, err := o.contextPackSections(context.Background(), []string{"billing"}, cfg); err == nil || !strings.Contains(err.Error(), "available: auth-flow") {
+		t.Errorf("contextPackSections(billing) error = %v, want the available packs", err)
+	}
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"fmt"
+	gotemplate "text/template"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+)
+
+// followDeprecation warns when a template's front matter marks it deprecated. With
+// redirect_deprecated, a template with replaced_by is swapped for its replacement, following
+// chains of renames; it returns the template to render and its name.
+func (o *Orchestrator) followDeprecation(tmpl *gotemplate.Template, name string, cfg *interfaces.Config) (*gotemplate.Template, string, error) {
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok {
+		return tmpl, name, nil
+	}
+
+	seen := map[string]bool{name: true}
+	for {
+		fm := processor.TemplateFrontMatter(tmpl)
+		switch {
+		case !fm.IsDeprecated():
+			return tmpl, name, nil
+		case fm.ReplacedBy == "":
+			o.warnings.Add("template %s is deprecated", name)
+			return tmpl, name, nil
+		case !cfg.RedirectDeprecated:
+			o.warnings.Add("template %s is deprecated; use %s instead", name, fm.ReplacedBy)
+			return tmpl, name, nil
+		case seen[fm.ReplacedBy]:
+			return nil, "", fmt.Errorf("template %s is replaced_by %s, which leads back to it", name, fm.ReplacedBy)
+		}
+
+		replacement, err := o.templateProcessor.LoadTemplate(fm.ReplacedBy)
+		if err != nil {
+			return nil, "", fmt.Errorf("template %s is deprecated and its replacement %s failed to load: %w", name, fm.ReplacedBy, err)
+		}
+		o.warnings.Add("template %s is deprecated; using %s instead", name, fm.ReplacedBy)
+		seen[fm.ReplacedBy] = true
+		tmpl, name = replacement, fm.ReplacedBy
+	}
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+func TestFollowDeprecation(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"pre/old.md":     "---\nreplaced_by: middle\n---\nold",
+		"pre/middle.md":  "---\ndeprecated: true\nreplaced_by: new\n---\nmiddle",
+		"pre/new.md":     "new",
+		"pre/retired.md": "---\ndeprecated: true\n---\nretired",
+		"pre/loop-a.md":  "---\nreplaced_by: loop-b\n---\na",
+		"pre/loop-b.md":  "---\nreplaced_by: loop-a\n---\nb",
+	}
+	writeTree(t, root, files)
+
+	tests := []struct {
+		name     string
+		redirect bool
+		want     string
+		warning  string
+		wantErr  bool
+	}{
+		{name: "old", want: "old", warning: "template old is deprecated; use middle instead"},
+		{name: "old", redirect: true, want: "new", warning: "template middle is deprecated; using new instead"},
+		{name: "retired", redirect: true, want: "retired", warning: "template retired is deprecated"},
+		{name: "new", redirect: true, want: "new"},
+		{name: "loop-a", redirect: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := New()
+			o.templateProcessor.(*template.Processor).SetPromptsLocation(root)
+			tmpl, err := o.templateProcessor.LoadTemplate(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, name, err := o.followDeprecation(tmpl, tt.name, &interfaces.Config{RedirectDeprecated: tt.redirect})
+			var out strings.Builder
+			warnings.Flush(&out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("followDeprecation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name != tt.want {
+				t.Errorf("followDeprecation() = %s, want %s", name, tt.want)
+			}
+			if !strings.Contains(out.String(), tt.warning) || (tt.warning == "" && out.Len() > 0) {
+				t.Errorf("warnings = %q, want %q", out.String(), tt.warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// changedFiles returns the files the working tree changes, staged or not, in the order git
+// lists them: relative to HEAD, or with since set, to where the branch left since (their merge
+// base), so a branch's commits count too. Paths inside cwd are made relative to it, like
+// --file arguments. Deleted files are skipped; untracked files follow the diff when untracked
+// is set.
+func changedFiles(cwd, since string, untracked bool) ([]string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed")
+	}
+	root, err := gitOutput(cwd, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository", cwd)
+	}
+	root = strings.TrimSpace(root)
+
+	base := "HEAD"
+	if _, err := gitOutput(cwd, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		base = emptyTreeHash
+	}
+	if since != "" && since != "HEAD" {
+		if _, err := gitOutput(cwd, "rev-parse", "--verify", "--quiet", since+"^{commit}"); err != nil {
+			return nil, NewValidationError("changed", since, "not a branch, tag, or commit")
+		}
+		mergeBase, err := gitOutput(cwd, "merge-base", since, "HEAD")
+		if err != nil {
+			return nil, NewValidationError("changed", since, "shares no history with HEAD")
+		}
+		base = strings.TrimSpace(mergeBase)
+	}
+	out, err := gitOutput(cwd, "diff", "--name-only", "-z", base)
+	if err != nil {
+		return nil, err
+	}
+	if untracked {
+		others, err := gitOutput(cwd, "ls-files", "-z", "--others", "--exclude-standard", "--full-name")
+		if err != nil {
+			return nil, err
+		}
+		out += others
+	}
+
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(root, name)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// formatChangedFiles embeds the current contents of files. Files policy skips are listed by
+// path only, as are files over maxEmbeddedFileBytes unless policy samples them, which embeds
+// an excerpt instead. It also returns an event for each file.
+func formatChangedFiles(files []string, policy embedPolicy) (string, []interfaces.FileEvent) {
+	var parts []string
+	var included []interfaces.FileEvent
+	reads := readFiles(files, policy.Sample, fileReadWorkers)
+	for i, file := range files {
+		read := reads[i]
+		switch {
+		case read.Info == nil:
+			continue
+		case read.Large && !policy.Sample:
+			parts = append(parts, file+" (too large to embed)")
+			included = append(included, interfaces.FileEvent{Path: file})
+			continue
+		case read.Err != nil:
+			continue
+		}
+		if reason := policy.skipReason(file, read.Content); reason != "" {
+			parts = append(parts, file+" ("+reason+")")
+			included = append(included, interfaces.FileEvent{Path: file, Skipped: reason})
+			continue
+		}
+
+		heading := file
+		if read.Large {
+			heading += " (sampled from " + formatSize(read.Info.Size()) + ")"
+		}
+		language := strings.TrimPrefix(filepath.Ext(file), ".")
+		parts = append(parts, fmt.Sprintf("%s:\n```%s\n%s\n```", heading, language, strings.TrimRight(read.Content, "\n")))
+		included = append(included, interfaces.FileEvent{Path: file, Embedded: true, Bytes: len(read.Content)})
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return "Changed files:\n\n" + strings.Join(parts, "\n\n"), included
+}
+
+// changedFilesSection builds the --files-from-diff or --changed section from the working
+// tree's diff
+func (o *Orchestrator) changedFilesSection(request *models.PromptRequest, policy embedPolicy) (promptSection, bool, error) {
+	cwd, err := WorkspaceDir()
+	if err != nil {
+		return promptSection{}, false, err
+	}
+	files, err := changedFiles(cwd, request.ChangedSince, request.ChangedUntracked)
+	if _, invalid := err.(*PrompterError); invalid {
+		return promptSection{}, false, err
+	}
+	if err != nil {
+		return promptSection{}, false, NewContentCollectionError(cwd, err)
+	}
+	if len(files) == 0 {
+		switch {
+		case request.ChangedSince != "" && request.ChangedSince != "HEAD":
+			o.warnings.Add("--changed: nothing has changed since %s", request.ChangedSince)
+		case request.ChangedUntracked:
+			o.warnings.Add("--changed: the working tree has no changes")
+		default:
+			o.warnings.Add("--files-from-diff: the working tree has no changes to tracked files")
+		}
+		return promptSection{}, false, nil
+	}
+
+	content, events := formatChangedFiles(files, policy)
+	for _, event := range events {
+		o.fileIncluded(event)
+	}
+	reportSkipped(request, events)
+	// It holds what the change touched, so the budget ranks it with the diff, above other files
+	return promptSection{Class: SectionDiff, Source: "files-from-diff", Content: content}, content != "", nil
+}
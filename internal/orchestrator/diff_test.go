@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestChangedFiles(t *testing.T) {
+	repo, git, writeFile := newTestRepo(t)
+	writeFile("main.go", "package main\n")
+	writeFile("sub/staged.go", "package sub\n")
+	git("add", ".")
+
+	// Without commits, everything in the index counts as changed
+	files, err := changedFiles(repo, "", false)
+	if err != nil {
+		t.Fatalf("changedFiles() before the first commit error = %v", err)
+	}
+	if strings.Join(files, ",") != "main.go,"+filepath.Join("sub", "staged.go") {
+		t.Errorf("changedFiles() before the first commit = %v", files)
+	}
+
+	writeFile("gone.go", "package main\n")
+	writeFile("same.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	writeFile("sub/staged.go", "package sub\n\nvar X = 1\n")
+	git("add", "sub/staged.go")
+	writeFile("untracked.go", "package main\n")
+	if err := os.Remove(filepath.Join(repo, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// From a subdirectory, files inside it are relative and the rest absolute
+	files, err = changedFiles(filepath.Join(repo, "sub"), "", false)
+	if err != nil {
+		t.Fatalf("changedFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(repo, "main.go"), "staged.go"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("changedFiles() = %v, want %v", files, want)
+	}
+
+	if _, err := changedFiles(t.TempDir(), "", false); err == nil {
+		t.Error("changedFiles() outside a repository expected error")
+	}
+}
+
+func TestChangedFilesSince(t *testing.T) {
+	repo, git, writeFile := newTestRepo(t)
+	writeFile("main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("branch", "-q", "base")
+
+	git("checkout", "-q", "-b", "feature")
+	writeFile("feature.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "feature")
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	writeFile("new.go", "package main\n")
+
+	// The base branch moving on doesn't count against the feature branch
+	git("checkout", "-q", "base")
+	writeFile("other.go", "package main\n")
+	git("add", "other.go")
+	git("commit", "-q", "-m", "other")
+	git("checkout", "-q", "feature")
+
+	tests := []struct {
+		name      string
+		since     string
+		untracked bool
+		want      []string
+	}{
+		{"working tree", "", false, []string{"main.go"}},
+		{"with untracked", "HEAD", true, []string{"main.go", "new.go"}},
+		{"since a branch", "base", true, []string{"feature.go", "main.go", "new.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := changedFiles(repo, tt.since, tt.untracked)
+			if err != nil {
+				t.Fatalf("changedFiles() error = %v", err)
+			}
+			if strings.Join(files, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("changedFiles() = %v, want %v", files, tt.want)
+			}
+		})
+	}
+
+	if _, err := changedFiles(repo, "no-such-branch", true); err == nil || !strings.Contains(err.Error(), "no-such-branch") {
+		t.Errorf("changedFiles() since an unknown ref error = %v", err)
+	}
+}
+
+func TestFormatChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	binary := filepath.Join(dir, "logo.png")
+	large := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(source, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, []byte{0x89, 'P', 'N', 'G', 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte(strings.Repeat("x", maxEmbeddedFileBytes+1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, events := formatChangedFiles([]string{source, binary, large, filepath.Join(dir, "missing.go")}, embedPolicy{})
+	want := "Changed files:\n\n" + source + ":\n```go\npackage main\n```\n\n" + binary + " (binary)\n\n" + large + " (too large to embed)"
+	if got != want {
+		t.Errorf("formatChangedFiles() = %q, want %q", got, want)
+	}
+	if len(events) != 3 || !events[0].Embedded || events[0].Bytes != len("package main\n") || events[1].Embedded || events[2].Embedded {
+		t.Errorf("formatChangedFiles() events = %+v", events)
+	}
+
+	got, events = formatChangedFiles([]string{large}, embedPolicy{Sample: true})
+	if !strings.HasPrefix(got, "Changed files:\n\n"+large+" (sampled from 65.5 KB):\n```txt\nxxx") || !strings.Contains(got, " omitted ...]") {
+		t.Errorf("formatChangedFiles(sample) = %q, want an excerpt of big.txt", got)
+	}
+	if len(events) != 1 || !events[0].Embedded || events[0].Bytes > maxEmbeddedFileBytes {
+		t.Errorf("formatChangedFiles(sample) events = %+v", events)
+	}
+
+	lock := filepath.Join(dir, "go.sum")
+	if err := os.WriteFile(lock, []byte("github.com/x/y v1.0.0 h1:abc=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, events = formatChangedFiles([]string{lock}, embedPolicy{})
+	if got != "Changed files:\n\n"+lock+" (lock file)" || len(events) != 1 || events[0].Embedded || events[0].Skipped != skipLockFile {
+		t.Errorf("formatChangedFiles(lock file) = %q, %+v; want it listed as a lock file", got, events)
+	}
+	if got, events = formatChangedFiles([]string{lock}, embedPolicy{IncludeGenerated: true}); len(events) != 1 || !events[0].Embedded {
+		t.Errorf("formatChangedFiles(lock file, IncludeGenerated) = %q, want it embedded", got)
+	}
+
+	if got, events := formatChangedFiles(nil, embedPolicy{}); got != "" || events != nil {
+		t.Errorf("formatChangedFiles(nil, embedPolicy{}) = %q, %v; want empty", got, events)
+	}
+}
+
+func TestChangedFilesSection(t *testing.T) {
+	repo, git, writeFile := newTestRepo(t)
+	writeFile("main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	t.Chdir(repo)
+
+	section, ok, err := New().changedFilesSection(&models.PromptRequest{FilesFromDiff: true}, embedPolicy{})
+	if err != nil || !ok {
+		t.Fatalf("changedFilesSection() = %v, %v", ok, err)
+	}
+	if section.Class != SectionDiff || !strings.Contains(section.Content, "func main() {}") {
+		t.Errorf("changedFilesSection() = %+v, want the changed file in a diff section", section)
+	}
+}
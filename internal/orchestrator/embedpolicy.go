@@ -0,0 +1,131 @@
+package orchestrator
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Why a file is listed by path instead of embedded, for FileEvent.Skipped
+const (
+	skipBinary    = "binary"
+	skipLockFile  = "lock file"
+	skipMinified  = "minified"
+	skipGenerated = "generated"
+)
+
+// lockFiles are the dependency lock files package managers write; they are long, and say
+// nothing a model needs that the manifest beside them doesn't
+var lockFiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lock":            true,
+	"go.sum":              true,
+	"Cargo.lock":          true,
+	"Gemfile.lock":        true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"uv.lock":             true,
+	"composer.lock":       true,
+	"mix.lock":            true,
+	"Podfile.lock":        true,
+	"flake.lock":          true,
+	"packages.lock.json":  true,
+}
+
+// minifiableExtensions are the file types minifiers output
+var minifiableExtensions = map[string]bool{".js": true, ".mjs": true, ".cjs": true, ".css": true, ".map": true}
+
+// generatedMarker matches the comments code generators put at the top of their output: Go's
+// "Code generated ... DO NOT EDIT.", @generated, and .NET's <auto-generated>
+var generatedMarker = regexp.MustCompile(`(?i)code generated .*do not edit|@generated\b|<auto-generated|this file (is|was) (automatically |auto-?)generated`)
+
+// Minified files have few, very long lines; generators mark their output near the top
+const (
+	minifiedMinBytes       = 2048
+	minifiedMinLineLength  = 500
+	generatedMarkerHeadLen = 1024
+)
+
+// embedPolicy decides how the files a prompt embeds are read, and which are listed by path
+// instead of embedded
+type embedPolicy struct {
+	Sample           bool // Embed an excerpt of files over maxEmbeddedFileBytes (large_files = "sample")
+	IncludeBinary    bool // Embed binary files as well (--include-binary)
+	IncludeGenerated bool // Embed lock files and minified and generated files as well (--include-generated)
+}
+
+// newEmbedPolicy returns the embed policy request and cfg ask for
+func newEmbedPolicy(request *models.PromptRequest, cfg *interfaces.Config) embedPolicy {
+	return embedPolicy{
+		Sample:           cfg.LargeFiles == LargeFilesSample,
+		IncludeBinary:    request.IncludeBinary,
+		IncludeGenerated: request.IncludeGenerated,
+	}
+}
+
+// skipReason returns why the file at path, holding content, should be listed by path rather
+// than embedded, or "" to embed it
+func (p embedPolicy) skipReason(path, content string) string {
+	if !p.IncludeBinary && isBinaryContent(content) {
+		return skipBinary
+	}
+	if p.IncludeGenerated {
+		return ""
+	}
+	switch {
+	case lockFiles[filepath.Base(path)]:
+		return skipLockFile
+	case isMinified(path, content):
+		return skipMinified
+	case generatedMarker.MatchString(content[:min(len(content), generatedMarkerHeadLen)]):
+		return skipGenerated
+	}
+	return ""
+}
+
+// isBinaryContent reports whether content holds a null byte or sniffs as a non-text type,
+// such as an image or an archive
+func isBinaryContent(content string) bool {
+	if strings.IndexByte(content, 0) >= 0 {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType([]byte(content[:min(len(content), 512)])))
+	return !isTextMediaType(mediaType)
+}
+
+// isMinified reports whether path is a minified script or stylesheet: named .min.js and the
+// like, or averaging minifiedMinLineLength bytes a line
+func isMinified(path, content string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	ext := filepath.Ext(name)
+	if !minifiableExtensions[ext] {
+		return false
+	}
+	if strings.HasSuffix(strings.TrimSuffix(name, ext), ".min") {
+		return true
+	}
+	lines := strings.Count(content, "\n") + 1
+	return len(content) >= minifiedMinBytes && len(content)/lines >= minifiedMinLineLength
+}
+
+// reportSkipped tells --debug runs which files were listed rather than embedded, and why
+func reportSkipped(request *models.PromptRequest, events []interfaces.FileEvent) {
+	for _, event := range events {
+		if event.Skipped == "" {
+			continue
+		}
+		flag := "--include-generated"
+		if event.Skipped == skipBinary {
+			flag = "--include-binary"
+		}
+		debugLog(request, "listed %s without its content (%s); %s embeds it", event.Path, event.Skipped, flag)
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestEmbedPolicySkipReason(t *testing.T) {
+	minified := strings.Repeat("function a(b){return b+1};", 200)
+	tests := []struct {
+		name    string
+		policy  embedPolicy
+		path    string
+		content string
+		want    string
+	}{
+		{"source", embedPolicy{}, "main.go", "package main\n\nfunc main() {}\n", ""},
+		{"empty", embedPolicy{}, "empty.txt", "", ""},
+		{"null byte", embedPolicy{}, "data.bin", "abc\x00def", skipBinary},
+		{"sniffed image", embedPolicy{}, "logo.gif", "GIF89a\x01\x02", skipBinary},
+		{"binary included", embedPolicy{IncludeBinary: true}, "data.bin", "abc\x00def", ""},
+		{"lock file", embedPolicy{}, "frontend/package-lock.json", "{\n  \"lockfileVersion\": 3\n}\n", skipLockFile},
+		{"go.sum", embedPolicy{}, "go.sum", "github.com/x/y v1.0.0 h1:abc=\n", skipLockFile},
+		{"min.js by name", embedPolicy{}, "dist/app.min.js", "var a=1;\n", skipMinified},
+		{"minified by line length", embedPolicy{}, "dist/app.js", minified, skipMinified},
+		{"long lines outside scripts", embedPolicy{}, "data.txt", minified, ""},
+		{"readable script", embedPolicy{}, "app.js", strings.Repeat("const a = 1;\n", 300), ""},
+		{"go generated", embedPolicy{}, "api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n", skipGenerated},
+		{"@generated", embedPolicy{}, "schema.ts", "/**\n * @generated\n */\nexport type A = string;\n", skipGenerated},
+		{"marker past the head", embedPolicy{}, "notes.md", strings.Repeat("text\n", 300) + "@generated\n", ""},
+		{"generated included", embedPolicy{IncludeGenerated: true}, "go.sum", "github.com/x/y v1.0.0 h1:abc=\n", ""},
+		{"generated included, binary not", embedPolicy{IncludeGenerated: true}, "data.bin", "\x00", skipBinary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.skipReason(tt.path, tt.content); got != tt.want {
+				t.Errorf("skipReason(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEmbedPolicy(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.IncludeGenerated = true
+	cfg := &interfaces.Config{LargeFiles: LargeFilesSample}
+
+	if got, want := newEmbedPolicy(request, cfg), (embedPolicy{Sample: true, IncludeGenerated: true}); got != want {
+		t.Errorf("newEmbedPolicy() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"errors"
+	"strings"
+)
+
+// ANSI styles RenderError uses for each part of an error
+const (
+	styleLabel    = "\x1b[1;31m" // "Error:"
+	styleCategory = "\x1b[31m"   // The error type, e.g. "template error"
+	styleGuidance = "\x1b[36m"   // What to do about it
+	styleReset    = "\x1b[0m"
+)
+
+// RenderError formats err the way prompter prints it before exiting: "Error: " and the message,
+// then a PrompterError's guidance after a blank line. A width above zero wraps the lines to that
+// many columns, and color highlights the label, the error type, and the guidance.
+func RenderError(err error, color bool, width int) string {
+	headline, guidance, category := "Error: "+err.Error(), "", ""
+	var prompterErr *PrompterError
+	if errors.As(err, &prompterErr) {
+		category = prompterErr.Type.Error()
+		// Wrapping adds prefixes, so the guidance still ends the text
+		if suffix := "\n\n" + prompterErr.Guidance; prompterErr.Guidance != "" && strings.HasSuffix(headline, suffix) {
+			headline, guidance = strings.TrimSuffix(headline, suffix), prompterErr.Guidance
+		}
+	}
+
+	headline, guidance = wrapLines(headline, width), wrapLines(guidance, width)
+	if color {
+		rest := strings.TrimPrefix(headline, "Error:")
+		if i := strings.Index(rest, category+":"); category != "" && i >= 0 {
+			rest = rest[:i] + styleCategory + category + styleReset + rest[i+len(category):]
+		}
+		headline = styleLabel + "Error:" + styleReset + rest
+		if guidance != "" {
+			guidance = styleGuidance + guidance + styleReset
+		}
+	}
+
+	if guidance == "" {
+		return headline + "\n"
+	}
+	return headline + "\n\n" + guidance + "\n"
+}
+
+// wrapLines wraps each line of text longer than width on its own, keeping its indentation, so
+// listings such as one error per line stay as they are. A width of zero or less disables it.
+func wrapLines(text string, width int) string {
+	if width <= 0 || text == "" {
+		return text
+	}
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if displayWidth(line) <= width {
+			out = append(out, line)
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		out = append(out, fillLines(strings.Fields(line), width, indent, indent)...)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRenderError(t *testing.T) {
+	validation := NewValidationError("target", "nowhere", "unknown")
+	validation.Guidance = "Invalid target. Run 'prompter --help' for valid output targets."
+
+	tests := []struct {
+		name  string
+		err   error
+		color bool
+		width int
+		want  string
+	}{
+		{
+			name: "plain error",
+			err:  errors.New("accepts at most 1 arg(s), received 2"),
+			want: "Error: accepts at most 1 arg(s), received 2\n",
+		},
+		{
+			name: "guidance after a blank line",
+			err:  fmt.Errorf("prompt generation failed: %w", validation),
+			want: "Error: prompt generation failed: validation error: validation failed for target: nowhere (unknown)\n\n" +
+				"Invalid target. Run 'prompter --help' for valid output targets.\n",
+		},
+		{
+			name:  "wrapped to the terminal",
+			err:   validation,
+			width: 40,
+			want: "Error: validation error: validation\nfailed for target: nowhere (unknown)\n\n" +
+				"Invalid target. Run 'prompter --help'\nfor valid output targets.\n",
+		},
+		{
+			name:  "colored",
+			err:   fmt.Errorf("prompt generation failed: %w", validation),
+			color: true,
+			want: "\x1b[1;31mError:\x1b[0m prompt generation failed: \x1b[31mvalidation error\x1b[0m: validation failed for target: nowhere (unknown)\n\n" +
+				"\x1b[36mInvalid target. Run 'prompter --help' for valid output targets.\x1b[0m\n",
+		},
+		{
+			name:  "wide characters take two columns",
+			err:   errors.New("テンプレートが 見つかりません"),
+			width: 20,
+			want:  "Error:\nテンプレートが\n見つかりません\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderError(tt.err, tt.color, tt.width); got != tt.want {
+				t.Errorf("RenderError() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+
+	"github.com/imdevan/prompter/internal/config"
 )
 
 // Error types for different categories of failures
@@ -16,6 +19,7 @@ var (
 	ErrFixModeInvalid       = errors.New("fix mode error")
 	ErrOutputFailed         = errors.New("output error")
 	ErrValidationFailed     = errors.New("validation error")
+	ErrInteractionRequired  = errors.New("interaction required")
 )
 
 // PrompterError represents a structured error with actionable guidance
@@ -24,6 +28,7 @@ type PrompterError struct {
 	Message  string
 	Guidance string
 	Cause    error
+	Partial  string // Prompt assembled before the failure, if any (e.g. the user cancelled a review)
 }
 
 func (e *PrompterError) Error() string {
@@ -37,6 +42,57 @@ func (e *PrompterError) Unwrap() error {
 	return e.Cause
 }
 
+// Exit statuses for each error category, so wrapper scripts can branch on the kind of failure
+const (
+	ExitFailure       = 1 // Errors outside the categories below
+	ExitConfiguration = 2
+	ExitTemplate      = 3
+	ExitContent       = 4
+	ExitOutput        = 5
+	ExitValidation    = 6
+	ExitInteraction   = 7 // A --ci run reached something it would have asked about
+	ExitFixMode       = 8 // Fix mode had nothing to fix, or couldn't read the output to fix
+)
+
+// ExitCode returns the status prompter exits with for the error's category
+func (e *PrompterError) ExitCode() int {
+	return categoryExitCode(e.Type)
+}
+
+// ExitCode returns the status prompter exits with after err: 0 for nil, the category's status
+// for a PrompterError or an error wrapping one of the Err* categories, and ExitFailure otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var prompterErr *PrompterError
+	if errors.As(err, &prompterErr) {
+		return prompterErr.ExitCode()
+	}
+	return categoryExitCode(err)
+}
+
+// categoryExitCode returns the exit status for the error category err is or wraps
+func categoryExitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrConfigurationInvalid):
+		return ExitConfiguration
+	case errors.Is(err, ErrTemplateNotFound), errors.Is(err, ErrTemplateInvalid):
+		return ExitTemplate
+	case errors.Is(err, ErrContentCollection):
+		return ExitContent
+	case errors.Is(err, ErrOutputFailed):
+		return ExitOutput
+	case errors.Is(err, ErrValidationFailed):
+		return ExitValidation
+	case errors.Is(err, ErrInteractionRequired):
+		return ExitInteraction
+	case errors.Is(err, ErrFixModeInvalid):
+		return ExitFixMode
+	}
+	return ExitFailure
+}
+
 // Error constructors with actionable guidance
 
 func NewConfigurationError(message string, cause error) *PrompterError {
@@ -57,12 +113,19 @@ func NewConfigurationError(message string, cause error) *PrompterError {
 	}
 }
 
+// unknownFieldError matches one of template.CheckFields' errors, with its location
+var unknownFieldError = regexp.MustCompile(`[^\s:]+:\d+:\d+: unknown field [^\n]*`)
+
 func NewTemplateError(templateName string, cause error) *PrompterError {
 	message := fmt.Sprintf("failed to process template '%s'", templateName)
 	guidance := "Run 'prompter --help' for template usage and configuration."
 	
 	if strings.Contains(cause.Error(), "not found") {
 		guidance = fmt.Sprintf("Template '%s' not found. Run 'prompter --help' for template setup.", templateName)
+	} else if fields := unknownFieldError.FindAllString(cause.Error(), -1); len(fields) > 0 {
+		guidance = fmt.Sprintf("Template '%s' uses fields templates don't have:\n  %s", templateName, strings.Join(fields, "\n  "))
+	} else if strings.Contains(cause.Error(), "max_tokens of") {
+		guidance = fmt.Sprintf("Template '%s' is larger than its max_tokens. Raise the cap, or set template_token_limit = \"warn\".", templateName)
 	} else if strings.Contains(cause.Error(), "parse") || strings.Contains(cause.Error(), "syntax") {
 		guidance = fmt.Sprintf("Template '%s' has syntax errors. Run 'prompter --help' for template format.", templateName)
 	}
@@ -97,7 +160,15 @@ func NewFixModeError(fixFile string, cause error) *PrompterError {
 	message := fmt.Sprintf("fix mode failed with file '%s'", fixFile)
 	guidance := "Run 'prompter --help' for fix mode usage and examples."
 	
-	if strings.Contains(cause.Error(), "not found") || strings.Contains(cause.Error(), "does not exist") {
+	if strings.Contains(cause.Error(), "every task passed") {
+		guidance = fmt.Sprintf("There is nothing to fix: %v.", cause)
+	} else if strings.Contains(cause.Error(), "task") {
+		guidance = fmt.Sprintf("%v. Define tasks in the config's [tasks] table, or pass --task name=command.", cause)
+	} else if strings.Contains(cause.Error(), "clipboard is empty") {
+		guidance = "The clipboard is empty. Copy the output to fix, then run prompter again."
+	} else if strings.Contains(cause.Error(), "clipboard") {
+		guidance = strings.TrimSuffix(cause.Error(), ".") + "."
+	} else if strings.Contains(cause.Error(), "not found") || strings.Contains(cause.Error(), "does not exist") {
 		guidance = "Fix file not found. Run 'prompter --help' for fix mode setup."
 	} else if strings.Contains(cause.Error(), "empty") {
 		guidance = "Fix file is empty. Run 'prompter --help' for fix mode usage."
@@ -117,6 +188,10 @@ func NewOutputError(target string, cause error) *PrompterError {
 	
 	if target == "clipboard" {
 		guidance = "Clipboard access failed. Try --target stdout or run 'prompter --help' for options."
+	} else if strings.HasPrefix(target, "cmd:") {
+		guidance = fmt.Sprintf("Command failed: %v. Check that the command runs on its own, or try --target stdout.", cause)
+	} else if target == "tmux" || target == "osc52" {
+		guidance = fmt.Sprintf("Copy failed: %v. Try --target stdout or run 'prompter --help' for options.", cause)
 	} else if strings.HasPrefix(target, "file:") {
 		guidance = "File write failed. Run 'prompter --help' for output options."
 	} else if strings.Contains(cause.Error(), "editor") {
@@ -144,6 +219,10 @@ func NewValidationError(field string, value interface{}, reason string) *Prompte
 		guidance = "Invalid config path. Run 'prompter --help' for configuration options."
 	case "template_name":
 		guidance = "Invalid template name. Run 'prompter --help' for template usage."
+	case "ci":
+		guidance = "--ci never prompts. Drop -i, or drop --ci to answer prompts."
+	case "schema":
+		guidance = "--schema takes a JSON Schema file, e.g. {\"type\": \"object\", \"properties\": {\"summary\": {\"type\": \"string\"}}}."
 	}
 	
 	return &PrompterError{
@@ -154,6 +233,15 @@ func NewValidationError(field string, value interface{}, reason string) *Prompte
 	}
 }
 
+// NewInteractionRequiredError reports a --ci run that reached something it would ask the user
+func NewInteractionRequiredError(what string, guidance string) *PrompterError {
+	return &PrompterError{
+		Type:     ErrInteractionRequired,
+		Message:  fmt.Sprintf("--ci runs can't ask for %s", what),
+		Guidance: guidance,
+	}
+}
+
 // Recovery strategies
 
 // RecoverFromError attempts to recover from common errors with fallback strategies
@@ -188,12 +276,11 @@ func RecoverFromError(err error) error {
 
 func recoverFromConfigError(err *PrompterError) error {
 	// Try to create default config directory if it doesn't exist
-	homeDir, homeErr := os.UserHomeDir()
-	if homeErr != nil {
+	configDir, dirErr := config.ConfigDir()
+	if dirErr != nil {
 		return err // Can't recover
 	}
 	
-	configDir := fmt.Sprintf("%s/.config/prompter", homeDir)
 	if _, statErr := os.Stat(configDir); os.IsNotExist(statErr) {
 		if mkdirErr := os.MkdirAll(configDir, 0755); mkdirErr != nil {
 			// Add recovery attempt info to guidance
@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: 0},
+		{name: "plain error", err: errors.New("boom"), want: ExitFailure},
+		{name: "configuration", err: NewConfigurationError("config invalid", nil), want: ExitConfiguration},
+		{name: "wrapped configuration category", err: fmt.Errorf("%w: %w", ErrConfigurationInvalid, errors.New("bad key")), want: ExitConfiguration},
+		{name: "template not found", err: &PrompterError{Type: ErrTemplateNotFound}, want: ExitTemplate},
+		{name: "template", err: NewTemplateError("review", errors.New("parse")), want: ExitTemplate},
+		{name: "content", err: NewContentCollectionError("main.go", os.ErrNotExist), want: ExitContent},
+		{name: "output", err: NewOutputError("clipboard", errors.New("no display")), want: ExitOutput},
+		{name: "wrapped validation", err: fmt.Errorf("prompt generation failed: %w", NewValidationError("target", "nowhere", "unknown")), want: ExitValidation},
+		{name: "fix mode", err: NewFixModeError("fix.md", errors.New("empty")), want: ExitFixMode},
+		{name: "wrapped fix mode category", err: fmt.Errorf("%w: nothing captured", ErrFixModeInvalid), want: ExitFixMode},
+		{name: "interaction", err: NewInteractionRequiredError("a base prompt", ""), want: ExitInteraction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
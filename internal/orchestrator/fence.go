@@ -0,0 +1,23 @@
+package orchestrator
+
+import "strings"
+
+// Fence returns a backtick fence longer than any run of backticks in content,
+// so content that itself contains fenced blocks can be embedded safely
+func Fence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
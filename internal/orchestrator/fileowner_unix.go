@@ -0,0 +1,28 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateFile reports an error unless path is a file owned by the current user that
+// no one else can read or write, such as one created with mode 0600
+func checkPrivateFile(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by another user", path)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s can be read or written by other users (mode %04o; chmod 600 it)", path, info.Mode().Perm())
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkPrivateFile reports an error unless path is a regular file. Windows keeps per-user
+// files private through the ACLs of the profile directory, so modes aren't checked.
+func checkPrivateFile(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	return nil
+}
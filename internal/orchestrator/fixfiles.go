@@ -0,0 +1,154 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/diagnostics"
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// Limits on how much referenced source is embedded in a fix prompt
+const (
+	maxEmbeddedFiles      = 5
+	maxEmbeddedFileBytes  = 64 * 1024
+	maxEmbeddedTotalBytes = 192 * 1024
+)
+
+// referencedFiles combines parsed diagnostics with bare file:line mentions in the output.
+// Paths are cleaned so "./main.go" and "main.go" are treated as one file.
+func referencedFiles(content string, diags []interfaces.Diagnostic) []interfaces.Diagnostic {
+	all := append(append([]interfaces.Diagnostic{}, diags...), diagnostics.References(content)...)
+	for i := range all {
+		all[i].File = filepath.Clean(all[i].File)
+	}
+	return all
+}
+
+// formatDiagnosticFiles lists (or embeds) the workspace files referenced by diagnostics.
+// Relative paths resolve against cwd; files outside root or missing are skipped, and
+// files past the size limits or skipped by policy are listed by path only. When policy samples
+// them, a file over maxEmbeddedFileBytes is embedded as an excerpt instead. It also returns an
+// event for each file included.
+func formatDiagnosticFiles(diags []interfaces.Diagnostic, cwd, root string, embed bool, policy embedPolicy) (string, []interfaces.FileEvent) {
+	var parts []string
+	var included []interfaces.FileEvent
+	embedded, embeddedBytes := 0, int64(0)
+
+	for _, file := range diagnostics.Files(diags) {
+		path, ok := resolveWorkspaceFile(file, cwd, root)
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			// Paths from other machines or stripped build roots can't be referenced
+			continue
+		}
+
+		reference := formatFileReference(file, diags)
+		large := info.Size() > maxEmbeddedFileBytes
+		size := min(info.Size(), maxEmbeddedFileBytes)
+		if !embed || embedded >= maxEmbeddedFiles || (large && !policy.Sample) || embeddedBytes+size > maxEmbeddedTotalBytes {
+			parts = append(parts, reference)
+			included = append(included, interfaces.FileEvent{Path: path})
+			continue
+		}
+
+		content, err := readEmbeddedFile(path, large)
+		if err != nil {
+			parts = append(parts, reference)
+			included = append(included, interfaces.FileEvent{Path: path})
+			continue
+		}
+		if reason := policy.skipReason(path, content); reason != "" {
+			parts = append(parts, reference+" ("+reason+")")
+			included = append(included, interfaces.FileEvent{Path: path, Skipped: reason})
+			continue
+		}
+		embedded++
+		embeddedBytes += size
+
+		if large {
+			reference += " (sampled from " + formatSize(info.Size()) + ")"
+		}
+		language := strings.TrimPrefix(filepath.Ext(file), ".")
+		parts = append(parts, fmt.Sprintf("%s:\n```%s\n%s\n```", reference, language, strings.TrimRight(content, "\n")))
+		included = append(included, interfaces.FileEvent{Path: path, Embedded: true, Bytes: len(content)})
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	header := "Referencing files:"
+	if embed {
+		return header + "\n\n" + strings.Join(parts, "\n\n"), included
+	}
+	return header + "\n" + strings.Join(parts, "\n"), included
+}
+
+// resolveWorkspaceFile resolves file against cwd and reports whether it lies inside root
+func resolveWorkspaceFile(file, cwd, root string) (string, bool) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return path, true
+}
+
+// projectFile resolves file against the working directory and returns it only when it lies
+// inside the project root, following symlinks, so a checked-in file can't read beyond it
+func projectFile(file string) (string, error) {
+	cwd, err := WorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+	root := detectProject(cwd).Root
+	path, ok := resolveProjectFile(file, cwd, root)
+	if !ok {
+		return "", fmt.Errorf("%s is outside the project (%s)", file, root)
+	}
+	return path, nil
+}
+
+// resolveProjectFile is resolveWorkspaceFile for files a repository names itself: symlinks are
+// followed before the check, so one can't lead outside root
+func resolveProjectFile(file, cwd, root string) (string, bool) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return resolveWorkspaceFile(path, cwd, root)
+}
+
+// formatFileReference renders a path with the lines diagnostics point at, e.g. "main.go (lines 12, 40)"
+func formatFileReference(file string, diags []interfaces.Diagnostic) string {
+	var lines []string
+	seen := make(map[int]bool)
+	for _, d := range diags {
+		if d.File == file && d.Line > 0 && !seen[d.Line] {
+			seen[d.Line] = true
+			lines = append(lines, fmt.Sprint(d.Line))
+		}
+	}
+
+	switch len(lines) {
+	case 0:
+		return file
+	case 1:
+		return fmt.Sprintf("%s (line %s)", file, lines[0])
+	default:
+		return fmt.Sprintf("%s (lines %s)", file, strings.Join(lines, ", "))
+	}
+}
@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/diagnostics"
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestFormatDiagnosticFiles(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "other.go")
+	if err := os.WriteFile(outside, []byte("package other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diags := []interfaces.Diagnostic{
+		{File: "main.go", Line: 3, Message: "missing return"},
+		{File: "main.go", Line: 1, Message: "bad package"},
+		{File: "main.go", Line: 3, Tool: diagnostics.ToolReference},
+		{File: "missing.go", Line: 1, Message: "not on disk"},
+		{File: outside, Line: 1, Message: "outside the workspace"},
+	}
+
+	listed, included := formatDiagnosticFiles(diags, root, root, false, embedPolicy{})
+	expected := "Referencing files:\nmain.go (lines 3, 1)"
+	if listed != expected {
+		t.Errorf("formatDiagnosticFiles(embed=false) = %q, expected %q", listed, expected)
+	}
+	if len(included) != 1 || included[0].Embedded || filepath.Base(included[0].Path) != "main.go" {
+		t.Errorf("formatDiagnosticFiles(embed=false) included %+v, expected a main.go reference", included)
+	}
+
+	embedded, included := formatDiagnosticFiles(diags, root, root, true, embedPolicy{})
+	if !strings.Contains(embedded, "```go\npackage main\n\nfunc main() {}\n```") {
+		t.Errorf("expected embedded file content, got %q", embedded)
+	}
+	if strings.Contains(embedded, "missing.go") || strings.Contains(embedded, "other.go") {
+		t.Errorf("missing and outside files should be skipped, got %q", embedded)
+	}
+	if len(included) != 1 || !included[0].Embedded || included[0].Bytes == 0 {
+		t.Errorf("formatDiagnosticFiles(embed=true) included %+v, expected embedded main.go", included)
+	}
+
+	if result, _ := formatDiagnosticFiles(nil, root, root, true, embedPolicy{}); result != "" {
+		t.Errorf("expected empty result without diagnostics, got %q", result)
+	}
+}
+
+func TestReferencedFiles(t *testing.T) {
+	content := "./main.go:3:1: missing return\npanic at main.go:3 and util/io.go:9"
+	result := referencedFiles(content, diagnostics.Parse(content))
+
+	files := diagnostics.Files(result)
+	expected := []string{"main.go", "util/io.go"}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("files = %v, expected %v", files, expected)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// emptyTreeHash is git's empty tree, the base for diffs in a repository without commits
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// gitOutput runs git in dir and returns its output
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// readGitInfo collects the repository metadata templates see as .Git. Outside a repository,
+// or without git installed, it's empty. AuthorName and AuthorEmail are the configured user,
+// who authors the next commit.
+func readGitInfo(dir string) interfaces.GitInfo {
+	if _, err := exec.LookPath("git"); err != nil {
+		return interfaces.GitInfo{}
+	}
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return interfaces.GitInfo{}
+	}
+
+	output := func(args ...string) string {
+		out, _ := gitOutput(dir, args...)
+		return strings.TrimSpace(out)
+	}
+	info := interfaces.GitInfo{
+		Root:        strings.TrimSpace(root),
+		Branch:      output("branch", "--show-current"),
+		Dirty:       output("status", "--porcelain") != "",
+		AuthorName:  output("config", "user.name"),
+		AuthorEmail: output("config", "user.email"),
+	}
+
+	// The hash and message are separated by a NUL so the message can hold anything
+	if commit, message, ok := strings.Cut(output("log", "-1", "--format=%H%x00%B"), "\x00"); ok {
+		info.Commit = commit
+		info.LastCommitMessage = strings.TrimSpace(message)
+	}
+	return info
+}
@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// newTestRepo initializes an empty git repository, returning its path and helpers that run git
+// in it and write files into it. The test is skipped without git.
+func newTestRepo(t *testing.T) (string, func(args ...string), func(name, content string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	git("config", "user.name", "Ada Lovelace")
+	git("config", "user.email", "ada@example.com")
+	return repo, git, writeFile
+}
+
+func TestReadGitInfo(t *testing.T) {
+	repo, git, writeFile := newTestRepo(t)
+
+	info := readGitInfo(repo)
+	if info.Root != repo || info.Branch != "main" || info.Commit != "" || info.LastCommitMessage != "" || info.Dirty {
+		t.Errorf("readGitInfo() before the first commit = %+v", info)
+	}
+
+	writeFile("main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add main\n\nWith a body.")
+
+	info = readGitInfo(repo)
+	if len(info.Commit) != 40 {
+		t.Errorf("Commit = %q, want a full hash", info.Commit)
+	}
+	if info.LastCommitMessage != "Add main\n\nWith a body." {
+		t.Errorf("LastCommitMessage = %q", info.LastCommitMessage)
+	}
+	if info.AuthorName != "Ada Lovelace" || info.AuthorEmail != "ada@example.com" {
+		t.Errorf("author = %q <%s>", info.AuthorName, info.AuthorEmail)
+	}
+	if info.Dirty {
+		t.Error("Dirty = true for a clean tree")
+	}
+
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	if !readGitInfo(repo).Dirty {
+		t.Error("Dirty = false with a modified file")
+	}
+
+	git("checkout", "-q", "--detach")
+	if branch := readGitInfo(repo).Branch; branch != "" {
+		t.Errorf("Branch = %q with a detached HEAD, want empty", branch)
+	}
+
+	if info := readGitInfo(t.TempDir()); info != (interfaces.GitInfo{}) {
+		t.Errorf("readGitInfo() outside a repository = %+v, want empty", info)
+	}
+}
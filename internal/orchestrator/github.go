@@ -0,0 +1,237 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits for issue and pull request context
+const (
+	githubFetchTimeout   = 15 * time.Second
+	githubRecentComments = 10
+	maxGitHubBodyBytes   = 16 * 1024
+)
+
+var (
+	// githubShortRef matches owner/repo#123
+	githubShortRef = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+	// githubIssueURL matches issue and pull request pages, ignoring anything after the number
+	githubIssueURL = regexp.MustCompile(`^https?://github\.com/([\w.-]+)/([\w.-]+)/(?:issues|pull)/(\d+)(?:[/?#].*)?$`)
+)
+
+// githubRef identifies an issue or pull request
+type githubRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// String formats the reference as owner/repo#123
+func (r githubRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// parseGitHubRef parses owner/repo#123 or a github.com issue or pull request URL
+func parseGitHubRef(ref string) (githubRef, error) {
+	match := githubShortRef.FindStringSubmatch(ref)
+	if match == nil {
+		match = githubIssueURL.FindStringSubmatch(ref)
+	}
+	if match == nil {
+		return githubRef{}, fmt.Errorf("invalid GitHub reference %q", ref)
+	}
+
+	number, _ := strconv.Atoi(match[3])
+	return githubRef{Owner: match[1], Repo: match[2], Number: number}, nil
+}
+
+// isGitHubIssueURL reports whether a --url value is better fetched through the GitHub API
+func isGitHubIssueURL(pageURL string) bool {
+	return githubIssueURL.MatchString(pageURL)
+}
+
+// githubToken returns the API token from the environment, falling back to the config
+func githubToken(configToken string) string {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return configToken
+}
+
+// githubIssue is the subset of the issue payload that is used; pull requests are issues too
+type githubIssue struct {
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	HTMLURL     string          `json:"html_url"`
+	Comments    int             `json:"comments"`
+	User        githubUser      `json:"user"`
+	Labels      []githubLabel   `json:"labels"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+// githubComment is the subset of the comment payload that is used
+type githubComment struct {
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      githubUser `json:"user"`
+}
+
+// githubUser is the author of an issue or comment
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubLabel is an issue label
+type githubLabel struct {
+	Name string `json:"name"`
+}
+
+// githubClient fetches issue context from the GitHub REST API
+type githubClient struct {
+	apiURL string
+	token  string
+}
+
+// get decodes the JSON response for path into value
+func (c githubClient) get(ctx context.Context, path string, value interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.apiURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && c.token == "":
+		return fmt.Errorf("%s (private repositories need GITHUB_TOKEN or github_token)", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(value)
+}
+
+// fetchIssue returns the issue or pull request and its most recent comments
+func (c githubClient) fetchIssue(ctx context.Context, ref githubRef) (githubIssue, []githubComment, error) {
+	ctx, cancel := context.WithTimeout(ctx, githubFetchTimeout)
+	defer cancel()
+
+	var issue githubIssue
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/issues/%d", ref.Owner, ref.Repo, ref.Number), &issue); err != nil {
+		return githubIssue{}, nil, fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+
+	var comments []githubComment
+	if issue.Comments > 0 {
+		// Comments are oldest first; request the page holding the most recent ones
+		page := (issue.Comments + githubRecentComments - 1) / githubRecentComments
+		path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments?per_page=%d&page=%d", ref.Owner, ref.Repo, ref.Number, githubRecentComments, page)
+		if err := c.get(ctx, path, &comments); err != nil {
+			return githubIssue{}, nil, fmt.Errorf("failed to fetch comments for %s: %w", ref, err)
+		}
+
+		// The last page may hold only a few; include the previous page to fill it up
+		if len(comments) < githubRecentComments && page > 1 {
+			var previous []githubComment
+			path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments?per_page=%d&page=%d", ref.Owner, ref.Repo, ref.Number, githubRecentComments, page-1)
+			if err := c.get(ctx, path, &previous); err == nil {
+				comments = append(previous, comments...)
+			}
+		}
+		if len(comments) > githubRecentComments {
+			comments = comments[len(comments)-githubRecentComments:]
+		}
+	}
+
+	return issue, comments, nil
+}
+
+// formatGitHubIssue renders an issue or pull request as structured prompt context
+func formatGitHubIssue(ref githubRef, issue githubIssue, comments []githubComment) string {
+	kind := "issue"
+	if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+		kind = "pull request"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "GitHub %s %s: %s\n", kind, ref, issue.Title)
+
+	details := []string{"State: " + issue.State}
+	if issue.User.Login != "" {
+		details = append(details, "Author: @"+issue.User.Login)
+	}
+	if len(issue.Labels) > 0 {
+		var labels []string
+		for _, label := range issue.Labels {
+			labels = append(labels, label.Name)
+		}
+		details = append(details, "Labels: "+strings.Join(labels, ", "))
+	}
+	b.WriteString(strings.Join(details, " | ") + "\n")
+	if issue.HTMLURL != "" {
+		b.WriteString("URL: " + issue.HTMLURL + "\n")
+	}
+
+	if body := truncateGitHubText(issue.Body); body != "" {
+		fence := Fence(body)
+		fmt.Fprintf(&b, "\n%smarkdown\n%s\n%s\n", fence, body, fence)
+	}
+
+	if len(comments) > 0 {
+		if issue.Comments > len(comments) {
+			fmt.Fprintf(&b, "\nComments (latest %d of %d):\n", len(comments), issue.Comments)
+		} else {
+			b.WriteString("\nComments:\n")
+		}
+		for _, comment := range comments {
+			body := truncateGitHubText(comment.Body)
+			fence := Fence(body)
+			fmt.Fprintf(&b, "\n@%s (%s):\n%smarkdown\n%s\n%s\n", comment.User.Login, comment.CreatedAt.Format("2006-01-02"), fence, body, fence)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// truncateGitHubText trims whitespace and caps long bodies
+func truncateGitHubText(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if len(text) > maxGitHubBodyBytes {
+		text = strings.ToValidUTF8(text[:maxGitHubBodyBytes], "") + fmt.Sprintf("\n\n[truncated at %d KB]", maxGitHubBodyBytes/1024)
+	}
+	return text
+}
+
+// formatGitHubRef fetches ref and formats it as a context section, falling back to a reference
+func formatGitHubRef(ctx context.Context, ref string, apiURL, token string) (string, error) {
+	parsed, err := parseGitHubRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	client := githubClient{apiURL: apiURL, token: token}
+	issue, comments, err := client.fetchIssue(ctx, parsed)
+	if err != nil {
+		return "Referencing GitHub issue:\n" + ref, err
+	}
+
+	return formatGitHubIssue(parsed, issue, comments), nil
+}
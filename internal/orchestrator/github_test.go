@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGitHubRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    githubRef
+		wantErr bool
+	}{
+		{ref: "imdevan/prompter#123", want: githubRef{Owner: "imdevan", Repo: "prompter", Number: 123}},
+		{ref: "https://github.com/imdevan/prompter/issues/7", want: githubRef{Owner: "imdevan", Repo: "prompter", Number: 7}},
+		{ref: "https://github.com/imdevan/prompter/pull/42/files#diff-1", want: githubRef{Owner: "imdevan", Repo: "prompter", Number: 42}},
+		{ref: "imdevan/prompter", wantErr: true},
+		{ref: "https://github.com/imdevan/prompter/blob/main/README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseGitHubRef(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseGitHubRef(%q) expected error, got %+v", tt.ref, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseGitHubRef(%q) = %+v, %v; want %+v", tt.ref, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatGitHubRef(t *testing.T) {
+	const totalComments = 12
+	var gotAuth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/repos/imdevan/prompter/issues/5":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"title":        "Crash on empty config",
+				"body":         "Steps:\r\n1. run it",
+				"state":        "open",
+				"html_url":     "https://github.com/imdevan/prompter/pull/5",
+				"comments":     totalComments,
+				"user":         map[string]string{"login": "alice"},
+				"labels":       []map[string]string{{"name": "bug"}},
+				"pull_request": map[string]string{"url": "x"},
+			})
+		case "/repos/imdevan/prompter/issues/5/comments":
+			// Serve comment n as "comment n", 10 per page
+			var page int
+			fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+			var comments []map[string]interface{}
+			for n := (page-1)*10 + 1; n <= page*10 && n <= totalComments; n++ {
+				comments = append(comments, map[string]interface{}{
+					"body":       fmt.Sprintf("comment %d", n),
+					"created_at": time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC),
+					"user":       map[string]string{"login": "bob"},
+				})
+			}
+			json.NewEncoder(w).Encode(comments)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	got, err := formatGitHubRef(context.Background(), "imdevan/prompter#5", server.URL, "secret")
+	if err != nil {
+		t.Fatalf("formatGitHubRef(context.Background(), ) error = %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want bearer token", gotAuth)
+	}
+
+	for _, want := range []string{
+		"GitHub pull request imdevan/prompter#5: Crash on empty config",
+		"State: open | Author: @alice | Labels: bug",
+		"```markdown\nSteps:\n1. run it\n```",
+		"Comments (latest 10 of 12):",
+		"@bob (2026-01-03):\n```markdown\ncomment 3\n```",
+		"comment 12",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatGitHubRef(context.Background(), ) missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "comment 2\n") {
+		t.Errorf("formatGitHubRef(context.Background(), ) included an older comment:\n%s", got)
+	}
+
+	if _, err := formatGitHubRef(context.Background(), "imdevan/prompter#6", server.URL, ""); err == nil || !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Errorf("formatGitHubRef(context.Background(), ) for missing issue error = %v, want token hint", err)
+	}
+}
@@ -0,0 +1,164 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Limits on --grep, so a pattern that matches everywhere doesn't stall the run or flood the
+// prompt
+const (
+	grepMaxFiles     = 100
+	grepMaxFileBytes = 1 << 20
+)
+
+// grepMatch is a file --grep matched and the numbers of its matching lines, counted from 0
+type grepMatch struct {
+	Path  string
+	Lines []string
+	Hits  []int
+}
+
+// grepWorkspace searches the files under the current directory for lines matching any of
+// patterns. The files are the ones the map lists, so ignored ones are skipped, and files
+// that would be listed rather than embedded aren't searched.
+func grepWorkspace(patterns []string, strategy string, policy embedPolicy) ([]grepMatch, int, error) {
+	var exprs []*regexp.Regexp
+	for _, pattern := range patterns {
+		expr, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, 0, NewValidationError("grep", pattern, err.Error())
+		}
+		exprs = append(exprs, expr)
+	}
+
+	cwd, err := WorkspaceDir()
+	if err != nil {
+		return nil, 0, err
+	}
+	ignored, err := ignore.Find(cwd)
+	if err != nil {
+		return nil, 0, NewContentCollectionError(cwd, err)
+	}
+	files, err := repoMapFiles(cwd, strategy, ignored)
+	if err != nil {
+		return nil, 0, NewContentCollectionError(cwd, err)
+	}
+
+	var matches []grepMatch
+	total := 0
+	for _, file := range files {
+		file = filepath.FromSlash(file)
+		if info, err := os.Stat(file); err != nil || info.Size() > grepMaxFileBytes {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil || policy.skipReason(file, string(data)) != "" {
+			continue
+		}
+
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		var hits []int
+		for i, line := range lines {
+			for _, expr := range exprs {
+				if expr.MatchString(line) {
+					hits = append(hits, i)
+					break
+				}
+			}
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		if total++; len(matches) < grepMaxFiles {
+			matches = append(matches, grepMatch{Path: file, Lines: lines, Hits: hits})
+		}
+	}
+	return matches, total, nil
+}
+
+// formatGrepRegions shows each match's matching lines with context lines around them, in the
+// style of grep -n: "12:" marks a match and "12-" a line of context, and "--" separates
+// regions that don't touch
+func formatGrepRegions(patterns []string, matches []grepMatch, context int) string {
+	parts := []string{fmt.Sprintf("Matches for %s:", quoteJoin(patterns))}
+	for _, match := range matches {
+		hit := make(map[int]bool, len(match.Hits))
+		for _, i := range match.Hits {
+			hit[i] = true
+		}
+
+		var lines []string
+		last := -1
+		for _, i := range match.Hits {
+			start, end := max(i-context, last+1, 0), min(i+context, len(match.Lines)-1)
+			if last >= 0 && start > last+1 {
+				lines = append(lines, "--")
+			}
+			for line := start; line <= end; line++ {
+				marker := "-"
+				if hit[line] {
+					marker = ":"
+				}
+				lines = append(lines, fmt.Sprintf("%d%s%s", line+1, marker, match.Lines[line]))
+			}
+			last = max(last, end)
+		}
+
+		body := strings.Join(lines, "\n")
+		fence := Fence(body)
+		parts = append(parts, fmt.Sprintf("%s:\n%s\n%s\n%s", filepath.ToSlash(match.Path), fence, body, fence))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// quoteJoin quotes each pattern and joins them with "or"
+func quoteJoin(patterns []string) string {
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = fmt.Sprintf("%q", pattern)
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// grepSection searches for --grep. It returns the matching regions as a section or, with
+// --grep-files, the matching files to include like --file.
+func (o *Orchestrator) grepSection(request *models.PromptRequest, cfg *interfaces.Config) (promptSection, []string, error) {
+	policy := newEmbedPolicy(request, cfg)
+	matches, total, err := grepWorkspace(request.GrepPatterns, cfg.DirectoryStrategy, policy)
+	if err != nil {
+		return promptSection{}, nil, err
+	}
+	if total == 0 {
+		o.warnings.Add("--grep %s matched nothing", quoteJoin(request.GrepPatterns))
+		return promptSection{}, nil, nil
+	}
+	if total > len(matches) {
+		o.warnings.Add("--grep matched %d files; only the first %d are included", total, len(matches))
+	}
+	debugLog(request, "--grep matched %d files", total)
+
+	if request.GrepFiles {
+		files := make([]string, len(matches))
+		for i, match := range matches {
+			files[i] = match.Path
+		}
+		return promptSection{}, files, nil
+	}
+
+	for _, match := range matches {
+		o.fileIncluded(interfaces.FileEvent{Path: match.Path})
+	}
+	return promptSection{
+		Class:   SectionFiles,
+		Source:  "grep",
+		Content: formatGrepRegions(request.GrepPatterns, matches, request.GrepContext),
+	}, nil, nil
+}
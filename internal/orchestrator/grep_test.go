@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/ignore"
+)
+
+func TestFormatGrepRegions(t *testing.T) {
+	lines := strings.Split("a\nb\nmatch one\nc\nd\ne\nf\ng\nmatch two\nmatch three\nh", "\n")
+	matches := []grepMatch{{Path: "main.go", Lines: lines, Hits: []int{2, 8, 9}}}
+
+	tests := []struct {
+		name    string
+		context int
+		want    string
+	}{
+		{
+			name:    "separate regions",
+			context: 1,
+			want:    "2-b\n3:match one\n4-c\n--\n8-g\n9:match two\n10:match three\n11-h",
+		},
+		{
+			name:    "overlapping regions merge",
+			context: 3,
+			want:    "1-a\n2-b\n3:match one\n4-c\n5-d\n6-e\n7-f\n8-g\n9:match two\n10:match three\n11-h",
+		},
+		{
+			name:    "no context",
+			context: 0,
+			want:    "3:match one\n--\n9:match two\n10:match three",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatGrepRegions([]string{"match"}, matches, tt.context)
+			want := "Matches for \"match\":\n\nmain.go:\n```\n" + tt.want + "\n```"
+			if got != want {
+				t.Errorf("formatGrepRegions() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestGrepWorkspace(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"auth/login.go":        "package auth\n\nfunc Login() { refreshToken() }\n",
+		"auth/session.go":      "package auth\n\nfunc refreshToken() {}\n",
+		"cmd/main.go":          "package main\n",
+		"fixtures/tokens.json": "{\"refreshToken\": true}\n",
+		"logo.gif":             "GIF89a refreshToken\x00",
+		ignore.FileName:        "fixtures/\n",
+	}
+	writeTree(t, root, files)
+	t.Chdir(root)
+
+	matches, total, err := grepWorkspace([]string{`refreshToken\(`, `^package main`}, "filesystem", embedPolicy{})
+	if err != nil {
+		t.Fatalf("grepWorkspace() error = %v", err)
+	}
+	var got []string
+	for _, match := range matches {
+		got = append(got, filepath.ToSlash(match.Path))
+	}
+	// Ignored and binary files aren't searched
+	want := []string{"auth/login.go", "auth/session.go", "cmd/main.go"}
+	if total != 3 || !reflect.DeepEqual(got, want) {
+		t.Errorf("grepWorkspace() = %v (%d), want %v", got, total, want)
+	}
+	if !reflect.DeepEqual(matches[0].Hits, []int{2}) {
+		t.Errorf("grepWorkspace() hits = %v, want [2]", matches[0].Hits)
+	}
+
+	if _, _, err := grepWorkspace([]string{"("}, "filesystem", embedPolicy{}); err == nil {
+		t.Error("grepWorkspace() with an invalid pattern should fail")
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/imdevan/prompter/internal/template"
+)
+
+// Ways a [template_group] picks its template
+const (
+	GroupSelectRandom = "random"
+	GroupSelectRotate = "rotate"
+)
+
+// groupRandom returns a number in [0, n); tests replace it
+var groupRandom = rand.IntN
+
+// groupMember is a template a group can stand for
+type groupMember struct {
+	Ref    string // Name shown in messages, e.g. team/brainstorm
+	Path   string
+	Weight int
+}
+
+// rotation is where a rotating group continues next run
+type rotation struct {
+	Next int `json:"next"`
+}
+
+// groupMembers lists a group's templates of templateType: those named in Templates, then
+// tagged templates in precedence order. Shadowed files and duplicates are left out.
+func groupMembers(processor *template.Processor, group interfaces.TemplateGroup, templateType string) ([]groupMember, error) {
+	var members []groupMember
+	seen := make(map[string]bool)
+	add := func(ref, path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		fm, _ := template.ReadFrontMatter(path)
+		members = append(members, groupMember{Ref: ref, Path: path, Weight: max(fm.Weight, 1)})
+	}
+
+	for _, name := range group.Templates {
+		path, err := processor.ResolveTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		add(name, path)
+	}
+
+	if group.Tag != "" {
+		reached := make(map[string]bool)
+		for _, file := range processor.TemplateFiles() {
+			key := file.Type + "/" + strings.ToLower(file.Name)
+			if reached[key] || file.Type != templateType {
+				continue
+			}
+			reached[key] = true
+
+			fm, err := template.ReadFrontMatter(file.Path)
+			if err != nil {
+				continue
+			}
+			for _, tag := range fm.Tags {
+				if strings.EqualFold(tag, group.Tag) {
+					add(file.Namespace+"/"+file.Name, file.Path)
+					break
+				}
+			}
+		}
+	}
+	return members, nil
+}
+
+// pickWeighted picks a member at random, in proportion to its weight
+func pickWeighted(members []groupMember) groupMember {
+	total := 0
+	for _, member := range members {
+		total += member.Weight
+	}
+	n := groupRandom(total)
+	for _, member := range members {
+		if n < member.Weight {
+			return member
+		}
+		n -= member.Weight
+	}
+	return members[len(members)-1]
+}
+
+// pickRotating picks the member after the one the group used last run. Without the state
+// store it falls back to a random pick.
+func (o *Orchestrator) pickRotating(name string, members []groupMember, stateFile string) groupMember {
+	st, err := store.Open(stateFile)
+	if err != nil {
+		o.warnings.Add("template group %s: can't rotate without the state store (%v); picking at random", name, err)
+		return pickWeighted(members)
+	}
+	defer st.Close()
+
+	var state rotation
+	var picked int
+	err = st.Modify(interfaces.BucketRotation, name, &state, func(found bool) error {
+		picked = state.Next % len(members)
+		state.Next = picked + 1
+		return nil
+	})
+	if err != nil {
+		o.warnings.Add("template group %s: can't rotate without the state store (%v); picking at random", name, err)
+		return pickWeighted(members)
+	}
+	return members[picked]
+}
+
+// resolveTemplateGroup picks the template a [template_group] called name stands for. ok is
+// false when name isn't a group, so it's loaded as a template.
+func (o *Orchestrator) resolveTemplateGroup(name, templateType string, cfg *interfaces.Config) (groupMember, bool, error) {
+	key := strings.ToLower(name)
+	group, ok := cfg.TemplateGroups[key]
+	if !ok {
+		return groupMember{}, false, nil
+	}
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok {
+		return groupMember{}, false, nil
+	}
+
+	members, err := groupMembers(processor, group, templateType)
+	if err != nil {
+		return groupMember{}, true, fmt.Errorf("template group %s: %w", name, err)
+	}
+	if len(members) == 0 {
+		return groupMember{}, true, fmt.Errorf("template group %s has no %s templates tagged %q", name, templateType, group.Tag)
+	}
+
+	if group.Select == GroupSelectRotate {
+		return o.pickRotating(key, members, cfg.StateFile), true, nil
+	}
+	return pickWeighted(members), true, nil
+}
@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+)
+
+func TestPickWeighted(t *testing.T) {
+	members := []groupMember{{Ref: "a", Weight: 1}, {Ref: "b", Weight: 3}}
+	defer func(original func(int) int) { groupRandom = original }(groupRandom)
+
+	for n, want := range []string{"a", "b", "b", "b"} {
+		groupRandom = func(total int) int {
+			if total != 4 {
+				t.Fatalf("groupRandom(%d), want the total weight 4", total)
+			}
+			return n
+		}
+		if got := pickWeighted(members).Ref; got != want {
+			t.Errorf("pickWeighted() with %d = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestResolveTemplateGroup(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"global/pre/calm.md":     "---\ntags: [brainstorm]\n---\ncalm",
+		"global/pre/wild.md":     "---\ntags: [Brainstorm]\nweight: 2\n---\nwild",
+		"global/pre/plain.md":    "plain",
+		"global/post/tagged.md":  "---\ntags: [brainstorm]\n---\npost",
+		"local/pre/wild.md":      "---\ntags: [other]\n---\nshadows the global wild",
+		"global/pre/explicit.md": "explicit",
+	}
+	writeTree(t, root, files)
+
+	o := New()
+	processor := o.templateProcessor.(*template.Processor)
+	processor.SetPromptsLocation(filepath.Join(root, "global"))
+	processor.SetLocalPromptsLocation(filepath.Join(root, "local"))
+	cfg := &interfaces.Config{
+		StateFile: filepath.Join(t.TempDir(), "state.db"),
+		TemplateGroups: map[string]interfaces.TemplateGroup{
+			"brainstorm-any": {Tag: "brainstorm", Templates: []string{"explicit"}, Select: GroupSelectRotate},
+			"empty":          {Tag: "missing"},
+		},
+	}
+
+	// Rotation goes through the named templates, then tagged ones; the shadowed wild is left out
+	var got []string
+	for i := 0; i < 3; i++ {
+		member, ok, err := o.resolveTemplateGroup("Brainstorm-Any", "pre", cfg)
+		if err != nil || !ok {
+			t.Fatalf("resolveTemplateGroup() = %v, %v", ok, err)
+		}
+		got = append(got, member.Ref)
+	}
+	want := []string{"explicit", "global/calm", "explicit"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rotation = %v, want %v", got, want)
+		}
+	}
+
+	if _, ok, err := o.resolveTemplateGroup("plain", "pre", cfg); ok || err != nil {
+		t.Errorf("resolveTemplateGroup(plain) = %v, %v; want not a group", ok, err)
+	}
+	if _, ok, err := o.resolveTemplateGroup("empty", "pre", cfg); !ok || err == nil {
+		t.Errorf("resolveTemplateGroup(empty) = %v, %v; want an error", ok, err)
+	}
+
+	// Post groups only draw from post templates
+	member, _, err := o.resolveTemplateGroup("brainstorm-any", "post", &interfaces.Config{
+		TemplateGroups: map[string]interfaces.TemplateGroup{"brainstorm-any": {Tag: "brainstorm"}},
+	})
+	if err != nil || member.Path != filepath.Join(root, "global/post/tagged.md") {
+		t.Errorf("post group picked %+v, %v", member, err)
+	}
+}
@@ -0,0 +1,249 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+)
+
+// Shell identifiers used for history parsing and command execution
+const (
+	shellZsh        = "zsh"
+	shellBash       = "bash"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+	shellCmd        = "cmd"
+)
+
+// hookEnvVar is exported by the `prompter hook` shell snippet, set to the shell name
+const hookEnvVar = "PROMPTER_HOOK"
+
+// hookFixFileReady reports whether the shell hook is active and has recorded a command into
+// fixFile. A fix file other users could have written is ignored, with a warning.
+func (o *Orchestrator) hookFixFileReady(fixFile string) bool {
+	if os.Getenv(hookEnvVar) == "" || fixFile == "" {
+		return false
+	}
+	info, err := os.Stat(fixFile)
+	if err != nil || info.IsDir() || info.Size() == 0 {
+		return false
+	}
+	if err := checkPrivateFile(fixFile); err != nil {
+		o.warnings.Add("shell hook capture ignored: %v", err)
+		return false
+	}
+	return true
+}
+
+// CheckPrivateFile reports an error unless path is a file only the current user can read or
+// write, as the shell hook's fix file must be (exported for app layer)
+func CheckPrivateFile(path string) error {
+	return checkPrivateFile(path)
+}
+
+// historySource describes a shell history file and the shell that wrote it
+type historySource struct {
+	Path  string
+	Shell string
+}
+
+// historySources returns candidate history files in lookup order for the current platform
+func historySources(homeDir string) []historySource {
+	psHistory := powerShellHistoryPath(homeDir)
+
+	if runtime.GOOS == "windows" {
+		return []historySource{
+			{Path: psHistory, Shell: shellPowerShell},
+			{Path: filepath.Join(homeDir, ".bash_history"), Shell: shellBash}, // Git Bash / MSYS
+		}
+	}
+
+	sources := []historySource{
+		{Path: filepath.Join(homeDir, ".zsh_history"), Shell: shellZsh},
+		{Path: filepath.Join(homeDir, ".bash_history"), Shell: shellBash},
+		{Path: fishHistoryPath(homeDir), Shell: shellFish},
+		{Path: psHistory, Shell: shellPowerShell},
+	}
+
+	return preferCurrentShell(sources, filepath.Base(os.Getenv("SHELL")))
+}
+
+// preferCurrentShell moves the history of the user's login shell to the front,
+// so a stale .bash_history doesn't shadow the shell actually in use
+func preferCurrentShell(sources []historySource, shell string) []historySource {
+	for i, source := range sources {
+		if source.Shell == shell && i > 0 {
+			ordered := []historySource{source}
+			ordered = append(ordered, sources[:i]...)
+			return append(ordered, sources[i+1:]...)
+		}
+	}
+	return sources
+}
+
+// fishHistoryPath returns the fish history file location
+func fishHistoryPath(homeDir string) string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "fish", "fish_history")
+	}
+	return filepath.Join(homeDir, ".local", "share", "fish", "fish_history")
+}
+
+// findHistorySource returns the first history file that exists
+func findHistorySource() (historySource, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return historySource{}, err
+	}
+
+	for _, source := range historySources(homeDir) {
+		if _, err := os.Stat(source.Path); err == nil {
+			return source, nil
+		}
+	}
+
+	return historySource{}, fmt.Errorf("no shell history found")
+}
+
+// powerShellHistoryPath returns the PSReadLine history file location
+func powerShellHistoryPath(homeDir string) string {
+	if runtime.GOOS == "windows" {
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(homeDir, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Microsoft", "Windows", "PowerShell", "PSReadLine", "ConsoleHost_history.txt")
+	}
+	return filepath.Join(homeDir, ".local", "share", "powershell", "PSReadLine", "ConsoleHost_history.txt")
+}
+
+// readHistoryCommands reads a history file and returns its commands, oldest first
+func readHistoryCommands(source historySource) ([]string, error) {
+	content, err := os.ReadFile(source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// PSReadLine writes CRLF on Windows
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+	if source.Shell == shellFish {
+		return parseFishHistory(text), nil
+	}
+
+	lines := strings.Split(text, "\n")
+
+	var commands []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// PowerShell stores multi-line commands with a trailing backtick on each continued line
+		if source.Shell == shellPowerShell {
+			for strings.HasSuffix(line, "`") && i+1 < len(lines) {
+				i++
+				line = strings.TrimSuffix(line, "`") + "\n" + lines[i]
+			}
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// For zsh, remove timestamp if present
+		if source.Shell == shellZsh && strings.Contains(line, ":") {
+			parts := strings.SplitN(line, ";", 2)
+			if len(parts) == 2 {
+				line = parts[1]
+			}
+		}
+
+		commands = append(commands, line)
+	}
+
+	return commands, nil
+}
+
+// parseFishHistory extracts commands from fish's YAML-like history format:
+//
+//	- cmd: go test ./...
+//	  when: 1700000000
+//	  paths:
+//	    - ./...
+func parseFishHistory(text string) []string {
+	var commands []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "- cmd: ") {
+			continue
+		}
+
+		command := unescapeFishCommand(strings.TrimPrefix(line, "- cmd: "))
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// unescapeFishCommand reverses fish's escaping of backslashes and newlines
+func unescapeFishCommand(command string) string {
+	var result strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] == '\\' && i+1 < len(command) {
+			switch command[i+1] {
+			case 'n':
+				result.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				result.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		result.WriteByte(command[i])
+	}
+	return result.String()
+}
+
+// shellCommand builds an exec.Cmd that runs command through the appropriate shell, killed when
+// ctx is done
+func shellCommand(ctx context.Context, shell, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		switch shell {
+		case shellPowerShell:
+			return exec.CommandContext(ctx, powerShellBinary(), "-NoProfile", "-Command", command)
+		case shellBash, shellZsh:
+			if path, err := exec.LookPath(shell); err == nil {
+				return exec.CommandContext(ctx, path, "-c", command)
+			}
+		}
+		return exec.CommandContext(ctx, shellCmd, "/C", command)
+	}
+
+	switch shell {
+	case shellPowerShell:
+		if path, err := exec.LookPath("pwsh"); err == nil {
+			return exec.CommandContext(ctx, path, "-NoProfile", "-Command", command)
+		}
+	case shellFish:
+		// fish syntax isn't POSIX, so run fish commands through fish when available
+		if path, err := exec.LookPath(shellFish); err == nil {
+			return exec.CommandContext(ctx, path, "-c", command)
+		}
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// powerShellBinary prefers PowerShell 7 (pwsh) and falls back to Windows PowerShell
+func powerShellBinary() string {
+	if path, err := exec.LookPath("pwsh"); err == nil {
+		return path
+	}
+	return "powershell"
+}
@@ -0,0 +1,307 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+func TestReadHistoryCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		shell    string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "bash history",
+			shell:    shellBash,
+			content:  "ls -la\n\ngo build ./...\n",
+			expected: []string{"ls -la", "go build ./..."},
+		},
+		{
+			name:     "zsh extended history",
+			shell:    shellZsh,
+			content:  ": 1700000000:0;go test ./...\n: 1700000001:0;make build\n",
+			expected: []string{"go test ./...", "make build"},
+		},
+		{
+			name:     "powershell history with CRLF and continuations",
+			shell:    shellPowerShell,
+			content:  "Get-ChildItem\r\ndotnet build `\r\n  --no-restore\r\n",
+			expected: []string{"Get-ChildItem", "dotnet build \n  --no-restore"},
+		},
+		{
+			name:     "fish history",
+			shell:    shellFish,
+			content:  "- cmd: go test ./...\n  when: 1700000000\n- cmd: echo a\\nb \\\\ c\n  when: 1700000001\n  paths:\n    - ./...\n",
+			expected: []string{"go test ./...", "echo a\nb \\ c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			commands, err := readHistoryCommands(historySource{Path: path, Shell: tt.shell})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(commands) != len(tt.expected) {
+				t.Fatalf("expected %d commands, got %d: %q", len(tt.expected), len(commands), commands)
+			}
+			for i := range commands {
+				if commands[i] != tt.expected[i] {
+					t.Errorf("command %d = %q, expected %q", i, commands[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetLastCommandFromHistory_SkipsPrompter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	content := "go build ./...\nprompter --fix\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orch := New()
+	command, err := orch.getLastCommandFromHistory(historySource{Path: path, Shell: shellBash})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if command != "go build ./..." {
+		t.Errorf("expected last non-prompter command, got %q", command)
+	}
+}
+
+func TestGetRecentCommandsFromHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	content := "make lint\ngo test ./...\n# comment\ngo build ./...\ngo test ./...\nprompter --fix\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orch := New()
+	commands, err := orch.getRecentCommandsFromHistory(historySource{Path: path, Shell: shellBash}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"go test ./...", "go build ./..."}
+	if len(commands) != len(expected) || commands[0] != expected[0] || commands[1] != expected[1] {
+		t.Errorf("expected %q, got %q", expected, commands)
+	}
+}
+
+func TestCommandLabel(t *testing.T) {
+	if got := commandLabel("for f in *\ndo echo $f\ndone"); got != "for f in * ..." {
+		t.Errorf("commandLabel() = %q", got)
+	}
+	if got := commandLabel(strings.Repeat("x", 100)); len(got) != 80 {
+		t.Errorf("expected label truncated to 80 chars, got %d", len(got))
+	}
+	// Multibyte characters are cut whole, counting characters rather than bytes
+	got := commandLabel("echo " + strings.Repeat("é", 100))
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) != 80 {
+		t.Errorf("commandLabel() = %q, want 80 valid characters", got)
+	}
+	if got := commandLabel("echo " + strings.Repeat("é", 60)); got != "echo "+strings.Repeat("é", 60) {
+		t.Errorf("commandLabel() = %q, want it unchanged under 80 characters", got)
+	}
+}
+
+func TestPreferCurrentShell(t *testing.T) {
+	sources := []historySource{
+		{Path: "zsh_history", Shell: shellZsh},
+		{Path: "bash_history", Shell: shellBash},
+		{Path: "fish_history", Shell: shellFish},
+	}
+
+	ordered := preferCurrentShell(sources, shellFish)
+	if ordered[0].Shell != shellFish || ordered[1].Shell != shellZsh || ordered[2].Shell != shellBash {
+		t.Errorf("expected fish first then original order, got %v", ordered)
+	}
+
+	unchanged := preferCurrentShell(sources, "tcsh")
+	if unchanged[0].Shell != shellZsh {
+		t.Errorf("expected unknown shell to leave order unchanged, got %v", unchanged)
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	cmd := shellCommand(context.Background(), shellBash, "echo hi")
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	if filepath.Base(cmd.Path) != "sh" {
+		t.Errorf("expected sh, got %s", cmd.Path)
+	}
+	if len(cmd.Args) != 3 || cmd.Args[1] != "-c" || cmd.Args[2] != "echo hi" {
+		t.Errorf("unexpected args: %q", cmd.Args)
+	}
+}
+
+func TestExecuteAndCaptureCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+
+	orch := New()
+	fixInfo, err := orch.executeAndCaptureCommand(context.Background(), "echo broken; exit 3", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fixInfo.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, expected 3", fixInfo.ExitCode)
+	}
+	if fixInfo.Output != "broken" {
+		t.Errorf("Output = %q, expected %q", fixInfo.Output, "broken")
+	}
+	if fixInfo.Command != "echo broken; exit 3" {
+		t.Errorf("Command = %q", fixInfo.Command)
+	}
+	if !strings.HasPrefix(fixInfo.Raw, "$ echo broken; exit 3\n\nbroken") || !strings.Contains(fixInfo.Raw, "# Exit code: 3") {
+		t.Errorf("unexpected Raw: %q", fixInfo.Raw)
+	}
+}
+
+func TestExecuteAndCaptureCommand_SeparatesStreams(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+
+	orch := New()
+	fixInfo, err := orch.executeAndCaptureCommand(context.Background(), "echo building; echo 'main.go:3: undefined: x' >&2; exit 1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fixInfo.Stdout != "building" {
+		t.Errorf("Stdout = %q, expected %q", fixInfo.Stdout, "building")
+	}
+	if fixInfo.Stderr != "main.go:3: undefined: x" {
+		t.Errorf("Stderr = %q, expected %q", fixInfo.Stderr, "main.go:3: undefined: x")
+	}
+	if !strings.Contains(fixInfo.Output, "building") || !strings.Contains(fixInfo.Output, "undefined: x") {
+		t.Errorf("Output should contain both streams, got %q", fixInfo.Output)
+	}
+}
+
+func TestExecuteAndCaptureCommand_StopsWithContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New().executeAndCaptureCommand(ctx, "echo waiting; sleep 10", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the command ran for %s after the deadline", elapsed)
+	}
+}
+
+func TestParseFixContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		command  string
+		output   string
+		exitCode int
+	}{
+		{
+			name:    "command and output",
+			content: "$ go build\n\nmain.go:3:1: undefined: x",
+			command: "go build",
+			output:  "main.go:3:1: undefined: x",
+		},
+		{
+			name:     "shell hook capture with exit code",
+			content:  "$ ls /missing\n\nls: cannot access '/missing'\n\n# Exit code: 2",
+			command:  "ls /missing",
+			output:   "ls: cannot access '/missing'",
+			exitCode: 2,
+		},
+		{
+			name:     "prompter capture with duration",
+			content:  "$ make\n\nmake: *** No rule\n\n# Exit code: 2 (took 4ms)",
+			command:  "make",
+			output:   "make: *** No rule",
+			exitCode: 2,
+		},
+		{
+			name:     "fish hook records command only",
+			content:  "$ npm test\n\n# Exit code: 1",
+			command:  "npm test",
+			output:   "",
+			exitCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixInfo := parseFixContent(tt.content)
+			if fixInfo.Command != tt.command {
+				t.Errorf("Command = %q, expected %q", fixInfo.Command, tt.command)
+			}
+			if fixInfo.Output != tt.output {
+				t.Errorf("Output = %q, expected %q", fixInfo.Output, tt.output)
+			}
+			if fixInfo.ExitCode != tt.exitCode {
+				t.Errorf("ExitCode = %d, expected %d", fixInfo.ExitCode, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestHookFixFileReady(t *testing.T) {
+	defer warnings.Flush(io.Discard)
+	fixFile := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ make\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(hookEnvVar, "")
+	if New().hookFixFileReady(fixFile) {
+		t.Error("expected false without the hook environment variable")
+	}
+
+	t.Setenv(hookEnvVar, "zsh")
+	if !New().hookFixFileReady(fixFile) {
+		t.Error("expected true with the hook active and a recorded command")
+	}
+	if New().hookFixFileReady(filepath.Join(t.TempDir(), "missing.txt")) {
+		t.Error("expected false for a missing fix file")
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(fixFile, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if New().hookFixFileReady(fixFile) {
+			t.Error("expected false for a fix file other users can write")
+		}
+	}
+}
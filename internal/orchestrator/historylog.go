@@ -0,0 +1,176 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+)
+
+// historyLimit and captureLogLimit bound how many entries BucketHistory and BucketCaptures
+// keep; the oldest are dropped as new ones are added
+const (
+	historyLimit    = 200
+	captureLogLimit = 20
+)
+
+// captureLogMaxBytes bounds the capture a log entry keeps, from the end, where errors usually are
+const captureLogMaxBytes = 64 * 1024
+
+// RecordHistory adds a prompt that was output to the history in state_file, dropping the
+// oldest beyond historyLimit (exported for app layer)
+func RecordHistory(cfg *interfaces.Config, entry interfaces.HistoryEntry) error {
+	redactor, err := newRedactor(cfg.Redact, cfg.RedactPatterns)
+	if err != nil {
+		return err
+	}
+	entry.Prompt = redactor.Apply(entry.Prompt)
+	return appendBounded(cfg.StateFile, interfaces.BucketHistory, entry, historyLimit)
+}
+
+// LoadHistory returns the prompt history, oldest first (exported for app layer)
+func LoadHistory(cfg *interfaces.Config) ([]interfaces.HistoryEntry, error) {
+	var entries []interfaces.HistoryEntry
+	err := readLog(cfg.StateFile, interfaces.BucketHistory, func(decode func(value interface{}) error) error {
+		var entry interfaces.HistoryEntry
+		if err := decode(&entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// RecordCapture adds the output fix mode captured to the capture log in state_file, dropping
+// the oldest beyond captureLogLimit (exported for app layer)
+func RecordCapture(cfg *interfaces.Config, entry interfaces.CaptureEntry) error {
+	redactor, err := newRedactor(cfg.Redact, cfg.RedactPatterns)
+	if err != nil {
+		return err
+	}
+	entry.Command = redactor.Apply(entry.Command)
+	entry.Raw = redactor.Apply(entry.Raw)
+	if len(entry.Raw) > captureLogMaxBytes {
+		// Start at a line, which is also where a character starts, and keep the "$ command"
+		// line fix mode reads the command from
+		header := ""
+		if entry.Command != "" && len(entry.Command) < captureLogMaxBytes/2 {
+			header = "$ " + entry.Command + "\n"
+		}
+		tail := entry.Raw[len(entry.Raw)-captureLogMaxBytes+len(header):]
+		if i := strings.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+		entry.Raw = header + tail
+	}
+	return appendBounded(cfg.StateFile, interfaces.BucketCaptures, entry, captureLogLimit)
+}
+
+// LoadCaptures returns the capture log, oldest first (exported for app layer)
+func LoadCaptures(cfg *interfaces.Config) ([]interfaces.CaptureEntry, error) {
+	var entries []interfaces.CaptureEntry
+	err := readLog(cfg.StateFile, interfaces.BucketCaptures, func(decode func(value interface{}) error) error {
+		var entry interfaces.CaptureEntry
+		if err := decode(&entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// LastCapture returns the capture fix mode read this run, for the capture log; ok is false
+// when nothing was captured (exported for app layer)
+func (o *Orchestrator) LastCapture() (interfaces.CaptureEntry, bool) {
+	if o.lastCapture == nil {
+		return interfaces.CaptureEntry{}, false
+	}
+	return *o.lastCapture, true
+}
+
+// captureLast reuses the latest capture logged in the working directory, so a fix prompt can
+// be assembled again, e.g. with another template, without re-running the command
+func (o *Orchestrator) captureLast(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return interfaces.FixInfo{}, err
+	}
+	entries, err := LoadCaptures(&interfaces.Config{StateFile: request.StateFile})
+	if err != nil {
+		return interfaces.FixInfo{}, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Dir != cwd {
+			continue
+		}
+		return parseFixContent(entries[i].Raw), nil
+	}
+	return interfaces.FixInfo{}, fmt.Errorf("no capture logged in %s yet (captures are logged while history is on)", cwd)
+}
+
+// appendBounded appends value to bucket in the store at path, then drops the oldest entries
+// beyond limit
+func appendBounded(path, bucket string, value interface{}, limit int) error {
+	st, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if _, err := st.Append(bucket, value); err != nil {
+		return err
+	}
+	keys, err := st.Keys(bucket)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[:max(len(keys)-limit, 0)] {
+		if err := st.Delete(bucket, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLog calls fn for each entry of bucket in the store at path, oldest first, with a
+// function that decodes it
+func readLog(path, bucket string, fn func(decode func(value interface{}) error) error) error {
+	st, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	keys, err := st.Keys(bucket)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err := fn(func(value interface{}) error {
+			_, err := st.Get(bucket, key, value)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCaptureEntry describes fixInfo, read from source, for the capture log
+func newCaptureEntry(source string, fixInfo interfaces.FixInfo) *interfaces.CaptureEntry {
+	cwd, _ := os.Getwd()
+	return &interfaces.CaptureEntry{
+		Time:     time.Now(),
+		Dir:      cwd,
+		Source:   source,
+		Command:  fixInfo.Command,
+		ExitCode: fixInfo.ExitCode,
+		Raw:      fixInfo.Raw,
+	}
+}
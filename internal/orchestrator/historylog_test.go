@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestRecordHistory(t *testing.T) {
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db"), Redact: true}
+
+	for i := range historyLimit + 5 {
+		if err := RecordHistory(cfg, interfaces.HistoryEntry{Prompt: fmt.Sprintf("prompt %d", i)}); err != nil {
+			t.Fatalf("RecordHistory() error: %v", err)
+		}
+	}
+	if err := RecordHistory(cfg, interfaces.HistoryEntry{Prompt: "deploy with\nAPI_TOKEN=s3cr3tvalue99"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadHistory(cfg)
+	if err != nil {
+		t.Fatalf("LoadHistory() error: %v", err)
+	}
+	if len(entries) != historyLimit {
+		t.Fatalf("LoadHistory() returned %d entries, want the last %d", len(entries), historyLimit)
+	}
+	if entries[0].Prompt != "prompt 6" {
+		t.Errorf("oldest entry = %q, want prompt 6", entries[0].Prompt)
+	}
+	if last := entries[len(entries)-1].Prompt; strings.Contains(last, "s3cr3tvalue99") {
+		t.Errorf("newest entry = %q, want the token redacted", last)
+	}
+}
+
+func TestCaptureLast(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+	o := New()
+
+	if _, err := o.captureLast(interfaces.CaptureRequest{StateFile: cfg.StateFile}); err == nil {
+		t.Error("captureLast() with nothing logged succeeded")
+	}
+
+	long := "$ go test ./...\n" + strings.Repeat("--- FAIL: TestX\n", captureLogMaxBytes/8) + "FAIL"
+	for _, entry := range []interfaces.CaptureEntry{
+		{Dir: dir, Source: FixSourceCommand, Command: "go build", Raw: "$ go build\n./main.go:3:2: undefined: x"},
+		{Dir: dir, Source: FixSourceRerun, Command: "go test ./...", Raw: long},
+		{Dir: t.TempDir(), Source: FixSourceCommand, Command: "make", Raw: "$ make\nerror"},
+	} {
+		if err := RecordCapture(cfg, entry); err != nil {
+			t.Fatalf("RecordCapture() error: %v", err)
+		}
+	}
+
+	fixInfo, err := o.captureLast(interfaces.CaptureRequest{StateFile: cfg.StateFile})
+	if err != nil {
+		t.Fatalf("captureLast() error: %v", err)
+	}
+	if fixInfo.Command != "go test ./..." || !strings.HasSuffix(fixInfo.Raw, "FAIL") || len(fixInfo.Raw) > captureLogMaxBytes {
+		t.Errorf("captureLast() = command %q, %d bytes; want the latest capture here, truncated", fixInfo.Command, len(fixInfo.Raw))
+	}
+}
@@ -0,0 +1,209 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Limits for Jira ticket context
+const (
+	jiraFetchTimeout = 15 * time.Second
+	maxJiraTextBytes = 16 * 1024
+	maxJiraBodyBytes = 4 * 1024 * 1024
+)
+
+var (
+	// jiraKeyPattern matches ticket keys such as PROJ-123
+	jiraKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-\d+$`)
+	// jiraAcceptanceHeading finds an "Acceptance criteria" heading inside a description
+	jiraAcceptanceHeading = regexp.MustCompile(`(?im)^\s*(?:h\d\.\s*|#+\s*|\*)?acceptance criteria[*:]*\s*$`)
+	// jiraNextHeading finds the heading that ends the acceptance criteria section
+	jiraNextHeading = regexp.MustCompile(`(?m)^\s*(?:h\d\.\s|#+\s)`)
+)
+
+// jiraSettings holds the connection options for --jira
+type jiraSettings struct {
+	BaseURL         string // e.g. https://example.atlassian.net
+	Email           string // Jira Cloud account for basic auth; empty uses a bearer token
+	Token           string
+	AcceptanceField string // Custom field holding acceptance criteria, e.g. customfield_10034
+}
+
+// jiraToken returns the API token from the environment, falling back to the config
+func jiraToken(configToken string) string {
+	if token := os.Getenv("JIRA_API_TOKEN"); token != "" {
+		return token
+	}
+	return configToken
+}
+
+// jiraIssue is the subset of the issue payload that is used
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		Status      struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+	} `json:"fields"`
+}
+
+// fetchJiraIssue fetches key through the REST API, which returns descriptions as wiki markup
+func fetchJiraIssue(ctx context.Context, settings jiraSettings, key string) (jiraIssue, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, jiraFetchTimeout)
+	defer cancel()
+
+	fields := []string{"summary", "description", "status", "issuetype"}
+	if settings.AcceptanceField != "" {
+		fields = append(fields, settings.AcceptanceField)
+	}
+	endpoint := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=%s",
+		strings.TrimRight(settings.BaseURL, "/"), url.PathEscape(key), url.QueryEscape(strings.Join(fields, ",")))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return jiraIssue{}, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case settings.Email != "":
+		req.SetBasicAuth(settings.Email, settings.Token)
+	case settings.Token != "":
+		req.Header.Set("Authorization", "Bearer "+settings.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return jiraIssue{}, "", fmt.Errorf("failed to fetch %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return jiraIssue{}, "", fmt.Errorf("failed to fetch %s: %s", key, resp.Status)
+	}
+
+	// Read one byte past the limit to tell whether the response was cut off; JSON cut short
+	// doesn't parse
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJiraBodyBytes+1))
+	if err != nil {
+		return jiraIssue{}, "", fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if len(body) > maxJiraBodyBytes {
+		return jiraIssue{}, "", fmt.Errorf("response for %s exceeds %d MB", key, maxJiraBodyBytes/(1024*1024))
+	}
+	var issue jiraIssue
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return jiraIssue{}, "", fmt.Errorf("invalid response for %s: %w", key, err)
+	}
+
+	// Acceptance criteria come from the configured field, or a section of the description
+	acceptance := jiraTextField(body, settings.AcceptanceField)
+	if strings.TrimSpace(acceptance) == "" {
+		issue.Fields.Description, acceptance = splitAcceptanceCriteria(issue.Fields.Description)
+	}
+
+	return issue, strings.TrimSpace(acceptance), nil
+}
+
+// jiraTextField returns a custom text field from the raw issue payload; fields vary per site
+func jiraTextField(body []byte, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	var payload struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+	var text string
+	if json.Unmarshal(body, &payload) == nil {
+		json.Unmarshal(payload.Fields[name], &text) // Non-text fields are left empty
+	}
+	return text
+}
+
+// splitAcceptanceCriteria separates an "Acceptance criteria" section from a description
+func splitAcceptanceCriteria(description string) (string, string) {
+	heading := jiraAcceptanceHeading.FindStringIndex(description)
+	if heading == nil {
+		return description, ""
+	}
+
+	rest := description[heading[1]:]
+	end := len(rest)
+	if next := jiraNextHeading.FindStringIndex(rest); next != nil {
+		end = next[0]
+	}
+
+	remaining := strings.TrimSpace(strings.TrimSpace(description[:heading[0]]) + "\n\n" + strings.TrimSpace(rest[end:]))
+	return remaining, strings.TrimSpace(rest[:end])
+}
+
+// formatJiraIssue renders a ticket as structured prompt context
+func formatJiraIssue(settings jiraSettings, issue jiraIssue, acceptance string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Jira ticket %s: %s\n", issue.Key, issue.Fields.Summary)
+
+	var details []string
+	if issue.Fields.IssueType.Name != "" {
+		details = append(details, "Type: "+issue.Fields.IssueType.Name)
+	}
+	if issue.Fields.Status.Name != "" {
+		details = append(details, "Status: "+issue.Fields.Status.Name)
+	}
+	if len(details) > 0 {
+		b.WriteString(strings.Join(details, " | ") + "\n")
+	}
+	fmt.Fprintf(&b, "URL: %s/browse/%s\n", strings.TrimRight(settings.BaseURL, "/"), issue.Key)
+
+	for _, part := range []struct {
+		title string
+		text  string
+	}{
+		{"Description", issue.Fields.Description},
+		{"Acceptance criteria", acceptance},
+	} {
+		text := truncateJiraText(part.text)
+		if text == "" {
+			continue
+		}
+		fence := Fence(text)
+		fmt.Fprintf(&b, "\n%s:\n%s\n%s\n%s\n", part.title, fence, text, fence)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// truncateJiraText trims whitespace and caps long fields
+func truncateJiraText(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if len(text) > maxJiraTextBytes {
+		text = strings.ToValidUTF8(text[:maxJiraTextBytes], "") + fmt.Sprintf("\n\n[truncated at %d KB]", maxJiraTextBytes/1024)
+	}
+	return text
+}
+
+// formatJiraRef fetches key and formats it as a context section, falling back to a reference
+func formatJiraRef(ctx context.Context, key string, settings jiraSettings) (string, error) {
+	if settings.BaseURL == "" {
+		return "Referencing Jira ticket:\n" + key, fmt.Errorf("jira_url must be set in the config to fetch %s", key)
+	}
+
+	issue, acceptance, err := fetchJiraIssue(ctx, settings, key)
+	if err != nil {
+		return "Referencing Jira ticket:\n" + key, err
+	}
+
+	return formatJiraIssue(settings, issue, acceptance), nil
+}
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitAcceptanceCriteria(t *testing.T) {
+	tests := []struct {
+		name            string
+		description     string
+		wantDescription string
+		wantAcceptance  string
+	}{
+		{
+			name:            "wiki heading",
+			description:     "Users need export.\n\nh2. Acceptance Criteria\n* CSV works\n* JSON works\n\nh2. Notes\nLater.",
+			wantDescription: "Users need export.\n\nh2. Notes\nLater.",
+			wantAcceptance:  "* CSV works\n* JSON works",
+		},
+		{
+			name:            "bold label at end",
+			description:     "Users need export.\n*Acceptance criteria:*\n- CSV works",
+			wantDescription: "Users need export.",
+			wantAcceptance:  "- CSV works",
+		},
+		{
+			name:            "no section",
+			description:     "Users need export.",
+			wantDescription: "Users need export.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, acceptance := splitAcceptanceCriteria(tt.description)
+			if description != tt.wantDescription || acceptance != tt.wantAcceptance {
+				t.Errorf("splitAcceptanceCriteria() = %q, %q; want %q, %q", description, acceptance, tt.wantDescription, tt.wantAcceptance)
+			}
+		})
+	}
+}
+
+func TestFormatJiraRef(t *testing.T) {
+	var gotUser, gotToken, gotFields string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotToken, _ = r.BasicAuth()
+		gotFields = r.URL.Query().Get("fields")
+		if r.URL.Path != "/rest/api/2/issue/PROJ-7" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"key": "PROJ-7",
+			"fields": map[string]interface{}{
+				"summary":           "Export reports",
+				"description":       "Users need export.\r\n",
+				"status":            map[string]string{"name": "In Progress"},
+				"issuetype":         map[string]string{"name": "Story"},
+				"customfield_10034": "CSV and JSON both work",
+			},
+		})
+	}))
+	defer server.Close()
+
+	settings := jiraSettings{BaseURL: server.URL, Email: "me@example.com", Token: "secret", AcceptanceField: "customfield_10034"}
+	got, err := formatJiraRef(context.Background(), "PROJ-7", settings)
+	if err != nil {
+		t.Fatalf("formatJiraRef(context.Background(), ) error = %v", err)
+	}
+	if gotUser != "me@example.com" || gotToken != "secret" {
+		t.Errorf("basic auth = %q:%q, want email and token", gotUser, gotToken)
+	}
+	if !strings.Contains(gotFields, "customfield_10034") {
+		t.Errorf("fields = %q, want acceptance field requested", gotFields)
+	}
+
+	for _, want := range []string{
+		"Jira ticket PROJ-7: Export reports",
+		"Type: Story | Status: In Progress",
+		"URL: " + server.URL + "/browse/PROJ-7",
+		"Description:\n```\nUsers need export.\n```",
+		"Acceptance criteria:\n```\nCSV and JSON both work\n```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatJiraRef(context.Background(), ) missing %q in:\n%s", want, got)
+		}
+	}
+
+	got, err = formatJiraRef(context.Background(), "PROJ-8", settings)
+	if err == nil || got != "Referencing Jira ticket:\nPROJ-8" {
+		t.Errorf("formatJiraRef(context.Background(), ) for missing ticket = %q, %v; want reference and error", got, err)
+	}
+
+	if _, err := formatJiraRef(context.Background(), "PROJ-7", jiraSettings{}); err == nil || !strings.Contains(err.Error(), "jira_url") {
+		t.Errorf("formatJiraRef(context.Background(), ) without jira_url error = %v, want config hint", err)
+	}
+}
+
+func TestFetchJiraIssueTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"key": "PROJ-7", "fields": {"description": "`))
+		w.Write([]byte(strings.Repeat("x", maxJiraBodyBytes)))
+		w.Write([]byte(`"}}`))
+	}))
+	defer server.Close()
+
+	_, _, err := fetchJiraIssue(context.Background(), jiraSettings{BaseURL: server.URL}, "PROJ-7")
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("fetchJiraIssue() for an oversized response error = %v, want size limit", err)
+	}
+}
@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ansiPattern matches CSI sequences (colors, cursor movement) and OSC sequences (titles, links)
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-B]|\x1b[=>]`)
+
+// DefaultNoisePatterns match lines that rarely help diagnose a failure
+var DefaultNoisePatterns = []string{
+	// Progress bars: "45% |████      |", "[=====>    ] 12/40", "###### 60%"
+	`^\s*\d{1,3}(\.\d+)?%\s*\|[^|]*\|`,
+	`^\s*\[[=#>\-. ]{5,}\]`,
+	`^\s*[#=█▉▊▋▌▍▎▏░▒▓]{5,}\s*\d{1,3}(\.\d+)?%`,
+	// Spinner-only lines
+	`^\s*[⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏|/\\-]\s*$`,
+	// docker pull layer status lines
+	`^[0-9a-f]{12}: (Pulling fs layer|Waiting|Downloading|Verifying Checksum|Download complete|Pull complete|Extracting|Already exists)`,
+	// BuildKit layer transfer lines
+	`^#\d+ sha256:[0-9a-f]{64}`,
+	`^#\d+ (extracting|resolve|DONE \d|CACHED)`,
+	// npm/yarn fetch progress
+	`^(npm|yarn) (http fetch|timing|sill|verb)\b`,
+}
+
+// noiseFilter strips ANSI escapes and drops lines matching noise patterns
+type noiseFilter struct {
+	stripANSI bool
+	patterns  []*regexp.Regexp
+}
+
+// newNoiseFilter compiles the default patterns (when enabled) plus any custom ones
+func newNoiseFilter(stripANSI, useDefaults bool, custom []string) (*noiseFilter, error) {
+	filter := &noiseFilter{stripANSI: stripANSI}
+
+	var sources []string
+	if useDefaults {
+		sources = append(sources, DefaultNoisePatterns...)
+	}
+	sources = append(sources, custom...)
+
+	for _, source := range sources {
+		pattern, err := regexp.Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid noise filter %q: %w", source, err)
+		}
+		filter.patterns = append(filter.patterns, pattern)
+	}
+
+	return filter, nil
+}
+
+// Apply cleans captured output line by line
+func (f *noiseFilter) Apply(text string) string {
+	if f.stripANSI {
+		text = stripANSI(text)
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		// Carriage returns redraw the line in place; only the final state was visible
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+
+		if f.isNoise(line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// isNoise reports whether line matches any noise pattern
+func (f *noiseFilter) isNoise(line string) bool {
+	for _, pattern := range f.patterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripANSI removes terminal escape sequences from text
+func stripANSI(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	input := "\x1b[31merror\x1b[0m: \x1b[1mbuild failed\x1b[0m\x1b]0;title\x07"
+	if got := stripANSI(input); got != "error: build failed" {
+		t.Errorf("stripANSI() = %q", got)
+	}
+}
+
+func TestNoiseFilter_Apply(t *testing.T) {
+	filter, err := newNoiseFilter(true, true, []string{`^DEBUG `})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	input := "Step 1\n" +
+		" 45% |█████     | 45/100\n" +
+		"a1b2c3d4e5f6: Pull complete\n" +
+		"Downloading 10%\rDownloading 100%\n" +
+		"DEBUG cache hit\n" +
+		"\x1b[31mmain.go:12: undefined: foo\x1b[0m"
+
+	expected := "Step 1\nDownloading 100%\nmain.go:12: undefined: foo"
+	if got := filter.Apply(input); got != expected {
+		t.Errorf("Apply() = %q, expected %q", got, expected)
+	}
+}
+
+func TestNoiseFilter_Disabled(t *testing.T) {
+	filter, err := newNoiseFilter(false, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	input := "\x1b[31m 45% |█████     |\x1b[0m"
+	if got := filter.Apply(input); got != input {
+		t.Errorf("expected input unchanged, got %q", got)
+	}
+}
+
+func TestNewNoiseFilter_InvalidPattern(t *testing.T) {
+	if _, err := newNoiseFilter(true, false, []string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,32 @@
+package orchestrator
+
+import (
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// AddObserver registers an observer for progress events from GeneratePrompt and OutputPrompt
+func (o *Orchestrator) AddObserver(observer interfaces.Observer) {
+	o.observers = append(o.observers, observer)
+}
+
+// templateResolved notifies observers that a template was rendered
+func (o *Orchestrator) templateResolved(event interfaces.TemplateEvent) {
+	for _, observer := range o.observers {
+		observer.OnTemplateResolved(event)
+	}
+}
+
+// fileIncluded notifies observers that a file was added to the prompt
+func (o *Orchestrator) fileIncluded(event interfaces.FileEvent) {
+	for _, observer := range o.observers {
+		observer.OnFileIncluded(event)
+	}
+}
+
+// outputWritten notifies observers that the prompt reached its target
+func (o *Orchestrator) outputWritten(target, prompt string) {
+	event := interfaces.OutputEvent{Target: target, Bytes: len(prompt), Tokens: estimateTokens(prompt)}
+	for _, observer := range o.observers {
+		observer.OnOutputWritten(event)
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// recordingObserver keeps every event it receives
+type recordingObserver struct {
+	templates []interfaces.TemplateEvent
+	files     []interfaces.FileEvent
+	outputs   []interfaces.OutputEvent
+}
+
+func (r *recordingObserver) OnTemplateResolved(event interfaces.TemplateEvent) {
+	r.templates = append(r.templates, event)
+}
+
+func (r *recordingObserver) OnFileIncluded(event interfaces.FileEvent) {
+	r.files = append(r.files, event)
+}
+
+func (r *recordingObserver) OnOutputWritten(event interfaces.OutputEvent) {
+	r.outputs = append(r.outputs, event)
+}
+
+func TestOrchestrator_Observers(t *testing.T) {
+	promptsDir := t.TempDir()
+	templatePath := filepath.Join(promptsDir, "pre", "review.md")
+	if err := os.MkdirAll(filepath.Dir(templatePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(templatePath, []byte("Review this change."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "prompt.md")
+
+	request := models.NewPromptRequest()
+	request.BasePrompt = "looks fine?"
+	request.PreTemplate = "review"
+	request.Files = []string{"main.go"}
+	request.Target = "file:" + target
+	cfg := &interfaces.Config{PromptsLocation: promptsDir}
+
+	o := New()
+	recorder := &recordingObserver{}
+	o.AddObserver(recorder)
+	o.AddObserver(interfaces.NopObserver{})
+
+	result, err := o.generateNormalPrompt(context.Background(), request, cfg)
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	prompt := result.Text()
+	if err := o.OutputPrompt(context.Background(), prompt, request, cfg); err != nil {
+		t.Fatalf("OutputPrompt() error = %v", err)
+	}
+
+	if len(recorder.templates) != 1 || recorder.templates[0].Path != templatePath || recorder.templates[0].Type != "pre" || recorder.templates[0].Tokens == 0 {
+		t.Errorf("template events = %+v, want review resolved to %s", recorder.templates, templatePath)
+	}
+	if len(recorder.files) != 1 || recorder.files[0].Path != "main.go" || recorder.files[0].Embedded {
+		t.Errorf("file events = %+v, want a main.go reference", recorder.files)
+	}
+	if len(recorder.outputs) != 1 || recorder.outputs[0].Target != request.Target || recorder.outputs[0].Bytes != len(prompt) {
+		t.Errorf("output events = %+v, want one write to %s", recorder.outputs, request.Target)
+	}
+}
@@ -2,28 +2,49 @@ package orchestrator
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/diagnostics"
+	"github.com/imdevan/prompter/internal/interactive"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
 	"golang.org/x/term"
-	"prompter-cli/internal/config"
-	"prompter-cli/internal/interfaces"
-	"prompter-cli/internal/template"
-	"prompter-cli/pkg/models"
 )
 
 // Orchestrator coordinates all components to generate prompts
 type Orchestrator struct {
-	configManager     interfaces.ConfigManager
-	templateProcessor interfaces.TemplateProcessor
-	outputHandler     interfaces.OutputHandler
+	configManager      interfaces.ConfigManager
+	templateProcessor  interfaces.TemplateProcessor
+	outputHandler      interfaces.OutputHandler
+	untrustedWorkspace bool                     // Project-local templates are ignored until the directory is trusted
+	observers          []interfaces.Observer    // Notified of progress; see AddObserver
+	vars               map[string]string        // .Vars, read once per run; see templateVars
+	redactor           *redactor                // Removes secrets from included content this run; nil when off
+	fixInfo            *interfaces.FixInfo      // Fix content captured this run, for templates' .Fix; nil outside fix mode
+	lastCapture        *interfaces.CaptureEntry // Fix content captured this run, for the capture log; see LastCapture
+	confirm            confirmFunc              // Asks yes/no questions; nil uses selectYesNo
+	warnings           *warnings.Channel        // Collects warnings to print after the output; see SetDiagnostics
+	progress           io.Writer                // Shows which commands run; see SetDiagnostics
 }
 
 // New creates a new orchestrator with all required components
@@ -32,32 +53,61 @@ func New() *Orchestrator {
 		configManager:     config.NewManager(),
 		templateProcessor: template.NewProcessor(""),
 		outputHandler:     NewOutputHandler(),
+		warnings:          warnings.Default,
+		progress:          os.Stderr,
 	}
 }
 
-// GeneratePrompt orchestrates the entire prompt generation process
-func (o *Orchestrator) GeneratePrompt(request *models.PromptRequest) (string, error) {
+// SetDiagnostics sends the warnings raised while assembling a prompt to channel, and progress
+// lines such as the commands being run to progress, instead of warnings.Default and stderr
+// (exported for embedders)
+func (o *Orchestrator) SetDiagnostics(channel *warnings.Channel, progress io.Writer) {
+	o.warnings, o.progress = channel, progress
+	if manager, ok := o.configManager.(*config.Manager); ok {
+		manager.SetWarnings(channel)
+	}
+	if processor, ok := o.templateProcessor.(*template.Processor); ok {
+		processor.SetWarnings(channel)
+	}
+}
+
+// GeneratePrompt orchestrates the entire prompt generation process. The result holds the
+// prompt's sections in order; its Text is the plain prompt. Commands and fetches it starts
+// are stopped when ctx is done, and it returns ctx's error.
+func (o *Orchestrator) GeneratePrompt(ctx context.Context, request *models.PromptRequest) (*models.Result, error) {
 	// Validate request first
 	if err := o.validateRequest(request); err != nil {
-		return "", RecoverFromError(err)
+		return nil, RecoverFromError(err)
 	}
 
 	// Load and resolve configuration
 	cfg, err := o.loadConfiguration(request.ConfigPath)
 	if err != nil {
 		configErr := NewConfigurationError("failed to load configuration", err)
-		return "", RecoverFromError(configErr)
+		return nil, RecoverFromError(configErr)
 	}
 
 	// Apply configuration defaults to request
-	o.applyConfigDefaults(request, cfg)
+	if err := o.applyConfigDefaults(request, cfg); err != nil {
+		configErr := NewConfigurationError("failed to resolve configuration defaults", err)
+		return nil, RecoverFromError(configErr)
+	}
+
+	// Secrets are removed from what the prompt includes unless redaction is turned off
+	if o.redactor, err = newRedactor(cfg.Redact && !request.NoRedact, cfg.RedactPatterns); err != nil {
+		return nil, RecoverFromError(NewConfigurationError("invalid redact_patterns", err))
+	}
+	defer o.redactor.report(o.warnings)
+	if processor, ok := o.templateProcessor.(*template.Processor); ok {
+		processor.SetFileIncluder(newTemplateIncluder(request, cfg, o.redactor, o.warnings))
+	}
 
 	// Detect and handle mode (normal vs fix)
 	if request.FixMode {
-		return o.generateFixModePrompt(request, cfg)
+		return o.generateFixModePrompt(ctx, request, cfg)
 	}
 
-	return o.generateNormalPrompt(request, cfg)
+	return o.generateNormalPrompt(ctx, request, cfg)
 }
 
 // LoadConfiguration loads and resolves configuration with precedence (exported for app layer)
@@ -65,6 +115,33 @@ func (o *Orchestrator) LoadConfiguration(configPath string) (*interfaces.Config,
 	return o.loadConfiguration(configPath)
 }
 
+// SetConfigOverrides sets config keys for this run from key=value assignments, taking
+// precedence over the config file and environment (exported for app layer)
+func (o *Orchestrator) SetConfigOverrides(assignments []string) error {
+	manager, ok := o.configManager.(*config.Manager)
+	if !ok {
+		return nil
+	}
+	for _, assignment := range assignments {
+		key, value, err := config.ParseOverride(assignment)
+		if err != nil {
+			return err
+		}
+		manager.SetFlag(key, value)
+	}
+	return nil
+}
+
+// ExplainConfig returns the resolved value of a config key and the layers that set it, highest
+// precedence first. Call it after LoadConfiguration (exported for app layer).
+func (o *Orchestrator) ExplainConfig(key string) (interface{}, []config.Layer, error) {
+	manager, ok := o.configManager.(*config.Manager)
+	if !ok {
+		return nil, nil, fmt.Errorf("config explain needs the built-in config manager")
+	}
+	return manager.Explain(key)
+}
+
 // GetTemplateProcessor returns the template processor (exported for app layer)
 func (o *Orchestrator) GetTemplateProcessor() interfaces.TemplateProcessor {
 	return o.templateProcessor
@@ -93,129 +170,556 @@ func (o *Orchestrator) loadConfiguration(configPath string) (*interfaces.Config,
 	if processor, ok := o.templateProcessor.(*template.Processor); ok {
 		processor.SetPromptsLocation(cfg.PromptsLocation)
 		processor.SetLocalPromptsFromConfig(cfg.LocalPromptsLocation)
+		if o.untrustedWorkspace {
+			processor.SetLocalPromptsLocation("")
+		}
 		processor.SetCustomTemplates(cfg.CustomTemplates)
+		processor.SetAgeIdentity(cfg.AgeIdentity)
+		processor.SetTemplateExec(cfg.AllowTemplateExec, cfg.TemplateExecTimeout)
 	}
 
 	return cfg, nil
 }
 
 // applyConfigDefaults applies configuration defaults to the request
-func (o *Orchestrator) applyConfigDefaults(request *models.PromptRequest, cfg *interfaces.Config) {
-	if request.PreTemplate == "" && cfg.DefaultPre != "" {
-		request.PreTemplate = cfg.DefaultPre
+func (o *Orchestrator) applyConfigDefaults(request *models.PromptRequest, cfg *interfaces.Config) error {
+	// Config defaults may reference template data, e.g. default_pre = "{{.Project.Type}}-style"
+	if err := o.interpolateConfigDefaults(request, cfg); err != nil {
+		return err
 	}
-	if request.PostTemplate == "" && cfg.DefaultPost != "" {
-		request.PostTemplate = cfg.DefaultPost
+
+	// Fix prompts have their own defaults, so a persona for questions doesn't wrap every fix
+	defaultPre, defaultPost := cfg.DefaultPre, cfg.DefaultPost
+	if request.FixMode {
+		defaultPre, defaultPost = cfg.FixDefaultPre, cfg.FixDefaultPost
+	}
+	if request.PreTemplate == "" && request.PreInline == "" && defaultPre != "" {
+		request.PreTemplate = defaultPre
+	}
+	if request.PostTemplate == "" && request.PostInline == "" && defaultPost != "" {
+		request.PostTemplate = defaultPost
 	}
 	if request.Target == "" && cfg.Target != "" {
 		request.Target = cfg.Target
 	}
+	if err := o.resolveTargetAlias(request, cfg); err != nil {
+		return err
+	}
+	if request.TokenBudget == 0 && cfg.TokenBudget > 0 {
+		request.TokenBudget = cfg.TokenBudget
+	}
 	// Don't set editor from config - only use when explicitly requested
 	// In fix mode, don't set fix file from config - let it read from stdin if not explicitly set
 	if !request.FixMode && request.FixFile == "" && cfg.FixFile != "" {
 		request.FixFile = cfg.FixFile
 	}
+	// A configured fix source applies when the command line doesn't name one
+	if request.FixMode && request.FixSource == "" && request.FixFile == "" && request.FixCommand == "" && len(request.FixTasks) == 0 && cfg.FixSource != "" {
+		request.FixSource = cfg.FixSource
+	}
+	// ...unless the shell hook is recording commands into it
+	if request.FixMode && request.FixSource == "" && request.FixFile == "" && request.FixCommand == "" && len(request.FixTasks) == 0 && o.hookFixFileReady(cfg.FixFile) {
+		request.FixFile = cfg.FixFile
+	}
+	// The file source reads fix_file when no --fix-file is given
+	if request.FixSource == FixSourceFile && request.FixFile == "" {
+		request.FixFile = cfg.FixFile
+	}
+
+	return nil
 }
 
-// generateNormalPrompt generates a prompt in normal mode
-func (o *Orchestrator) generateNormalPrompt(request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
-	var promptParts []string
+// ResolveTargetAlias expands a [targets] alias given with --target (exported for app layer)
+func (o *Orchestrator) ResolveTargetAlias(request *models.PromptRequest, cfg *interfaces.Config) error {
+	return o.resolveTargetAlias(request, cfg)
+}
 
-	// Process pre-template if specified
-	if request.PreTemplate != "" {
-		preContent, err := o.processTemplate(request.PreTemplate, request, cfg, "pre")
-		if err != nil {
-			templateErr := NewTemplateError(request.PreTemplate, err)
-			// Check if this is recoverable (template not found)
-			if IsRecoverableError(templateErr) {
-				// Log warning but continue without template
-				fmt.Fprintf(os.Stderr, "Warning: %s\n", templateErr.Error())
-			} else {
-				return "", RecoverFromError(templateErr)
+// resolveTargetAlias expands a [targets] alias in request.Target to its target spec
+func (o *Orchestrator) resolveTargetAlias(request *models.PromptRequest, cfg *interfaces.Config) error {
+	if request.Target == "" || config.IsTargetSpec(request.Target) {
+		return nil
+	}
+
+	spec, ok := cfg.Targets[strings.ToLower(request.Target)]
+	if !ok {
+		return NewValidationError("target", request.Target, "is not a built-in target or a [targets] alias")
+	}
+
+	// Alias specs may use template variables like target does
+	if strings.Contains(spec, "{{") {
+		if processor, ok := o.templateProcessor.(*template.Processor); ok {
+			rendered, err := processor.RenderString("targets."+request.Target, spec, *o.buildBaseTemplateData(request, cfg))
+			if err != nil {
+				return fmt.Errorf("failed to render target alias %s: %w", request.Target, err)
 			}
-		} else if preContent != "" {
-			promptParts = append(promptParts, preContent)
+			spec = strings.TrimSpace(rendered)
 		}
 	}
 
+	request.Target = spec
+	return nil
+}
+
+// interpolateConfigDefaults renders templated string defaults in cfg against the current context
+func (o *Orchestrator) interpolateConfigDefaults(request *models.PromptRequest, cfg *interfaces.Config) error {
+	fields := []struct {
+		key   string
+		value *string
+	}{
+		{"default_pre", &cfg.DefaultPre},
+		{"default_post", &cfg.DefaultPost},
+		{"fix_default_pre", &cfg.FixDefaultPre},
+		{"fix_default_post", &cfg.FixDefaultPost},
+		{"target", &cfg.Target},
+		{"fix_file", &cfg.FixFile},
+	}
+
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok {
+		return nil
+	}
+
+	var data *interfaces.TemplateData
+	for _, field := range fields {
+		if !strings.Contains(*field.value, "{{") {
+			continue
+		}
+
+		// Build the context lazily; most configs have no templated values
+		if data == nil {
+			data = o.buildBaseTemplateData(request, cfg)
+		}
+
+		rendered, err := processor.RenderString(field.key, *field.value, *data)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", field.key, err)
+		}
+		*field.value = strings.TrimSpace(rendered)
+	}
+
+	return nil
+}
+
+// generateNormalPrompt generates a prompt in normal mode
+func (o *Orchestrator) generateNormalPrompt(ctx context.Context, request *models.PromptRequest, cfg *interfaces.Config) (*models.Result, error) {
+	sections, err := o.templateSections(ctx, "pre", request, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// An active session's transcript comes before the prompt it continues
+	sections = append(sections, o.sessionSections(cfg)...)
+
 	// Add base prompt
 	if request.BasePrompt != "" {
-		promptParts = append(promptParts, request.BasePrompt)
+		sections = append(sections, promptSection{Class: SectionBase, Source: "prompt", Content: request.BasePrompt})
+	}
+
+	// Context packs add their files to --file and their commands' output as context
+	included := request
+	var packSections []promptSection
+	if len(request.ContextPacks) > 0 {
+		packFiles, sections, err := o.contextPackSections(ctx, request.ContextPacks, cfg)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		withPacks := *request
+		withPacks.Files = append(append([]string{}, request.Files...), packFiles...)
+		included, packSections = &withPacks, sections
+	}
+
+	// --grep adds the regions that match, or with --grep-files the files, to --file
+	var grepSections []promptSection
+	if len(request.GrepPatterns) > 0 {
+		section, files, err := o.grepSection(request, cfg)
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		if len(files) > 0 {
+			withGrep := *included
+			withGrep.Files = append(append([]string{}, included.Files...), files...)
+			included = &withGrep
+		}
+		if section.Content != "" {
+			grepSections = append(grepSections, section)
+		}
 	}
 
 	// Include file content
-	if len(request.Files) > 0 || request.Directory != "" {
-		contentPart := o.formatContent(request)
+	if len(included.Files) > 0 || included.Directory != "" {
+		contentPart := o.formatContent(ctx, included)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if contentPart != "" {
-			promptParts = append(promptParts, contentPart)
+			sections = append(sections, promptSection{Class: SectionFiles, Source: "files", Content: contentPart})
 		}
 	}
+	sections = append(sections, packSections...)
+	sections = append(sections, grepSections...)
 
-	// Process post-template if specified
-	if request.PostTemplate != "" {
-		postContent, err := o.processTemplate(request.PostTemplate, request, cfg, "post")
+	// Include single Go declarations rather than the files they are in
+	if len(request.Symbols) > 0 {
+		symbolSections, err := o.symbolSections(request, cfg)
 		if err != nil {
-			templateErr := NewTemplateError(request.PostTemplate, err)
-			// Check if this is recoverable (template not found)
-			if IsRecoverableError(templateErr) {
-				// Log warning but continue without template
-				fmt.Fprintf(os.Stderr, "Warning: %s\n", templateErr.Error())
-			} else {
-				return "", RecoverFromError(templateErr)
-			}
-		} else if postContent != "" {
-			promptParts = append(promptParts, postContent)
+			return nil, RecoverFromError(err)
+		}
+		sections = append(sections, symbolSections...)
+	}
+
+	// Include the current contents of files changed in the working tree
+	if request.FilesFromDiff {
+		section, ok, err := o.changedFilesSection(request, newEmbedPolicy(request, cfg))
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		if ok {
+			sections = append(sections, section)
+		}
+	}
+
+	// Outline the repository instead of embedding it
+	if request.RepoMap {
+		section, ok, err := o.repoMapSection(request, cfg)
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		if ok {
+			sections = append(sections, section)
+		}
+	}
+
+	// Include issues and pull requests as context
+	for _, ref := range request.GitHubRefs {
+		content, err := formatGitHubRef(ctx, ref, cfg.GitHubAPIURL, githubToken(cfg.GitHubToken))
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			o.warnings.Add("%v", err)
+		}
+		sections = append(sections, promptSection{Class: SectionContext, Source: "github:" + ref, Content: content})
+	}
+
+	// Include Jira tickets as context
+	for _, key := range request.JiraKeys {
+		content, err := formatJiraRef(ctx, strings.ToUpper(key), jiraSettings{
+			BaseURL:         cfg.JiraURL,
+			Email:           cfg.JiraEmail,
+			Token:           jiraToken(cfg.JiraToken),
+			AcceptanceField: cfg.JiraAcceptanceField,
+		})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			o.warnings.Add("%v", err)
+		}
+		sections = append(sections, promptSection{Class: SectionContext, Source: "jira:" + strings.ToUpper(key), Content: content})
+	}
+
+	// Include fetched pages as context; GitHub issue links go through the API for structure
+	for _, pageURL := range request.URLs {
+		var content string
+		var err error
+		if isGitHubIssueURL(pageURL) {
+			content, err = formatGitHubRef(ctx, pageURL, cfg.GitHubAPIURL, githubToken(cfg.GitHubToken))
+		} else {
+			content, err = formatURL(ctx, pageURL, cfg.URLMarkdown)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
+		if err != nil {
+			o.warnings.Add("%v", err)
+		}
+		sections = append(sections, promptSection{Class: SectionContext, Source: "url:" + pageURL, Content: content})
+	}
+
+	postSections, err := o.templateSections(ctx, "post", request, cfg)
+	if err != nil {
+		return nil, err
+	}
+	sections = append(sections, postSections...)
+
+	// Spell out the response format the caller expects
+	if request.SchemaFile != "" {
+		section, err := schemaSection(request.SchemaFile)
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		sections = append(sections, section)
 	}
 
-	return strings.Join(promptParts, "\n\n"), nil
+	sections = redactSections(sections, o.redactor)
+	sections = fitToBudget(sections, request.TokenBudget, cfg.BudgetWeights)
+	sections = wrapSections(sections, request.WrapWidth)
+	return newResult(sections), nil
 }
 
 // generateFixModePrompt generates a prompt in fix mode
-func (o *Orchestrator) generateFixModePrompt(request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
+func (o *Orchestrator) generateFixModePrompt(ctx context.Context, request *models.PromptRequest, cfg *interfaces.Config) (*models.Result, error) {
 	// Load fix content from file, re-run command, or stdin
-	fixContent, err := o.loadFixContent(request.FixFile, request.Interactive, request.NumberSelect)
+	fixInfo, err := o.loadFixContent(ctx, request, cfg)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
-		fixErr := NewFixModeError(request.FixFile, err)
-		return "", RecoverFromError(fixErr)
+		fixErr := NewFixModeError(fixSource(request), err)
+		return nil, RecoverFromError(fixErr)
 	}
 
-	var promptParts []string
+	o.lastCapture = newCaptureEntry(resolveFixSource(request), fixInfo)
+
+	// Templates see this capture as .Fix; loading it again would re-run its command
+	captured := fixInfo
+	captured.Tasks = slices.Clone(fixInfo.Tasks)
+	captured.Diagnostics = diagnostics.Parse(stripANSI(captured.Output))
+	o.fixInfo = &captured
+	defer func() { o.fixInfo = nil }()
+
+	// Strip escape sequences, known-noise lines, and secrets from the captured output
+	filter, err := newNoiseFilter(cfg.StripANSI, cfg.NoiseDefaultFilters, cfg.NoiseFilters)
+	if err != nil {
+		return nil, RecoverFromError(NewConfigurationError("invalid noise filter", err))
+	}
+	clean := func(text string) string {
+		return o.redactor.Apply(strings.TrimSpace(filter.Apply(text)))
+	}
+	fixContent := clean(fixInfo.Raw)
 
-	// Try to load fix.md from prompts_location root, fallback to "Please fix"
-	fixPrompt, err := o.loadFixPrompt(cfg.PromptsLocation)
+	// Load the named fix prompt from fix/, or fix.md from prompts_location root, fallback to "Please fix"
+	fixName := request.FixTemplate
+	fixPrompt, err := o.loadFixPrompt(cfg.PromptsLocation, fixName)
+	if err != nil && fixName != "" {
+		// Like a missing pre- or post-template, a missing named one warns and falls back to fix.md
+		o.warnings.Add("%v, using fix.md", err)
+		fixName = ""
+		fixPrompt, err = o.loadFixPrompt(cfg.PromptsLocation, "")
+	}
 	if err != nil {
 		// Fallback to default "Please fix" prompt
 		fixPrompt = "Please fix"
 	}
-	
+	fixPromptSource := fixPromptPath(fixName)
+
+	// Let the user trim noise from the captured output before it is included
+	if request.Interactive && cfg.FixReview {
+		reviewed, err := o.reviewFixContent(fixContent)
+		if err != nil {
+			fixErr := NewFixModeError(fixSource(request), err)
+			if interactive.IsCancelled(err) {
+				// Keep the untrimmed prompt so the caller can offer it instead of discarding the capture
+				fixErr.Partial = joinSections([]promptSection{
+					{Class: SectionBase, Source: fixPromptSource, Content: fixPrompt},
+					{Class: SectionFix, Source: "fix", Content: fixContent},
+				})
+			}
+			return nil, RecoverFromError(fixErr)
+		}
+		fixContent = reviewed
+	}
+
+	// Pre- and post-templates wrap the fix prompt, from the flags or fix_default_pre/post
+	sections, err := o.templateSections(ctx, "pre", request, cfg)
+	if err != nil {
+		return nil, err
+	}
+	sections = append(sections, o.sessionSections(cfg)...)
+
+	// The fix prompt is a template that sees the capture as .Fix
+	fixInfo.Diagnostics = diagnostics.Parse(fixContent)
+	fixInfo.Raw = fixContent
+	fixInfo.Command = o.redactor.Apply(fixInfo.Command)
+	fixInfo.Output = clean(fixInfo.Output)
+	fixInfo.Stdout = clean(fixInfo.Stdout)
+	fixInfo.Stderr = clean(fixInfo.Stderr)
+	for i, task := range fixInfo.Tasks {
+		fixInfo.Tasks[i].Command = o.redactor.Apply(task.Command)
+		fixInfo.Tasks[i].Output = clean(task.Output)
+		fixInfo.Tasks[i].Stdout = clean(task.Stdout)
+		fixInfo.Tasks[i].Stderr = clean(task.Stderr)
+	}
+	fixPrompt, formatsCapture, err := o.renderFixPrompt(fixPromptSource, fixPrompt, fixInfo, request, cfg)
+	if err != nil {
+		return nil, RecoverFromError(NewTemplateError(fixPromptSource, err))
+	}
+
 	// Add the fix prompt
-	promptParts = append(promptParts, fixPrompt)
+	sections = append(sections, promptSection{Class: SectionBase, Source: fixPromptSource, Content: fixPrompt})
 
-	// Add the captured content (command + output) as a separate part
-	promptParts = append(promptParts, fixContent)
+	// Add the captured content (command + output) as a separate part, unless the prompt laid it out
+	if !formatsCapture {
+		sections = append(sections, promptSection{Class: SectionFix, Source: "fix", Content: fixContent})
+	}
 
-	return strings.Join(promptParts, "\n\n"), nil
+	// Attach the workspace files named by compiler, test, and linter errors
+	if !request.NoFixFiles {
+		cwd, err := WorkspaceDir()
+		if err != nil {
+			return nil, err
+		}
+		referenced := referencedFiles(fixContent, fixInfo.Diagnostics)
+		references, included := formatDiagnosticFiles(referenced, cwd, detectProject(cwd).Root, cfg.FixEmbedFiles, newEmbedPolicy(request, cfg))
+		if references != "" {
+			sections = append(sections, promptSection{Class: SectionFiles, Source: "fix-files", Content: references})
+		}
+		for _, event := range included {
+			o.fileIncluded(event)
+		}
+		reportSkipped(request, included)
+	}
+
+	postSections, err := o.templateSections(ctx, "post", request, cfg)
+	if err != nil {
+		return nil, err
+	}
+	sections = append(sections, postSections...)
+
+	// Spell out the response format the caller expects
+	if request.SchemaFile != "" {
+		section, err := schemaSection(request.SchemaFile)
+		if err != nil {
+			return nil, RecoverFromError(err)
+		}
+		sections = append(sections, section)
+	}
+
+	sections = redactSections(sections, o.redactor)
+	sections = fitToBudget(sections, request.TokenBudget, cfg.BudgetWeights)
+	sections = wrapSections(sections, request.WrapWidth)
+	return newResult(sections), nil
+}
+
+// fixCaptureField matches the .Fix fields holding captured output. A fix prompt that uses one
+// places the output itself.
+var fixCaptureField = regexp.MustCompile(`\.Fix\.(Raw|Output|Stdout|Stderr)\b`)
+
+// renderFixPrompt executes the fix prompt text as a template with fix as .Fix. It also reports
+// whether the prompt includes the captured output, so it isn't appended a second time.
+func (o *Orchestrator) renderFixPrompt(name, text string, fix interfaces.FixInfo, request *models.PromptRequest, cfg *interfaces.Config) (string, bool, error) {
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok {
+		return text, false, nil
+	}
+	data := o.buildBaseTemplateData(request, cfg)
+	fix.Enabled = true
+	data.Fix = fix
+	rendered, err := processor.RenderString(name, text, *data)
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(rendered), fixCaptureField.MatchString(text), nil
+}
+
+// fixSource describes where fix content came from for error messages
+func fixSource(request *models.PromptRequest) string {
+	switch name := resolveFixSource(request); name {
+	case FixSourceCommand:
+		return request.FixCommand
+	case FixSourceTasks:
+		return strings.Join(request.FixTasks, ", ")
+	case FixSourceFile:
+		return request.FixFile
+	case FixSourceRerun:
+		return ""
+	default:
+		return name
+	}
+}
+
+// templateSections renders the pre- or post-template of request (templateType), or its
+// inline text. A template that isn't found is skipped with a warning.
+func (o *Orchestrator) templateSections(ctx context.Context, templateType string, request *models.PromptRequest, cfg *interfaces.Config) ([]promptSection, error) {
+	name, inline := request.PreTemplate, request.PreInline
+	if templateType == "post" {
+		name, inline = request.PostTemplate, request.PostInline
+	}
+
+	switch {
+	case name != "":
+		content, err := o.processTemplate(ctx, name, request, cfg, templateType)
+		if err != nil {
+			templateErr := NewTemplateError(name, err)
+			// Check if this is recoverable (template not found)
+			if IsRecoverableError(templateErr) {
+				// Log warning but continue without template
+				o.warnings.Add("%s", templateErr.Error())
+				return nil, nil
+			}
+			return nil, RecoverFromError(templateErr)
+		}
+		if content != "" {
+			return []promptSection{{Class: SectionBase, Source: templateType + ":" + name, Content: content}}, nil
+		}
+	case inline != "":
+		content, err := o.renderInline(ctx, templateType+"-inline", inline, request, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []promptSection{{Class: SectionBase, Source: templateType + ":inline", Content: content}}, nil
+	}
+	return nil, nil
+}
+
+// renderInline renders template text given with --pre-inline or --post-inline, so it can use
+// the same data as template files
+func (o *Orchestrator) renderInline(ctx context.Context, name, text string, request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok {
+		return text, nil
+	}
+	data, err := o.buildTemplateData(ctx, request, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to build template data: %w", err)
+	}
+	rendered, err := processor.RenderString(name, text, *data)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return rendered, nil
 }
 
 // processTemplate processes a template with the current context
-func (o *Orchestrator) processTemplate(templateName string, request *models.PromptRequest, cfg *interfaces.Config, templateType string) (string, error) {
+func (o *Orchestrator) processTemplate(ctx context.Context, templateName string, request *models.PromptRequest, cfg *interfaces.Config, templateType string) (string, error) {
 	// Update template processor with prompts location
 	if processor, ok := o.templateProcessor.(*template.Processor); ok {
 		processor.SetPromptsLocation(cfg.PromptsLocation)
 		processor.SetLocalPromptsFromConfig(cfg.LocalPromptsLocation)
+		if o.untrustedWorkspace {
+			processor.SetLocalPromptsLocation("")
+		}
 		processor.SetCustomTemplates(cfg.CustomTemplates)
+		processor.SetAgeIdentity(cfg.AgeIdentity)
+		processor.SetTemplateExec(cfg.AllowTemplateExec, cfg.TemplateExecTimeout)
+	}
+
+	// A template group stands for one of its members, picked each run
+	loadName := templateName
+	member, isGroup, err := o.resolveTemplateGroup(templateName, templateType, cfg)
+	if err != nil {
+		return "", err
+	}
+	if isGroup {
+		fmt.Fprintf(o.progress, "Template group %s: using %s\n", templateName, member.Ref)
+		loadName, templateName = member.Path, member.Ref
 	}
 
 	// Load template using the template processor's discovery mechanism
 	// The processor will find the correct file (including .default. files)
-	tmpl, err := o.templateProcessor.LoadTemplate(templateName)
+	tmpl, err := o.templateProcessor.LoadTemplate(loadName)
 	if err != nil {
 		return "", fmt.Errorf("failed to load template %s: %w", templateName, err)
 	}
+	if tmpl, templateName, err = o.followDeprecation(tmpl, templateName, cfg); err != nil {
+		return "", err
+	}
 
 	// Build template data
-	templateData, err := o.buildTemplateData(request, cfg)
+	templateData, err := o.buildTemplateData(ctx, request, cfg)
 	if err != nil {
 		return "", fmt.Errorf("failed to build template data: %w", err)
 	}
@@ -226,19 +730,54 @@ func (o *Orchestrator) processTemplate(templateName string, request *models.Prom
 		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
 	}
 
+	// Enforce the template's own max_tokens cap
+	event := interfaces.TemplateEvent{Name: templateName, Type: templateType, Tokens: estimateTokens(result)}
+	if processor, ok := o.templateProcessor.(*template.Processor); ok {
+		if err := o.checkTemplateTokens(templateName, result, processor.TemplateFrontMatter(tmpl).MaxTokens, cfg.TemplateTokenLimit); err != nil {
+			return "", err
+		}
+		event.Path = processor.TemplatePath(tmpl)
+	}
+	o.templateResolved(event)
+
 	return result, nil
 }
 
 // formatContent formats files and directory for inclusion in the prompt
-func (o *Orchestrator) formatContent(request *models.PromptRequest) string {
+func (o *Orchestrator) formatContent(ctx context.Context, request *models.PromptRequest) string {
 	var parts []string
 
-	// Add file references
-	if len(request.Files) > 0 {
+	// Add file references; remote files are fetched since the reader can't open them
+	var localFiles, remoteFiles []string
+	for _, file := range request.Files {
+		if isRemoteFile(file) {
+			remoteFiles = append(remoteFiles, file)
+		} else {
+			localFiles = append(localFiles, file)
+		}
+	}
+
+	if len(localFiles) > 0 {
 		parts = append(parts, "Referencing files:")
-		for _, file := range request.Files {
-			parts = append(parts, file)
+		parts = append(parts, localFiles...)
+		for _, file := range localFiles {
+			o.fileIncluded(interfaces.FileEvent{Path: file})
+		}
+	}
+
+	for _, file := range remoteFiles {
+		content, err := fetchRemoteFile(ctx, file)
+		if ctx.Err() != nil {
+			return ""
 		}
+		if err != nil {
+			o.warnings.Add("%v", err)
+			parts = append(parts, "Referencing remote file:", file)
+			o.fileIncluded(interfaces.FileEvent{Path: file})
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("Remote file %s:\n```\n%s\n```", file, strings.TrimRight(content, "\n")))
+		o.fileIncluded(interfaces.FileEvent{Path: file, Embedded: true, Bytes: len(content)})
 	}
 
 	// Add directory reference using current working directory
@@ -258,13 +797,26 @@ func (o *Orchestrator) formatContent(request *models.PromptRequest) string {
 				parts = append(parts, request.Directory)
 			}
 		}
+		o.fileIncluded(interfaces.FileEvent{Path: parts[len(parts)-1]})
 	}
 
 	return strings.Join(parts, "\n")
 }
 
 // buildTemplateData builds the template data context
-func (o *Orchestrator) buildTemplateData(request *models.PromptRequest, cfg *interfaces.Config) (*interfaces.TemplateData, error) {
+func (o *Orchestrator) buildTemplateData(ctx context.Context, request *models.PromptRequest, cfg *interfaces.Config) (*interfaces.TemplateData, error) {
+	data := o.buildBaseTemplateData(request, cfg)
+
+	// Fix content is captured once, by generateFixModePrompt
+	if request.FixMode && o.fixInfo != nil {
+		data.Fix = *o.fixInfo
+	}
+
+	return data, nil
+}
+
+// buildBaseTemplateData builds the template context that doesn't depend on captured fix content
+func (o *Orchestrator) buildBaseTemplateData(request *models.PromptRequest, cfg *interfaces.Config) *interfaces.TemplateData {
 	cwd, _ := os.Getwd()
 
 	// Build environment map
@@ -276,8 +828,11 @@ func (o *Orchestrator) buildTemplateData(request *models.PromptRequest, cfg *int
 		}
 	}
 
-	// Build config map
-	configMap := map[string]interface{}{
+	// Build config map: every key in the config, custom ones included, with the values this
+	// run settled on (expanded paths, rendered defaults) for the ones prompter uses most
+	configMap := make(map[string]interface{}, len(cfg.Settings))
+	maps.Copy(configMap, cfg.Settings)
+	maps.Copy(configMap, map[string]interface{}{
 		"prompts_location":   cfg.PromptsLocation,
 		"editor":             cfg.Editor,
 		"default_pre":        cfg.DefaultPre,
@@ -285,85 +840,131 @@ func (o *Orchestrator) buildTemplateData(request *models.PromptRequest, cfg *int
 		"fix_file":           cfg.FixFile,
 		"directory_strategy": cfg.DirectoryStrategy,
 		"target":             cfg.Target,
-	}
+	})
 
 	// Build git info
 	gitInfo := o.buildGitInfo()
 
-	// Build fix info
-	fixInfo := interfaces.FixInfo{
-		Enabled: request.FixMode,
-	}
-	if request.FixMode && request.FixFile != "" {
-		if content, err := o.loadFixContent(request.FixFile, request.Interactive, request.NumberSelect); err == nil {
-			fixInfo.Raw = content
-			// Try to parse command and output (simple implementation)
-			lines := strings.Split(content, "\n")
-			if len(lines) > 0 {
-				fixInfo.Command = lines[0]
-				if len(lines) > 1 {
-					fixInfo.Output = strings.Join(lines[1:], "\n")
-				}
-			}
+	return &interfaces.TemplateData{
+		Prompt:  request.BasePrompt,
+		Now:     time.Now(),
+		CWD:     cwd,
+		Files:   o.redactFiles(buildFileInfo(request.Files, cwd, newEmbedPolicy(request, cfg))),
+		Git:     gitInfo,
+		Project: detectProject(cwd),
+		Config:  configMap,
+		Env:     envMap,
+		Vars:    o.templateVars(cfg),
+		Fix: interfaces.FixInfo{
+			Enabled: request.FixMode,
+		},
+	}
+}
+
+// buildFileInfo reads the local --file arguments for templates' .Files. Remote files are
+// left out, as are files that can't be read and files policy skips. Files over
+// maxEmbeddedFileBytes are left out too unless policy samples them, which gives them an
+// excerpt as their content.
+func buildFileInfo(files []string, cwd string, policy embedPolicy) []interfaces.FileInfo {
+	var local, paths []string
+	for _, file := range files {
+		if isRemoteFile(file) {
+			continue
+		}
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
 		}
+		local = append(local, file)
+		paths = append(paths, path)
 	}
 
-	return &interfaces.TemplateData{
-		Prompt: request.BasePrompt,
-		Now:    time.Now(),
-		CWD:    cwd,
-		Files:  []interfaces.FileInfo{}, // No longer used
-		Git:    gitInfo,
-		Config: configMap,
-		Env:    envMap,
-		Fix:    fixInfo,
-	}, nil
+	infos := []interfaces.FileInfo{}
+	for i, read := range readFiles(paths, policy.Sample, fileReadWorkers) {
+		if read.Info == nil || read.Err != nil || (read.Large && !policy.Sample) || policy.skipReason(paths[i], read.Content) != "" {
+			continue
+		}
+
+		file, path := local[i], paths[i]
+		relPath := file
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			relPath = rel
+		}
+		infos = append(infos, interfaces.FileInfo{
+			Path:     path,
+			RelPath:  relPath,
+			Language: strings.TrimPrefix(filepath.Ext(file), "."),
+			Content:  strings.TrimRight(read.Content, "\n"),
+			Sampled:  read.Large,
+		})
+	}
+	return infos
 }
 
-// buildGitInfo builds git repository information
+// buildGitInfo builds git repository information for the current directory
 func (o *Orchestrator) buildGitInfo() interfaces.GitInfo {
-	gitInfo := interfaces.GitInfo{}
+	return readGitInfo(".")
+}
 
-	// This is a simple implementation - in a real scenario we'd use git libraries
-	// For now, we'll just try to detect if we're in a git repo
-	if _, err := os.Stat(".git"); err == nil {
-		if cwd, err := os.Getwd(); err == nil {
-			gitInfo.Root = cwd
+// loadFixContent loads fix content from the source chosen by resolveFixSource
+func (o *Orchestrator) loadFixContent(ctx context.Context, request *models.PromptRequest, cfg *interfaces.Config) (interfaces.FixInfo, error) {
+	// Output handed over directly needs no capture; a leading "$ command" line names its command
+	if content := strings.TrimSpace(request.FixContent); content != "" {
+		if strings.HasPrefix(content, "$ ") {
+			return parseFixContent(content), nil
 		}
-		// TODO: Implement proper git info extraction
-		gitInfo.Branch = "main" // Default
-		gitInfo.Commit = "unknown"
-		gitInfo.Dirty = false
+		return interfaces.FixInfo{Enabled: true, Raw: content, Output: content}, nil
 	}
 
-	return gitInfo
+	name := resolveFixSource(request)
+	provider, ok := o.captureProviders()[name]
+	if !ok {
+		return interfaces.FixInfo{}, fmt.Errorf("unknown fix source %q", name)
+	}
+	tasks, err := resolveTasks(request.FixTasks, cfg.Tasks)
+	if err != nil {
+		return interfaces.FixInfo{}, err
+	}
+
+	return provider.Capture(interfaces.CaptureRequest{
+		Context:      ctx,
+		Command:      request.FixCommand,
+		Tasks:        tasks,
+		File:         request.FixFile,
+		ScriptFile:   cfg.ScriptFile,
+		StateFile:    cfg.StateFile,
+		Lines:        defaultCaptureLines,
+		Interactive:  request.Interactive,
+		NumberSelect: request.NumberSelect,
+	})
 }
 
-// loadFixContent loads content from the fix file, re-runs last command, or reads from stdin
-func (o *Orchestrator) loadFixContent(fixFile string, interactive bool, numberSelect bool) (string, error) {
-	if fixFile != "" {
-		// Read from specified file
-		content, err := os.ReadFile(fixFile)
-		if err != nil {
-			return "", err // Let the caller wrap this with appropriate error type
-		}
+// exitCodeTrailer matches the "# Exit code: N" line that ends a capture
+var exitCodeTrailer = regexp.MustCompile(`(?:^|\n)# Exit code: (-?\d+)[^\n]*$`)
 
-		trimmedContent := strings.TrimSpace(string(content))
-		if trimmedContent == "" {
-			return "", fmt.Errorf("fix file is empty")
-		}
+// parseFixContent splits raw captured content into command and output
+func parseFixContent(content string) interfaces.FixInfo {
+	fixInfo := interfaces.FixInfo{
+		Enabled: true,
+		Raw:     content,
+	}
 
-		return trimmedContent, nil
+	// Try to parse command and output (simple implementation)
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 {
+		fixInfo.Command = strings.TrimPrefix(lines[0], "$ ")
+		if len(lines) > 1 {
+			fixInfo.Output = strings.TrimLeft(strings.Join(lines[1:], "\n"), "\n")
+		}
 	}
 
-	// No fix file specified - try to re-run the last command
-	if interactive {
-		// Interactive mode: prompt user to re-run last command
-		return o.promptAndRerunLastCommand(numberSelect)
-	} else {
-		// Non-interactive mode: automatically re-run last command
-		return o.rerunLastCommand()
+	// Captures written by prompter or the shell hook end with the exit code
+	if match := exitCodeTrailer.FindStringSubmatchIndex(fixInfo.Output); match != nil {
+		fixInfo.ExitCode, _ = strconv.Atoi(fixInfo.Output[match[2]:match[3]])
+		fixInfo.Output = strings.TrimSpace(fixInfo.Output[:match[0]])
 	}
+
+	return fixInfo
 }
 
 // readFromStdin reads all content from stdin
@@ -396,43 +997,33 @@ Current fallback captured recent shell history only.`)
 
 // tryAdvancedTerminalCapture attempts advanced terminal output capture
 func (o *Orchestrator) tryAdvancedTerminalCapture() (string, error) {
-	// This would require terminal-specific implementations
-	// For now, return error to fall back to shell history
-	return "", fmt.Errorf("advanced terminal capture not available")
+	// Only tmux exposes scrollback to other processes
+	fixInfo, err := captureTmuxPane(defaultCaptureLines)
+	if err != nil {
+		return "", err
+	}
+	return fixInfo.Raw, nil
 }
 
 // tryShellHistory attempts to get recent commands and their context
 func (o *Orchestrator) tryShellHistory() (string, error) {
 	// Try to read recent shell history
-	homeDir, err := os.UserHomeDir()
+	source, err := findHistorySource()
 	if err != nil {
 		return "", err
 	}
 
-	// Check for zsh history
-	historyFile := filepath.Join(homeDir, ".zsh_history")
-	if _, err := os.Stat(historyFile); err == nil {
-		return o.readRecentHistory(historyFile, "zsh")
-	}
-
-	// Check for bash history
-	historyFile = filepath.Join(homeDir, ".bash_history")
-	if _, err := os.Stat(historyFile); err == nil {
-		return o.readRecentHistory(historyFile, "bash")
-	}
-
-	return "", fmt.Errorf("no shell history found")
+	return o.readRecentHistory(source)
 }
 
 // readRecentHistory reads recent commands from shell history
-func (o *Orchestrator) readRecentHistory(historyFile, shell string) (string, error) {
-	content, err := os.ReadFile(historyFile)
+func (o *Orchestrator) readRecentHistory(source historySource) (string, error) {
+	commands, err := readHistoryCommands(source)
 	if err != nil {
 		return "", err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	if len(lines) < 2 {
+	if len(commands) < 2 {
 		return "", fmt.Errorf("insufficient history")
 	}
 
@@ -440,19 +1031,8 @@ func (o *Orchestrator) readRecentHistory(historyFile, shell string) (string, err
 	var recentLines []string
 
 	// Work backwards through history to find recent commands
-	for i := len(lines) - 1; i >= 0 && len(recentLines) < 5; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-
-		// For zsh, remove timestamp if present
-		if shell == "zsh" && strings.Contains(line, ":") {
-			parts := strings.SplitN(line, ";", 2)
-			if len(parts) == 2 {
-				line = parts[1]
-			}
-		}
+	for i := len(commands) - 1; i >= 0 && len(recentLines) < 5; i-- {
+		line := commands[i]
 
 		// Skip the current prompter command to avoid recursion
 		if strings.Contains(line, "prompter") && strings.Contains(line, "--fix") {
@@ -473,127 +1053,261 @@ func (o *Orchestrator) readRecentHistory(historyFile, shell string) (string, err
 	return result, nil
 }
 
-// promptAndRerunLastCommand prompts user to re-run the last command and captures output
-func (o *Orchestrator) promptAndRerunLastCommand(numberSelect bool) (string, error) {
-	// Get the last command from history
-	lastCmd, err := o.getLastCommand()
+// recentCommandCount is how many history entries are offered when picking a command to re-run.
+// Nine keeps every entry reachable with a single number key.
+const recentCommandCount = 9
+
+// cancelRerunOption ends the re-run selection without running anything
+const cancelRerunOption = "Cancel"
+
+// promptAndRerunLastCommand lets the user pick a recent command to re-run and captures its output
+func (o *Orchestrator) promptAndRerunLastCommand(ctx context.Context, numberSelect bool) (interfaces.FixInfo, error) {
+	// Get recent commands from history
+	commands, shell, err := o.getRecentCommands(recentCommandCount)
 	if err != nil {
-		return "", fmt.Errorf("failed to get last command: %w", err)
+		return interfaces.FixInfo{}, fmt.Errorf("failed to get last command: %w", err)
+	}
+
+	var selected string
+	if len(commands) == 1 {
+		// Prompt user to confirm re-running the only candidate
+		confirmed, err := o.selectYesNo(
+			fmt.Sprintf("Re-run last command to capture output?\n  $ %s", commands[0]),
+			"This will execute the command and capture its output for fixing",
+			true, // default to Yes
+			numberSelect,
+		)
+		if err != nil {
+			return interfaces.FixInfo{}, fmt.Errorf("failed to get user confirmation: %w", err)
+		}
+		if !confirmed {
+			return interfaces.FixInfo{}, fmt.Errorf("user declined to re-run command")
+		}
+		selected = commands[0]
+	} else {
+		selected, err = o.selectRecentCommand(commands, numberSelect)
+		if err != nil {
+			return interfaces.FixInfo{}, err
+		}
 	}
 
-	// Prompt user to confirm re-running the command
-	confirmed, err := o.selectYesNo(
-		fmt.Sprintf("Re-run last command to capture output?\n  $ %s", lastCmd),
-		"This will execute the command and capture its output for fixing",
-		true, // default to Yes
+	// Execute the command and capture output
+	return o.executeAndCaptureCommand(ctx, selected, shell)
+}
+
+// selectRecentCommand shows recent commands (most recent first) and returns the chosen one
+func (o *Orchestrator) selectRecentCommand(commands []string, numberSelect bool) (string, error) {
+	// Map single-line labels back to the full command
+	labels := make(map[string]string)
+	var options []string
+	for _, command := range commands {
+		label := "$ " + commandLabel(command)
+		if _, exists := labels[label]; exists {
+			continue
+		}
+		labels[label] = command
+		options = append(options, label)
+	}
+	options = append(options, cancelRerunOption)
+
+	prompter := interactive.NewPrompter("")
+	selected, err := prompter.SelectOption(
+		options,
+		"Select a command to re-run and capture:",
+		"The selected command will be executed and its output captured for fixing",
 		numberSelect,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user confirmation: %w", err)
+		return "", fmt.Errorf("failed to select command: %w", err)
 	}
 
-	if !confirmed {
+	if selected == cancelRerunOption {
 		return "", fmt.Errorf("user declined to re-run command")
 	}
 
-	// Execute the command and capture output
-	return o.executeAndCaptureCommand(lastCmd)
+	return labels[selected], nil
+}
+
+// commandLabel shortens a command to a single display line of at most 80 characters
+func commandLabel(command string) string {
+	label := command
+	if i := strings.Index(label, "\n"); i >= 0 {
+		label = label[:i] + " ..."
+	}
+	if runes := []rune(label); len(runes) > 80 {
+		label = string(runes[:77]) + "..."
+	}
+	return label
 }
 
 // rerunLastCommand automatically re-runs the last command (non-interactive mode)
-func (o *Orchestrator) rerunLastCommand() (string, error) {
+func (o *Orchestrator) rerunLastCommand(ctx context.Context) (interfaces.FixInfo, error) {
 	// Get the last command from history
-	lastCmd, err := o.getLastCommand()
+	lastCmd, shell, err := o.getLastCommand()
 	if err != nil {
-		return "", fmt.Errorf("failed to get last command: %w", err)
+		return interfaces.FixInfo{}, fmt.Errorf("failed to get last command: %w", err)
 	}
 
 	fmt.Printf("Re-running last command: %s\n", lastCmd)
 
 	// Execute the command and capture output
-	return o.executeAndCaptureCommand(lastCmd)
+	return o.executeAndCaptureCommand(ctx, lastCmd, shell)
 }
 
-// getLastCommand retrieves the last command from shell history
-func (o *Orchestrator) getLastCommand() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// getLastCommand retrieves the last command from shell history along with the shell it came from
+func (o *Orchestrator) getLastCommand() (string, string, error) {
+	source, err := findHistorySource()
 	if err != nil {
-		return "", err
-	}
-
-	// Check for zsh history first
-	historyFile := filepath.Join(homeDir, ".zsh_history")
-	if _, err := os.Stat(historyFile); err == nil {
-		return o.getLastCommandFromHistory(historyFile, "zsh")
+		return "", "", err
 	}
 
-	// Check for bash history
-	historyFile = filepath.Join(homeDir, ".bash_history")
-	if _, err := os.Stat(historyFile); err == nil {
-		return o.getLastCommandFromHistory(historyFile, "bash")
+	command, err := o.getLastCommandFromHistory(source)
+	if err != nil {
+		return "", "", err
 	}
 
-	return "", fmt.Errorf("no shell history found")
+	return command, source.Shell, nil
 }
 
 // getLastCommandFromHistory extracts the last command from a history file
-func (o *Orchestrator) getLastCommandFromHistory(historyFile, shell string) (string, error) {
-	content, err := os.ReadFile(historyFile)
+func (o *Orchestrator) getLastCommandFromHistory(source historySource) (string, error) {
+	commands, err := o.getRecentCommandsFromHistory(source, 1)
 	if err != nil {
 		return "", err
 	}
 
-	lines := strings.Split(string(content), "\n")
+	return commands[0], nil
+}
 
-	// Work backwards to find the last non-prompter command
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
+// getRecentCommands retrieves up to n recent commands (most recent first) and the shell they came from
+func (o *Orchestrator) getRecentCommands(n int) ([]string, string, error) {
+	source, err := findHistorySource()
+	if err != nil {
+		return nil, "", err
+	}
 
-		// For zsh, remove timestamp if present
-		if shell == "zsh" && strings.Contains(line, ":") {
-			parts := strings.SplitN(line, ";", 2)
-			if len(parts) == 2 {
-				line = parts[1]
-			}
-		}
+	commands, err := o.getRecentCommandsFromHistory(source, n)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return commands, source.Shell, nil
+}
+
+// getRecentCommandsFromHistory returns up to n distinct recent commands, most recent first
+func (o *Orchestrator) getRecentCommandsFromHistory(source historySource, n int) ([]string, error) {
+	commands, err := readHistoryCommands(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []string
+	seen := make(map[string]bool)
+
+	// Work backwards to find recent non-prompter commands
+	for i := len(commands) - 1; i >= 0 && len(recent) < n; i-- {
+		line := commands[i]
 
 		// Skip prompter commands to avoid recursion
 		if strings.Contains(line, "prompter") {
 			continue
 		}
 
-		// Skip empty lines and comments
-		if line != "" && !strings.HasPrefix(line, "#") {
-			return line, nil
+		// Skip comments and repeats
+		if strings.HasPrefix(line, "#") || seen[line] {
+			continue
 		}
+
+		seen[line] = true
+		recent = append(recent, line)
 	}
 
-	return "", fmt.Errorf("no suitable command found in history")
-}
+	if len(recent) == 0 {
+		return nil, fmt.Errorf("no suitable command found in history")
+	}
 
-// executeAndCaptureCommand executes a command and captures both stdout and stderr
-func (o *Orchestrator) executeAndCaptureCommand(command string) (string, error) {
-	// Execute the command using the shell
-	cmd := exec.Command("sh", "-c", command)
+	return recent, nil
+}
 
-	// Capture both stdout and stderr
-	output, _ := cmd.CombinedOutput()
+// commandWaitDelay is how long a stopped command's output is read for before giving up on it
+const commandWaitDelay = 2 * time.Second
+
+// executeAndCaptureCommand executes a command and captures both stdout and stderr. The command
+// is killed when ctx is done, which is an error rather than a failing exit.
+func (o *Orchestrator) executeAndCaptureCommand(ctx context.Context, command, shell string) (interfaces.FixInfo, error) {
+	// Execute the command using the shell it was recorded in
+	cmd := shellCommand(ctx, shell, command)
+	stopProcessGroup(cmd)
+	// Children that outlive a stopped command may hold its output open; stop waiting for them
+	cmd.WaitDelay = commandWaitDelay
+
+	// Capture stdout and stderr separately, plus interleaved as the user would see them
+	var stdout, stderr strings.Builder
+	combined := &lockedBuffer{}
+	cmd.Stdout = io.MultiWriter(&stdout, combined)
+	cmd.Stderr = io.MultiWriter(&stderr, combined)
+
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+	output := combined.String()
+	if ctx.Err() != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("stopped %q after %s: %w", command, duration.Round(time.Millisecond), ctx.Err())
+	}
+
+	// A non-zero exit is the expected case in fix mode; only failing to start is an error
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return interfaces.FixInfo{}, fmt.Errorf("failed to run command %q: %w", command, err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
 
 	// Format the result with command and output separated by a blank line
 	var result strings.Builder
 	result.WriteString("$ ")
 	result.WriteString(command)
 	result.WriteString("\n\n")
-	result.Write(output)
+	result.WriteString(strings.TrimSpace(output))
+	result.WriteString(fmt.Sprintf("\n\n# Exit code: %d (took %s)", exitCode, duration.Round(time.Millisecond)))
+
+	return interfaces.FixInfo{
+		Enabled:  true,
+		Raw:      strings.TrimSpace(result.String()),
+		Command:  command,
+		Output:   strings.TrimSpace(output),
+		Stdout:   strings.TrimSpace(stdout.String()),
+		Stderr:   strings.TrimSpace(stderr.String()),
+		ExitCode: exitCode,
+		Duration: duration,
+	}, nil
+}
+
+// lockedBuffer is a writer safe for the concurrent stdout and stderr copies of a command
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
 
-	return strings.TrimSpace(result.String()), nil
+// Write appends p to the buffer
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
 }
 
-// OutputPrompt handles the final output of the generated prompt
-func (o *Orchestrator) OutputPrompt(prompt string, request *models.PromptRequest, cfg *interfaces.Config) error {
+// String returns the buffered content
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// OutputPrompt handles the final output of the generated prompt. A cmd: target's command is
+// stopped when ctx is done.
+func (o *Orchestrator) OutputPrompt(ctx context.Context, prompt string, request *models.PromptRequest, cfg *interfaces.Config) error {
 	target := request.Target
 	if target == "" {
 		target = cfg.Target
@@ -602,47 +1316,268 @@ func (o *Orchestrator) OutputPrompt(prompt string, request *models.PromptRequest
 		target = "stdout" // Default fallback
 	}
 
+	// Review in the editor first, so the target gets the edited prompt
+	if request.EditorRequested {
+		edited, err := o.reviewInEditor(prompt, request, cfg)
+		if err != nil {
+			return err
+		}
+		prompt = edited
+	}
+
+	if request.AppendOutput && !isFileTarget(target) {
+		o.warnings.Add("--append only applies to file targets; writing to %s as usual", target)
+	}
+
 	// Handle different output targets
 	switch {
 	case target == "clipboard":
+		debugf := func(format string, args ...any) { debugLog(request, format, args...) }
 		if err := o.outputHandler.WriteToClipboard(prompt); err != nil {
+			debugf("system clipboard: %v", err)
+			// Headless and SSH machines have no system clipboard; a tool, tmux, or the terminal may
+			if backend, backendErr := copyWithBackend(prompt, debugf); backendErr == nil {
+				debugf("clipboard backend: %s", backend)
+				fmt.Printf("Prompt copied to clipboard (via %s)\n", backend)
+				o.outputWritten(backend, prompt)
+				break
+			}
 			outputErr := NewOutputError(target, err)
 			// Try to recover by falling back to stdout
 			if IsRecoverableError(outputErr) {
 				fmt.Fprintf(os.Stderr, "Warning: %s\nFalling back to stdout:\n\n", outputErr.Error())
-				return o.outputHandler.WriteToStdout(prompt)
+				if err := o.outputHandler.WriteToStdout(prompt); err != nil {
+					return err
+				}
+				o.outputWritten("stdout", prompt)
+				return nil
 			}
 			return RecoverFromError(outputErr)
 		}
+		if cfg.ClipboardVerify {
+			written, err := o.verifyClipboard(prompt, request)
+			if err != nil {
+				return RecoverFromError(NewOutputError(target, err))
+			}
+			if written {
+				o.outputWritten("file:"+clipboardFallbackFile(), prompt)
+				break
+			}
+		}
+		debugf("clipboard backend: system")
 		fmt.Println("Prompt copied to clipboard")
+		o.outputWritten(target, prompt)
+
+	case target == "tmux" || target == "osc52":
+		write := writeTmuxBuffer
+		message := "Prompt copied to the tmux paste buffer"
+		if target == "osc52" {
+			write = writeOSC52
+			message = "Prompt sent to the terminal's clipboard"
+		}
+		if err := write(prompt); err != nil {
+			return RecoverFromError(NewOutputError(target, err))
+		}
+		fmt.Println(message)
+		o.outputWritten(target, prompt)
+
+	case strings.HasPrefix(target, "cmd:"):
+		command, ok := config.ParseCommandTarget(target)
+		if !ok {
+			return RecoverFromError(NewValidationError("target", target, "cmd: needs a command, e.g. cmd:llm"))
+		}
+		debugLog(request, "piping the prompt to %s", command)
+		if err := pipeToCommand(ctx, prompt, command); err != nil {
+			return RecoverFromError(NewOutputError(target, err))
+		}
+		o.outputWritten(target, prompt)
 
 	case target == "stdout":
 		if err := o.outputHandler.WriteToStdout(prompt); err != nil {
 			outputErr := NewOutputError(target, err)
 			return RecoverFromError(outputErr)
 		}
+		o.outputWritten(target, prompt)
 
-	case strings.HasPrefix(target, "file:"):
-		filePath := strings.TrimPrefix(target, "file:")
-		if err := o.outputHandler.WriteToFile(prompt, filePath); err != nil {
+	case isFileTarget(target):
+		filePath, mode, _ := config.ParseFileTarget(target)
+		filePath = expandTimePattern(filePath, time.Now())
+		if request.AppendOutput && mode == config.FileReplace {
+			mode = config.FileAppend
+		}
+		if err := o.confirmTargetDirectory(filePath, request); err != nil {
+			return RecoverFromError(NewOutputError(target, err))
+		}
+
+		var err error
+		verb := "written to"
+		switch mode {
+		case config.FileAppend:
+			err = o.appendToFile(prompt, filePath)
+			verb = "appended to"
+		case config.FilePrepend:
+			err = o.prependToFile(prompt, filePath)
+			verb = "prepended to"
+		default:
+			err = o.outputHandler.WriteToFile(prompt, filePath)
+		}
+		if err != nil {
 			outputErr := NewOutputError(target, err)
 			return RecoverFromError(outputErr)
 		}
-		fmt.Printf("Prompt written to %s\n", filePath)
+		fmt.Printf("Prompt %s %s\n", verb, filePath)
+		o.outputWritten(target, prompt)
 
 	default:
 		return RecoverFromError(NewValidationError("target", target, "unsupported output target"))
 	}
 
-	// Handle editor integration if explicitly requested
-	if request.EditorRequested {
-		editor := o.resolveEditor(request.Editor, cfg.Editor)
-		if err := o.outputHandler.OpenInEditor(prompt, editor); err != nil {
-			outputErr := NewOutputError("editor", err)
-			return RecoverFromError(outputErr)
-		}
+	return nil
+}
+
+// reviewInEditor opens prompt in the editor and returns it as edited. Emptying the file cancels
+// the output, as with a git commit message.
+func (o *Orchestrator) reviewInEditor(prompt string, request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
+	editor := o.resolveEditor(request.Editor, cfg.Editor)
+	wait := cfg.EditorWait && !request.NoEditorWait
+	edited, err := o.outputHandler.OpenInEditor(prompt, editor, wait)
+	if err != nil {
+		return "", RecoverFromError(NewOutputError("editor", err))
+	}
+	if strings.TrimSpace(edited) == "" {
+		return "", fmt.Errorf("cancelled: the edited prompt is empty, so nothing was output")
+	}
+	return edited, nil
+}
+
+// EditPrompt opens prompt in the editor, waiting for it even when editor_wait is off, and
+// returns it as edited (exported for app layer)
+func (o *Orchestrator) EditPrompt(prompt string, request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
+	edited, err := o.ComposeInEditor(prompt, request, cfg)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(edited) == "" {
+		return "", fmt.Errorf("cancelled: the edited prompt is empty, so nothing was output")
+	}
+	return edited, nil
+}
+
+// ComposeInEditor opens text in the editor, waiting for it even when editor_wait is off, and
+// returns what was saved, which may be empty (exported for app layer)
+func (o *Orchestrator) ComposeInEditor(text string, request *models.PromptRequest, cfg *interfaces.Config) (string, error) {
+	editor := o.resolveEditor(request.Editor, cfg.Editor)
+	composed, err := o.outputHandler.OpenInEditor(text, editor, true)
+	if err != nil {
+		return "", RecoverFromError(NewOutputError("editor", err))
+	}
+	return composed, nil
+}
+
+// debugLog reports an internal decision on stderr when --debug is set
+func debugLog(request *models.PromptRequest, format string, args ...any) {
+	if request.Debug {
+		fmt.Fprintf(os.Stderr, "debug: "+format+"\n", args...)
+	}
+}
+
+// clipboardFallbackFile is where a prompt goes when the clipboard didn't hold it intact
+func clipboardFallbackFile() string {
+	return filepath.Join(os.TempDir(), "prompter-prompt.md")
+}
+
+// clipboardMatches reports whether the clipboard holds content, ignoring the line ending and
+// trailing whitespace changes some clipboards make
+func clipboardMatches(content, clipboardContent string) bool {
+	normalize := func(s string) string {
+		return strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), " \t\r\n")
+	}
+	return normalize(content) == normalize(clipboardContent)
+}
+
+// verifyClipboard reads the clipboard back after a copy, since clipboard managers may silently
+// truncate large prompts. On a mismatch it offers to write the prompt to a file instead, and
+// reports whether it did.
+func (o *Orchestrator) verifyClipboard(prompt string, request *models.PromptRequest) (bool, error) {
+	got, err := o.outputHandler.ReadFromClipboard()
+	if err != nil {
+		o.warnings.Add("could not read the clipboard back to verify the copy: %v", err)
+		return false, nil
+	}
+	if clipboardMatches(prompt, got) {
+		return false, nil
+	}
+
+	fallback := clipboardFallbackFile()
+	mismatch := fmt.Sprintf("the clipboard holds %d of the prompt's %d characters",
+		utf8.RuneCountInString(got), utf8.RuneCountInString(prompt))
+	if !request.Interactive {
+		o.warnings.Add("%s; a clipboard manager may have truncated it (use --target file:%s instead)", mismatch, fallback)
+		return false, nil
+	}
+
+	write, err := o.selectYesNo(
+		fmt.Sprintf("Clipboard doesn't match the prompt (%s). Write it to %s instead?", mismatch, fallback),
+		"A clipboard manager may have truncated it.", true, request.NumberSelect)
+	if err != nil {
+		return false, err
+	}
+	if !write {
+		o.warnings.Add("%s; a clipboard manager may have truncated it", mismatch)
+		return false, nil
+	}
+
+	if err := o.outputHandler.WriteToFile(prompt, fallback); err != nil {
+		return false, err
+	}
+	fmt.Printf("Prompt written to %s\n", fallback)
+	return true, nil
+}
+
+// appendToFile adds prompt to the end of filePath, separated from earlier prompts by a blank line.
+// The whole file is rewritten through WriteToFile so appends stay atomic too.
+func (o *Orchestrator) appendToFile(prompt, filePath string) error {
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := prompt + "\n"
+	if trimmed := strings.TrimRight(string(existing), "\n"); trimmed != "" {
+		content = trimmed + "\n\n" + content
+	}
+	return o.outputHandler.WriteToFile(content, filePath)
+}
+
+// prependToFile writes prompt before the file's existing content, separated by a blank line
+func (o *Orchestrator) prependToFile(prompt, filePath string) error {
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := strings.TrimRight(prompt, "\n") + "\n"
+	if rest := strings.TrimLeft(string(existing), "\n"); rest != "" {
+		content += "\n" + rest
+	}
+	return o.outputHandler.WriteToFile(content, filePath)
+}
+
+// confirmTargetDirectory asks before creating a missing parent directory for a file target.
+// Non-interactive runs create it without asking.
+func (o *Orchestrator) confirmTargetDirectory(filePath string, request *models.PromptRequest) error {
+	dir := filepath.Dir(filePath)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) || !request.Interactive {
+		return nil
 	}
 
+	create, err := o.selectYesNo(fmt.Sprintf("Directory %s does not exist. Create it?", dir), "", true, request.NumberSelect)
+	if err != nil {
+		return err
+	}
+	if !create {
+		return fmt.Errorf("directory %s does not exist", dir)
+	}
 	return nil
 }
 
@@ -654,22 +1589,16 @@ func (o *Orchestrator) validateRequest(request *models.PromptRequest) error {
 
 	// In noninteractive mode, base prompt is required unless in fix mode or clipboard flag is used
 	if !request.Interactive && request.BasePrompt == "" && !request.FixMode && !request.FromClipboard {
+		if request.CI {
+			return NewInteractionRequiredError("a base prompt", "Pass the prompt as an argument or pipe it in.")
+		}
 		return NewValidationError("base_prompt", "", "required in noninteractive mode")
 	}
 
 	// Validate target format if specified
-	if request.Target != "" {
-		validTargets := []string{"clipboard", "stdout"}
-		isValid := false
-		for _, valid := range validTargets {
-			if request.Target == valid || strings.HasPrefix(request.Target, "file:") {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			return NewValidationError("target", request.Target, "must be 'clipboard', 'stdout', or 'file:/path'")
-		}
+	// Aliases are expanded by ResolveTargetAlias before the request gets here
+	if request.Target != "" && !config.IsTargetSpec(request.Target) {
+		return NewValidationError("target", request.Target, "must be 'clipboard', 'tmux', 'osc52', 'stdout', 'file:/path', 'file+:/path', 'file+prepend:/path', 'cmd:command', or a [targets] alias")
 	}
 
 	// Validate config path if specified
@@ -687,9 +1616,92 @@ func (o *Orchestrator) validateRequest(request *models.PromptRequest) error {
 		return NewValidationError("template_name", request.PostTemplate, "post-template name cannot be empty")
 	}
 
+	for _, file := range request.Files {
+		if isRemoteFile(file) {
+			if _, err := parseRemoteFile(file); err != nil {
+				return NewValidationError("file", file, "must be ssh://[user@]host[:port]/path")
+			}
+		}
+	}
+
+	for _, ref := range request.GitHubRefs {
+		if _, err := parseGitHubRef(ref); err != nil {
+			return NewValidationError("github", ref, "must be owner/repo#123 or a github.com issue or pull request URL")
+		}
+	}
+
+	for _, key := range request.JiraKeys {
+		if !jiraKeyPattern.MatchString(strings.ToUpper(key)) {
+			return NewValidationError("jira", key, "must be a ticket key such as PROJ-123")
+		}
+	}
+
+	for _, pageURL := range request.URLs {
+		if err := validateURL(pageURL); err != nil {
+			return NewValidationError("url", pageURL, "must be an http:// or https:// URL")
+		}
+	}
+
+	if request.FixSource != "" {
+		if _, ok := o.captureProviders()[request.FixSource]; !ok {
+			return NewValidationError("fix_source", request.FixSource, "must be one of: "+strings.Join(o.captureSourceNames(), ", "))
+		}
+		if request.FixCommand != "" && request.FixSource != FixSourceCommand {
+			return NewValidationError("fix_source", request.FixSource, "cannot be combined with --fix-cmd")
+		}
+		if request.FixFile != "" && request.FixSource != FixSourceFile {
+			return NewValidationError("fix_source", request.FixSource, "cannot be combined with --fix-file")
+		}
+	}
+
+	if request.FixCommand != "" && request.FixFile != "" {
+		return NewValidationError("fix_command", request.FixCommand, "cannot be combined with --fix-file")
+	}
+
+	if len(request.FixTasks) > 0 {
+		switch {
+		case request.FixCommand != "":
+			return NewValidationError("fix_tasks", request.FixTasks, "cannot be combined with --fix-cmd; add the command as another --task name=command")
+		case request.FixFile != "":
+			return NewValidationError("fix_tasks", request.FixTasks, "cannot be combined with --fix-file")
+		case request.FixSource != "" && request.FixSource != FixSourceTasks:
+			return NewValidationError("fix_tasks", request.FixTasks, "cannot be combined with --fix-source "+request.FixSource)
+		}
+	}
+
+	if request.TokenBudget < 0 {
+		return NewValidationError("token_budget", request.TokenBudget, "must be 0 or greater")
+	}
+
+	if request.WrapWidth < 0 {
+		return NewValidationError("wrap", request.WrapWidth, "must be 0 or greater")
+	}
+
+	if request.Timeout < 0 {
+		return NewValidationError("timeout", request.Timeout, "must be 0 or greater")
+	}
+
+	if request.GrepContext < 0 {
+		return NewValidationError("grep-context", request.GrepContext, "must be 0 or greater")
+	}
+
+	// Check the schema before any fix command runs
+	if request.SchemaFile != "" {
+		if _, err := loadSchema(request.SchemaFile); err != nil {
+			return err
+		}
+	}
+
+	if request.Format != "" && !slices.Contains(OutputFormats, request.Format) {
+		return NewValidationError("format", request.Format, "must be one of "+strings.Join(OutputFormats, ", "))
+	}
+
 	return nil
 }
 
+// confirmFunc asks a yes/no question, as selectYesNo does
+type confirmFunc func(message, help string, defaultValue, numberSelect bool) (bool, error)
+
 // selectYesNo handles yes/no selection with optional number key support
 func (o *Orchestrator) selectYesNo(message, help string, defaultValue, numberSelect bool) (bool, error) {
 	if numberSelect {
@@ -774,7 +1786,7 @@ func (o *Orchestrator) selectYesNoWithNumbers(message, help string, defaultValue
 		// Handle Escape or Ctrl+C
 		if char == 27 || char == 3 {
 			fmt.Println()
-			return false, fmt.Errorf("selection cancelled")
+			return false, interactive.ErrCancelled
 		}
 
 		// For any other key, continue waiting
@@ -811,18 +1823,35 @@ func (o *Orchestrator) fallbackYesNoSelection(defaultValue bool) (bool, error) {
 	}
 }
 
-// loadFixPrompt loads the fix prompt from prompts_location/fix.md
-func (o *Orchestrator) loadFixPrompt(promptsLocation string) (string, error) {
-	fixPath := filepath.Join(promptsLocation, "fix.md")
+// fixPromptPath returns the fix prompt's path in the prompts location: fix.md, or
+// fix/<name>.md for a named one
+func fixPromptPath(name string) string {
+	if name == "" {
+		return "fix.md"
+	}
+	return filepath.Join("fix", name+".md")
+}
+
+// loadFixPrompt loads the fix prompt from prompts_location/fix.md, or the named one from fix/
+func (o *Orchestrator) loadFixPrompt(promptsLocation, name string) (string, error) {
+	if name != "" && (name != filepath.Base(name) || strings.HasPrefix(name, ".")) {
+		return "", fmt.Errorf("invalid fix prompt name %q", name)
+	}
+	fixPath := filepath.Join(promptsLocation, fixPromptPath(name))
 	
 	content, err := os.ReadFile(fixPath)
 	if err != nil {
-		return "", fmt.Errorf("fix.md not found at %s: %w", fixPath, err)
+		return "", fmt.Errorf("%s not found: %w", fixPromptPath(name), err)
 	}
 	
 	return strings.TrimSpace(string(content)), nil
 }
 
+// ResolveEditor returns the editor to use, as for --editor (exported for app layer)
+func (o *Orchestrator) ResolveEditor(requestEditor, configEditor string) string {
+	return o.resolveEditor(requestEditor, configEditor)
+}
+
 // resolveEditor resolves the editor using precedence rules
 func (o *Orchestrator) resolveEditor(requestEditor, configEditor string) string {
 	// Precedence: --editor flag > $VISUAL > $EDITOR > config editor > nvim > vi
@@ -2,12 +2,28 @@ package orchestrator
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
-	"prompter-cli/pkg/models"
+	"github.com/imdevan/prompter/pkg/models"
 )
 
+// writeTree writes files, keyed by path relative to root, creating their directories
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestOrchestrator_validateRequest(t *testing.T) {
 	orch := New()
 
@@ -204,4 +220,26 @@ func TestIsRecoverableError(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestBuildFileInfo(t *testing.T) {
+	cwd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cwd, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(outside, []byte("# Notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := buildFileInfo([]string{"main.go", outside, "missing.go", "ssh://host/etc/hosts", "."}, cwd, embedPolicy{})
+	if len(files) != 2 {
+		t.Fatalf("buildFileInfo() = %+v, want main.go and notes.md", files)
+	}
+	if got := files[0]; got.Path != filepath.Join(cwd, "main.go") || got.RelPath != "main.go" || got.Language != "go" || got.Content != "package main" {
+		t.Errorf("buildFileInfo()[0] = %+v", got)
+	}
+	if got := files[1]; got.Path != outside || got.RelPath != outside || got.Language != "md" {
+		t.Errorf("buildFileInfo()[1] = %+v", got)
+	}
+}
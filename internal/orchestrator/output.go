@@ -1,15 +1,94 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
-	"prompter-cli/internal/interfaces"
+	"github.com/imdevan/prompter/internal/config"
+	"github.com/imdevan/prompter/internal/interfaces"
 )
 
+// timeDirectives are the strftime directives file target paths may use, as Go layouts
+var timeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'H': "15",
+	'M': "04",
+	'S': "05",
+	'b': "Jan",
+	'a': "Mon",
+	'j': "002",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'z': "-0700",
+}
+
+// isFileTarget reports whether target writes to a file
+func isFileTarget(target string) bool {
+	_, _, ok := config.ParseFileTarget(target)
+	return ok
+}
+
+// pipeToCommand runs command through the shell with prompt on its stdin, streaming its output
+// to the terminal. The command's exit status is its result, so a failing agent fails prompter.
+func pipeToCommand(ctx context.Context, prompt, command string) error {
+	cmd := shellCommand(ctx, "", command)
+	cmd.Stdin = strings.NewReader(prompt)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("stopped %q: %w", command, ctx.Err())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%q exited with status %d", command, exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run %q: %w", command, err)
+	}
+	return nil
+}
+
+// expandTimePattern replaces strftime directives in path (e.g. prompt-%Y%m%d-%H%M%S.md) with
+// t, so each run can write a new file. %s is the Unix time and %% a literal %; other % are kept.
+func expandTimePattern(path string, t time.Time) string {
+	if !strings.Contains(path, "%") {
+		return path
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' || i+1 == len(path) {
+			out.WriteByte(path[i])
+			continue
+		}
+		directive := path[i+1]
+		switch layout, ok := timeDirectives[directive]; {
+		case ok:
+			out.WriteString(t.Format(layout))
+		case directive == 's':
+			out.WriteString(fmt.Sprint(t.Unix()))
+		case directive == '%':
+			out.WriteByte('%')
+		default:
+			out.WriteByte('%')
+			continue
+		}
+		i++
+	}
+	return out.String()
+}
+
 // OutputHandler implements the OutputHandler interface
 type OutputHandler struct{}
 
@@ -23,42 +102,149 @@ func (h *OutputHandler) WriteToClipboard(content string) error {
 	return clipboard.WriteAll(content)
 }
 
+// ReadFromClipboard returns the system clipboard's content
+func (h *OutputHandler) ReadFromClipboard() (string, error) {
+	return clipboard.ReadAll()
+}
+
 // WriteToStdout writes content to standard output
 func (h *OutputHandler) WriteToStdout(content string) error {
 	_, err := fmt.Println(content)
 	return err
 }
 
-// WriteToFile writes content to the specified file path
+// WriteToFile writes content to the specified file path, creating parent directories.
+// The content goes to a temporary file that is renamed into place, so readers never see
+// a partially written prompt.
 func (h *OutputHandler) WriteToFile(content string, path string) error {
-	return ioutil.WriteFile(path, []byte(content), 0644)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	// Keep the permissions of a file being replaced
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to flush temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// guiEditorWaitFlags maps GUI editors to the flag that keeps their launcher running
+// until the file is closed; without it the launcher returns as soon as the window opens
+var guiEditorWaitFlags = map[string]string{
+	"code":          "--wait",
+	"code-insiders": "--wait",
+	"codium":        "--wait",
+	"cursor":        "--wait",
+	"windsurf":      "--wait",
+	"zed":           "--wait",
+	"atom":          "--wait",
+	"bbedit":        "--wait",
+	"subl":          "-w",
+	"sublime_text":  "-w",
+	"mate":          "-w",
+	"gvim":          "-f",
+	"mvim":          "-f",
+	"idea":          "--wait",
+	"goland":        "--wait",
+	"pycharm":       "--wait",
+	"webstorm":      "--wait",
+}
+
+// editorCommand splits an editor setting such as "code" or "subl -n" into its program and
+// arguments. For known GUI editors it adds the wait flag when wait is set, and reports gui.
+func editorCommand(editor string, wait bool) (args []string, gui bool) {
+	args = strings.Fields(editor)
+	if len(args) == 0 {
+		return nil, false
+	}
+
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	flag, gui := guiEditorWaitFlags[name]
+	if !gui || !wait {
+		return args, gui
+	}
+
+	// Respect a wait flag already in the editor setting
+	for _, arg := range args[1:] {
+		if arg == flag || arg == "--wait" {
+			return args, gui
+		}
+	}
+	return append(args, flag), gui
 }
 
-// OpenInEditor opens content in the specified editor
-func (h *OutputHandler) OpenInEditor(content string, editor string) error {
+// OpenInEditor opens content in the specified editor and returns the file as the editor left
+// it. Terminal editors always run in the foreground; GUI editors are waited on only when wait is
+// set, and otherwise return content unchanged since the edits arrive too late to use.
+func (h *OutputHandler) OpenInEditor(content string, editor string, wait bool) (string, error) {
+	args, gui := editorCommand(editor, wait)
+	if len(args) == 0 {
+		return "", fmt.Errorf("no editor configured")
+	}
+
 	// Create a temporary file
 	tmpFile, err := ioutil.TempFile("", "prompter-*.md")
 	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
 	}
-	defer os.Remove(tmpFile.Name()) // Clean up
 
 	// Write content to temporary file
 	if _, err := tmpFile.WriteString(content); err != nil {
 		tmpFile.Close()
-		return fmt.Errorf("failed to write to temporary file: %w", err)
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write to temporary file: %w", err)
 	}
 	tmpFile.Close()
 
 	// Launch editor
-	cmd := exec.Command(editor, tmpFile.Name())
+	cmd := exec.Command(args[0], append(args[1:], tmpFile.Name())...)
+
+	if gui && !wait {
+		// Return immediately; the file stays in the temp directory for the editor to open
+		if err := cmd.Start(); err != nil {
+			os.Remove(tmpFile.Name())
+			return "", fmt.Errorf("failed to launch editor %s: %w", editor, err)
+		}
+		return content, cmd.Process.Release()
+	}
+	defer os.Remove(tmpFile.Name()) // Clean up
+
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to launch editor %s: %w", editor, err)
+		return "", fmt.Errorf("failed to launch editor %s: %w", editor, err)
 	}
 
-	return nil
-}
\ No newline at end of file
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the edited prompt: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,366 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestOutputHandler_WriteToFile(t *testing.T) {
+	handler := &OutputHandler{}
+
+	t.Run("creates parent directories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prompts", "nested", "prompt.md")
+
+		if err := handler.WriteToFile("hello", path); err != nil {
+			t.Fatalf("WriteToFile() error = %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read written file: %v", err)
+		}
+		if string(data) != "hello" {
+			t.Errorf("file content = %q, want %q", data, "hello")
+		}
+	})
+
+	t.Run("replaces existing file and keeps its mode", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "prompt.md")
+		if err := os.WriteFile(path, []byte("old content that is longer"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := handler.WriteToFile("new", path); err != nil {
+			t.Fatalf("WriteToFile() error = %v", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if string(data) != "new" {
+			t.Errorf("file content = %q, want %q", data, "new")
+		}
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+		}
+
+		// No temporary files are left behind
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 {
+			t.Errorf("directory has %d entries, want 1", len(entries))
+		}
+	})
+}
+
+func TestOrchestrator_appendToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "prompts.md")
+	o := New()
+
+	for _, prompt := range []string{"first prompt", "second prompt"} {
+		if err := o.appendToFile(prompt, path); err != nil {
+			t.Fatalf("appendToFile() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "first prompt\n\nsecond prompt\n"; string(data) != want {
+		t.Errorf("file content = %q, want %q", data, want)
+	}
+}
+
+func TestOrchestrator_prependToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "prompts.md")
+	o := New()
+
+	for _, prompt := range []string{"first prompt", "second prompt"} {
+		if err := o.prependToFile(prompt, path); err != nil {
+			t.Fatalf("prependToFile() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "second prompt\n\nfirst prompt\n"; string(data) != want {
+		t.Errorf("file content = %q, want %q", data, want)
+	}
+}
+
+func TestExpandTimePattern(t *testing.T) {
+	at := time.Date(2026, 3, 7, 9, 5, 2, 0, time.UTC)
+	tests := []struct {
+		path, want string
+	}{
+		{"/tmp/prompt.md", "/tmp/prompt.md"},
+		{"/tmp/prompt-%Y%m%d-%H%M%S.md", "/tmp/prompt-20260307-090502.md"},
+		{"/tmp/%F/%s.md", "/tmp/2026-03-07/1772874302.md"},
+		{"/tmp/100%%-%q.md", "/tmp/100%-%q.md"},
+		{"/tmp/trailing%", "/tmp/trailing%"},
+	}
+
+	for _, tt := range tests {
+		if got := expandTimePattern(tt.path, at); got != tt.want {
+			t.Errorf("expandTimePattern(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOrchestrator_OutputPromptAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.md")
+	request := models.NewPromptRequest()
+	request.Interactive = false
+	request.Target = "file:" + path
+	request.AppendOutput = true
+
+	o := New()
+	for _, prompt := range []string{"one", "two"} {
+		if err := o.OutputPrompt(context.Background(), prompt, request, &interfaces.Config{}); err != nil {
+			t.Fatalf("OutputPrompt() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "one\n\ntwo\n"; string(data) != want {
+		t.Errorf("file content = %q, want %q", data, want)
+	}
+}
+
+func TestOrchestrator_resolveTargetAlias(t *testing.T) {
+	cfg := &interfaces.Config{
+		Targets: map[string]string{
+			"notes":   "file+:/tmp/notes.md",
+			"project": "file:/tmp/{{.Project.Name}}.md",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{name: "alias", target: "notes", want: "file+:/tmp/notes.md"},
+		{name: "alias is case insensitive", target: "Notes", want: "file+:/tmp/notes.md"},
+		{name: "built-in target untouched", target: "stdout", want: "stdout"},
+		{name: "templated alias", target: "project", want: "file:/tmp/" + detectProject(mustGetwd(t)).Name + ".md"},
+		{name: "unknown alias", target: "journal", wantErr: true},
+	}
+
+	o := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &models.PromptRequest{Target: tt.target}
+			err := o.resolveTargetAlias(request, cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveTargetAlias(%q) expected error, got target %q", tt.target, request.Target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveTargetAlias(%q) error = %v", tt.target, err)
+			}
+			if request.Target != tt.want {
+				t.Errorf("resolveTargetAlias(%q) = %q, want %q", tt.target, request.Target, tt.want)
+			}
+		})
+	}
+}
+
+// mustGetwd returns the working directory or fails the test
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cwd
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		editor  string
+		wait    bool
+		want    []string
+		wantGUI bool
+	}{
+		{name: "terminal editor", editor: "nvim", wait: true, want: []string{"nvim"}},
+		{name: "vscode waits", editor: "code", wait: true, want: []string{"code", "--wait"}, wantGUI: true},
+		{name: "sublime waits", editor: "/usr/local/bin/subl -n", wait: true, want: []string{"/usr/local/bin/subl", "-n", "-w"}, wantGUI: true},
+		{name: "existing wait flag kept", editor: "code --wait", wait: true, want: []string{"code", "--wait"}, wantGUI: true},
+		{name: "no wait", editor: "code", wait: false, want: []string{"code"}, wantGUI: true},
+		{name: "windows executable", editor: "code.exe", wait: true, want: []string{"code.exe", "--wait"}, wantGUI: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gui := editorCommand(tt.editor, tt.wait)
+			if !reflect.DeepEqual(got, tt.want) || gui != tt.wantGUI {
+				t.Errorf("editorCommand(%q, %v) = %v, %v; want %v, %v", tt.editor, tt.wait, got, gui, tt.want, tt.wantGUI)
+			}
+		})
+	}
+}
+
+// clipboardStub is an output handler whose clipboard keeps only the first limit bytes
+type clipboardStub struct {
+	OutputHandler
+	limit     int
+	clipboard string
+}
+
+func (h *clipboardStub) WriteToClipboard(content string) error {
+	if h.limit > 0 && len(content) > h.limit {
+		content = content[:h.limit]
+	}
+	h.clipboard = content
+	return nil
+}
+
+func (h *clipboardStub) ReadFromClipboard() (string, error) {
+	return h.clipboard, nil
+}
+
+func TestClipboardMatches(t *testing.T) {
+	tests := []struct {
+		content, clipboard string
+		want               bool
+	}{
+		{"prompt\n", "prompt\n", true},
+		{"line one\nline two\n", "line one\r\nline two", true},
+		{"prompt", "prom", false},
+		{"prompt", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := clipboardMatches(tt.content, tt.clipboard); got != tt.want {
+			t.Errorf("clipboardMatches(%q, %q) = %v, want %v", tt.content, tt.clipboard, got, tt.want)
+		}
+	}
+}
+
+func TestOrchestrator_verifyClipboard(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.Interactive = false
+
+	o := New()
+	stub := &clipboardStub{}
+	o.outputHandler = stub
+
+	stub.WriteToClipboard("the whole prompt")
+	if written, err := o.verifyClipboard("the whole prompt", request); err != nil || written {
+		t.Errorf("verifyClipboard() on an intact copy = %v, %v; want false, nil", written, err)
+	}
+
+	// Non-interactive runs warn about a truncated copy rather than asking
+	stub.limit = 3
+	stub.WriteToClipboard("the whole prompt")
+	if written, err := o.verifyClipboard("the whole prompt", request); err != nil || written {
+		t.Errorf("verifyClipboard() on a truncated copy = %v, %v; want false, nil", written, err)
+	}
+	var out strings.Builder
+	warnings.Flush(&out)
+	if !strings.Contains(out.String(), "holds 3 of the prompt's 16 characters") {
+		t.Errorf("verifyClipboard() warnings = %q, want a truncation warning", out.String())
+	}
+}
+
+// editorStub is an output handler whose editor replaces the prompt with edited
+type editorStub struct {
+	OutputHandler
+	edited string
+	opened string
+}
+
+func (h *editorStub) OpenInEditor(content string, editor string, wait bool) (string, error) {
+	h.opened = content
+	return h.edited, nil
+}
+
+func TestOrchestrator_OutputPromptEditor(t *testing.T) {
+	tests := []struct {
+		name    string
+		edited  string
+		want    string
+		wantErr bool
+	}{
+		{name: "edits reach the target", edited: "edited prompt\n", want: "edited prompt\n"},
+		{name: "empty file cancels", edited: " \n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prompt.md")
+			request := models.NewPromptRequest()
+			request.Interactive = false
+			request.Target = "file:" + path
+			request.EditorRequested = true
+			request.Editor = "vi"
+
+			o := New()
+			stub := &editorStub{edited: tt.edited}
+			o.outputHandler = stub
+
+			err := o.OutputPrompt(context.Background(), "original prompt", request, &interfaces.Config{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OutputPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stub.opened != "original prompt" {
+				t.Errorf("editor opened %q, want the original prompt", stub.opened)
+			}
+
+			data, readErr := os.ReadFile(path)
+			if tt.wantErr {
+				if readErr == nil {
+					t.Errorf("OutputPrompt() wrote %q after a cancelled edit", data)
+				}
+				return
+			}
+			if string(data) != tt.want {
+				t.Errorf("target got %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_OutputPromptCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "received.md")
+	request := models.NewPromptRequest()
+	request.Interactive = false
+	request.Target = "cmd:cat > " + path
+
+	o := New()
+	if err := o.OutputPrompt(context.Background(), "the prompt", request, &interfaces.Config{}); err != nil {
+		t.Fatalf("OutputPrompt() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "the prompt" {
+		t.Errorf("command got %q, want the prompt", data)
+	}
+
+	request.Target = "cmd:exit 3"
+	err = o.OutputPrompt(context.Background(), "the prompt", request, &interfaces.Config{})
+	if err == nil || !strings.Contains(err.Error(), "failed to output") {
+		t.Errorf("OutputPrompt() with a failing command error = %v", err)
+	}
+}
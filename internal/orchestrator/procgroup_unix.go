@@ -0,0 +1,17 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// stopProcessGroup runs cmd in a process group of its own and has stopping it kill the group,
+// so children of the shell that runs a command, such as a test runner's workers, stop with it
+func stopProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,7 @@
+package orchestrator
+
+import "os/exec"
+
+// stopProcessGroup leaves cmd as it is: stopping it kills the shell, and WaitDelay stops
+// waiting on children that outlive it
+func stopProcessGroup(cmd *exec.Cmd) {}
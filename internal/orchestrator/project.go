@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// projectMarker maps a file that identifies a project root to the project type
+type projectMarker struct {
+	File string
+	Type string
+}
+
+// projectMarkers are checked in order; the first match in a directory wins
+var projectMarkers = []projectMarker{
+	{File: "go.mod", Type: "go"},
+	{File: "Cargo.toml", Type: "rust"},
+	{File: "tsconfig.json", Type: "typescript"},
+	{File: "package.json", Type: "node"},
+	{File: "pyproject.toml", Type: "python"},
+	{File: "setup.py", Type: "python"},
+	{File: "requirements.txt", Type: "python"},
+	{File: "Gemfile", Type: "ruby"},
+	{File: "pom.xml", Type: "java"},
+	{File: "build.gradle", Type: "java"},
+	{File: "build.gradle.kts", Type: "kotlin"},
+	{File: "composer.json", Type: "php"},
+	{File: "mix.exs", Type: "elixir"},
+	{File: "Package.swift", Type: "swift"},
+	{File: "CMakeLists.txt", Type: "cpp"},
+}
+
+// detectProject finds the nearest project root at or above dir and its type
+func detectProject(dir string) interfaces.ProjectInfo {
+	current := dir
+	for {
+		for _, marker := range projectMarkers {
+			if _, err := os.Stat(filepath.Join(current, marker.File)); err == nil {
+				return interfaces.ProjectInfo{
+					Root: current,
+					Name: filepath.Base(current),
+					Type: marker.Type,
+				}
+			}
+		}
+
+		// Stop at the repository root so unrelated parents aren't picked up
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return interfaces.ProjectInfo{
+				Root: current,
+				Name: filepath.Base(current),
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	// No marker found: treat the working directory as an untyped project
+	return interfaces.ProjectInfo{
+		Root: dir,
+		Name: filepath.Base(dir),
+	}
+}
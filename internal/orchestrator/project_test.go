@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestDetectProject(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "service")
+	nested := filepath.Join(repo, "internal", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Untyped repository: root is the .git directory, markers above it are ignored
+	if err := os.WriteFile(filepath.Join(root, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := detectProject(nested)
+	want := interfaces.ProjectInfo{Root: repo, Name: "service"}
+	if got != want {
+		t.Errorf("detectProject() = %+v, want %+v", got, want)
+	}
+
+	// Typed repository detected from a nested directory
+	if err := os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module service\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got = detectProject(nested)
+	want = interfaces.ProjectInfo{Root: repo, Name: "service", Type: "go"}
+	if got != want {
+		t.Errorf("detectProject() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyConfigDefaults_Interpolation(t *testing.T) {
+	orch := New()
+
+	cfg := &interfaces.Config{
+		DefaultPre:  "{{.Config.target}}-style",
+		DefaultPost: "plain",
+		Target:      "clipboard",
+	}
+	request := &models.PromptRequest{BasePrompt: "test"}
+
+	if err := orch.applyConfigDefaults(request, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.PreTemplate != "clipboard-style" {
+		t.Errorf("PreTemplate = %q, want %q", request.PreTemplate, "clipboard-style")
+	}
+	if request.PostTemplate != "plain" {
+		t.Errorf("PostTemplate = %q, want %q", request.PostTemplate, "plain")
+	}
+
+	cfg = &interfaces.Config{DefaultPre: "{{.Project.Type"}
+	if err := orch.applyConfigDefaults(&models.PromptRequest{}, cfg); err == nil {
+		t.Error("expected error for invalid template in default_pre")
+	}
+}
@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"os"
+	"sync"
+)
+
+// fileReadWorkers is how many files are read at once when a prompt embeds many. Reads mostly
+// wait on the disk, so a few more than a laptop has cores keeps it busy.
+const fileReadWorkers = 16
+
+// fileRead is what readFiles found at one path
+type fileRead struct {
+	Info    os.FileInfo // nil when the path is missing or a directory
+	Large   bool        // Over maxEmbeddedFileBytes
+	Content string      // The file, or an excerpt when it is large; "" when it wasn't read
+	Err     error       // Why the file couldn't be read
+}
+
+// readFiles stats and reads paths, up to workers at a time, and returns what it found in the
+// order of paths. Files over maxEmbeddedFileBytes are sampled when sample is set and otherwise
+// left unread.
+func readFiles(paths []string, sample bool, workers int) []fileRead {
+	reads := make([]fileRead, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range max(min(workers, len(paths)), 1) {
+		wg.Go(func() {
+			for i := range indexes {
+				reads[i] = readFile(paths[i], sample)
+			}
+		})
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return reads
+}
+
+// readFile stats and reads one path for readFiles
+func readFile(path string, sample bool) fileRead {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return fileRead{Err: err}
+	}
+	read := fileRead{Info: info, Large: info.Size() > maxEmbeddedFileBytes}
+	if read.Large && !sample {
+		return read
+	}
+	read.Content, read.Err = readEmbeddedFile(path, read.Large)
+	return read
+}
@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes n small source files to dir and returns their paths
+func writeFiles(t testing.TB, dir string, n int) []string {
+	t.Helper()
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("file%03d.go", i))
+		content := fmt.Sprintf("package main\n\n// file %d\n%s", i, strings.Repeat("var x = 1\n", 400))
+		if err := os.WriteFile(paths[i], []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeFiles(t, dir, 50)
+	large := filepath.Join(dir, "large.txt")
+	if err := os.WriteFile(large, []byte(strings.Repeat("line\n", maxEmbeddedFileBytes/4)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.go"), dir, large)
+
+	for _, workers := range []int{0, 1, 4, fileReadWorkers} {
+		reads := readFiles(paths, false, workers)
+		if len(reads) != len(paths) {
+			t.Fatalf("readFiles(workers=%d) returned %d reads for %d paths", workers, len(reads), len(paths))
+		}
+		for i := range 50 {
+			if want := fmt.Sprintf("// file %d\n", i); !strings.Contains(reads[i].Content, want) || reads[i].Err != nil {
+				t.Fatalf("readFiles(workers=%d)[%d] = %.40q, %v; want file %d", workers, i, reads[i].Content, reads[i].Err, i)
+			}
+		}
+		if missing := reads[50]; missing.Info != nil || missing.Err == nil {
+			t.Errorf("missing file read = %+v, want no info and an error", missing)
+		}
+		if directory := reads[51]; directory.Info != nil {
+			t.Errorf("directory read = %+v, want no info", directory)
+		}
+		if unsampled := reads[52]; !unsampled.Large || unsampled.Content != "" {
+			t.Errorf("large file read without sampling = %+v, want it left unread", unsampled)
+		}
+	}
+
+	sampled := readFiles([]string{large}, true, fileReadWorkers)[0]
+	if !sampled.Large || sampled.Content == "" || len(sampled.Content) > maxEmbeddedFileBytes {
+		t.Errorf("large file read with sampling = %d bytes, want an excerpt", len(sampled.Content))
+	}
+}
+
+// BenchmarkReadFiles compares reading a large context set one file at a time with reading it
+// through the worker pool
+func BenchmarkReadFiles(b *testing.B) {
+	paths := writeFiles(b, b.TempDir(), 500)
+	for _, workers := range []int{1, fileReadWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for b.Loop() {
+				readFiles(paths, false, workers)
+			}
+		})
+	}
+}
+
+func BenchmarkFormatChangedFiles(b *testing.B) {
+	paths := writeFiles(b, b.TempDir(), 500)
+	for b.Loop() {
+		formatChangedFiles(paths, embedPolicy{})
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+// redactionRule is a pattern for one kind of secret. When the pattern has a group named
+// secret, only that group is replaced, so the name of an assignment stays readable.
+type redactionRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// DefaultRedactionPatterns match credentials that turn up in source, config, and logs
+var DefaultRedactionPatterns = []struct{ Name, Pattern string }{
+	{"private-key", `-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`},
+	{"aws-access-key", `\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`},
+	{"aws-secret-key", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})\b`},
+	{"github-token", `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`},
+	{"slack-token", `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{"bearer-token", `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{16,}=*)`},
+	// .env-style NAME=value assignments whose name says they hold a credential
+	{"env-secret", `(?m)^[ \t]*(export[ \t]+)?[A-Z0-9_]*(SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_]*[ \t]*[=:][ \t]*(?P<secret>"[^"\n$]{8,}"|'[^'\n$]{8,}'|[^\s#'"$][^\s#'"]{7,})`},
+}
+
+// redactedPrefix starts the placeholder a secret is replaced with
+const redactedPrefix = "[REDACTED:"
+
+// redactor replaces secrets in prompt content with placeholders naming what was removed, and
+// keeps track of the distinct secrets it replaced so the run can say so
+type redactor struct {
+	rules    []redactionRule
+	redacted map[string]map[string]bool // Rule name to the secrets it matched
+}
+
+// newRedactor compiles the default patterns plus any custom ones, or returns nil, which
+// redacts nothing, when redaction is off
+func newRedactor(enabled bool, custom []string) (*redactor, error) {
+	if !enabled {
+		return nil, nil
+	}
+
+	r := &redactor{redacted: map[string]map[string]bool{}}
+	for _, rule := range DefaultRedactionPatterns {
+		r.rules = append(r.rules, redactionRule{name: rule.Name, pattern: regexp.MustCompile(rule.Pattern)})
+	}
+	for _, source := range custom {
+		pattern, err := regexp.Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", source, err)
+		}
+		r.rules = append(r.rules, redactionRule{name: "custom", pattern: pattern})
+	}
+	return r, nil
+}
+
+// Apply replaces the secrets in text with [REDACTED:kind] placeholders
+func (r *redactor) Apply(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.rules {
+		text = r.applyRule(rule, text)
+	}
+	return text
+}
+
+// applyRule replaces rule's matches in text, or just their secret group when it has one
+func (r *redactor) applyRule(rule redactionRule, text string) string {
+	matches := rule.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	group := rule.pattern.SubexpIndex("secret")
+	placeholder := redactedPrefix + rule.name + "]"
+
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if group > 0 && match[2*group] >= 0 {
+			start, end = match[2*group], match[2*group+1]
+		}
+		// Content is redacted at several stages, so a placeholder may come round again
+		if strings.HasPrefix(text[start:end], redactedPrefix) {
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(placeholder)
+		last = end
+		if r.redacted[rule.name] == nil {
+			r.redacted[rule.name] = map[string]bool{}
+		}
+		r.redacted[rule.name][text[start:end]] = true
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// redactSections redacts the sections that hold included content: files, fix output, and
+// fetched context. Templates and the prompt the user wrote are left alone.
+func redactSections(sections []promptSection, r *redactor) []promptSection {
+	if r == nil {
+		return sections
+	}
+	for i, section := range sections {
+		if section.Class != SectionBase {
+			sections[i].Content = r.Apply(section.Content)
+		}
+	}
+	return sections
+}
+
+// redactFiles redacts the content of templates' .Files
+func (o *Orchestrator) redactFiles(files []interfaces.FileInfo) []interfaces.FileInfo {
+	for i := range files {
+		files[i].Content = o.redactor.Apply(files[i].Content)
+	}
+	return files
+}
+
+// report notes what was redacted, so a model's confusion over a placeholder isn't a mystery
+func (r *redactor) report(channel *warnings.Channel) {
+	if r == nil || len(r.redacted) == 0 {
+		return
+	}
+	total := 0
+	var kinds []string
+	for name, secrets := range r.redacted {
+		total += len(secrets)
+		kinds = append(kinds, name)
+	}
+	sort.Strings(kinds)
+	noun := "secrets"
+	if total == 1 {
+		noun = "secret"
+	}
+	channel.Add("redacted %d %s (%s); --no-redact keeps them", total, noun, strings.Join(kinds, ", "))
+}
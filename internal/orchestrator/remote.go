@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// remoteFetchTimeout bounds how long a single remote file fetch may take
+const remoteFetchTimeout = 30 * time.Second
+
+// remoteFile identifies a file on another host reachable over ssh
+type remoteFile struct {
+	User string
+	Host string
+	Port string
+	Path string
+}
+
+// isRemoteFile reports whether a --file argument refers to a remote file
+func isRemoteFile(file string) bool {
+	return strings.HasPrefix(file, "ssh://") || strings.HasPrefix(file, "scp://")
+}
+
+// IsRemoteFile reports whether a --file argument names a file on another host (exported for
+// app layer)
+func IsRemoteFile(file string) bool {
+	return isRemoteFile(file)
+}
+
+// parseRemoteFile parses ssh://[user@]host[:port]/path (scp:// is accepted as an alias).
+// Paths are absolute; use ssh://host/~/file for a path relative to the remote home.
+func parseRemoteFile(file string) (remoteFile, error) {
+	u, err := url.Parse(file)
+	if err != nil {
+		return remoteFile{}, fmt.Errorf("invalid remote file %q: %w", file, err)
+	}
+	if u.Scheme != "ssh" && u.Scheme != "scp" {
+		return remoteFile{}, fmt.Errorf("invalid remote file %q: unsupported scheme %q", file, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return remoteFile{}, fmt.Errorf("invalid remote file %q: missing host", file)
+	}
+	// ssh would read a leading - as an option, e.g. ssh://-oProxyCommand=cmd/x
+	if strings.HasPrefix(u.Hostname(), "-") || (u.User != nil && strings.HasPrefix(u.User.Username(), "-")) {
+		return remoteFile{}, fmt.Errorf("invalid remote file %q: user and host can't start with -", file)
+	}
+	if u.Path == "" || u.Path == "/" {
+		return remoteFile{}, fmt.Errorf("invalid remote file %q: missing path", file)
+	}
+
+	remote := remoteFile{
+		Host: u.Hostname(),
+		Port: u.Port(),
+		Path: u.Path,
+	}
+	if u.User != nil {
+		remote.User = u.User.Username()
+	}
+
+	// ssh://host/~/logs/app.log refers to the remote home directory
+	if strings.HasPrefix(remote.Path, "/~/") {
+		remote.Path = strings.TrimPrefix(remote.Path, "/")
+	}
+
+	return remote, nil
+}
+
+// destination returns the ssh destination argument ([user@]host)
+func (r remoteFile) destination() string {
+	if r.User != "" {
+		return r.User + "@" + r.Host
+	}
+	return r.Host
+}
+
+// Name returns the file's base name, used to label the included content
+func (r remoteFile) Name() string {
+	return path.Base(r.Path)
+}
+
+// sshArgs builds the arguments for reading the file with ssh
+func (r remoteFile) sshArgs() []string {
+	// BatchMode fails fast instead of prompting for a password mid-prompt-generation
+	args := []string{"-o", "BatchMode=yes"}
+	if r.Port != "" {
+		args = append(args, "-p", r.Port)
+	}
+	// -- ends ssh's options, so the destination is never read as one
+	return append(args, "--", r.destination(), "cat -- "+remoteShellPath(r.Path))
+}
+
+// remoteShellPath quotes a path for the remote shell, leaving a leading ~/ unquoted so it expands
+func remoteShellPath(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		return "~/" + shellQuote(strings.TrimPrefix(p, "~/"))
+	}
+	return shellQuote(p)
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fetchRemoteFile reads a remote file's content over ssh
+func fetchRemoteFile(ctx context.Context, file string) (string, error) {
+	remote, err := parseRemoteFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(fetchCtx, "ssh", remote.sshArgs()...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if fetchCtx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out fetching %s after %s", file, remoteFetchTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("failed to fetch %s: %s", file, msg)
+		}
+		return "", fmt.Errorf("failed to fetch %s: %w", file, err)
+	}
+
+	return string(output), nil
+}
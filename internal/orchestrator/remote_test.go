@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRemoteFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    remoteFile
+		wantErr bool
+	}{
+		{
+			name: "host and absolute path",
+			file: "ssh://prod-1/var/log/app.log",
+			want: remoteFile{Host: "prod-1", Path: "/var/log/app.log"},
+		},
+		{
+			name: "user and port",
+			file: "ssh://deploy@prod-1:2222/var/log/app.log",
+			want: remoteFile{User: "deploy", Host: "prod-1", Port: "2222", Path: "/var/log/app.log"},
+		},
+		{
+			name: "home relative path",
+			file: "scp://prod-1/~/app/config.yml",
+			want: remoteFile{Host: "prod-1", Path: "~/app/config.yml"},
+		},
+		{name: "missing path", file: "ssh://prod-1", wantErr: true},
+		{name: "missing host", file: "ssh:///var/log/app.log", wantErr: true},
+		{name: "host starting with -", file: "ssh://-oProxyCommand=id/x", wantErr: true},
+		{name: "user starting with -", file: "ssh://-oProxyCommand=id@prod-1/x", wantErr: true},
+		{name: "wrong scheme", file: "http://prod-1/var/log/app.log", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRemoteFile(tt.file)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRemoteFile(%q) expected error, got %+v", tt.file, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseRemoteFile(%q) = %+v, want %+v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteFile_sshArgs(t *testing.T) {
+	remote := remoteFile{User: "deploy", Host: "prod-1", Port: "2222", Path: "~/it's here.log"}
+	want := []string{"-o", "BatchMode=yes", "-p", "2222", "--", "deploy@prod-1", `cat -- ~/'it'\''s here.log'`}
+	if got := remote.sshArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sshArgs() = %q, want %q", got, want)
+	}
+}
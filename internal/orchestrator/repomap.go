@@ -0,0 +1,283 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// repoMapMaxFiles bounds the map, so a monorepo's outline still fits in a prompt
+const repoMapMaxFiles = 5000
+
+// repoMapSkippedDirs are directories the map doesn't descend into outside git repositories
+var repoMapSkippedDirs = map[string]bool{".git": true, "node_modules": true, ".venv": true, "__pycache__": true}
+
+// Exported declarations in TypeScript and JavaScript, and top-level ones in Python
+var (
+	scriptExportPattern = regexp.MustCompile(`(?m)^export\s+(?:default\s+)?(?:declare\s+)?(?:async\s+)?(function\*?|class|abstract\s+class|interface|type|enum|const|let|var)\s+([A-Za-z_$][\w$]*)`)
+	pythonDefPattern    = regexp.MustCompile(`(?m)^(?:async\s+)?(def|class)\s+([A-Za-z]\w*)`)
+)
+
+// repoMapSection builds the --map section: the tree of the project, or of --directory, with
+// the exported symbols of each Go, TypeScript, JavaScript, and Python file
+func (o *Orchestrator) repoMapSection(request *models.PromptRequest, cfg *interfaces.Config) (promptSection, bool, error) {
+	root := request.Directory
+	if root == "" {
+		cwd, err := WorkspaceDir()
+		if err != nil {
+			return promptSection{}, false, err
+		}
+		root = detectProject(cwd).Root
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return promptSection{}, false, NewContentCollectionError(root, err)
+	}
+
+	ignored, err := ignore.Find(root)
+	if err != nil {
+		return promptSection{}, false, NewContentCollectionError(root, err)
+	}
+	files, err := repoMapFiles(root, cfg.DirectoryStrategy, ignored)
+	if err != nil {
+		return promptSection{}, false, NewContentCollectionError(root, err)
+	}
+	if len(files) == 0 {
+		return promptSection{}, false, nil
+	}
+
+	content := formatRepoMap(root, files, newEmbedPolicy(request, cfg))
+	debugLog(request, "mapped %d files under %s", len(files), root)
+	return promptSection{Class: SectionTree, Source: "map", Content: content}, true, nil
+}
+
+// repoMapFiles lists the files under root, relative to it and sorted, leaving out what
+// ignored excludes. With the git strategy in a repository they are the files git tracks or
+// would; otherwise the tree is walked, skipping repoMapSkippedDirs.
+func repoMapFiles(root, strategy string, ignored *ignore.Matcher) ([]string, error) {
+	var files []string
+	if strategy == "git" {
+		if out, err := gitOutput(root, "ls-files", "-z", "--cached", "--others", "--exclude-standard"); err == nil {
+			for _, file := range strings.Split(out, "\x00") {
+				path := filepath.Join(root, file)
+				// ls-files lists deleted files too
+				if info, err := os.Lstat(path); file != "" && err == nil && info.Mode().IsRegular() && !ignored.Match(path, false) {
+					files = append(files, filepath.ToSlash(file))
+				}
+			}
+			sort.Strings(files)
+			return files, nil
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are left off the map
+		}
+		if entry.IsDir() {
+			if path != root && (repoMapSkippedDirs[entry.Name()] || ignored.Match(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && !ignored.Match(path, false) {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// formatRepoMap renders files, relative to root, as an indented tree with each source file's
+// exported symbols beneath it. Large files, and those policy would list rather than embed,
+// appear without symbols.
+func formatRepoMap(root string, files []string, policy embedPolicy) string {
+	listed := files
+	if len(listed) > repoMapMaxFiles {
+		listed = listed[:repoMapMaxFiles]
+	}
+
+	// Only source files are read, a pool of them at a time
+	var sources []string
+	for _, file := range listed {
+		if outlineLanguage(file) != "" {
+			sources = append(sources, filepath.Join(root, file))
+		}
+	}
+	reads := readFiles(sources, false, fileReadWorkers)
+	symbols := make(map[string][]string, len(sources))
+	for i, path := range sources {
+		read := reads[i]
+		if read.Info == nil || read.Large || read.Err != nil || policy.skipReason(path, read.Content) != "" {
+			continue
+		}
+		rel, _ := filepath.Rel(root, path)
+		symbols[filepath.ToSlash(rel)] = outlineSymbols(path, read.Content)
+	}
+
+	var out strings.Builder
+	var previous []string
+	for _, file := range listed {
+		dirs := strings.Split(file, "/")
+		name := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+
+		// Open the directories this file is in that the previous one wasn't
+		common := 0
+		for common < len(dirs) && common < len(previous) && dirs[common] == previous[common] {
+			common++
+		}
+		for depth := common; depth < len(dirs); depth++ {
+			fmt.Fprintf(&out, "%s%s/\n", strings.Repeat("  ", depth), dirs[depth])
+		}
+		previous = dirs
+
+		indent := strings.Repeat("  ", len(dirs))
+		fmt.Fprintf(&out, "%s%s\n", indent, name)
+		for _, symbol := range symbols[file] {
+			fmt.Fprintf(&out, "%s  %s\n", indent, symbol)
+		}
+	}
+	if more := len(files) - len(listed); more > 0 {
+		fmt.Fprintf(&out, "... %d more files\n", more)
+	}
+
+	tree := strings.TrimRight(out.String(), "\n")
+	fence := Fence(tree)
+	return fmt.Sprintf("Repository map of %s (%d files, with their exported symbols):\n%s\n%s\n%s", filepath.Base(root), len(files), fence, tree, fence)
+}
+
+// outlineLanguage returns the language outlineSymbols reads path as, or "" for files it
+// doesn't outline. Tests are listed without their symbols.
+func outlineLanguage(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	switch ext := filepath.Ext(name); {
+	case ext == ".go" && !strings.HasSuffix(name, "_test.go"):
+		return "go"
+	case ext == ".ts" || ext == ".tsx" || ext == ".js" || ext == ".jsx" || ext == ".mjs" || ext == ".cjs":
+		if strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") {
+			return ""
+		}
+		return "script"
+	case ext == ".py" && !strings.HasPrefix(name, "test_"):
+		return "python"
+	}
+	return ""
+}
+
+// outlineSymbols returns the exported declarations in content, one line each
+func outlineSymbols(path, content string) []string {
+	switch outlineLanguage(path) {
+	case "go":
+		return goSymbols(content)
+	case "script":
+		return patternSymbols(scriptExportPattern, content)
+	case "python":
+		return patternSymbols(pythonDefPattern, content)
+	}
+	return nil
+}
+
+// patternSymbols returns "kind name" for each match of pattern, whose groups are the kind and
+// the name
+func patternSymbols(pattern *regexp.Regexp, content string) []string {
+	var symbols []string
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		kind := strings.Join(strings.Fields(match[1]), " ")
+		symbols = append(symbols, kind+" "+match[2])
+	}
+	return symbols
+}
+
+// goSymbols returns the signatures of the exported functions and methods in Go source, and
+// the exported types, constants, and variables it declares. Unparsable source has none.
+func goSymbols(content string) []string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	var symbols []string
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() || (decl.Recv != nil && !exportedReceiver(decl.Recv)) {
+				continue
+			}
+			symbols = append(symbols, printNode(fset, &ast.FuncDecl{Recv: decl.Recv, Name: decl.Name, Type: decl.Type}))
+		case *ast.GenDecl:
+			symbols = append(symbols, genDeclSymbols(fset, decl)...)
+		}
+	}
+	return symbols
+}
+
+// genDeclSymbols describes a type, const, or var declaration's exported names: types by
+// their kind, constants and variables by name
+func genDeclSymbols(fset *token.FileSet, decl *ast.GenDecl) []string {
+	var symbols []string
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			if !spec.Name.IsExported() {
+				continue
+			}
+			switch spec.Type.(type) {
+			case *ast.StructType:
+				symbols = append(symbols, "type "+spec.Name.Name+" struct")
+			case *ast.InterfaceType:
+				symbols = append(symbols, "type "+spec.Name.Name+" interface")
+			default:
+				alias := " "
+				if spec.Assign.IsValid() {
+					alias = " = "
+				}
+				symbols = append(symbols, "type "+spec.Name.Name+alias+printNode(fset, spec.Type))
+			}
+		case *ast.ValueSpec:
+			var names []string
+			for _, name := range spec.Names {
+				if name.IsExported() {
+					names = append(names, name.Name)
+				}
+			}
+			if len(names) > 0 {
+				symbols = append(symbols, decl.Tok.String()+" "+strings.Join(names, ", "))
+			}
+		}
+	}
+	return symbols
+}
+
+// exportedReceiver reports whether a method's receiver type is exported
+func exportedReceiver(recv *ast.FieldList) bool {
+	return ast.IsExported(receiverName(recv))
+}
+
+// printNode formats a syntax tree node on one line
+func printNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/ignore"
+)
+
+func TestGoSymbols(t *testing.T) {
+	source := `package api
+
+import "context"
+
+const Version, build = "1.0", "dev"
+
+var ErrClosed = errors.New("closed")
+
+type Client struct{ token string }
+
+type Store interface{ Get(key string) string }
+
+type ID = string
+
+type Handler func(ctx context.Context) error
+
+type state int
+
+func New(token string) *Client { return &Client{token: token} }
+
+func (c *Client) Fetch(ctx context.Context, id ID) ([]byte, error) { return nil, nil }
+
+func (c *Client) close() {}
+
+func (s state) String() string { return "" }
+
+func helper() {}
+`
+	want := []string{
+		"const Version",
+		"var ErrClosed",
+		"type Client struct",
+		"type Store interface",
+		"type ID = string",
+		"type Handler func(ctx context.Context) error",
+		"func New(token string) *Client",
+		"func (c *Client) Fetch(ctx context.Context, id ID) ([]byte, error)",
+	}
+	if got := goSymbols(source); !reflect.DeepEqual(got, want) {
+		t.Errorf("goSymbols() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := goSymbols("package broken\nfunc {"); got != nil {
+		t.Errorf("goSymbols() of unparsable source = %v, want none", got)
+	}
+}
+
+func TestOutlineSymbols(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "typescript",
+			path:    "src/api.ts",
+			content: "import x from 'y'\nexport interface User { id: string }\nexport default async function load() {}\nexport const client = make()\nfunction internal() {}\nexport abstract class Base {}\n",
+			want:    []string{"interface User", "function load", "const client", "abstract class Base"},
+		},
+		{
+			name:    "python",
+			path:    "app/models.py",
+			content: "class User:\n    def save(self):\n        pass\n\nasync def fetch():\n    pass\n\ndef _private():\n    pass\n",
+			want:    []string{"class User", "def fetch"},
+		},
+		{"go test", "api_test.go", "package api\n\nfunc TestX(t *testing.T) {}\n", nil},
+		{"spec", "src/api.spec.ts", "export const x = 1\n", nil},
+		{"markdown", "README.md", "export const x = 1\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outlineSymbols(tt.path, tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outlineSymbols(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoMap(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                 "module example\n",
+		"cmd/app/main.go":        "package main\n\nfunc main() {}\n",
+		"internal/api/client.go": "package api\n\nfunc New() *Client { return nil }\n\ntype Client struct{}\n",
+		"internal/api/gen.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n\ntype Message struct{}\n",
+		"web/index.ts":           "export function render() {}\n",
+		"fixtures/big.json":      "{}\n",
+		"node_modules/x/a.js":    "export const a = 1\n",
+		ignore.FileName:          "fixtures/\n",
+	}
+	writeTree(t, root, files)
+	ignored, err := ignore.Find(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside a repository the tree is walked whatever the strategy
+	listed, err := repoMapFiles(root, "git", ignored)
+	if err != nil {
+		t.Fatalf("repoMapFiles() error = %v", err)
+	}
+	want := []string{ignore.FileName, "cmd/app/main.go", "go.mod", "internal/api/client.go", "internal/api/gen.pb.go", "web/index.ts"}
+	if !reflect.DeepEqual(listed, want) {
+		t.Errorf("repoMapFiles() = %v, want %v", listed, want)
+	}
+
+	got := formatRepoMap(root, listed, embedPolicy{})
+	wantTree := strings.Join([]string{
+		ignore.FileName,
+		"cmd/",
+		"  app/",
+		"    main.go",
+		"go.mod",
+		"internal/",
+		"  api/",
+		"    client.go",
+		"      func New() *Client",
+		"      type Client struct",
+		"    gen.pb.go",
+		"web/",
+		"  index.ts",
+		"    function render",
+	}, "\n")
+	if !strings.Contains(got, "(6 files, with their exported symbols)") || !strings.Contains(got, "```\n"+wantTree+"\n```") {
+		t.Errorf("formatRepoMap() =\n%s\nwant the tree\n%s", got, wantTree)
+	}
+}
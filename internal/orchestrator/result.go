@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Output formats for --format
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMessages = "messages"
+)
+
+// OutputFormats lists the formats --format accepts
+var OutputFormats = []string{FormatText, FormatJSON, FormatMessages}
+
+// resultJSON is the --format json document: the sections plus their totals
+type resultJSON struct {
+	Sections []models.Section `json:"sections"`
+	Bytes    int              `json:"bytes"`
+	Tokens   int              `json:"tokens"`
+}
+
+// chatMessage is one entry of the --format messages array, as chat completion APIs take it
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// resultMessages splits result into a system message from the pre-templates and a user
+// message from everything else. Without a pre-template there is only the user message.
+func resultMessages(result *models.Result) []chatMessage {
+	var system, user []string
+	for _, section := range result.Sections {
+		if strings.HasPrefix(section.Source, "pre:") {
+			system = append(system, section.Content)
+		} else {
+			user = append(user, section.Content)
+		}
+	}
+
+	messages := []chatMessage{}
+	if len(system) > 0 {
+		messages = append(messages, chatMessage{Role: "system", Content: strings.Join(system, "\n\n")})
+	}
+	if len(user) > 0 {
+		messages = append(messages, chatMessage{Role: "user", Content: strings.Join(user, "\n\n")})
+	}
+	return messages
+}
+
+// newResult turns the assembled sections into a result, dropping empty ones
+func newResult(sections []promptSection) *models.Result {
+	result := &models.Result{Sections: []models.Section{}}
+	for _, section := range sections {
+		if section.Content == "" {
+			continue
+		}
+		result.Sections = append(result.Sections, models.Section{
+			Type:    section.Class,
+			Source:  section.Source,
+			Content: section.Content,
+			Bytes:   len(section.Content),
+			Tokens:  estimateTokens(section.Content),
+		})
+	}
+	return result
+}
+
+// FormatResult serializes result in format: the plain prompt for text (or ""), a JSON
+// document of its sections for json, or a system/user messages array for messages
+func FormatResult(result *models.Result, format string) (string, error) {
+	switch format {
+	case "", FormatText:
+		return result.Text(), nil
+	case FormatJSON:
+		text := result.Text()
+		data, err := json.MarshalIndent(resultJSON{Sections: result.Sections, Bytes: len(text), Tokens: result.Tokens()}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode the prompt as JSON: %w", err)
+		}
+		return string(data), nil
+	case FormatMessages:
+		data, err := json.MarshalIndent(resultMessages(result), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode the prompt as messages: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", NewValidationError("format", format, "must be one of "+strings.Join(OutputFormats, ", "))
+	}
+}
@@ -0,0 +1,323 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestNewResult(t *testing.T) {
+	result := newResult([]promptSection{
+		{Class: SectionBase, Source: "pre:review", Content: "Review this."},
+		{Class: SectionContext, Source: "url:https://example.com", Content: ""},
+		{Class: SectionBase, Source: "prompt", Content: "looks fine?"},
+	})
+
+	if len(result.Sections) != 2 {
+		t.Fatalf("newResult() kept %d sections, want 2 (empty ones dropped)", len(result.Sections))
+	}
+	want := models.Section{Type: SectionBase, Source: "pre:review", Content: "Review this.", Bytes: 12, Tokens: 3}
+	if result.Sections[0] != want {
+		t.Errorf("newResult() first section = %+v, want %+v", result.Sections[0], want)
+	}
+	if got := result.Text(); got != "Review this.\n\nlooks fine?" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	result := newResult([]promptSection{
+		{Class: SectionBase, Source: "prompt", Content: "hello"},
+		{Class: SectionFiles, Source: "files", Content: "Referencing file:\nmain.go"},
+	})
+
+	text, err := FormatResult(result, "")
+	if err != nil || text != result.Text() {
+		t.Errorf("FormatResult(text) = %q, %v; want the plain prompt", text, err)
+	}
+
+	out, err := FormatResult(result, FormatJSON)
+	if err != nil {
+		t.Fatalf("FormatResult(json) error = %v", err)
+	}
+	var doc resultJSON
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("FormatResult(json) isn't JSON: %v\n%s", err, out)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[1].Source != "files" || doc.Bytes != len(result.Text()) || doc.Tokens != result.Tokens() {
+		t.Errorf("FormatResult(json) = %+v", doc)
+	}
+
+	if _, err := FormatResult(result, "yaml"); err == nil {
+		t.Error("FormatResult(yaml) should fail")
+	}
+}
+
+func TestOrchestrator_InlineTemplates(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.BasePrompt = "fix the race"
+	request.PreInline = "You are a senior {{ .Project.Type | default \"Go\" }} reviewer."
+	request.PostInline = "Answer with a unified diff only."
+
+	result, err := New().generateNormalPrompt(context.Background(), request, &interfaces.Config{PromptsLocation: t.TempDir()})
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	if len(result.Sections) != 3 || result.Sections[0].Source != "pre:inline" || result.Sections[2].Source != "post:inline" {
+		t.Fatalf("sections = %+v, want the inline pre and post around the prompt", result.Sections)
+	}
+	if strings.Contains(result.Sections[0].Content, "{{") {
+		t.Errorf("pre-inline wasn't rendered: %q", result.Sections[0].Content)
+	}
+
+	request.PreInline = "{{ .Unclosed"
+	if _, err := New().generateNormalPrompt(context.Background(), request, &interfaces.Config{}); err == nil {
+		t.Error("expected error for invalid --pre-inline template text")
+	}
+}
+
+func TestOrchestrator_CustomConfigKeys(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.BasePrompt = "write the release notes"
+	request.PreInline = "{{.Config.product_name}} ({{.Config.style.guide}}), sent to {{.Config.target}}"
+
+	cfg := &interfaces.Config{
+		PromptsLocation: t.TempDir(),
+		Target:          "stdout",
+		Settings: map[string]interface{}{
+			"product_name": "Ledger",
+			"style":        map[string]interface{}{"guide": "https://example.com/style"},
+			"target":       "clipboard",
+		},
+	}
+	result, err := New().generateNormalPrompt(context.Background(), request, cfg)
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	if got, want := result.Sections[0].Content, "Ledger (https://example.com/style), sent to stdout"; got != want {
+		t.Errorf("pre-template = %q, want %q", got, want)
+	}
+}
+
+func TestFormatResultMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []promptSection
+		want     []chatMessage
+	}{
+		{
+			name: "pre-templates are the system message",
+			sections: []promptSection{
+				{Class: SectionBase, Source: "pre:review", Content: "You review Go."},
+				{Class: SectionBase, Source: "pre:inline", Content: "Be terse."},
+				{Class: SectionBase, Source: "prompt", Content: "check this"},
+				{Class: SectionFiles, Source: "files", Content: "Referencing file:\nmain.go"},
+				{Class: SectionBase, Source: "post:diff-only", Content: "Diff only."},
+			},
+			want: []chatMessage{
+				{Role: "system", Content: "You review Go.\n\nBe terse."},
+				{Role: "user", Content: "check this\n\nReferencing file:\nmain.go\n\nDiff only."},
+			},
+		},
+		{
+			name:     "no pre-template",
+			sections: []promptSection{{Class: SectionBase, Source: "prompt", Content: "hello"}},
+			want:     []chatMessage{{Role: "user", Content: "hello"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := FormatResult(newResult(tt.sections), FormatMessages)
+			if err != nil {
+				t.Fatalf("FormatResult(messages) error = %v", err)
+			}
+			var got []chatMessage
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("FormatResult(messages) isn't JSON: %v\n%s", err, out)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FormatResult(messages) = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_FixModeTemplates(t *testing.T) {
+	promptsDir := t.TempDir()
+	writeTree(t, promptsDir, map[string]string{"pre/persona.md": "You are terse.", "post/diff.md": "Diff only."})
+	fixFile := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ go build\nmain.go:3: undefined: x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := models.NewPromptRequest()
+	request.Interactive = false
+	request.FixMode = true
+	request.FixFile = fixFile
+	request.NoFixFiles = true
+	cfg := &interfaces.Config{PromptsLocation: promptsDir, DefaultPre: "unused", FixDefaultPre: "persona", FixDefaultPost: "diff"}
+
+	o := New()
+	o.confirm = func(string, string, bool, bool) (bool, error) { return true, nil }
+	if err := o.applyConfigDefaults(request, cfg); err != nil {
+		t.Fatal(err)
+	}
+	result, err := o.generateFixModePrompt(context.Background(), request, cfg)
+	if err != nil {
+		t.Fatalf("generateFixModePrompt() error = %v", err)
+	}
+
+	var sources []string
+	for _, section := range result.Sections {
+		sources = append(sources, section.Source)
+	}
+	if want := []string{"pre:persona", "fix.md", "fix", "post:diff"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("sections = %v, want %v", sources, want)
+	}
+}
+
+func TestOrchestrator_FixHookCommandRunsOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	promptsDir := filepath.Join(dir, "prompts")
+	writeTree(t, promptsDir, map[string]string{"pre/persona.md": "Ran {{.Fix.Command}}.", "post/diff.md": "Diff only."})
+	// The fish hook records the command without its output, so capturing re-runs it
+	count := filepath.Join(dir, "count.txt")
+	fixFile := filepath.Join(dir, "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ echo ran >> "+count+"; exit 1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(hookEnvVar, shellFish)
+
+	request := models.NewPromptRequest()
+	request.FixMode = true
+	request.FixFile = fixFile
+	request.NoFixFiles = true
+	cfg := &interfaces.Config{PromptsLocation: promptsDir, FixDefaultPre: "persona", FixDefaultPost: "diff"}
+
+	o := New()
+	o.confirm = func(string, string, bool, bool) (bool, error) { return true, nil }
+	if err := o.applyConfigDefaults(request, cfg); err != nil {
+		t.Fatal(err)
+	}
+	result, err := o.generateFixModePrompt(context.Background(), request, cfg)
+	if err != nil {
+		t.Fatalf("generateFixModePrompt() error = %v", err)
+	}
+	if want := "Ran echo ran >> " + count + "; exit 1."; result.Sections[0].Content != want {
+		t.Errorf("pre-template = %q, want %q", result.Sections[0].Content, want)
+	}
+	if runs, err := os.ReadFile(count); err != nil || string(runs) != "ran\n" {
+		t.Errorf("command ran %q, %v; want once", runs, err)
+	}
+}
+
+func TestOrchestrator_NamedFixPrompt(t *testing.T) {
+	promptsDir := t.TempDir()
+	writeTree(t, promptsDir, map[string]string{"fix.md": "Please fix", "fix/test.md": "Make the tests pass."})
+	fixFile := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ go test\nFAIL"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, fixTemplate, source, content string
+	}{
+		{name: "default", source: "fix.md", content: "Please fix"},
+		{name: "named", fixTemplate: "test", source: filepath.Join("fix", "test.md"), content: "Make the tests pass."},
+		{name: "missing falls back to fix.md", fixTemplate: "lint", source: "fix.md", content: "Please fix"},
+		{name: "outside fix/", fixTemplate: "../fix", source: "fix.md", content: "Please fix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.NewPromptRequest()
+			request.Interactive = false
+			request.FixMode = true
+			request.FixTemplate = tt.fixTemplate
+			request.FixFile = fixFile
+			request.NoFixFiles = true
+
+			result, err := New().generateFixModePrompt(context.Background(), request, &interfaces.Config{PromptsLocation: promptsDir})
+			if err != nil {
+				t.Fatalf("generateFixModePrompt() error = %v", err)
+			}
+			if section := result.Sections[0]; section.Source != tt.source || section.Content != tt.content {
+				t.Errorf("fix prompt = %s %q, want %s %q", section.Source, section.Content, tt.source, tt.content)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_FixPromptTemplate(t *testing.T) {
+	fixFile := filepath.Join(t.TempDir(), "fix.txt")
+	if err := os.WriteFile(fixFile, []byte("$ go build\nmain.go:3: undefined: x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, fixPrompt string
+		wantPrompt      string
+		wantSources     []string
+	}{
+		{
+			name:        "static",
+			fixPrompt:   "Please fix",
+			wantPrompt:  "Please fix",
+			wantSources: []string{"fix.md", "fix"},
+		},
+		{
+			name:        "uses the command",
+			fixPrompt:   "Fix `{{.Fix.Command}}`:",
+			wantPrompt:  "Fix `go build`:",
+			wantSources: []string{"fix.md", "fix"},
+		},
+		{
+			name:        "lays out the output",
+			fixPrompt:   "{{range .Fix.Diagnostics}}{{.File}}:{{.Line}} {{end}}\n{{mdFence \"\" .Fix.Output}}",
+			wantPrompt:  "main.go:3 \n```\nmain.go:3: undefined: x\n```",
+			wantSources: []string{"fix.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptsDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(promptsDir, "fix.md"), []byte(tt.fixPrompt), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			request := models.NewPromptRequest()
+			request.Interactive = false
+			request.FixMode = true
+			request.FixFile = fixFile
+			request.NoFixFiles = true
+
+			result, err := New().generateFixModePrompt(context.Background(), request, &interfaces.Config{PromptsLocation: promptsDir})
+			if err != nil {
+				t.Fatalf("generateFixModePrompt() error = %v", err)
+			}
+			var sources []string
+			for _, section := range result.Sections {
+				sources = append(sources, section.Source)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sections = %v, want %v", sources, tt.wantSources)
+			}
+			if got := result.Sections[0].Content; got != tt.wantPrompt {
+				t.Errorf("fix prompt = %q, want %q", got, tt.wantPrompt)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Review actions offered while trimming captured fix output
+const (
+	reviewUseAsIs     = "Use output as is"
+	reviewKeepLast    = "Keep only the last N lines"
+	reviewDeleteRange = "Delete a range of lines"
+	reviewDeleteMatch = "Delete lines containing text"
+	reviewShowAll     = "Show all lines"
+	reviewReset       = "Undo all changes"
+)
+
+// reviewPreviewLines is how many trailing lines are shown in the preview
+const reviewPreviewLines = 15
+
+// reviewFixContent shows captured fix output and lets the user trim it before
+// it is added to the prompt. The leading "$ command" header is always kept.
+func (o *Orchestrator) reviewFixContent(content string) (string, error) {
+	header, output := splitFixHeader(content)
+	original := splitLines(output)
+	lines := original
+
+	for {
+		printReviewPreview(header, lines, false)
+
+		prompt := &survey.Select{
+			Message: "Review captured output:",
+			Options: []string{reviewUseAsIs, reviewKeepLast, reviewDeleteRange, reviewDeleteMatch, reviewShowAll, reviewReset},
+			Default: reviewUseAsIs,
+			Help:    "Trim noise from the captured output before it is added to the prompt",
+		}
+
+		var action string
+		if err := survey.AskOne(prompt, &action); err != nil {
+			return "", err
+		}
+
+		switch action {
+		case reviewUseAsIs:
+			return joinFixContent(header, lines), nil
+
+		case reviewKeepLast:
+			n, err := askInt(fmt.Sprintf("Number of lines to keep (1-%d):", len(lines)))
+			if err != nil {
+				return "", err
+			}
+			lines = keepLastLines(lines, n)
+
+		case reviewDeleteRange:
+			var input string
+			if err := survey.AskOne(&survey.Input{
+				Message: "Lines to delete (e.g. 10-250):",
+			}, &input); err != nil {
+				return "", err
+			}
+			start, end, err := parseLineRange(input, len(lines))
+			if err != nil {
+				fmt.Printf("  %v\n", err)
+				continue
+			}
+			lines = deleteLineRange(lines, start, end)
+
+		case reviewDeleteMatch:
+			var text string
+			if err := survey.AskOne(&survey.Input{
+				Message: "Delete lines containing:",
+			}, &text); err != nil {
+				return "", err
+			}
+			lines = deleteMatchingLines(lines, text)
+
+		case reviewShowAll:
+			printReviewPreview(header, lines, true)
+
+		case reviewReset:
+			lines = original
+		}
+	}
+}
+
+// printReviewPreview prints numbered output lines, either all or just the tail
+func printReviewPreview(header string, lines []string, all bool) {
+	fmt.Println()
+	if header != "" {
+		fmt.Println(header)
+	}
+	fmt.Printf("Captured output: %d lines, %d bytes\n", len(lines), len(strings.Join(lines, "\n")))
+
+	start := 0
+	if !all && len(lines) > reviewPreviewLines {
+		start = len(lines) - reviewPreviewLines
+		fmt.Printf("  ... (%d earlier lines hidden)\n", start)
+	}
+	for i := start; i < len(lines); i++ {
+		fmt.Printf("%5d | %s\n", i+1, lines[i])
+	}
+	fmt.Println()
+}
+
+// askInt prompts for a positive integer
+func askInt(message string) (int, error) {
+	var input string
+	validator := func(ans interface{}) error {
+		n, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(ans)))
+		if err != nil || n < 1 {
+			return fmt.Errorf("please enter a positive number")
+		}
+		return nil
+	}
+	if err := survey.AskOne(&survey.Input{Message: message}, &input, survey.WithValidator(validator)); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(input))
+}
+
+// splitFixHeader separates a leading "$ command" line from the captured output
+func splitFixHeader(content string) (string, string) {
+	if !strings.HasPrefix(content, "$ ") {
+		return "", content
+	}
+	parts := strings.SplitN(content, "\n", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], strings.TrimLeft(parts[1], "\n")
+}
+
+// joinFixContent reassembles the header and output in the captured format
+func joinFixContent(header string, lines []string) string {
+	output := strings.Join(lines, "\n")
+	if header == "" {
+		return output
+	}
+	return strings.TrimSpace(header + "\n\n" + output)
+}
+
+// splitLines splits text into lines, returning nil for empty text
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// keepLastLines returns the last n lines
+func keepLastLines(lines []string, n int) []string {
+	if n >= len(lines) {
+		return lines
+	}
+	return lines[len(lines)-n:]
+}
+
+// parseLineRange parses "a-b" or "a" (1-based, inclusive) against total lines
+func parseLineRange(input string, total int) (int, int, error) {
+	input = strings.TrimSpace(input)
+	startText, endText, isRange := strings.Cut(input, "-")
+	if !isRange {
+		endText = startText
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: use a line number or start-end", input)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: use a line number or start-end", input)
+	}
+
+	if start < 1 || end < start || end > total {
+		return 0, 0, fmt.Errorf("invalid range %q: lines must be between 1 and %d", input, total)
+	}
+
+	return start, end, nil
+}
+
+// deleteLineRange removes lines start through end (1-based, inclusive)
+func deleteLineRange(lines []string, start, end int) []string {
+	result := make([]string, 0, len(lines)-(end-start+1))
+	result = append(result, lines[:start-1]...)
+	return append(result, lines[end:]...)
+}
+
+// deleteMatchingLines removes every line containing text
+func deleteMatchingLines(lines []string, text string) []string {
+	if text == "" {
+		return lines
+	}
+	var result []string
+	for _, line := range lines {
+		if !strings.Contains(line, text) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFixHeader(t *testing.T) {
+	header, output := splitFixHeader("$ go build\n\nmain.go:1: error")
+	if header != "$ go build" || output != "main.go:1: error" {
+		t.Errorf("unexpected split: %q / %q", header, output)
+	}
+
+	header, output = splitFixHeader("plain output")
+	if header != "" || output != "plain output" {
+		t.Errorf("expected no header, got %q / %q", header, output)
+	}
+
+	if joined := joinFixContent("$ go build", []string{"a", "b"}); joined != "$ go build\n\na\nb" {
+		t.Errorf("unexpected join: %q", joined)
+	}
+}
+
+func TestTrimHelpers(t *testing.T) {
+	lines := []string{"one", "two", "noise", "three", "noise again"}
+
+	if got := keepLastLines(lines, 2); !reflect.DeepEqual(got, []string{"three", "noise again"}) {
+		t.Errorf("keepLastLines = %q", got)
+	}
+	if got := keepLastLines(lines, 10); len(got) != 5 {
+		t.Errorf("keepLastLines with large n = %q", got)
+	}
+	if got := deleteLineRange(lines, 2, 3); !reflect.DeepEqual(got, []string{"one", "three", "noise again"}) {
+		t.Errorf("deleteLineRange = %q", got)
+	}
+	if got := deleteMatchingLines(lines, "noise"); !reflect.DeepEqual(got, []string{"one", "two", "three"}) {
+		t.Errorf("deleteMatchingLines = %q", got)
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		input      string
+		start, end int
+		wantErr    bool
+	}{
+		{input: "2-4", start: 2, end: 4},
+		{input: " 3 ", start: 3, end: 3},
+		{input: "0-2", wantErr: true},
+		{input: "4-2", wantErr: true},
+		{input: "1-99", wantErr: true},
+		{input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := parseLineRange(tt.input, 10)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseLineRange(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || start != tt.start || end != tt.end {
+			t.Errorf("parseLineRange(%q) = %d, %d, %v", tt.input, start, end, err)
+		}
+	}
+}
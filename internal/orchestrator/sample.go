@@ -0,0 +1,185 @@
+package orchestrator
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// How files over maxEmbeddedFileBytes are included, for large_files
+const (
+	LargeFilesPath   = "path"   // Listed by path only
+	LargeFilesSample = "sample" // Excerpted with sampleFile
+)
+
+// sampleWindows is how many excerpts from evenly spaced points in the middle a sample gets
+const sampleWindows = 3
+
+// sampleRegion is a byte range of a file kept in a sample, [start, end)
+type sampleRegion struct {
+	start, end int64
+}
+
+// sampleFile excerpts a large file in about limit bytes: its head and tail (two fifths of the
+// limit each) and sampleWindows windows from evenly spaced points in between, cut at line
+// boundaries, with a marker naming the lines left out of each gap. Only the excerpts are read,
+// plus one pass counting lines, so generated SQL or a big fixture doesn't have to fit in memory.
+func sampleFile(path string, limit int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+	if size <= int64(limit) {
+		content, err := io.ReadAll(f)
+		return string(content), err
+	}
+
+	edge, window := int64(limit)*2/5, int64(limit)/5/sampleWindows
+	regions := []sampleRegion{{0, edge}, {size - edge, size}}
+	for i := int64(1); i <= sampleWindows; i++ {
+		center := size * i / (sampleWindows + 1)
+		regions = append(regions, sampleRegion{center - window/2, center + window/2})
+	}
+	slices.SortFunc(regions, func(a, b sampleRegion) int { return cmp.Compare(a.start, b.start) })
+
+	// Read each region and trim it to whole lines, dropping the partial line at either end
+	var texts [][]byte
+	var kept []sampleRegion
+	for _, region := range regions {
+		data := make([]byte, region.end-region.start)
+		if _, err := f.ReadAt(data, region.start); err != nil && err != io.EOF {
+			return "", err
+		}
+		if region.start > 0 {
+			if i := bytes.IndexByte(data, '\n'); i >= 0 && i < len(data)-1 {
+				region.start += int64(i + 1)
+				data = data[i+1:]
+			}
+		}
+		if region.end < size {
+			if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+				region.end -= int64(len(data) - i - 1)
+				data = data[:i+1]
+			}
+		}
+		lo, hi := runeBounds(data)
+		region.start, region.end = region.start+int64(lo), region.end-int64(len(data)-hi)
+		data = data[lo:hi]
+
+		// Regions meet when the file is barely over the limit; keep the overlap once
+		if n := len(kept); n > 0 && region.start <= kept[n-1].end {
+			if region.end > kept[n-1].end {
+				texts[n-1] = append(texts[n-1], data[kept[n-1].end-region.start:]...)
+				kept[n-1].end = region.end
+			}
+			continue
+		}
+		kept = append(kept, region)
+		texts = append(texts, data)
+	}
+
+	offsets := make([]int64, 0, 2*len(kept))
+	for _, region := range kept {
+		offsets = append(offsets, region.start, region.end)
+	}
+	newlines, err := countNewlines(f, offsets)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for i, region := range kept {
+		if i > 0 {
+			size := formatSize(region.start - kept[i-1].end)
+			if first, last := newlines[2*i-1]+1, newlines[2*i]; last >= first {
+				fmt.Fprintf(&out, "\n[... lines %d-%d omitted (%s) ...]\n", first, last, size)
+			} else {
+				// No whole line fits in the gap, as in minified files
+				fmt.Fprintf(&out, "\n[... %s omitted ...]\n", size)
+			}
+		}
+		out.Write(bytes.TrimRight(texts[i], "\n"))
+	}
+	return out.String(), nil
+}
+
+// readEmbeddedFile reads a file to embed, or an excerpt of it when it's too large to embed
+// whole
+func readEmbeddedFile(path string, large bool) (string, error) {
+	if large {
+		return sampleFile(path, maxEmbeddedFileBytes)
+	}
+	content, err := os.ReadFile(path)
+	return string(content), err
+}
+
+// countNewlines returns the number of newlines before each of offsets, which are ascending
+func countNewlines(f *os.File, offsets []int64) ([]int, error) {
+	counts := make([]int, len(offsets))
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 64*1024)
+	var pos int64
+	count, next := 0, 0
+	for next < len(offsets) {
+		n, err := f.Read(buf)
+		chunk := buf[:n]
+		for next < len(offsets) && offsets[next] <= pos+int64(n) {
+			counts[next] = count + bytes.Count(chunk[:offsets[next]-pos], []byte("\n"))
+			next++
+		}
+		count += bytes.Count(chunk, []byte("\n"))
+		pos += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for ; next < len(offsets); next++ {
+		counts[next] = count
+	}
+	return counts, nil
+}
+
+// runeBounds returns the bounds of data without the incomplete UTF-8 sequences it can start or
+// end with, as excerpts of a file without newlines (minified JSON) are cut at arbitrary bytes
+func runeBounds(data []byte) (lo, hi int) {
+	hi = len(data)
+	for lo < hi && !utf8.RuneStart(data[lo]) {
+		lo++
+	}
+	for i := hi - 1; i >= lo && i >= hi-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:hi]) {
+				hi = i
+			}
+			break
+		}
+	}
+	return lo, hi
+}
+
+// formatSize formats n bytes as B, KB, or MB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1000*1000:
+		return fmt.Sprintf("%.1f MB", float64(n)/1000/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1f KB", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSampleFile(t *testing.T) {
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i := 1; i <= 20000; i++ {
+		fmt.Fprintf(&lines, "INSERT INTO events VALUES (%d, 'row');\n", i)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		markers int
+	}{
+		{"small file is returned whole", "a\nb\n", 1024, 0},
+		{"lines", lines.String(), 4096, 4},
+		{"just over the limit", lines.String()[:5000], 4096, 2},
+		{"minified", "[" + strings.Repeat(`{"név":"é"},`, 20000) + "]", 4096, 4},
+	}
+	markerPattern := regexp.MustCompile(`^\[\.\.\. (?:lines (\d+)-(\d+) omitted \(.+\)|.+ omitted) \.\.\.\]$`)
+	rowPattern := regexp.MustCompile(`^INSERT INTO events VALUES \((\d+), 'row'\);$`)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-"))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := sampleFile(path, tt.limit)
+			if err != nil {
+				t.Fatalf("sampleFile() error = %v", err)
+			}
+			if tt.markers == 0 {
+				if got != tt.content {
+					t.Errorf("sampleFile() = %q, want the whole file", got)
+				}
+				return
+			}
+			if len(got) > tt.limit+1024 || !utf8.ValidString(got) {
+				t.Errorf("sampleFile() returned %d bytes (valid UTF-8: %v), want about %d", len(got), utf8.ValidString(got), tt.limit)
+			}
+
+			// Rows run on from each marker's last omitted line, from the first row to the last
+			markers, want := 0, 1
+			for _, line := range strings.Split(got, "\n") {
+				if m := markerPattern.FindStringSubmatch(line); m != nil {
+					markers++
+					if m[1] != "" {
+						if first, _ := strconv.Atoi(m[1]); first != want {
+							t.Errorf("marker %q starts at line %d, want %d", line, first, want)
+						}
+						want, _ = strconv.Atoi(m[2])
+						want++
+					}
+					continue
+				}
+				if m := rowPattern.FindStringSubmatch(line); m != nil {
+					if row, _ := strconv.Atoi(m[1]); row != want {
+						t.Errorf("row %d comes where line %d should", row, want)
+					}
+					want++
+				}
+			}
+			if markers != tt.markers {
+				t.Errorf("sampleFile() has %d markers, want %d:\n%s", markers, tt.markers, got)
+			}
+			if strings.HasPrefix(tt.content, "INSERT") && !strings.HasSuffix(got, strings.TrimRight(tt.content[strings.LastIndex(strings.TrimRight(tt.content, "\n"), "\n")+1:], "\n")) {
+				t.Errorf("sampleFile() doesn't end with the file's last line")
+			}
+		})
+	}
+}
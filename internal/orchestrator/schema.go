@@ -0,0 +1,201 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// schemaTypes are the values JSON Schema allows for "type"
+var schemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// Schema keywords whose values are subschemas, by shape
+var (
+	schemaKeywords     = []string{"items", "additionalProperties", "additionalItems", "contains", "not", "if", "then", "else", "propertyNames", "unevaluatedProperties", "unevaluatedItems"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+)
+
+// loadSchema reads the JSON Schema at path for --schema, checks it, and returns it indented
+// consistently, with its keys in the order the file has them
+func loadSchema(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", NewContentCollectionError(path, err)
+	}
+
+	var schema any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&schema); err != nil {
+		return "", NewValidationError("schema", path, "not valid JSON: "+jsonErrorPosition(data, err))
+	}
+	if decoder.More() {
+		return "", NewValidationError("schema", path, "not valid JSON: more than one value")
+	}
+	if _, ok := schema.(map[string]any); !ok {
+		return "", NewValidationError("schema", path, "must be a JSON object")
+	}
+	if err := checkSchema(schema, "#"); err != nil {
+		return "", NewValidationError("schema", path, err.Error())
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bytes.TrimSpace(data), "", "  "); err != nil {
+		return "", NewValidationError("schema", path, "not valid JSON: "+err.Error())
+	}
+	return indented.String(), nil
+}
+
+// jsonErrorPosition adds the line and column to a syntax error from decoding data
+func jsonErrorPosition(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err.Error()
+	}
+	before := data[:syntaxErr.Offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1 // Offset is just past the bad byte
+	return fmt.Sprintf("%v at line %d, column %d", err, line, column)
+}
+
+// checkSchema checks the keywords of schema that shape the output, reporting the first problem
+// with its JSON pointer, e.g. #/properties/name/type. Keywords it doesn't know are left alone.
+func checkSchema(schema any, pointer string) error {
+	if _, ok := schema.(bool); ok {
+		return nil // true and false are schemas that accept anything and nothing
+	}
+	object, ok := schema.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: a schema must be an object or a boolean", pointer)
+	}
+
+	if value, ok := object["type"]; ok {
+		if err := checkSchemaType(value, pointer+"/type"); err != nil {
+			return err
+		}
+	}
+	if value, ok := object["enum"]; ok {
+		if _, ok := value.([]any); !ok {
+			return fmt.Errorf("%s/enum: must be an array", pointer)
+		}
+	}
+
+	properties, _ := object["properties"].(map[string]any)
+	if value, ok := object["required"]; ok {
+		required, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s/required: must be an array of property names", pointer)
+		}
+		for i, name := range required {
+			name, ok := name.(string)
+			if !ok {
+				return fmt.Errorf("%s/required/%d: must be a property name", pointer, i)
+			}
+			if _, defined := properties[name]; properties != nil && !defined {
+				return fmt.Errorf("%s/required/%d: %q isn't in properties", pointer, i, name)
+			}
+		}
+	}
+
+	for _, keyword := range schemaKeywords {
+		value, ok := object[keyword]
+		if !ok {
+			continue
+		}
+		// Older drafts give items as a list, one schema per position
+		if list, isList := value.([]any); isList && keyword == "items" {
+			for i, item := range list {
+				if err := checkSchema(item, fmt.Sprintf("%s/items/%d", pointer, i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := checkSchema(value, pointer+"/"+keyword); err != nil {
+			return err
+		}
+	}
+	for _, keyword := range schemaListKeywords {
+		value, ok := object[keyword]
+		if !ok {
+			continue
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s/%s: must be an array of schemas", pointer, keyword)
+		}
+		for i, item := range list {
+			if err := checkSchema(item, fmt.Sprintf("%s/%s/%d", pointer, keyword, i)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range schemaMapKeywords {
+		value, ok := object[keyword]
+		if !ok {
+			continue
+		}
+		schemas, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s/%s: must be an object of schemas", pointer, keyword)
+		}
+		for _, name := range sortedKeys(schemas) {
+			if err := checkSchema(schemas[name], pointer+"/"+keyword+"/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSchemaType checks a "type" value: one of schemaTypes or a list of them
+func checkSchemaType(value any, pointer string) error {
+	names, isList := value.([]any)
+	if !isList {
+		names = []any{value}
+	}
+	for _, name := range names {
+		name, ok := name.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a type name or a list of them", pointer)
+		}
+		if !slices.Contains(schemaTypes, name) {
+			return fmt.Errorf("%s: %q is not a JSON Schema type (use one of %s)", pointer, name, strings.Join(schemaTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in order, so the first problem reported doesn't vary
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// escapePointer escapes a property name for use in a JSON pointer
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// schemaSection returns the output contract for the schema at path. It goes after everything
+// else, where the model reads it last.
+func schemaSection(path string) (promptSection, error) {
+	schema, err := loadSchema(path)
+	if err != nil {
+		return promptSection{}, err
+	}
+	fence := Fence(schema)
+	content := "## Output format\n\n" +
+		"Respond with only a JSON value that matches this JSON Schema. Don't add prose or code fences around it.\n\n" +
+		fence + "json\n" + schema + "\n" + fence
+	return promptSection{Class: SectionBase, Source: "schema:" + path, Content: content}, nil
+}
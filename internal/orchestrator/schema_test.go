@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestLoadSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "indented in file order",
+			schema: `{"type":"object","properties":{"summary":{"type":"string"},"risks":{"type":"array","items":{"type":"string"}}},"required":["summary"]}`,
+			want:   "{\n  \"type\": \"object\",\n  \"properties\": {\n    \"summary\": {\n      \"type\": \"string\"\n    },\n    \"risks\": {\n      \"type\": \"array\",\n      \"items\": {\n        \"type\": \"string\"\n      }\n    }\n  },\n  \"required\": [\n    \"summary\"\n  ]\n}",
+		},
+		{name: "boolean subschema", schema: `{"type": "object", "additionalProperties": false}`},
+		{name: "type list", schema: `{"type": ["string", "null"]}`},
+		{name: "invalid JSON", schema: "{\n  \"type\": \"object\",,\n}", wantErr: "line 2"},
+		{name: "trailing value", schema: `{"type": "object"} {}`, wantErr: "more than one value"},
+		{name: "not an object", schema: `["string"]`, wantErr: "must be a JSON object"},
+		{name: "unknown type", schema: `{"properties": {"name": {"type": "strng"}}}`, wantErr: `#/properties/name/type: "strng" is not a JSON Schema type`},
+		{name: "required not in properties", schema: `{"properties": {"a": {}}, "required": ["b"]}`, wantErr: `#/required/0: "b" isn't in properties`},
+		{name: "nested items", schema: `{"type": "array", "items": {"type": "object", "properties": {"n": {"type": 1}}}}`, wantErr: "#/items/properties/n/type"},
+		{name: "anyOf not a list", schema: `{"anyOf": {"type": "string"}}`, wantErr: "#/anyOf: must be an array of schemas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "schema.json")
+			if err := os.WriteFile(path, []byte(tt.schema), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := loadSchema(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSchema() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadSchema() error = %v", err)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("loadSchema() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadSchema(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing schema file")
+	}
+}
+
+func TestOrchestrator_SchemaSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	if err := os.WriteFile(path, []byte(`{"type": "object", "properties": {"verdict": {"enum": ["approve", "block"]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := models.NewPromptRequest()
+	request.BasePrompt = "review this change"
+	request.PostInline = "Be terse."
+	request.SchemaFile = path
+
+	result, err := New().generateNormalPrompt(context.Background(), request, &interfaces.Config{PromptsLocation: t.TempDir()})
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	last := result.Sections[len(result.Sections)-1]
+	if last.Source != "schema:"+path {
+		t.Fatalf("last section = %s, want the schema after the post-template", last.Source)
+	}
+	if !strings.Contains(last.Content, "```json\n{\n  \"type\": \"object\",") {
+		t.Errorf("schema section = %q, want the indented schema in a json fence", last.Content)
+	}
+}
@@ -0,0 +1,242 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Roles of the turns in a session
+const (
+	SessionPrompt   = "prompt"
+	SessionResponse = "response"
+)
+
+// The transcript included in each prompt is condensed: only the latest turns, each cut to a
+// length that keeps the gist
+const (
+	sessionTranscriptTurns = 10
+	sessionTurnChars       = 1500
+)
+
+// Session is a conversation prompter is keeping for a project: the prompts it output and the
+// responses pasted back in, in order
+type Session struct {
+	Name      string        `json:"name"`
+	StartedAt time.Time     `json:"started_at"`
+	Turns     []SessionTurn `json:"turns"`
+}
+
+// SessionTurn is one prompt or response in a session
+type SessionTurn struct {
+	Role    string    `json:"role"`
+	Text    string    `json:"text"`
+	Context []string  `json:"context,omitempty"` // What the prompt included, e.g. pre:review or url:https://...
+	At      time.Time `json:"at"`
+}
+
+// ProjectSessionScope returns the key of the current project's session: its root
+func ProjectSessionScope() (string, error) {
+	dir, err := WorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+	return detectProject(dir).Root, nil
+}
+
+// StartSession starts a session named name in scope. Only one session is active per scope.
+func StartSession(cfg *interfaces.Config, scope, name string) (*Session, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	var session Session
+	err = st.Modify(interfaces.BucketSessions, scope, &session, func(found bool) error {
+		if found {
+			return fmt.Errorf("session %q is already active; end it with 'prompter session end' first", session.Name)
+		}
+		session = Session{Name: name, StartedAt: time.Now()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// LoadSession returns the active session in scope, or nil when there is none
+func LoadSession(cfg *interfaces.Config, scope string) (*Session, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	var session Session
+	found, err := st.Get(interfaces.BucketSessions, scope, &session)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// AddSessionTurn appends turn to the active session in scope
+func AddSessionTurn(cfg *interfaces.Config, scope string, turn SessionTurn) (*Session, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	var session Session
+	err = st.Modify(interfaces.BucketSessions, scope, &session, func(found bool) error {
+		if !found {
+			return fmt.Errorf("no session is active; start one with 'prompter session start'")
+		}
+		if turn.At.IsZero() {
+			turn.At = time.Now()
+		}
+		session.Turns = append(session.Turns, turn)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// EndSession removes the active session in scope and returns it, or nil when there was none
+func EndSession(cfg *interfaces.Config, scope string) (*Session, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	var session Session
+	found, err := st.Get(interfaces.BucketSessions, scope, &session)
+	if err != nil || !found {
+		return nil, err
+	}
+	if err := st.Delete(interfaces.BucketSessions, scope); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SessionPromptTurn describes the prompt request assembled: its base prompt and the names of
+// what it included, not their content
+func SessionPromptTurn(request *models.PromptRequest) SessionTurn {
+	var context []string
+	add := func(prefix string, values ...string) {
+		for _, value := range values {
+			if value != "" {
+				context = append(context, prefix+value)
+			}
+		}
+	}
+
+	add("pre:", request.PreTemplate)
+	if request.PreInline != "" {
+		add("pre:", "inline")
+	}
+	if request.FixMode {
+		add("fix:", resolveFixSource(request))
+	}
+	add("file:", request.Files...)
+	add("dir:", request.Directory)
+	if request.FilesFromDiff {
+		add("", "changed-files")
+	}
+	if request.ChangedSince != "" && request.ChangedSince != "HEAD" {
+		add("since:", request.ChangedSince)
+	}
+	add("pack:", request.ContextPacks...)
+	add("github:", request.GitHubRefs...)
+	add("jira:", request.JiraKeys...)
+	add("url:", request.URLs...)
+	add("post:", request.PostTemplate)
+	if request.PostInline != "" {
+		add("post:", "inline")
+	}
+
+	return SessionTurn{Role: SessionPrompt, Text: request.BasePrompt, Context: context}
+}
+
+// sessionSections returns the condensed transcript of the current project's session, if one
+// is active, to go before the prompt
+func (o *Orchestrator) sessionSections(cfg *interfaces.Config) []promptSection {
+	session, err := activeSession(cfg)
+	if err != nil {
+		o.warnings.Add("session unavailable: %v", err)
+		return nil
+	}
+	if session == nil || len(session.Turns) == 0 {
+		return nil
+	}
+	return []promptSection{{Class: SectionContext, Source: "session:" + session.Name, Content: formatTranscript(session)}}
+}
+
+// activeSession returns the current project's session, or nil when none is active
+func activeSession(cfg *interfaces.Config) (*Session, error) {
+	if cfg.StateFile == "" {
+		return nil, nil
+	}
+	scope, err := ProjectSessionScope()
+	if err != nil {
+		return nil, nil
+	}
+	return LoadSession(cfg, scope)
+}
+
+// formatTranscript condenses session into the conversation so far: the latest turns, each cut
+// to sessionTurnChars
+func formatTranscript(session *Session) string {
+	turns := session.Turns
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conversation so far (session %q):\n", session.Name)
+	if omitted := len(turns) - sessionTranscriptTurns; omitted > 0 {
+		noun := "turns"
+		if omitted == 1 {
+			noun = "turn"
+		}
+		fmt.Fprintf(&b, "\n[... %d earlier %s omitted ...]\n", omitted, noun)
+		turns = turns[omitted:]
+	}
+
+	for _, turn := range turns {
+		heading := "Prompt"
+		if turn.Role == SessionResponse {
+			heading = "Response"
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", heading)
+		if text := strings.TrimSpace(turn.Text); text != "" {
+			b.WriteString(condenseTurn(text))
+			b.WriteString("\n")
+		}
+		if len(turn.Context) > 0 {
+			fmt.Fprintf(&b, "(included %s)\n", strings.Join(turn.Context, ", "))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// condenseTurn cuts text over sessionTurnChars at a line or word boundary
+func condenseTurn(text string) string {
+	runes := []rune(text)
+	if len(runes) <= sessionTurnChars {
+		return text
+	}
+	kept := string(runes[:sessionTurnChars])
+	if i := strings.LastIndexAny(kept, "\n "); i > sessionTurnChars/2 {
+		kept = kept[:i]
+	}
+	kept = strings.TrimRight(kept, " \n")
+	return fmt.Sprintf("%s\n[... %d characters omitted ...]", kept, len(runes)-len([]rune(kept)))
+}
@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestSessionTranscript(t *testing.T) {
+	t.Chdir(t.TempDir())
+	scope, err := ProjectSessionScope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+
+	if _, err := AddSessionTurn(cfg, scope, SessionTurn{Role: SessionResponse, Text: "ok"}); err == nil {
+		t.Error("expected an error adding to a session that wasn't started")
+	}
+	if _, err := StartSession(cfg, scope, "auth"); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if _, err := StartSession(cfg, scope, "other"); err == nil || !strings.Contains(err.Error(), `"auth" is already active`) {
+		t.Errorf("StartSession() over an active session error = %v", err)
+	}
+
+	request := models.NewPromptRequest()
+	request.BasePrompt = "refactor the login handler"
+	request.PreTemplate = "review"
+	request.Files = []string{"login.go"}
+	if _, err := AddSessionTurn(cfg, scope, SessionPromptTurn(request)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddSessionTurn(cfg, scope, SessionTurn{Role: SessionResponse, Text: "Split it into two functions."}); err != nil {
+		t.Fatal(err)
+	}
+	if reason := uncacheableReason(models.NewPromptRequest(), cfg, nil); !strings.Contains(reason, "session") {
+		t.Errorf("uncacheableReason() = %q, want prompts in a session left uncached", reason)
+	}
+
+	next := models.NewPromptRequest()
+	next.BasePrompt = "now add tests"
+	result, err := New().generateNormalPrompt(context.Background(), next, cfg)
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	want := "Conversation so far (session \"auth\"):\n\n### Prompt\n\nrefactor the login handler\n(included pre:review, file:login.go)\n\n### Response\n\nSplit it into two functions."
+	if len(result.Sections) != 2 || result.Sections[0].Source != "session:auth" || result.Sections[0].Content != want {
+		t.Fatalf("sections = %+v, want the transcript before the prompt:\n%s", result.Sections, want)
+	}
+
+	session, err := EndSession(cfg, scope)
+	if err != nil || session == nil || len(session.Turns) != 2 {
+		t.Fatalf("EndSession() = %+v, %v", session, err)
+	}
+	if session, err := LoadSession(cfg, scope); err != nil || session != nil {
+		t.Errorf("LoadSession() after EndSession = %+v, %v; want none", session, err)
+	}
+}
+
+func TestFormatTranscriptCondenses(t *testing.T) {
+	session := &Session{Name: "long"}
+	for i := 0; i < sessionTranscriptTurns+2; i++ {
+		session.Turns = append(session.Turns, SessionTurn{Role: SessionResponse, Text: strings.Repeat("word ", 400)})
+	}
+
+	transcript := formatTranscript(session)
+	if !strings.Contains(transcript, "[... 2 earlier turns omitted ...]") {
+		t.Error("transcript doesn't note the turns it left out")
+	}
+	if got := strings.Count(transcript, "### Response"); got != sessionTranscriptTurns {
+		t.Errorf("transcript has %d turns, want %d", got, sessionTranscriptTurns)
+	}
+	if !strings.Contains(transcript, "word\n[... 500 characters omitted ...]") {
+		t.Errorf("long turns aren't cut at a word:\n%s", transcript[:2000])
+	}
+}
+
+func TestSessionPromptTurn(t *testing.T) {
+	request := models.NewPromptRequest()
+	request.FixMode = true
+	request.FixCommand = "go test ./..."
+	request.URLs = []string{"https://example.com/spec"}
+	request.PostInline = "be brief"
+
+	turn := SessionPromptTurn(request)
+	want := []string{"fix:command", "url:https://example.com/spec", "post:inline"}
+	if turn.Role != SessionPrompt || !slices.Equal(turn.Context, want) {
+		t.Errorf("SessionPromptTurn() = %+v, want context %v", turn, want)
+	}
+}
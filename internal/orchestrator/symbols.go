@@ -0,0 +1,234 @@
+package orchestrator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// symbolRef is a --symbol argument: pkg.Name, or pkg.Type.Method for a method. Package is a
+// package name or a slash-separated path to its directory, such as internal/orchestrator.
+type symbolRef struct {
+	Package string
+	Name    string
+	Method  string
+}
+
+// String returns the reference as it was written
+func (r symbolRef) String() string {
+	if r.Method != "" {
+		return r.Package + "." + r.Name + "." + r.Method
+	}
+	return r.Package + "." + r.Name
+}
+
+// parseSymbolRef parses a --symbol argument
+func parseSymbolRef(arg string) (symbolRef, error) {
+	dir, rest := "", arg
+	if i := strings.LastIndex(arg, "/"); i >= 0 {
+		dir, rest = arg[:i+1], arg[i+1:]
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) < 2 || len(parts) > 3 || dir+parts[0] == "" || parts[1] == "" || (len(parts) == 3 && parts[2] == "") {
+		return symbolRef{}, NewValidationError("symbol", arg, "expected pkg.Name or pkg.Type.Method")
+	}
+	ref := symbolRef{Package: dir + parts[0], Name: parts[1]}
+	if len(parts) == 3 {
+		ref.Method = parts[2]
+	}
+	return ref, nil
+}
+
+// matchesPackage reports whether the Go file at rel, a slash-separated path in package name,
+// is in the package ref names
+func (r symbolRef) matchesPackage(rel, name string) bool {
+	dir := path.Dir(rel)
+	if strings.Contains(r.Package, "/") {
+		return dir == r.Package || strings.HasSuffix(dir, "/"+r.Package)
+	}
+	return name == r.Package || path.Base(dir) == r.Package
+}
+
+// symbolSections builds a section for each --symbol: the declaration and its doc comment,
+// found in the project's Go files. A symbol that isn't declared anywhere is an error.
+func (o *Orchestrator) symbolSections(request *models.PromptRequest, cfg *interfaces.Config) ([]promptSection, error) {
+	refs := make([]symbolRef, 0, len(request.Symbols))
+	for _, arg := range request.Symbols {
+		ref, err := parseSymbolRef(arg)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	cwd, err := WorkspaceDir()
+	if err != nil {
+		return nil, err
+	}
+	root := detectProject(cwd).Root
+	ignored, err := ignore.Find(root)
+	if err != nil {
+		return nil, NewContentCollectionError(root, err)
+	}
+	files, err := repoMapFiles(root, cfg.DirectoryStrategy, ignored)
+	if err != nil {
+		return nil, NewContentCollectionError(root, err)
+	}
+
+	found := make([][]promptSection, len(refs))
+	for _, rel := range files {
+		if !strings.HasSuffix(rel, ".go") {
+			continue
+		}
+		// Read and parse only files that could declare one of the symbols
+		src, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil {
+			continue
+		}
+		var wanted []int
+		for i, ref := range refs {
+			if strings.Contains(string(src), ref.Name) && (ref.Method == "" || strings.Contains(string(src), ref.Method)) {
+				wanted = append(wanted, i)
+			}
+		}
+		if len(wanted) == 0 {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, rel, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, i := range wanted {
+			if !refs[i].matchesPackage(rel, file.Name.Name) {
+				continue
+			}
+			for _, decl := range findDeclarations(file, refs[i]) {
+				section := symbolSection(fset, src, rel, refs[i], decl)
+				found[i] = append(found[i], section)
+				o.fileIncluded(interfaces.FileEvent{Path: rel, Embedded: true, Bytes: len(section.Content)})
+			}
+		}
+	}
+
+	var sections []promptSection
+	for i, ref := range refs {
+		if len(found[i]) == 0 {
+			return nil, NewValidationError("symbol", ref.String(), "no Go declaration found under "+root)
+		}
+		sections = append(sections, found[i]...)
+	}
+	return sections, nil
+}
+
+// declaration is the source range of a declaration, its doc comment, and the keyword to put
+// before it when it was taken from a grouped declaration
+type declaration struct {
+	Doc      *ast.CommentGroup
+	Pos, End token.Pos
+	Prefix   string
+}
+
+// findDeclarations returns the declarations of ref's symbol in file: a function, method,
+// type, constant, or variable. Constants and variables come with the group they are in.
+func findDeclarations(file *ast.File, ref symbolRef) []declaration {
+	var found []declaration
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if ref.Method == "" && decl.Recv == nil && decl.Name.Name == ref.Name {
+				found = append(found, declaration{Doc: decl.Doc, Pos: decl.Pos(), End: decl.End()})
+			}
+			if ref.Method != "" && decl.Recv != nil && decl.Name.Name == ref.Method && receiverName(decl.Recv) == ref.Name {
+				found = append(found, declaration{Doc: decl.Doc, Pos: decl.Pos(), End: decl.End()})
+			}
+		case *ast.GenDecl:
+			if ref.Method != "" {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				if !specDeclares(spec, ref.Name) {
+					continue
+				}
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && decl.Lparen.IsValid() {
+					found = append(found, declaration{Doc: typeSpec.Doc, Pos: typeSpec.Pos(), End: typeSpec.End(), Prefix: "type "})
+				} else {
+					found = append(found, declaration{Doc: decl.Doc, Pos: decl.Pos(), End: decl.End()})
+				}
+				break
+			}
+		}
+	}
+	return found
+}
+
+// specDeclares reports whether spec declares name
+func specDeclares(spec ast.Spec, name string) bool {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.Name == name
+	case *ast.ValueSpec:
+		for _, ident := range spec.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// receiverName returns the name of a method's receiver type, without pointers or type
+// parameters
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// symbolSection formats decl, found in src at rel, as a section headed by where it is
+func symbolSection(fset *token.FileSet, src []byte, rel string, ref symbolRef, decl declaration) promptSection {
+	start, end := fset.Position(decl.Pos), fset.Position(decl.End)
+	code := decl.Prefix + string(src[start.Offset:end.Offset])
+	line := start.Line
+	if decl.Doc != nil {
+		// Comments are taken as written, without the indentation of a grouped declaration
+		var doc []string
+		for _, comment := range decl.Doc.List {
+			doc = append(doc, comment.Text)
+		}
+		code = strings.Join(doc, "\n") + "\n" + code
+		line = fset.Position(decl.Doc.Pos()).Line
+	}
+	location := fmt.Sprintf("%s:%d", rel, line)
+	fence := Fence(code)
+	return promptSection{
+		Class:   SectionFiles,
+		Source:  "symbol:" + ref.String(),
+		Content: fmt.Sprintf("%s (%s):\n%sgo\n%s\n%s", ref, location, fence, code, fence),
+	}
+}
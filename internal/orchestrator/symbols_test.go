@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestParseSymbolRef(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    symbolRef
+		wantErr bool
+	}{
+		{arg: "api.New", want: symbolRef{Package: "api", Name: "New"}},
+		{arg: "api.Client.Fetch", want: symbolRef{Package: "api", Name: "Client", Method: "Fetch"}},
+		{arg: "internal/api.New", want: symbolRef{Package: "internal/api", Name: "New"}},
+		{arg: "New", wantErr: true},
+		{arg: "api.", wantErr: true},
+		{arg: ".New", wantErr: true},
+		{arg: "a.b.c.d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := parseSymbolRef(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSymbolRef(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseSymbolRef(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbolSections(t *testing.T) {
+	root := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	files := map[string]string{
+		"go.mod": "module example\n",
+		"internal/api/client.go": `package api
+
+// Client calls the API
+type Client struct {
+	token string
+}
+
+// New returns a Client for token
+func New(token string) *Client {
+	return &Client{token: token}
+}
+
+// Fetch gets one record
+func (c *Client) Fetch(id string) error {
+	return nil
+}
+
+type (
+	// ID names a record
+	ID string
+	other int
+)
+
+const (
+	DefaultLimit = 10
+	maxLimit     = 100
+)
+`,
+		"internal/store/new.go": "package store\n\nfunc New() {}\n",
+	}
+	writeTree(t, root, files)
+	t.Chdir(filepath.Join(root, "internal"))
+
+	o := New()
+	cfg := &interfaces.Config{DirectoryStrategy: "filesystem"}
+	request := &models.PromptRequest{Symbols: []string{"api.New", "internal/api.Client.Fetch", "api.ID", "api.DefaultLimit", "api.Client"}}
+	sections, err := o.symbolSections(request, cfg)
+	if err != nil {
+		t.Fatalf("symbolSections() error = %v", err)
+	}
+
+	want := []string{
+		"api.New (internal/api/client.go:8):\n```go\n// New returns a Client for token\nfunc New(token string) *Client {\n\treturn &Client{token: token}\n}\n```",
+		"internal/api.Client.Fetch (internal/api/client.go:13):\n```go\n// Fetch gets one record\nfunc (c *Client) Fetch(id string) error {\n\treturn nil\n}\n```",
+		"api.ID (internal/api/client.go:19):\n```go\n// ID names a record\ntype ID string\n```",
+		"api.DefaultLimit (internal/api/client.go:24):\n```go\nconst (\n\tDefaultLimit = 10\n\tmaxLimit     = 100\n)\n```",
+		"api.Client (internal/api/client.go:3):\n```go\n// Client calls the API\ntype Client struct {\n\ttoken string\n}\n```",
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("symbolSections() = %d sections, want %d", len(sections), len(want))
+	}
+	for i, section := range sections {
+		if section.Class != SectionFiles || section.Content != want[i] {
+			t.Errorf("section %d = %q (%s), want %q", i, section.Content, section.Class, want[i])
+		}
+	}
+
+	request.Symbols = []string{"api.Missing"}
+	if _, err := o.symbolSections(request, cfg); err == nil || !strings.Contains(err.Error(), "api.Missing") {
+		t.Errorf("symbolSections() for a missing symbol error = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// resolveTasks looks up the --task arguments: a name from the [tasks] table, or name=command
+// for a task the config doesn't define
+func resolveTasks(args []string, defined map[string]string) ([]interfaces.Task, error) {
+	var tasks []interfaces.Task
+	for _, arg := range args {
+		if name, command, ok := strings.Cut(arg, "="); ok {
+			name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+			if name == "" || command == "" {
+				return nil, fmt.Errorf("invalid task %q: use name=command", arg)
+			}
+			tasks = append(tasks, interfaces.Task{Name: name, Command: command})
+			continue
+		}
+
+		name := strings.TrimSpace(arg)
+		command, ok := defined[strings.ToLower(name)]
+		if !ok {
+			names := make([]string, 0, len(defined))
+			for task := range defined {
+				names = append(names, task)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return nil, fmt.Errorf("unknown task %q: the config has no [tasks] table; define one, or use --task %s=<command>", name, name)
+			}
+			return nil, fmt.Errorf("unknown task %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		tasks = append(tasks, interfaces.Task{Name: name, Command: command})
+	}
+	return tasks, nil
+}
+
+// captureTasks runs each task in turn, whether or not an earlier one failed, and combines the
+// failures into one capture with a labeled section per task. Tasks that pass are listed so the
+// model knows they don't need fixing.
+func (o *Orchestrator) captureTasks(request interfaces.CaptureRequest) (interfaces.FixInfo, error) {
+	if len(request.Tasks) == 0 {
+		return interfaces.FixInfo{}, fmt.Errorf("no tasks given; use --task")
+	}
+
+	combined := interfaces.FixInfo{Enabled: true}
+	var raw, output, commands, failed []string
+	for _, task := range request.Tasks {
+		fmt.Fprintf(o.progress, "Running %s: %s\n", task.Name, task.Command)
+		info, err := o.executeAndCaptureCommand(captureContext(request), task.Command, "")
+		if err != nil {
+			return interfaces.FixInfo{}, fmt.Errorf("task %s: %w", task.Name, err)
+		}
+
+		combined.Tasks = append(combined.Tasks, interfaces.TaskResult{
+			Name:     task.Name,
+			Command:  task.Command,
+			Output:   info.Output,
+			Stdout:   info.Stdout,
+			Stderr:   info.Stderr,
+			ExitCode: info.ExitCode,
+			Duration: info.Duration,
+		})
+		combined.Duration += info.Duration
+		commands = append(commands, task.Command)
+
+		if info.ExitCode == 0 {
+			raw = append(raw, fmt.Sprintf("## %s passed\n\n$ %s (took %s)", task.Name, task.Command, info.Duration.Round(time.Millisecond)))
+			continue
+		}
+		if combined.ExitCode == 0 {
+			combined.ExitCode = info.ExitCode
+		}
+		failed = append(failed, task.Name)
+		heading := fmt.Sprintf("## %s failed (exit code %d)", task.Name, info.ExitCode)
+		raw = append(raw, heading+"\n\n"+info.Raw)
+		output = append(output, heading+"\n\n"+info.Output)
+	}
+
+	if len(failed) == 0 {
+		return interfaces.FixInfo{}, fmt.Errorf("every task passed (%s)", taskNames(request.Tasks))
+	}
+	fmt.Fprintf(o.progress, "Failed: %s\n", strings.Join(failed, ", "))
+
+	combined.Raw = strings.Join(raw, "\n\n")
+	combined.Output = strings.Join(output, "\n\n")
+	combined.Command = strings.Join(commands, "; ")
+	return combined, nil
+}
+
+// taskNames lists the names of tasks, comma-separated
+func taskNames(tasks []interfaces.Task) string {
+	names := make([]string, len(tasks))
+	for i, task := range tasks {
+		names[i] = task.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestResolveTasks(t *testing.T) {
+	defined := map[string]string{"build": "go build ./...", "test": "go test ./..."}
+	tests := []struct {
+		name    string
+		args    []string
+		defined map[string]string
+		want    []interfaces.Task
+		wantErr string
+	}{
+		{"named", []string{"build", "Test"}, defined, []interfaces.Task{{Name: "build", Command: "go build ./..."}, {Name: "Test", Command: "go test ./..."}}, ""},
+		{"inline", []string{"vet=go vet ./..."}, nil, []interfaces.Task{{Name: "vet", Command: "go vet ./..."}}, ""},
+		{"unknown", []string{"lint"}, defined, nil, "available: build, test"},
+		{"no tasks table", []string{"lint"}, nil, nil, "no [tasks] table"},
+		{"empty command", []string{"lint="}, defined, nil, "use name=command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTasks(tt.args, tt.defined)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("resolveTasks() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(got) != len(tt.want) {
+				t.Fatalf("resolveTasks() = %+v, %v; want %+v", got, err, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("resolveTasks()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCaptureTasks(t *testing.T) {
+	orch := New()
+	fixInfo, err := orch.captureTasks(interfaces.CaptureRequest{Tasks: []interfaces.Task{
+		{Name: "build", Command: "echo 'main.go:3: undefined: x'; exit 2"},
+		{Name: "lint", Command: "true"},
+		{Name: "test", Command: "echo 'FAIL TestParse'; exit 1"},
+	}})
+	if err != nil {
+		t.Fatalf("captureTasks() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"## build failed (exit code 2)\n\n$ echo 'main.go:3: undefined: x'; exit 2\n\nmain.go:3: undefined: x\n\n# Exit code: 2",
+		"## lint passed\n\n$ true (took ",
+		"## test failed (exit code 1)\n\n$ echo 'FAIL TestParse'; exit 1\n\nFAIL TestParse",
+	} {
+		if !strings.Contains(fixInfo.Raw, want) {
+			t.Errorf("captureTasks() Raw missing %q:\n%s", want, fixInfo.Raw)
+		}
+	}
+	if fixInfo.Output != "## build failed (exit code 2)\n\nmain.go:3: undefined: x\n\n## test failed (exit code 1)\n\nFAIL TestParse" {
+		t.Errorf("captureTasks() Output = %q, want only the failures", fixInfo.Output)
+	}
+	if fixInfo.ExitCode != 2 || len(fixInfo.Tasks) != 3 || fixInfo.Tasks[2].ExitCode != 1 || fixInfo.Tasks[1].Output != "" {
+		t.Errorf("captureTasks() = exit code %d, tasks %+v", fixInfo.ExitCode, fixInfo.Tasks)
+	}
+
+	if _, err := orch.captureTasks(interfaces.CaptureRequest{Tasks: []interfaces.Task{{Name: "lint", Command: "true"}}}); err == nil || !strings.Contains(err.Error(), "every task passed") {
+		t.Errorf("captureTasks() with every task passing: error = %v", err)
+	}
+}
+
+func TestValidateRequest_Tasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		wantErr bool
+	}{
+		{"tasks", &models.PromptRequest{FixMode: true, FixTasks: []string{"build"}}, false},
+		{"with tasks source", &models.PromptRequest{FixMode: true, FixTasks: []string{"build"}, FixSource: FixSourceTasks}, false},
+		{"with fix command", &models.PromptRequest{FixMode: true, FixTasks: []string{"build"}, FixCommand: "make"}, true},
+		{"with fix file", &models.PromptRequest{FixMode: true, FixTasks: []string{"build"}, FixFile: "out.txt"}, true},
+		{"with another source", &models.PromptRequest{FixMode: true, FixTasks: []string{"build"}, FixSource: FixSourceTmux}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().validateRequest(tt.request); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
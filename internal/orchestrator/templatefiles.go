@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// templateIncluder reads the files templates include with includeFile and includeDir, relative
+// to the working directory and only inside the project, the way the files a prompt embeds are
+// read: large files are sampled or left out, binary and generated files are left out, and
+// secrets are redacted
+type templateIncluder struct {
+	policy   embedPolicy
+	strategy string    // directory_strategy, for listing directories
+	redactor *redactor         // nil when redaction is off
+	warnings *warnings.Channel // Where files left out are reported
+}
+
+// newTemplateIncluder returns the includer for templates rendered for request
+func newTemplateIncluder(request *models.PromptRequest, cfg *interfaces.Config, redactor *redactor, channel *warnings.Channel) *templateIncluder {
+	return &templateIncluder{policy: newEmbedPolicy(request, cfg), strategy: cfg.DirectoryStrategy, redactor: redactor, warnings: channel}
+}
+
+// IncludeFile returns the file at path, or "" with a warning when the embed rules leave it out
+func (i *templateIncluder) IncludeFile(path string) (string, error) {
+	resolved, err := projectFile(path)
+	if err != nil {
+		return "", err
+	}
+	read := readFile(resolved, i.policy.Sample)
+	switch {
+	case read.Err != nil:
+		return "", read.Err
+	case read.Info == nil:
+		return "", fmt.Errorf("%s is a directory; list it with includeDir", path)
+	case read.Large && !i.policy.Sample:
+		i.warnings.Add("template file %s left out: over %d KiB (large_files = \"sample\" includes an excerpt)", path, maxEmbeddedFileBytes/1024)
+		return "", nil
+	}
+	if reason := i.policy.skipReason(path, read.Content); reason != "" {
+		flag := "--include-generated"
+		if reason == skipBinary {
+			flag = "--include-binary"
+		}
+		i.warnings.Add("template file %s left out (%s); %s includes it", path, reason, flag)
+		return "", nil
+	}
+	return i.redactor.Apply(read.Content), nil
+}
+
+// IncludeDir lists the files under dir, as paths includeFile accepts, leaving out what the
+// ignore file excludes
+func (i *templateIncluder) IncludeDir(dir string) (string, error) {
+	resolved, err := projectFile(dir)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(resolved); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		return "", fmt.Errorf("%s is a file; insert it with includeFile", dir)
+	}
+	ignored, err := ignore.Find(resolved)
+	if err != nil {
+		return "", err
+	}
+	files, err := repoMapFiles(resolved, i.strategy, ignored)
+	if err != nil {
+		return "", err
+	}
+	if len(files) > repoMapMaxFiles {
+		i.warnings.Add("template directory %s lists only its first %d of %d files", dir, repoMapMaxFiles, len(files))
+		files = files[:repoMapMaxFiles]
+	}
+	for j, file := range files {
+		files[j] = path.Join(filepath.ToSlash(dir), file)
+	}
+	return i.redactor.Apply(strings.Join(files, "\n")), nil
+}
@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/ignore"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+func TestTemplateIncluder(t *testing.T) {
+	defer warnings.Flush(io.Discard)
+	dir := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	files := map[string]string{
+		".git/HEAD":               "ref: refs/heads/main\n",
+		"docs/STYLE.md":           "Use tabs.\n",
+		"docs/drafts/new.md":      "draft",
+		"docs/notes.tmp":          "scratch",
+		".env":                    "API_KEY=abcdef1234567890\n",
+		"image.png":               "\x89PNG\r\n\x1a\n\x00\x00",
+		"large.log":               strings.Repeat("line\n", maxEmbeddedFileBytes/4),
+		"docs/" + ignore.FileName: "*.tmp\n",
+	}
+	writeTree(t, dir, files)
+	redactor, err := newRedactor(true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	includer := &templateIncluder{redactor: redactor, warnings: warnings.Default}
+	path := func(name string) string { return filepath.Join(dir, name) }
+	t.Chdir(dir)
+
+	tests := []struct {
+		name   string
+		file   string
+		sample bool
+		want   string
+	}{
+		{name: "file", file: "docs/STYLE.md", want: "Use tabs.\n"},
+		{name: "secrets are redacted", file: ".env", want: "API_KEY=[REDACTED:env-secret]\n"},
+		{name: "binary is left out", file: "image.png", want: ""},
+		{name: "large file is left out", file: "large.log", want: ""},
+		{name: "large file is sampled", file: "large.log", sample: true, want: "line\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			includer.policy.Sample = tt.sample
+			got, err := includer.IncludeFile(tt.file)
+			if err != nil || !strings.HasPrefix(got, tt.want) || (tt.want == "") != (got == "") {
+				t.Errorf("IncludeFile(%s) = %.40q, %v; want %q", tt.file, got, err, tt.want)
+			}
+		})
+	}
+
+	if got, err := includer.IncludeFile(path("docs/STYLE.md")); err != nil || got != "Use tabs.\n" {
+		t.Errorf("IncludeFile() of an absolute path in the project = %q, %v", got, err)
+	}
+	if _, err := includer.IncludeFile("missing.md"); err == nil {
+		t.Error("IncludeFile() of a missing file succeeded")
+	}
+	if _, err := includer.IncludeFile(path("docs")); err == nil || !strings.Contains(err.Error(), "includeDir") {
+		t.Errorf("IncludeFile() of a directory error = %v, want a pointer to includeDir", err)
+	}
+
+	// The listing leaves out what the ignore file excludes
+	want := "docs/.prompterignore\ndocs/STYLE.md\ndocs/drafts/new.md"
+	if got, err := includer.IncludeDir("docs"); err != nil || got != want {
+		t.Errorf("IncludeDir() = %q, %v; want %q", got, err, want)
+	}
+	if _, err := includer.IncludeDir("docs/STYLE.md"); err == nil || !strings.Contains(err.Error(), "includeFile") {
+		t.Errorf("IncludeDir() of a file error = %v, want a pointer to includeFile", err)
+	}
+}
+
+func TestTemplateIncluder_OutsideProject(t *testing.T) {
+	parent := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(parent); err == nil {
+		parent = resolved
+	}
+	project := filepath.Join(parent, "project")
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("outside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(project, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	processor := template.NewProcessor(t.TempDir())
+	processor.SetFileIncluder(&templateIncluder{warnings: warnings.Default})
+
+	for _, text := range []string{
+		`{{includeFile "../secret.txt"}}`,
+		`{{includeFile "` + secret + `"}}`,
+		`{{includeFile "link.txt"}}`,
+		`{{includeDir ".."}}`,
+		`{{includeDir "` + parent + `"}}`,
+	} {
+		got, err := processor.RenderString("include", text, interfaces.TemplateData{})
+		if err == nil || !strings.Contains(err.Error(), "outside the project") {
+			t.Errorf("render(%s) = %q, %v; want an outside the project error", text, got, err)
+		}
+	}
+}
+
+func TestIncludesWorkspaceFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plain.md"), []byte(`{{include "persona"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if includesWorkspaceFiles([]string{dir, filepath.Join(dir, "missing")}, "", `{{.Prompt}}`) {
+		t.Error("includesWorkspaceFiles() = true for templates using only partials")
+	}
+	if !includesWorkspaceFiles(nil, `{{includeDir "docs"}}`) {
+		t.Error("includesWorkspaceFiles() = false for inline text using includeDir")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.md"), []byte(`{{includeFile "STYLE.md"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !includesWorkspaceFiles([]string{dir}) {
+		t.Error("includesWorkspaceFiles() = false for a template using includeFile")
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// captureTmuxPane captures the last lines of the current tmux pane's scrollback
+func captureTmuxPane(lines int) (interfaces.FixInfo, error) {
+	if os.Getenv("TMUX") == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("not running inside tmux")
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("tmux not found in PATH")
+	}
+
+	// -J joins wrapped lines so long errors aren't split mid-message
+	args := []string{"capture-pane", "-p", "-J", "-S", "-" + strconv.Itoa(lines)}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return interfaces.FixInfo{}, fmt.Errorf("tmux capture-pane failed: %w", err)
+	}
+
+	content := trimTerminalCapture(string(output))
+	if content == "" {
+		return interfaces.FixInfo{}, fmt.Errorf("tmux pane is empty")
+	}
+
+	return interfaces.FixInfo{
+		Enabled: true,
+		Raw:     content,
+		Output:  content,
+	}, nil
+}
+
+// trimTerminalCapture drops the blank area below the cursor and the prompt line that ran prompter
+func trimTerminalCapture(content string) string {
+	lines := strings.Split(strings.TrimRight(content, " \n"), "\n")
+
+	// The last line is the shell prompt where prompter was invoked
+	if n := len(lines); n > 0 && strings.Contains(lines[n-1], "prompter") {
+		lines = lines[:n-1]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
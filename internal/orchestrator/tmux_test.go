@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestTrimTerminalCapture(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "drops prompter prompt and blank area",
+			content: "$ go build\n./main.go:3:2: undefined: foo\n$ prompter --fix-source tmux\n\n\n   \n",
+			want:    "$ go build\n./main.go:3:2: undefined: foo",
+		},
+		{
+			name:    "keeps last line without prompter",
+			content: "$ make\nmake: *** [all] Error 1\n",
+			want:    "$ make\nmake: *** [all] Error 1",
+		},
+		{name: "only prompt line", content: "$ prompter --fix-source tmux\n", want: ""},
+		{name: "empty pane", content: "\n\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimTerminalCapture(tt.content); got != tt.want {
+				t.Errorf("trimTerminalCapture() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureTmuxPane_OutsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+
+	if _, err := captureTmuxPane(defaultCaptureLines); err == nil {
+		t.Error("captureTmuxPane() expected error outside tmux")
+	}
+}
+
+func TestValidateRequest_FixSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *models.PromptRequest
+		wantErr bool
+	}{
+		{name: "tmux", request: &models.PromptRequest{FixMode: true, FixSource: FixSourceTmux}},
+		{name: "rerun", request: &models.PromptRequest{FixMode: true, FixSource: FixSourceRerun}},
+		{name: "unknown source", request: &models.PromptRequest{FixMode: true, FixSource: "screen"}, wantErr: true},
+		{name: "with fix file", request: &models.PromptRequest{FixMode: true, FixSource: FixSourceTmux, FixFile: "out.txt"}, wantErr: true},
+		{name: "with fix command", request: &models.PromptRequest{FixMode: true, FixSource: FixSourceTmux, FixCommand: "make"}, wantErr: true},
+	}
+
+	o := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := o.validateRequest(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
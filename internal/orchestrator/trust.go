@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// trustDecision is the answer recorded for a workspace directory
+type trustDecision struct {
+	Trusted   bool      `json:"trusted"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// WorkspaceDir returns the directory trust decisions are recorded for: the current directory
+func WorkspaceDir() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+	return cwd, nil
+}
+
+// RecordWorkspaceTrust stores whether dir is trusted, replacing any earlier decision
+func RecordWorkspaceTrust(cfg *interfaces.Config, dir string, trusted bool) error {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	return st.Put(interfaces.BucketTrust, dir, trustDecision{Trusted: trusted, DecidedAt: time.Now()})
+}
+
+// ApplyWorkspaceTrust ignores project-local templates, which can read environment variables
+// and files when rendered, unless the current directory is trusted. The first run in a
+// directory asks when interactive and records the answer, so it is only asked once.
+func (o *Orchestrator) ApplyWorkspaceTrust(request *models.PromptRequest, cfg *interfaces.Config) error {
+	if !cfg.WorkspaceTrust || !o.hasProjectLocalPrompts(cfg) {
+		return nil
+	}
+
+	dir, err := WorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		// Without a place to record the decision, fail closed rather than asking every run
+		o.warnings.Add("project-local templates ignored: %v", err)
+		o.distrustWorkspace()
+		return nil
+	}
+	defer st.Close()
+
+	var decision trustDecision
+	found, err := st.Get(interfaces.BucketTrust, dir, &decision)
+	if err != nil {
+		o.warnings.Add("project-local templates ignored: %v", err)
+		o.distrustWorkspace()
+		return nil
+	}
+	if found {
+		if !decision.Trusted {
+			o.distrustWorkspace()
+		}
+		return nil
+	}
+
+	if request.CI {
+		return NewInteractionRequiredError("trust in "+dir, "Run 'prompter trust' in the directory first, or 'prompter trust --revoke' to ignore its templates.")
+	}
+	if !request.Interactive {
+		o.warnings.Add("project-local templates in %s are ignored until the directory is trusted (run 'prompter trust')", dir)
+		o.distrustWorkspace()
+		return nil
+	}
+
+	trusted, err := o.selectYesNo(
+		fmt.Sprintf("Trust project-local templates in %s?", dir),
+		"Templates can read environment variables and files when rendered. The answer is remembered; change it with 'prompter trust [--revoke]'.",
+		false, request.NumberSelect)
+	if err != nil {
+		return err
+	}
+
+	if err := st.Put(interfaces.BucketTrust, dir, trustDecision{Trusted: trusted, DecidedAt: time.Now()}); err != nil {
+		o.warnings.Add("failed to record workspace trust: %v", err)
+	}
+	if !trusted {
+		o.distrustWorkspace()
+	}
+	return nil
+}
+
+// hasProjectLocalPrompts reports whether a local prompts location from the current directory
+// is in use. An absolute local_prompts_location was chosen in the user's own config, so it
+// needs no trust.
+func (o *Orchestrator) hasProjectLocalPrompts(cfg *interfaces.Config) bool {
+	processor, ok := o.templateProcessor.(*template.Processor)
+	if !ok || processor.LocalPromptsLocation() == "" {
+		return false
+	}
+	return cfg.LocalPromptsLocation == "" || !filepath.IsAbs(cfg.LocalPromptsLocation)
+}
+
+// distrustWorkspace stops the template processor from using project-local templates
+func (o *Orchestrator) distrustWorkspace() {
+	o.untrustedWorkspace = true
+	if processor, ok := o.templateProcessor.(*template.Processor); ok {
+		processor.SetLocalPromptsLocation("")
+	}
+}
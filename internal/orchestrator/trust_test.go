@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/template"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestApplyWorkspaceTrust(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "prompts", "pre"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	dir, err := WorkspaceDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &interfaces.Config{WorkspaceTrust: true, StateFile: filepath.Join(t.TempDir(), "state.db")}
+	request := &models.PromptRequest{Interactive: false}
+
+	// localPrompts applies the trust decision to a fresh orchestrator and returns what it kept
+	localPrompts := func(cfg *interfaces.Config) string {
+		t.Helper()
+		o := New()
+		processor := o.templateProcessor.(*template.Processor)
+		processor.SetLocalPromptsFromConfig(cfg.LocalPromptsLocation)
+		if err := o.ApplyWorkspaceTrust(request, cfg); err != nil {
+			t.Fatalf("ApplyWorkspaceTrust() error = %v", err)
+		}
+		return processor.LocalPromptsLocation()
+	}
+
+	if got := localPrompts(cfg); got != "" {
+		t.Errorf("undecided directory kept local prompts %q", got)
+	}
+
+	if err := RecordWorkspaceTrust(cfg, dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := localPrompts(cfg); got == "" {
+		t.Errorf("trusted directory dropped local prompts")
+	}
+
+	if err := RecordWorkspaceTrust(cfg, dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := localPrompts(cfg); got != "" {
+		t.Errorf("distrusted directory kept local prompts %q", got)
+	}
+
+	// An absolute local_prompts_location comes from the user's own config and needs no trust
+	absolute := *cfg
+	absolute.LocalPromptsLocation = filepath.Join(project, "prompts")
+	if got := localPrompts(&absolute); got == "" {
+		t.Errorf("absolute local_prompts_location was dropped")
+	}
+
+	disabled := *cfg
+	disabled.WorkspaceTrust = false
+	if got := localPrompts(&disabled); got == "" {
+		t.Errorf("workspace_trust = false still dropped local prompts")
+	}
+}
@@ -0,0 +1,326 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Limits for pages included with --url
+const (
+	urlFetchTimeout = 15 * time.Second
+	maxURLBytes     = 256 * 1024
+)
+
+// githubBlobURL matches a file view on github.com, which is served as an HTML page
+var githubBlobURL = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/blob/(.+)$`)
+
+// validateURL checks that rawURL is an absolute http(s) URL
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// githubRawURL rewrites GitHub file views to their raw content
+func githubRawURL(pageURL string) string {
+	if match := githubBlobURL.FindStringSubmatch(pageURL); match != nil {
+		return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", match[1], match[2], match[3])
+	}
+	return pageURL
+}
+
+// fetchURL downloads rawURL, converting HTML pages to markdown when markdown is set.
+// It returns the content, the fence language to embed it with, and whether it was truncated.
+func fetchURL(ctx context.Context, rawURL string, markdown bool) (content, language string, truncated bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, urlFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubRawURL(rawURL), nil)
+	if err != nil {
+		return "", "", false, err
+	}
+	req.Header.Set("User-Agent", "prompter")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", false, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && !isTextMediaType(mediaType) {
+		return "", "", false, fmt.Errorf("failed to fetch %s: unsupported content type %s", rawURL, mediaType)
+	}
+
+	// Read one byte past the limit to tell whether the page was cut off
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxURLBytes+1))
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if len(body) > maxURLBytes {
+		body, truncated = body[:maxURLBytes], true
+	}
+
+	content = string(body)
+	switch {
+	case mediaType == "text/html" && markdown:
+		content, language = htmlToMarkdown(content), "markdown"
+	case mediaType == "text/html":
+		language = "html"
+	case mediaType == "application/json":
+		language = "json"
+	default:
+		language = strings.TrimPrefix(path.Ext(req.URL.Path), ".")
+	}
+
+	return strings.TrimSpace(content), language, truncated, nil
+}
+
+// isTextMediaType reports whether a response can be embedded in a prompt
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml" ||
+		mediaType == "application/javascript" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml")
+}
+
+// formatURL fetches rawURL and formats it as a context section, falling back to a reference
+func formatURL(ctx context.Context, rawURL string, markdown bool) (string, error) {
+	content, language, truncated, err := fetchURL(ctx, rawURL, markdown)
+	if err != nil {
+		return "Referencing URL:\n" + rawURL, err
+	}
+
+	if truncated {
+		content += fmt.Sprintf("\n\n[truncated at %d KB]", maxURLBytes/1024)
+	}
+	fence := Fence(content)
+	return fmt.Sprintf("URL %s:\n%s%s\n%s\n%s", rawURL, fence, language, content, fence), nil
+}
+
+// htmlSkipTags are elements whose content never belongs in the converted page
+var htmlSkipTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "svg": true, "template": true,
+	"head": true, "nav": true, "footer": true, "iframe": true, "form": true, "button": true,
+}
+
+// htmlBlockTags are elements that start a new paragraph
+var htmlBlockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true, "header": true,
+	"table": true, "tr": true, "ul": true, "ol": true, "blockquote": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "pre": true,
+	"dl": true, "dt": true, "dd": true, "figure": true,
+}
+
+// htmlToMarkdown converts the readable parts of an HTML page to markdown. It handles
+// headings, paragraphs, lists, links, emphasis, and code, and drops scripts and navigation.
+func htmlToMarkdown(page string) string {
+	var out strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(page))
+
+	var (
+		skipDepth int      // Nesting depth inside skipped elements
+		preDepth  int      // Nesting depth inside <pre>, where whitespace is kept
+		links     []string // href of each open <a>
+	)
+
+	newline := func(n int) {
+		text := out.String()
+		trailing := len(text) - len(strings.TrimRight(text, "\n"))
+		if len(text) == 0 {
+			return
+		}
+		for ; trailing < n; trailing++ {
+			out.WriteByte('\n')
+		}
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		tag := token.Data
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if htmlSkipTags[tag] {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			if htmlBlockTags[tag] {
+				newline(2)
+			}
+			switch tag {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				out.WriteString(strings.Repeat("#", int(tag[1]-'0')) + " ")
+			case "li":
+				newline(1)
+				out.WriteString("- ")
+			case "br":
+				newline(1)
+			case "hr":
+				out.WriteString("---")
+				newline(2)
+			case "pre":
+				out.WriteString("```\n")
+				preDepth++
+			case "code":
+				if preDepth == 0 {
+					out.WriteByte('`')
+				}
+			case "strong", "b":
+				out.WriteString("**")
+			case "em", "i":
+				out.WriteByte('_')
+			case "a":
+				href := htmlAttr(token, "href")
+				if strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+					href = "" // In-page anchors mean nothing outside the page
+				}
+				links = append(links, href)
+				if href != "" {
+					out.WriteByte('[')
+				}
+			case "img":
+				if alt := htmlAttr(token, "alt"); alt != "" {
+					out.WriteString("[image: " + alt + "]")
+				}
+			}
+
+		case html.EndTagToken:
+			if htmlSkipTags[tag] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			switch tag {
+			case "pre":
+				if preDepth > 0 {
+					preDepth--
+				}
+				newline(1)
+				out.WriteString("```")
+			case "code":
+				if preDepth == 0 {
+					out.WriteByte('`')
+				}
+			case "strong", "b":
+				out.WriteString("**")
+			case "em", "i":
+				out.WriteByte('_')
+			case "a":
+				if len(links) > 0 {
+					href := links[len(links)-1]
+					links = links[:len(links)-1]
+					if href != "" {
+						out.WriteString("](" + href + ")")
+					}
+				}
+			case "td", "th":
+				out.WriteString(" | ")
+			}
+			if htmlBlockTags[tag] {
+				newline(2)
+			}
+
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			if preDepth > 0 {
+				out.WriteString(token.Data)
+				continue
+			}
+			text := strings.Join(strings.Fields(token.Data), " ")
+			if text == "" {
+				continue
+			}
+			// Keep the space between inline elements and their neighbours
+			if current := out.String(); len(current) > 0 && startsWithSpace(token.Data) && !strings.HasSuffix(current, " ") && !strings.HasSuffix(current, "\n") {
+				out.WriteByte(' ')
+			}
+			out.WriteString(text)
+			if endsWithSpace(token.Data) {
+				out.WriteByte(' ')
+			}
+		}
+	}
+
+	return collapseBlankLines(out.String())
+}
+
+// htmlAttr returns the value of the named attribute on token
+func htmlAttr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// startsWithSpace reports whether text begins with whitespace
+func startsWithSpace(text string) bool {
+	return len(text) > 0 && strings.TrimLeft(text, " \t\r\n") != text
+}
+
+// endsWithSpace reports whether text ends with whitespace
+func endsWithSpace(text string) bool {
+	return len(text) > 0 && strings.TrimRight(text, " \t\r\n") != text
+}
+
+// collapseBlankLines trims trailing spaces and limits runs of blank lines to one
+func collapseBlankLines(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " ")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://example.com/docs"},
+		{url: "http://localhost:8080/issue/1"},
+		{url: "ftp://example.com/file", wantErr: true},
+		{url: "example.com/docs", wantErr: true},
+		{url: "https://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := validateURL(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("validateURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGithubRawURL(t *testing.T) {
+	got := githubRawURL("https://github.com/imdevan/prompter/blob/main/README.md")
+	want := "https://raw.githubusercontent.com/imdevan/prompter/main/README.md"
+	if got != want {
+		t.Errorf("githubRawURL() = %q, want %q", got, want)
+	}
+
+	other := "https://example.com/blob/main/README.md"
+	if got := githubRawURL(other); got != other {
+		t.Errorf("githubRawURL(%q) = %q, want unchanged", other, got)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	page := `<html><head><title>T</title><style>p{}</style></head><body>
+<nav><a href="/">Home</a></nav>
+<h1>Install</h1>
+<p>Run <code>go install</code> and see <a href="https://example.com/docs">the docs</a>.</p>
+<ul><li>one</li><li><strong>two</strong></li></ul>
+<pre>line 1
+  line 2</pre>
+<script>alert(1)</script>
+</body></html>`
+
+	want := "# Install\n\nRun `go install` and see [the docs](https://example.com/docs).\n\n- one\n- **two**\n\n```\nline 1\n  line 2\n```"
+	if got := htmlToMarkdown(page); got != want {
+		t.Errorf("htmlToMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<p>Hello <em>world</em></p>"))
+		case "/main.go":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("package main\n"))
+		case "/large.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", maxURLBytes+10)))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		markdown bool
+		contains string
+		wantErr  bool
+	}{
+		{name: "html as markdown", path: "/page", markdown: true, contains: "```markdown\nHello _world_\n```"},
+		{name: "html kept", path: "/page", contains: "```html\n<p>Hello <em>world</em></p>\n```"},
+		{name: "raw file", path: "/main.go", contains: "```go\npackage main\n```"},
+		{name: "truncated", path: "/large.txt", contains: "[truncated at 256 KB]"},
+		{name: "binary rejected", path: "/image.png", contains: "Referencing URL:", wantErr: true},
+		{name: "not found", path: "/missing", contains: "Referencing URL:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatURL(context.Background(), server.URL+tt.path, tt.markdown)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatURL(context.Background(), ) error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(got, tt.contains) {
+				t.Errorf("formatURL(context.Background(), ) = %q, want it to contain %q", got, tt.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// templateUsagePrefix starts the stats keys of template usage, e.g. "template/pre/review"
+const templateUsagePrefix = "template/"
+
+// RecordTemplateUsage counts a use, at now, of the pre- and post-templates request was
+// assembled with (exported for app layer)
+func RecordTemplateUsage(cfg *interfaces.Config, request *models.PromptRequest, now time.Time) error {
+	used := map[string]string{"pre": request.PreTemplate, "post": request.PostTemplate}
+	if request.FixMode || (used["pre"] == "" && used["post"] == "") {
+		return nil
+	}
+
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	for templateType, name := range used {
+		if name == "" {
+			continue
+		}
+		var usage interfaces.TemplateUsage
+		err := st.Modify(interfaces.BucketStats, templateUsagePrefix+templateType+"/"+name, &usage, func(bool) error {
+			usage.Count++
+			usage.LastUsed = now
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadTemplateUsage returns the recorded usage of the templates of templateType, pre or post,
+// by name (exported for app layer)
+func LoadTemplateUsage(cfg *interfaces.Config, templateType string) (map[string]interfaces.TemplateUsage, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	keys, err := st.Keys(interfaces.BucketStats)
+	if err != nil {
+		return nil, err
+	}
+	prefix := templateUsagePrefix + templateType + "/"
+	usage := map[string]interfaces.TemplateUsage{}
+	for _, key := range keys {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		var record interfaces.TemplateUsage
+		if _, err := st.Get(interfaces.BucketStats, key, &record); err != nil {
+			return nil, err
+		}
+		usage[name] = record
+	}
+	return usage, nil
+}
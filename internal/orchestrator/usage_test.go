@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestTemplateUsageRoundTrip(t *testing.T) {
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	runs := []struct {
+		request *models.PromptRequest
+		at      time.Time
+	}{
+		{&models.PromptRequest{PreTemplate: "review", PostTemplate: "strict"}, monday},
+		{&models.PromptRequest{PreTemplate: "review"}, tuesday},
+		// Fix prompts don't use the pickers
+		{&models.PromptRequest{FixMode: true, PreTemplate: "debug"}, tuesday},
+	}
+	for _, run := range runs {
+		if err := RecordTemplateUsage(cfg, run.request, run.at); err != nil {
+			t.Fatalf("RecordTemplateUsage() error = %v", err)
+		}
+	}
+
+	pre, err := LoadTemplateUsage(cfg, "pre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interfaces.TemplateUsage{"review": {Count: 2, LastUsed: tuesday}}
+	if !reflect.DeepEqual(pre, want) {
+		t.Errorf("LoadTemplateUsage(pre) = %v, want %v", pre, want)
+	}
+
+	post, err := LoadTemplateUsage(cfg, "post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := post["strict"]; got.Count != 1 || !got.LastUsed.Equal(monday) || len(post) != 1 {
+		t.Errorf("LoadTemplateUsage(post) = %v, want strict used once on monday", post)
+	}
+}
@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/store"
+)
+
+// VarsGlobalScope is the scope of variables that apply in every project. Project scopes are
+// keyed by the project root, which is always an absolute path.
+const VarsGlobalScope = "global"
+
+// varName matches names templates can reach as .Vars.<name>
+var varName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ProjectVarsScope returns the scope of variables for the current project: its root
+func ProjectVarsScope() (string, error) {
+	dir, err := WorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+	return detectProject(dir).Root, nil
+}
+
+// LoadVars returns the variables stored in scope
+func LoadVars(cfg *interfaces.Config, scope string) (map[string]string, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	vars := map[string]string{}
+	if _, err := st.Get(interfaces.BucketVars, scope, &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// SetVar stores value as the variable name in scope, replacing any earlier value
+func SetVar(cfg *interfaces.Config, scope, name, value string) error {
+	if !varName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q: use letters, digits, and underscores, so templates can read it as .Vars.%s", name, name)
+	}
+
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	var vars map[string]string
+	return st.Modify(interfaces.BucketVars, scope, &vars, func(found bool) error {
+		if vars == nil {
+			vars = map[string]string{}
+		}
+		vars[name] = value
+		return nil
+	})
+}
+
+// UnsetVar removes the variable name from scope, reporting whether it was set
+func UnsetVar(cfg *interfaces.Config, scope, name string) (bool, error) {
+	st, err := store.Open(cfg.StateFile)
+	if err != nil {
+		return false, err
+	}
+	defer st.Close()
+
+	vars := map[string]string{}
+	removed := false
+	err = st.Modify(interfaces.BucketVars, scope, &vars, func(found bool) error {
+		_, removed = vars[name]
+		delete(vars, name)
+		return nil
+	})
+	return removed, err
+}
+
+// templateVars returns .Vars: the global variables with the current project's on top. They are
+// read once per run, since every rendered template, alias, and default needs them.
+func (o *Orchestrator) templateVars(cfg *interfaces.Config) map[string]string {
+	if o.vars != nil {
+		return o.vars
+	}
+	o.vars = map[string]string{}
+	if cfg.StateFile == "" {
+		return o.vars
+	}
+
+	scopes := []string{VarsGlobalScope}
+	if project, err := ProjectVarsScope(); err == nil {
+		scopes = append(scopes, project)
+	}
+	for _, scope := range scopes {
+		vars, err := LoadVars(cfg, scope)
+		if err != nil {
+			o.warnings.Add("template variables unavailable: %v", err)
+			break
+		}
+		maps.Copy(o.vars, vars)
+	}
+	return o.vars
+}
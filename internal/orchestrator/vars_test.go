@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+func TestTemplateVars(t *testing.T) {
+	project := t.TempDir()
+	if err := os.Mkdir(filepath.Join(project, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(project, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	scope, err := ProjectVarsScope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Subdirectories share the project's variables
+	t.Chdir(filepath.Join(project, "sub"))
+
+	cfg := &interfaces.Config{StateFile: filepath.Join(t.TempDir(), "state.db")}
+	for _, v := range []struct{ scope, name, value string }{
+		{VarsGlobalScope, "team", "payments"},
+		{VarsGlobalScope, "service", "api"},
+		{scope, "team", "billing"},
+	} {
+		if err := SetVar(cfg, v.scope, v.name, v.value); err != nil {
+			t.Fatalf("SetVar(%s) error = %v", v.name, err)
+		}
+	}
+	if err := SetVar(cfg, VarsGlobalScope, "on-call", "me"); err == nil {
+		t.Error("expected an error for a name templates can't reach as .Vars.<name>")
+	}
+
+	request := models.NewPromptRequest()
+	request.BasePrompt = "go"
+	request.PreInline = "{{.Vars.team}}/{{.Vars.service}}"
+	result, err := New().generateNormalPrompt(context.Background(), request, cfg)
+	if err != nil {
+		t.Fatalf("generateNormalPrompt() error = %v", err)
+	}
+	if got := result.Sections[0].Content; got != "billing/api" {
+		t.Errorf("pre-template = %q, want the project's team over the global one", got)
+	}
+
+	removed, err := UnsetVar(cfg, scope, "team")
+	if err != nil || !removed {
+		t.Fatalf("UnsetVar() = %v, %v; want it removed", removed, err)
+	}
+	if removed, _ := UnsetVar(cfg, scope, "team"); removed {
+		t.Error("UnsetVar() removed a variable that wasn't set")
+	}
+	if got := New().templateVars(cfg)["team"]; got != "payments" {
+		t.Errorf(".Vars.team = %q after unsetting the project's, want the global one", got)
+	}
+}
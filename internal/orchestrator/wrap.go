@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// listItem matches the marker of a markdown list item, e.g. "- ", "  * ", or "2. "
+var listItem = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+`)
+
+// wrappedClasses are the section classes --wrap reflows. Files, trees, diffs, and captured
+// output keep their lines as they are.
+var wrappedClasses = map[string]bool{
+	SectionBase:    true,
+	SectionContext: true,
+}
+
+// wrapSections reflows the prose sections to width columns. A width of zero or less disables it.
+func wrapSections(sections []promptSection, width int) []promptSection {
+	if width <= 0 {
+		return sections
+	}
+	wrapped := make([]promptSection, len(sections))
+	for i, section := range sections {
+		if wrappedClasses[section.Class] {
+			section.Content = wrapProse(section.Content, width)
+		}
+		wrapped[i] = section
+	}
+	return wrapped
+}
+
+// wrapProse reflows the paragraphs and list items of markdown text to width columns. Code
+// fences, indented code, headings, tables, and block quotes are kept line for line, and a hard
+// line break ends a paragraph. Words longer than width get a line of their own.
+func wrapProse(text string, width int) string {
+	var out []string
+	var words []string
+	lead, indent := "", ""
+	flush := func() {
+		if len(words) > 0 {
+			out = append(out, fillLines(words, width, lead, indent)...)
+		}
+		words, lead, indent = nil, "", ""
+	}
+
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			out = append(out, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fence = trimmed[:3]
+			out = append(out, line)
+			continue
+		}
+
+		if marker := listItem.FindString(line); marker != "" {
+			// Continuation lines hang under the item's text
+			flush()
+			lead, indent = marker, strings.Repeat(" ", len(marker))
+			words = strings.Fields(line[len(marker):])
+		} else if isProse(line) {
+			if len(words) == 0 {
+				lead = line[:len(line)-len(strings.TrimLeft(line, " "))]
+				indent = lead
+			}
+			words = append(words, strings.Fields(line)...)
+		} else {
+			flush()
+			out = append(out, line)
+			continue
+		}
+		if hardBreak(line) {
+			flush()
+			if strings.HasSuffix(line, "  ") {
+				out[len(out)-1] += "  "
+			}
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// isProse reports whether line can be joined into a paragraph
+func isProse(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return false
+	case strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    "):
+		return false
+	case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, ">"):
+		return false
+	case strings.HasPrefix(trimmed, "<"):
+		return false
+	}
+	return true
+}
+
+// hardBreak reports whether line ends in a markdown hard line break
+func hardBreak(line string) bool {
+	return strings.HasSuffix(line, "  ") || strings.HasSuffix(line, `\`)
+}
+
+// fillLines packs words into lines of at most columns wide. The first line starts with lead
+// and the rest with indent.
+func fillLines(words []string, columns int, lead, indent string) []string {
+	var lines []string
+	line := lead
+	empty := true
+	for _, word := range words {
+		if !empty && displayWidth(line)+1+displayWidth(word) > columns {
+			lines = append(lines, line)
+			line, empty = indent, true
+		}
+		if !empty {
+			line += " "
+		}
+		line += word
+		empty = false
+	}
+	if !empty {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// displayWidth is the number of terminal columns text takes: one per character, and two for
+// the wide characters of Chinese, Japanese, and Korean
+func displayWidth(text string) int {
+	columns := 0
+	for _, r := range text {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			columns += 2
+		default:
+			columns++
+		}
+	}
+	return columns
+}
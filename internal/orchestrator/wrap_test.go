@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapProse(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "reflows a paragraph",
+			text:  "one two three\nfour five six seven",
+			width: 10,
+			want:  "one two\nthree four\nfive six\nseven",
+		},
+		{
+			name:  "keeps code fences",
+			text:  "alpha beta gamma\n```go\nfunc main() { println(\"a long line of code\") }\n```\ndelta epsilon",
+			width: 11,
+			want:  "alpha beta\ngamma\n```go\nfunc main() { println(\"a long line of code\") }\n```\ndelta\nepsilon",
+		},
+		{
+			name:  "hangs list items under their text",
+			text:  "- first item wraps here\n2. second",
+			width: 12,
+			want:  "- first item\n  wraps here\n2. second",
+		},
+		{
+			name:  "keeps headings, tables, quotes, and indented code",
+			text:  "# A long heading line\n| a | b |\n> quoted text here\n    indented code line",
+			width: 5,
+			want:  "# A long heading line\n| a | b |\n> quoted text here\n    indented code line",
+		},
+		{
+			name:  "long words get their own line",
+			text:  "see https://example.com/a/very/long/path now",
+			width: 10,
+			want:  "see\nhttps://example.com/a/very/long/path\nnow",
+		},
+		{
+			name:  "hard breaks end a paragraph",
+			text:  "first line  \nsecond line",
+			width: 40,
+			want:  "first line  \nsecond line",
+		},
+		{
+			name:  "keeps blank lines between paragraphs",
+			text:  "a b\n\nc d",
+			width: 40,
+			want:  "a b\n\nc d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapProse(tt.text, tt.width); got != tt.want {
+				t.Errorf("wrapProse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapSections(t *testing.T) {
+	sections := []promptSection{
+		{Class: SectionBase, Content: "wrap these words"},
+		{Class: SectionFix, Content: "keep these words"},
+	}
+
+	want := []promptSection{
+		{Class: SectionBase, Content: "wrap\nthese\nwords"},
+		{Class: SectionFix, Content: "keep these words"},
+	}
+	if got := wrapSections(sections, 6); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapSections() = %v, want %v", got, want)
+	}
+	if got := wrapSections(sections, 0); !reflect.DeepEqual(got, sections) {
+		t.Errorf("wrapSections() with width 0 = %v, want sections unchanged", got)
+	}
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// lockTimeout bounds how long an operation waits for another prompter process holding the lock
+const lockTimeout = 5 * time.Second
+
+// ErrBusy is returned when another process held the state database for longer than lockTimeout
+var ErrBusy = errors.New("state database is busy")
+
+// BoltStore implements the Store interface on a single bbolt database file.
+//
+// bbolt holds an exclusive file lock for as long as a database is open, so a long-lived
+// handle would block every other prompter invocation (and any future daemon). Instead each
+// operation opens the file, runs one transaction, and closes it: writers serialize on the
+// file lock, readers share it, and a crashed process never leaves the database locked.
+type BoltStore struct {
+	path string
+	mu   sync.Mutex // The file lock is per open file, so goroutines in one process queue here first
+}
+
+// Open prepares the state database at path (usually cfg.StateFile), creating it if needed
+func Open(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	s := &BoltStore{path: path}
+
+	// Create the file up front so read-only opens have something to open
+	if err := s.update(func(tx *bolt.Tx) error { return nil }); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// update runs fn in a read-write transaction while holding the exclusive file lock
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	return s.withDB(false, func(db *bolt.DB) error { return db.Update(fn) })
+}
+
+// view runs fn in a read-only transaction while holding a shared file lock
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	return s.withDB(true, func(db *bolt.DB) error { return db.View(fn) })
+}
+
+// withDB opens the database for the duration of fn
+func (s *BoltStore) withDB(readOnly bool, fn func(db *bolt.DB) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: lockTimeout, ReadOnly: readOnly})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("%w: %s is locked by another prompter process", ErrBusy, s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open state database %s: %w", s.path, err)
+	}
+
+	if err := fn(db); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// Put stores value as JSON under key in bucket, replacing any existing value
+func (s *BoltStore) Put(bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Get decodes the value under key into value, reporting whether it was found
+func (s *BoltStore) Get(bucket, key string, value interface{}) (bool, error) {
+	var data []byte
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		// Values are only valid inside the transaction, so copy them out
+		if v := b.Get([]byte(key)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Modify decodes the value under key into value, lets fn change it, and stores the result,
+// all in one transaction so concurrent read-modify-write updates (counters, lists) aren't lost
+func (s *BoltStore) Modify(bucket, key string, value interface{}, fn func(found bool) error) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+		}
+
+		existing := b.Get([]byte(key))
+		if existing != nil {
+			if err := json.Unmarshal(existing, value); err != nil {
+				return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+			}
+		}
+
+		if err := fn(existing != nil); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Delete removes key from bucket; deleting a missing key is not an error
+func (s *BoltStore) Delete(bucket, key string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// Append stores value under the next sequential key in bucket and returns that key.
+// Keys are zero-padded so they sort in insertion order.
+func (s *BoltStore) Append(bucket string, value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s entry: %w", bucket, err)
+	}
+
+	var key string
+	err = s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key = fmt.Sprintf("%020d", seq)
+		return b.Put([]byte(key), data)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// Keys returns the keys in bucket in ascending order
+func (s *BoltStore) Keys(bucket string) ([]string, error) {
+	var keys []string
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Close releases the store; the database is only held open during operations
+func (s *BoltStore) Close() error {
+	return nil
+}
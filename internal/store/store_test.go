@@ -0,0 +1,141 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// TestBoltStoreImplementsInterface verifies that BoltStore implements the Store interface
+func TestBoltStoreImplementsInterface(t *testing.T) {
+	var _ interfaces.Store = (*BoltStore)(nil)
+}
+
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "nested", "state.db"))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// testBucket holds the records these tests write; the store creates buckets on first write
+const testBucket = "test"
+
+func TestBoltStore_PutGetDelete(t *testing.T) {
+	s := openTestStore(t)
+
+	type favorite struct {
+		Pre  string `json:"pre"`
+		Post string `json:"post"`
+	}
+
+	var got favorite
+	if found, err := s.Get(testBucket, "review", &got); err != nil || found {
+		t.Fatalf("Get() on empty store = %v, %v; expected not found", found, err)
+	}
+
+	want := favorite{Pre: "question", Post: "clarify"}
+	if err := s.Put(testBucket, "review", want); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	found, err := s.Get(testBucket, "review", &got)
+	if err != nil || !found {
+		t.Fatalf("Get() = %v, %v; expected found", found, err)
+	}
+	if got != want {
+		t.Errorf("Get() = %+v, expected %+v", got, want)
+	}
+
+	if err := s.Delete(testBucket, "review"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if found, _ := s.Get(testBucket, "review", &got); found {
+		t.Error("expected key to be deleted")
+	}
+	if err := s.Delete(interfaces.BucketSessions, "missing"); err != nil {
+		t.Errorf("Delete() on missing bucket error: %v", err)
+	}
+}
+
+func TestBoltStore_AppendKeepsOrder(t *testing.T) {
+	s := openTestStore(t)
+
+	for _, command := range []string{"go build", "go vet", "go test"} {
+		if _, err := s.Append(testBucket, command); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+
+	keys, err := s.Keys(testBucket)
+	if err != nil {
+		t.Fatalf("Keys() error: %v", err)
+	}
+
+	var commands []string
+	for _, key := range keys {
+		var command string
+		if _, err := s.Get(testBucket, key, &command); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		commands = append(commands, command)
+	}
+
+	expected := []string{"go build", "go vet", "go test"}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("commands = %v, expected %v", commands, expected)
+	}
+}
+
+func TestBoltStore_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	// Separate stores on one file stand in for separate prompter processes
+	const writers, increments = 4, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := Open(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer s.Close()
+			for j := 0; j < increments; j++ {
+				var count int
+				if err := s.Modify(interfaces.BucketStats, "runs", &count, func(bool) error {
+					count++
+					return nil
+				}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent write error: %v", err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	var count int
+	if _, err := s.Get(interfaces.BucketStats, "runs", &count); err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if count != writers*increments {
+		t.Errorf("count = %d, expected %d", count, writers*increments)
+	}
+}
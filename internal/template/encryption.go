@@ -0,0 +1,145 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Tools that can encrypt templates at rest
+const (
+	EncryptionAge = "age"
+	EncryptionGPG = "gpg"
+)
+
+// encryptedExtensions maps the suffix of an encrypted template to the tool that decrypts it
+var encryptedExtensions = map[string]string{
+	".age": EncryptionAge,
+	".gpg": EncryptionGPG,
+	".asc": EncryptionGPG,
+}
+
+// EncryptedExtension returns the file suffix for templates encrypted with tool
+func EncryptedExtension(tool string) (string, error) {
+	switch tool {
+	case EncryptionAge:
+		return ".age", nil
+	case EncryptionGPG:
+		return ".gpg", nil
+	default:
+		return "", fmt.Errorf("unsupported encryption tool %q (use age or gpg)", tool)
+	}
+}
+
+// encryptionTool returns the tool that decrypts path, or "" for a plain template
+func encryptionTool(path string) string {
+	return encryptedExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// IsEncrypted reports whether path is a template stored encrypted
+func IsEncrypted(path string) bool {
+	return encryptionTool(path) != ""
+}
+
+// TemplateName returns the name of the template stored in filename, stripping .md and any
+// encryption suffix; ok is false for files that aren't templates
+func TemplateName(filename string) (name string, ok bool) {
+	if encryptionTool(filename) != "" {
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+	if filepath.Ext(filename) != ".md" {
+		return "", false
+	}
+	return strings.TrimSuffix(filename, ".md"), true
+}
+
+// EncryptTemplate encrypts content for recipient; without a recipient the tool asks for a passphrase
+func EncryptTemplate(content []byte, tool, recipient string) ([]byte, error) {
+	var args []string
+	switch tool {
+	case EncryptionAge:
+		args = []string{"--encrypt"}
+		if recipient != "" {
+			args = append(args, "--recipient", recipient)
+		} else {
+			args = append(args, "--passphrase")
+		}
+	case EncryptionGPG:
+		args = []string{"--quiet", "--output", "-"}
+		if recipient != "" {
+			args = append(args, "--encrypt", "--recipient", recipient)
+		} else {
+			args = append(args, "--symmetric")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption tool %q (use age or gpg)", tool)
+	}
+
+	return runEncryptionTool(tool, args, bytes.NewReader(content))
+}
+
+// decryptTemplate decrypts the template at path. gpg unlocks keys through its agent; age uses
+// ageIdentity when set and otherwise asks for the passphrase on the terminal. Neither reads
+// prompter's stdin, which may be a pipe holding the prompt.
+func decryptTemplate(path, ageIdentity string) ([]byte, error) {
+	var args []string
+	tool := encryptionTool(path)
+	switch tool {
+	case EncryptionAge:
+		args = []string{"--decrypt"}
+		if ageIdentity != "" {
+			args = append(args, "--identity", ageIdentity)
+		}
+	case EncryptionGPG:
+		args = []string{"--quiet", "--decrypt"}
+	default:
+		return nil, fmt.Errorf("%s is not an encrypted template", path)
+	}
+
+	// Only age's passphrase prompt takes input, and that comes from the terminal
+	var stdin io.Reader
+	if tool == EncryptionAge && ageIdentity == "" {
+		if tty, err := os.Open("/dev/tty"); err == nil {
+			defer tty.Close()
+			stdin = tty
+		}
+	}
+
+	content, err := runEncryptionTool(tool, append(args, path), stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt template %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// runEncryptionTool runs tool with stdin as input and returns its output. Passphrase prompts
+// don't use stdout or stderr: age reads from the terminal and gpg asks through its agent.
+func runEncryptionTool(tool string, args []string, stdin io.Reader) ([]byte, error) {
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s is not installed", tool)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tool, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: %s", tool, lastLine(message))
+		}
+		return nil, fmt.Errorf("%s: %w", tool, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the final line of text, where tools put the reason they failed
+func lastLine(text string) string {
+	lines := strings.Split(text, "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,114 @@
+package template
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestTemplateName(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		wantOK   bool
+	}{
+		{filename: "review.md", want: "review", wantOK: true},
+		{filename: "review.md.gpg", want: "review", wantOK: true},
+		{filename: "secret.default.md.age", want: "secret.default", wantOK: true},
+		{filename: "notes.txt", wantOK: false},
+		{filename: "notes.txt.gpg", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, ok := TemplateName(tt.filename)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TemplateName(%q) = %q, %v; want %q, %v", tt.filename, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProcessor_EncryptedTemplate(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+
+	// Use a throwaway keyring with an unprotected key so decryption needs no prompt
+	home := t.TempDir()
+	t.Cleanup(func() { exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run() })
+	t.Setenv("GNUPGHOME", home)
+	const recipient = "prompter-test@example.com"
+	if out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", recipient, "default", "default", "never").CombinedOutput(); err != nil {
+		t.Skipf("gpg key generation failed: %v\n%s", err, out)
+	}
+
+	encrypted, err := EncryptTemplate([]byte("Secret {{.Prompt}}"), EncryptionGPG, recipient)
+	if err != nil {
+		t.Fatalf("EncryptTemplate() error = %v", err)
+	}
+	if strings.Contains(string(encrypted), "Secret") {
+		t.Fatalf("EncryptTemplate() output contains the plaintext")
+	}
+
+	promptsDir := t.TempDir()
+	preDir := filepath.Join(promptsDir, "pre")
+	if err := os.MkdirAll(preDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(preDir, "private.md.gpg"), encrypted, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := NewProcessor(promptsDir)
+	tmpl, err := processor.LoadTemplate("private")
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	got, err := processor.Execute(tmpl, interfaces.TemplateData{Prompt: "plan"})
+	if err != nil || got != "Secret plan" {
+		t.Errorf("Execute() = %q, %v; want %q", got, err, "Secret plan")
+	}
+
+	// A file that isn't valid ciphertext reports which template failed
+	corrupt := filepath.Join(preDir, "corrupt.md.gpg")
+	if err := os.WriteFile(corrupt, []byte("not encrypted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := processor.LoadTemplate("corrupt"); err == nil || !strings.Contains(err.Error(), corrupt) {
+		t.Errorf("LoadTemplate() for corrupt template error = %v, want path in error", err)
+	}
+}
+
+func TestDecryptTemplateLeavesStdin(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("uses sh")
+	}
+
+	// A stand-in gpg that prints whatever it reads
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "gpg"), []byte("#!/bin/sh\ncat\necho decrypted\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.WriteString("piped prompt")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	got, err := decryptTemplate(filepath.Join(t.TempDir(), "review.md.gpg"), "")
+	if err != nil || string(got) != "decrypted\n" {
+		t.Errorf("decryptTemplate() = %q, %v; want stdin left unread", got, err)
+	}
+}
@@ -0,0 +1,61 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+)
+
+// SetTemplateExec turns the exec helper on or off and sets how long its commands may run
+func (p *Processor) SetTemplateExec(enabled bool, timeout time.Duration) {
+	p.execEnabled = enabled
+	p.execTimeout = timeout
+}
+
+// execFunc runs command through the shell and returns its output without the trailing
+// newline, e.g. {{exec "go env GOVERSION"}}. It only runs with allow_template_exec, since
+// templates from packs and shared directories would otherwise run arbitrary commands. A
+// command that exits non-zero still inserts its output, with a warning, so a failing test
+// run can be summarized.
+func (p *Processor) execFunc(command string) (string, error) {
+	if !p.execEnabled {
+		return "", fmt.Errorf("exec %q: running commands from templates is off; set allow_template_exec = true to allow it", command)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.execTimeout)
+	defer cancel()
+	cmd := execCommand(ctx, command)
+	// Children of the shell may hold its output open after it is killed
+	cmd.WaitDelay = time.Second
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return "", fmt.Errorf("exec %q: timed out after %s (template_exec_timeout)", command, p.execTimeout)
+	case errors.As(err, &exitErr):
+		message := fmt.Sprintf("template command %q exited with status %d", command, exitErr.ExitCode())
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			message += ": " + detail
+		}
+		p.warnings.Add("%s", message)
+	case err != nil:
+		return "", fmt.Errorf("exec %q: %w", command, err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// execCommand builds the command exec runs: through sh, or cmd on Windows
+func execCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,54 @@
+package template
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+func TestExecFunc(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	defer warnings.Flush(io.Discard)
+
+	processor := NewProcessor(t.TempDir())
+	render := func(text string) (string, error) {
+		return processor.RenderString("exec", text, interfaces.TemplateData{})
+	}
+
+	if _, err := render(`{{exec "echo hi"}}`); err == nil || !strings.Contains(err.Error(), "allow_template_exec") {
+		t.Errorf("exec while off error = %v, want a hint to set allow_template_exec", err)
+	}
+
+	processor.SetTemplateExec(true, 5*time.Second)
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "output", text: `Go: {{exec "printf 'go1.25\n\n'"}}.`, want: "Go: go1.25."},
+		{name: "pipes into helpers", text: `{{exec "echo fine" | upper}}`, want: "FINE"},
+		{name: "failing command keeps its output", text: `{{exec "echo 2 failed; exit 1"}}`, want: "2 failed"},
+		{name: "unknown command warns", text: `{{exec "/nonexistent/tool"}}`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := render(tt.text)
+			if err != nil || got != tt.want {
+				t.Errorf("render(%s) = %q, %v; want %q", tt.text, got, err, tt.want)
+			}
+		})
+	}
+
+	processor.SetTemplateExec(true, 50*time.Millisecond)
+	if _, err := render(`{{exec "sleep 5"}}`); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow exec error = %v, want a timeout", err)
+	}
+}
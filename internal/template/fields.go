@@ -0,0 +1,207 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// templateDataType is the type templates are executed with
+var templateDataType = reflect.TypeOf(interfaces.TemplateData{})
+
+// CheckFields returns an error for each field tmpl references that the template data doesn't
+// have, such as .Filez for .Files. Unlike execution, it also covers branches the current data
+// wouldn't reach. Fields of maps (.Env.HOME) and of function results aren't checked.
+func CheckFields(tmpl *template.Template) []error {
+	if tmpl.Tree == nil {
+		return nil
+	}
+	checker := &fieldChecker{tree: tmpl.Tree, vars: map[string]reflect.Type{}}
+	checker.walk(tmpl.Tree.Root, templateDataType)
+	return checker.errs
+}
+
+// fieldChecker follows the type of dot and of variables through a template's parse tree.
+// A nil type means unknown, and nothing reached through it is checked.
+type fieldChecker struct {
+	tree *parse.Tree
+	vars map[string]reflect.Type
+	errs []error
+}
+
+// walk checks node and its children with dot of type dot
+func (c *fieldChecker) walk(node parse.Node, dot reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		c.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		c.pipe(n.Pipe, dot)
+		c.walk(n.List, dot)
+		c.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		c.walk(n.List, c.pipe(n.Pipe, dot))
+		c.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		key, elem := rangeTypes(c.pipe(n.Pipe, dot))
+		switch len(n.Pipe.Decl) {
+		case 1:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = key
+			c.vars[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		c.walk(n.List, elem)
+		c.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			c.pipe(n.Pipe, dot)
+		}
+	}
+}
+
+// pipe checks a pipeline and returns the type it evaluates to, when that is known
+func (c *fieldChecker) pipe(pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	var result reflect.Type
+	for _, cmd := range pipe.Cmds {
+		result = nil
+		for _, arg := range cmd.Args {
+			argType := c.arg(arg, dot)
+			// Only a lone value keeps its type; anything else is a function call
+			if len(cmd.Args) == 1 {
+				result = argType
+			}
+		}
+	}
+	if len(pipe.Decl) == 1 {
+		c.vars[pipe.Decl[0].Ident[0]] = result
+	}
+	return result
+}
+
+// arg checks one argument of a command and returns its type, when that is known
+func (c *fieldChecker) arg(node parse.Node, dot reflect.Type) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.resolve(n, dot, n.Ident)
+	case *parse.VariableNode:
+		base := templateDataType
+		if n.Ident[0] != "$" {
+			base = c.vars[n.Ident[0]]
+		}
+		return c.resolve(n, base, n.Ident[1:])
+	case *parse.ChainNode:
+		return c.resolve(n, c.arg(n.Node, dot), n.Field)
+	case *parse.PipeNode:
+		return c.pipe(n, dot)
+	}
+	return nil
+}
+
+// resolve follows the field names from t, reporting the first one t doesn't have
+func (c *fieldChecker) resolve(node parse.Node, t reflect.Type, fields []string) reflect.Type {
+	for _, field := range fields {
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() == reflect.Interface {
+			return nil
+		}
+		if t.Kind() == reflect.Map {
+			t = t.Elem()
+			continue
+		}
+
+		if method, ok := reflect.PointerTo(t).MethodByName(field); ok {
+			if method.Type.NumOut() == 0 {
+				return nil
+			}
+			t = method.Type.Out(0)
+			continue
+		}
+		if t.Kind() == reflect.Struct {
+			if f, ok := t.FieldByName(field); ok && f.IsExported() {
+				t = f.Type
+				continue
+			}
+		}
+
+		location, _ := c.tree.ErrorContext(node)
+		c.errs = append(c.errs, fmt.Errorf("%s: unknown field %s in %s%s", location, field, typeName(t), suggestField(t, field)))
+		return nil
+	}
+	return t
+}
+
+// rangeTypes returns the key and element types of ranging over t
+func rangeTypes(t reflect.Type) (key, elem reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), t.Elem()
+	case reflect.Map:
+		return t.Key(), t.Elem()
+	}
+	return nil, nil
+}
+
+// typeName names t the way a template author knows it, e.g. FileInfo or string
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// suggestField returns a hint naming the field of t closest to the misspelled field
+func suggestField(t reflect.Type, field string) string {
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	best, bestDistance := "", 3 // More than two edits away is a different name, not a typo
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if d := editDistance(strings.ToLower(name), strings.ToLower(field)); t.Field(i).IsExported() && d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
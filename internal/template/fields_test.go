@@ -0,0 +1,45 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCheckFields(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string // Substrings of each error, in order
+	}{
+		{name: "known fields", text: "{{.Prompt}} {{.Git.Branch}} {{.Now.Format \"2006\"}} {{.Fix.Duration.Seconds}}"},
+		{name: "map keys", text: "{{.Env.HOME}} {{.Config.editor}}"},
+		{name: "range element", text: "{{range .Files}}{{.RelPath}}{{.Content}}{{end}}"},
+		{name: "range variables", text: "{{range $i, $d := .Fix.Diagnostics}}{{$i}} {{$d.File}}{{end}}"},
+		{name: "with", text: "{{with .Project}}{{.Type}}{{end}}"},
+		{name: "root variable", text: "{{range .Files}}{{$.Prompt}}{{end}}"},
+		{name: "function results unchecked", text: "{{(index .Files 0).Whatever}} {{first .Files}}"},
+		{name: "typo", text: "{{.Filez}}", want: []string{"unknown field Filez in TemplateData (did you mean Files?)"}},
+		{name: "nested typo", text: "{{.Git.Branc}}", want: []string{"unknown field Branc in GitInfo"}},
+		{name: "typo in range", text: "{{range .Files}}{{.Contents}}{{end}}", want: []string{"unknown field Contents in FileInfo"}},
+		{name: "unreached branch", text: "{{if false}}{{.Fix.Exitcode}}{{end}}", want: []string{"Exitcode in FixInfo (did you mean ExitCode?)"}},
+		{name: "field of a string", text: "{{.Prompt.Text}}", want: []string{"unknown field Text in string"}},
+		{name: "variable", text: "{{$f := .Fix}}{{$f.Outptu}}", want: []string{"unknown field Outptu in FixInfo"}},
+		{name: "each typo", text: "{{.Promt}}\n{{.Cwd}}", want: []string{"t:1:", "t:2:"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("t").Funcs(template.FuncMap{"first": func(v any) any { return v }}).Parse(tt.text))
+			errs := CheckFields(tmpl)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("CheckFields() = %v, want %d errors", errs, len(tt.want))
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.want[i]) {
+					t.Errorf("error %d = %q, want it to contain %q", i, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
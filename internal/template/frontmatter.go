@@ -0,0 +1,105 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FrontMatter holds the settings a template declares in a leading YAML block:
+//
+//	---
+//	description: Review a change for bugs
+//	max_tokens: 800
+//	type: pre
+//	tags: [review]
+//	---
+type FrontMatter struct {
+	Description string   `yaml:"description"`
+	MaxTokens   int      `yaml:"max_tokens"` // Warn or fail when the rendered template is larger (0 = no cap)
+	Type        string   `yaml:"type"`       // pre or post, used when importing with 'prompter add --from-file'
+	Tags        []string `yaml:"tags"`       // Labels [template_group] tag matches
+	Weight      int      `yaml:"weight"`     // Relative chance of being picked from a random group (0 counts as 1)
+	Deprecated  bool     `yaml:"deprecated"`  // Warn when the template is used
+	ReplacedBy  string   `yaml:"replaced_by"` // Template to use instead; implies deprecated
+}
+
+// IsDeprecated reports whether the template is marked deprecated or replaced
+func (fm FrontMatter) IsDeprecated() bool {
+	return fm.Deprecated || fm.ReplacedBy != ""
+}
+
+// frontMatterDelimiter opens and closes the front matter block
+var frontMatterDelimiter = []byte("---")
+
+// splitFrontMatter separates a leading front matter block from the template body.
+// Content without one is returned unchanged with empty front matter.
+func splitFrontMatter(content []byte) (FrontMatter, []byte, error) {
+	var fm FrontMatter
+
+	rest, ok := cutLine(content, frontMatterDelimiter)
+	if !ok {
+		return fm, content, nil
+	}
+
+	// Find the closing delimiter on a line of its own
+	var block []byte
+	for len(rest) > 0 {
+		line := rest
+		next := []byte(nil)
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, next = rest[:i+1], rest[i+1:]
+		}
+		if bytes.Equal(bytes.TrimRight(line, "\r\n"), frontMatterDelimiter) {
+			// Markdown between two horizontal rules isn't a YAML mapping, so it isn't front matter
+			var fields map[string]interface{}
+			if yaml.Unmarshal(block, &fields) != nil || len(fields) == 0 {
+				return FrontMatter{}, content, nil
+			}
+			if err := yaml.Unmarshal(block, &fm); err != nil {
+				return FrontMatter{}, nil, fmt.Errorf("invalid front matter: %w", err)
+			}
+			if fm.MaxTokens < 0 {
+				return FrontMatter{}, nil, fmt.Errorf("invalid front matter: max_tokens must be 0 or greater")
+			}
+			if fm.Weight < 0 {
+				return FrontMatter{}, nil, fmt.Errorf("invalid front matter: weight must be 0 or greater")
+			}
+			if fm.Type != "" && fm.Type != "pre" && fm.Type != "post" {
+				return FrontMatter{}, nil, fmt.Errorf("invalid front matter: type must be pre or post")
+			}
+			return fm, next, nil
+		}
+		block = append(block, line...)
+		rest = next
+	}
+
+	// No closing delimiter: the template merely starts with a horizontal rule
+	return FrontMatter{}, content, nil
+}
+
+// cutLine removes a first line equal to want, reporting whether it was there
+func cutLine(content, want []byte) ([]byte, bool) {
+	line, rest, found := bytes.Cut(content, []byte("\n"))
+	if !found || !bytes.Equal(bytes.TrimRight(line, "\r"), want) {
+		return content, false
+	}
+	return rest, true
+}
+
+// ReadFrontMatter returns the front matter of the template file at path without parsing the
+// template. Encrypted templates aren't decrypted for this and report empty front matter.
+func ReadFrontMatter(path string) (FrontMatter, error) {
+	if encryptionTool(path) != "" {
+		return FrontMatter{}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return FrontMatter{}, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+	fm, _, err := splitFrontMatter(content)
+	return fm, err
+}
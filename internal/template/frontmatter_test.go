@@ -0,0 +1,123 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     FrontMatter
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "front matter",
+			content:  "---\ndescription: Review a change\nmax_tokens: 800\n---\n# Review\n",
+			want:     FrontMatter{Description: "Review a change", MaxTokens: 800},
+			wantBody: "# Review\n",
+		},
+		{
+			name:     "crlf line endings",
+			content:  "---\r\nmax_tokens: 50\r\n---\r\nbody",
+			want:     FrontMatter{MaxTokens: 50},
+			wantBody: "body",
+		},
+		{
+			name:     "no front matter",
+			content:  "# Review\n---\nmore",
+			wantBody: "# Review\n---\nmore",
+		},
+		{
+			name:     "markdown between horizontal rules",
+			content:  "---\nSome intro text.\n---\nbody",
+			wantBody: "---\nSome intro text.\n---\nbody",
+		},
+		{
+			name:     "unclosed block",
+			content:  "---\nmax_tokens: 5\nbody",
+			wantBody: "---\nmax_tokens: 5\nbody",
+		},
+		{
+			name:    "wrong type",
+			content: "---\nmax_tokens: lots\n---\nbody",
+			wantErr: true,
+		},
+		{
+			name:    "negative cap",
+			content: "---\nmax_tokens: -1\n---\nbody",
+			wantErr: true,
+		},
+		{
+			name:     "template type",
+			content:  "---\ntype: post\n---\nbody",
+			want:     FrontMatter{Type: "post"},
+			wantBody: "body",
+		},
+		{
+			name:     "tags and weight",
+			content:  "---\ntags: [brainstorm, wild]\nweight: 3\n---\nbody",
+			want:     FrontMatter{Tags: []string{"brainstorm", "wild"}, Weight: 3},
+			wantBody: "body",
+		},
+		{
+			name:    "negative weight",
+			content: "---\nweight: -2\n---\nbody",
+			wantErr: true,
+		},
+		{
+			name:    "unknown template type",
+			content: "---\ntype: middle\n---\nbody",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := splitFrontMatter([]byte(tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitFrontMatter() expected error, got %+v", fm)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitFrontMatter() error = %v", err)
+			}
+			if !reflect.DeepEqual(fm, tt.want) || string(body) != tt.wantBody {
+				t.Errorf("splitFrontMatter() = %+v, %q; want %+v, %q", fm, body, tt.want, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestProcessor_TemplateFrontMatter(t *testing.T) {
+	promptsDir := t.TempDir()
+	preDir := filepath.Join(promptsDir, "pre")
+	if err := os.MkdirAll(preDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\ndescription: Short review\nmax_tokens: 10\n---\nReview {{.Prompt}}"
+	if err := os.WriteFile(filepath.Join(preDir, "review.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := NewProcessor(promptsDir)
+	tmpl, err := processor.LoadTemplate("review")
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	if fm := processor.TemplateFrontMatter(tmpl); fm.MaxTokens != 10 || fm.Description != "Short review" {
+		t.Errorf("TemplateFrontMatter() = %+v", fm)
+	}
+	got, err := processor.Execute(tmpl, interfaces.TemplateData{Prompt: "this"})
+	if err != nil || got != "Review this" {
+		t.Errorf("Execute() = %q, %v; want front matter stripped", got, err)
+	}
+}
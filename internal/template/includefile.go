@@ -0,0 +1,46 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileIncluder reads what the includeFile and includeDir helpers insert, applying the size
+// limits, skip rules, and redaction the files a prompt embeds get
+type FileIncluder interface {
+	// IncludeFile returns the content of the file at path
+	IncludeFile(path string) (string, error)
+	// IncludeDir returns the files under the directory at path, one per line
+	IncludeDir(path string) (string, error)
+}
+
+// SetFileIncluder sets what reads files for includeFile and includeDir; nil turns them off
+func (p *Processor) SetFileIncluder(includer FileIncluder) {
+	p.includer = includer
+}
+
+// includeFileFunc inserts a workspace file without its trailing newline, so templates can
+// carry the context they need, e.g. {{includeFile "docs/STYLE.md" | mdFence "markdown"}}
+func (p *Processor) includeFileFunc(path string) (string, error) {
+	if p.includer == nil {
+		return "", fmt.Errorf("includeFile %q: files can't be included here", path)
+	}
+	content, err := p.includer.IncludeFile(path)
+	if err != nil {
+		return "", fmt.Errorf("includeFile %q: %w", path, err)
+	}
+	return strings.TrimRight(content, "\r\n"), nil
+}
+
+// includeDirFunc inserts the files under a workspace directory, one path per line, e.g.
+// {{includeDir "migrations"}}
+func (p *Processor) includeDirFunc(path string) (string, error) {
+	if p.includer == nil {
+		return "", fmt.Errorf("includeDir %q: directories can't be included here", path)
+	}
+	listing, err := p.includer.IncludeDir(path)
+	if err != nil {
+		return "", fmt.Errorf("includeDir %q: %w", path, err)
+	}
+	return strings.TrimRight(listing, "\n"), nil
+}
@@ -0,0 +1,68 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// fakeIncluder serves files from a map and lists its keys under a directory
+type fakeIncluder map[string]string
+
+func (f fakeIncluder) IncludeFile(path string) (string, error) {
+	content, ok := f[path]
+	if !ok {
+		return "", errors.New("no such file")
+	}
+	return content, nil
+}
+
+func (f fakeIncluder) IncludeDir(path string) (string, error) {
+	var listing []string
+	for file := range f {
+		if strings.HasPrefix(file, path+"/") {
+			listing = append(listing, file)
+		}
+	}
+	return strings.Join(listing, "\n") + "\n", nil
+}
+
+func TestIncludeFileFuncs(t *testing.T) {
+	processor := NewProcessor(t.TempDir())
+	render := func(text string) (string, error) {
+		return processor.RenderString("include", text, interfaces.TemplateData{})
+	}
+
+	if _, err := render(`{{includeFile "docs/STYLE.md"}}`); err == nil || !strings.Contains(err.Error(), "can't be included here") {
+		t.Errorf("includeFile without an includer error = %v, want it refused", err)
+	}
+
+	processor.SetFileIncluder(fakeIncluder{"docs/STYLE.md": "Use tabs.\n\n"})
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "file", text: `Style: {{includeFile "docs/STYLE.md"}}!`, want: "Style: Use tabs.!"},
+		{name: "pipes into helpers", text: `{{includeFile "docs/STYLE.md" | indent 2}}`, want: "  Use tabs."},
+		{name: "directory", text: `{{includeDir "docs"}}`, want: "docs/STYLE.md"},
+		{name: "missing file", text: `{{includeFile "README.md"}}`, wantErr: `includeFile "README.md": no such file`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := render(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("render(%s) error = %v, want %q", tt.text, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("render(%s) = %q, %v; want %q", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxIndexBytes bounds how much of a pack index is read
+const maxIndexBytes = 4 << 20
+
+// PackListing is a template pack offered by a pack index
+type PackListing struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`      // Git repository the pack is installed from
+	Installs    int      `json:"installs"` // As counted by the index
+	Tags        []string `json:"tags"`
+}
+
+// packIndex is the document a pack index serves: {"packs": [...]}
+type packIndex struct {
+	Packs []PackListing `json:"packs"`
+}
+
+// FetchPackIndex reads the listings of the pack index at indexURL. The index must be served
+// over HTTPS, except from localhost. Listings without a valid pack name or git URL are dropped.
+func FetchPackIndex(ctx context.Context, indexURL string) ([]PackListing, error) {
+	parsed, err := url.Parse(indexURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid pack index URL %q", indexURL)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && isLoopback(parsed.Hostname())) {
+		return nil, fmt.Errorf("pack index %s must be an https:// URL", indexURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pack index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pack index %s: %s", indexURL, resp.Status)
+	}
+
+	var index packIndex
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexBytes)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid pack index %s: %w", indexURL, err)
+	}
+
+	listings := index.Packs[:0]
+	for _, listing := range index.Packs {
+		if ValidatePackName(listing.Name) != nil || IsReservedNamespace(listing.Name) || ValidatePackURL(listing.URL) != nil {
+			continue
+		}
+		listings = append(listings, listing)
+	}
+	return listings, nil
+}
+
+// SearchPacks returns the listings whose name, description, or tags contain query (ignoring
+// case), most installed first
+func SearchPacks(listings []PackListing, query string) []PackListing {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []PackListing
+	for _, listing := range listings {
+		text := strings.ToLower(listing.Name + "\n" + listing.Description + "\n" + strings.Join(listing.Tags, "\n"))
+		if strings.Contains(text, query) {
+			matches = append(matches, listing)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Installs != matches[j].Installs {
+			return matches[i].Installs > matches[j].Installs
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches
+}
+
+// isLoopback reports whether host is localhost or a loopback address
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,77 @@
+package template
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPackIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`{"packs": [
+				{"name": "review-kit", "description": "Code review prompts", "url": "https://github.com/acme/review-kit.git", "installs": 120, "tags": ["go"]},
+				{"name": "../escape", "url": "https://github.com/acme/escape.git"},
+				{"name": "local", "url": "https://github.com/acme/local.git"},
+				{"name": "no-url"},
+				{"name": "option", "url": "--upload-pack=touch /tmp/pwned"},
+				{"name": "proxy", "url": "ssh://-oProxyCommand=touch /tmp/pwned/x"},
+				{"name": "ext", "url": "ext::sh -c touch% /tmp/pwned"},
+				{"name": "commits", "description": "Commit messages", "url": "https://github.com/acme/commits.git", "installs": 800}
+			]}`))
+		case "/broken.json":
+			w.Write([]byte(`{"packs": [`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	listings, err := FetchPackIndex(context.Background(), server.URL+"/index.json")
+	if err != nil {
+		t.Fatalf("FetchPackIndex() error = %v", err)
+	}
+	if len(listings) != 2 || listings[0].Name != "review-kit" || listings[1].Name != "commits" {
+		t.Errorf("FetchPackIndex() = %+v, want review-kit and commits only", listings)
+	}
+
+	for _, tt := range []struct{ url, want string }{
+		{server.URL + "/broken.json", "invalid pack index"},
+		{server.URL + "/missing.json", "404"},
+		{"http://example.com/index.json", "must be an https:// URL"},
+		{"index.json", "invalid pack index URL"},
+	} {
+		if _, err := FetchPackIndex(context.Background(), tt.url); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("FetchPackIndex(%s) error = %v, want one containing %q", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestSearchPacks(t *testing.T) {
+	listings := []PackListing{
+		{Name: "review-kit", Description: "Code review prompts", Installs: 120, Tags: []string{"go"}},
+		{Name: "commits", Description: "Commit messages", Installs: 800},
+		{Name: "go-idioms", Description: "Idiomatic Go", Installs: 120},
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"commits", "go-idioms", "review-kit"}},
+		{"GO", []string{"go-idioms", "review-kit"}},
+		{"messages", []string{"commits"}},
+		{"rust", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, listing := range SearchPacks(listings, tt.query) {
+			got = append(got, listing.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SearchPacks(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"testing"
 	"time"
 
-	"prompter-cli/internal/interfaces"
+	"github.com/imdevan/prompter/internal/interfaces"
 )
 
 func TestProcessor_IntegrationWithRealTemplates(t *testing.T) {
 	// Use the actual prompts directory
 	promptsDir := filepath.Join("..", "..", "..", "prompts")
+	if _, err := os.Stat(promptsDir); os.IsNotExist(err) {
+		t.Skip("Real prompts directory doesn't exist")
+		return
+	}
 	processor := NewProcessor(promptsDir)
 	
 	// Test loading a real template
@@ -0,0 +1,148 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Namespaces for the built-in prompt locations; custom templates and packs use their own names
+const (
+	NamespaceLocal  = "local"
+	NamespaceGlobal = "global"
+)
+
+// IsReservedNamespace reports whether name is taken by a built-in prompt location
+func IsReservedNamespace(name string) bool {
+	return strings.EqualFold(name, NamespaceLocal) || strings.EqualFold(name, NamespaceGlobal)
+}
+
+// Namespace is a prompt location whose templates can be addressed as namespace/name
+type Namespace struct {
+	Name     string
+	Location string
+	Pack     bool // Installed with 'prompter templates install'
+}
+
+// TemplateFile is a template found in a namespace
+type TemplateFile struct {
+	Name      string // Display name, without extensions or a .default marker
+	Namespace string
+	Type      string // pre or post
+	Path      string
+}
+
+// Namespaces returns the prompt locations in precedence order: local, global, custom
+// templates by name, then packs by name. An unqualified name resolves to the first match.
+func (p *Processor) Namespaces() []Namespace {
+	var namespaces []Namespace
+	if p.localPromptsLocation != "" {
+		namespaces = append(namespaces, Namespace{Name: NamespaceLocal, Location: p.localPromptsLocation})
+	}
+	namespaces = append(namespaces, Namespace{Name: NamespaceGlobal, Location: p.promptsLocation})
+
+	names := make([]string, 0, len(p.customTemplates))
+	for name := range p.customTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		namespaces = append(namespaces, Namespace{Name: name, Location: p.customTemplates[name].Location})
+	}
+
+	for _, pack := range InstalledPacks(p.promptsLocation) {
+		namespaces = append(namespaces, Namespace{Name: pack, Location: filepath.Join(PacksDir(p.promptsLocation), pack), Pack: true})
+	}
+	return namespaces
+}
+
+// namespace returns the namespace called name; the first one wins if names repeat
+func (p *Processor) namespace(name string) (Namespace, bool) {
+	for _, ns := range p.Namespaces() {
+		if strings.EqualFold(ns.Name, name) {
+			return ns, true
+		}
+	}
+	return Namespace{}, false
+}
+
+// templateDirs returns the pre and post directories of namespaces, in order
+func templateDirs(namespaces ...Namespace) []string {
+	var directories []string
+	for _, ns := range namespaces {
+		directories = append(directories, filepath.Join(ns.Location, "pre"), filepath.Join(ns.Location, "post"))
+	}
+	return directories
+}
+
+// TemplateFiles returns every .md template in precedence order: by namespace, pre before post,
+// then by file name. The first file with a given type and name is the one an unqualified name loads.
+func (p *Processor) TemplateFiles() []TemplateFile {
+	var files []TemplateFile
+	for _, ns := range p.Namespaces() {
+		for _, templateType := range []string{"pre", "post"} {
+			dir := filepath.Join(ns.Location, templateType)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					continue
+				}
+				name, ok := TemplateName(entry.Name())
+				if !ok {
+					continue
+				}
+				files = append(files, TemplateFile{
+					Name:      displayName(name),
+					Namespace: ns.Name,
+					Type:      templateType,
+					Path:      filepath.Join(dir, entry.Name()),
+				})
+			}
+		}
+	}
+	return files
+}
+
+// displayName strips the .default marker that sorts a template first in interactive selection
+func displayName(stem string) string {
+	if strings.Contains(stem, ".default.") {
+		return strings.Trim(strings.ReplaceAll(stem, ".default.", "."), ".")
+	}
+	return strings.TrimSuffix(stem, ".default")
+}
+
+// splitNamespaceRef splits a namespace/name template reference
+func splitNamespaceRef(ref string) (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(ref, "/")
+	if !ok || name == "" || strings.Contains(name, "/") || !packNamePattern.MatchString(namespace) {
+		return "", "", false
+	}
+	return namespace, name, true
+}
+
+// findNamespacedTemplate resolves namespace/name when namespace exists, reporting whether it does
+func (p *Processor) findNamespacedTemplate(ref string) (string, bool, error) {
+	nsName, name, ok := splitNamespaceRef(ref)
+	if !ok {
+		return "", false, nil
+	}
+
+	ns, ok := p.namespace(nsName)
+	if !ok {
+		if strings.EqualFold(nsName, NamespaceLocal) {
+			return "", true, fmt.Errorf("template not found: %s (no local prompts location in use)", ref)
+		}
+		return "", false, nil
+	}
+
+	path, err := findTemplateInDirs(templateDirs(ns), name)
+	if err != nil {
+		return "", true, fmt.Errorf("template not found in %s: %s", ns.Name, name)
+	}
+	return path, true, nil
+}
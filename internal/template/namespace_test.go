@@ -0,0 +1,132 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+// writeTemplates creates files (relative path -> content) under root
+func writeTemplates(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessor_Namespaces(t *testing.T) {
+	root := t.TempDir()
+	promptsDir := filepath.Join(root, "global")
+	localDir := filepath.Join(root, "local")
+	writeTemplates(t, root, map[string]string{
+		"global/pre/review.md":              "global review",
+		"global/pre/plain.md":               "global plain",
+		"global/packs/acme/pre/review.md":   "acme review",
+		"local/pre/review.md":               "local review",
+		"team/pre/review.md":                "team review",
+		"team/post/strict.default.md":       "team strict",
+		"zeta/pre/review.md":                "zeta review",
+		"global/packs/acme/pre/unshared.md": "acme unshared",
+	})
+
+	processor := NewProcessor(promptsDir)
+	processor.SetLocalPromptsLocation(localDir)
+	processor.SetCustomTemplates(map[string]interfaces.CustomTemplate{
+		"zeta": {Location: filepath.Join(root, "zeta")},
+		"team": {Location: filepath.Join(root, "team")},
+	})
+
+	var names []string
+	for _, ns := range processor.Namespaces() {
+		names = append(names, ns.Name)
+	}
+	want := []string{"local", "global", "team", "zeta", "acme"}
+	if len(names) != len(want) {
+		t.Fatalf("Namespaces() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Namespaces() = %v, want %v", names, want)
+		}
+	}
+
+	tests := []struct {
+		ref       string
+		want      string
+		wantError bool
+	}{
+		{ref: "review", want: "local review"},
+		{ref: "local/review", want: "local review"},
+		{ref: "global/review", want: "global review"},
+		{ref: "team/review", want: "team review"},
+		{ref: "zeta/review", want: "zeta review"},
+		{ref: "acme/review", want: "acme review"},
+		{ref: "team/strict", want: "team strict"},
+		{ref: "unshared", want: "acme unshared"},
+		{ref: "team/plain", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			tmpl, err := processor.LoadTemplate(tt.ref)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LoadTemplate(%q) expected error", tt.ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTemplate(%q) error = %v", tt.ref, err)
+			}
+			got, err := processor.Execute(tmpl, interfaces.TemplateData{})
+			if err != nil || got != tt.want {
+				t.Errorf("LoadTemplate(%q) rendered %q, %v; want %q", tt.ref, got, err, tt.want)
+			}
+		})
+	}
+
+	if got, err := processor.ResolveTemplate("team/review"); err != nil || got != filepath.Join(root, "team", "pre", "review.md") {
+		t.Errorf("ResolveTemplate(team/review) = %q, %v; want the team file", got, err)
+	}
+
+	// Without a local location, local/ is still a namespace reference, not a relative path
+	processor.SetLocalPromptsLocation("")
+	if _, err := processor.LoadTemplate("local/review"); err == nil {
+		t.Error("LoadTemplate(local/review) without a local location expected error")
+	}
+}
+
+func TestProcessor_TemplateFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTemplates(t, root, map[string]string{
+		"global/pre/review.md":          "global review",
+		"global/post/strict.default.md": "strict",
+		"global/pre/notes.txt":          "not a template",
+		"local/pre/review.md":           "local review",
+	})
+
+	processor := NewProcessor(filepath.Join(root, "global"))
+	processor.SetLocalPromptsLocation(filepath.Join(root, "local"))
+
+	files := processor.TemplateFiles()
+	want := []TemplateFile{
+		{Name: "review", Namespace: "local", Type: "pre", Path: filepath.Join(root, "local/pre/review.md")},
+		{Name: "review", Namespace: "global", Type: "pre", Path: filepath.Join(root, "global/pre/review.md")},
+		{Name: "strict", Namespace: "global", Type: "post", Path: filepath.Join(root, "global/post/strict.default.md")},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("TemplateFiles() = %+v, want %+v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("TemplateFiles()[%d] = %+v, want %+v", i, files[i], want[i])
+		}
+	}
+}
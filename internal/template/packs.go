@@ -0,0 +1,83 @@
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PacksDirName is the subdirectory of the prompts location that holds installed template packs
+const PacksDirName = "packs"
+
+// packNamePattern limits pack names to a single safe path segment
+var packNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// packURLSchemes are the schemes packs are cloned over
+var packURLSchemes = []string{"https", "ssh", "git"}
+
+// scpLikePackURL matches the user@host:path form git reaches over ssh, e.g. git@github.com:acme/prompts.git
+var scpLikePackURL = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*@[A-Za-z0-9][A-Za-z0-9.-]*:[^/]`)
+
+// PacksDir returns the directory holding installed template packs
+func PacksDir(promptsLocation string) string {
+	return filepath.Join(promptsLocation, PacksDirName)
+}
+
+// InstalledPacks returns the names of the packs installed under promptsLocation, sorted
+func InstalledPacks(promptsLocation string) []string {
+	entries, err := os.ReadDir(PacksDir(promptsLocation))
+	if err != nil {
+		return nil
+	}
+
+	var packs []string
+	for _, entry := range entries {
+		if entry.IsDir() && packNamePattern.MatchString(entry.Name()) {
+			packs = append(packs, entry.Name())
+		}
+	}
+	sort.Strings(packs)
+	return packs
+}
+
+// ValidatePackName checks that name can be used as a pack directory
+func ValidatePackName(name string) error {
+	if !packNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid pack name %q (use letters, digits, '.', '_' or '-')", name)
+	}
+	return nil
+}
+
+// ValidatePackURL checks that gitURL names a repository git clones over the network: an
+// https, ssh, or git URL, or user@host:path. A leading '-' in the URL, host, or user would
+// reach git or ssh as an option, e.g. ssh://-oProxyCommand=cmd/x.
+func ValidatePackURL(gitURL string) error {
+	if strings.HasPrefix(gitURL, "-") {
+		return fmt.Errorf("invalid pack URL %q: it can't start with '-'", gitURL)
+	}
+	if scpLikePackURL.MatchString(gitURL) {
+		return nil
+	}
+	u, err := url.Parse(gitURL)
+	if err != nil || !slices.Contains(packURLSchemes, u.Scheme) || u.Hostname() == "" {
+		return fmt.Errorf("invalid pack URL %q (use an https://, ssh://, or git:// URL, or user@host:path)", gitURL)
+	}
+	if strings.HasPrefix(u.Host, "-") || strings.HasPrefix(u.User.Username(), "-") {
+		return fmt.Errorf("invalid pack URL %q: its host and user can't start with '-'", gitURL)
+	}
+	return nil
+}
+
+// PackNameFromURL derives a pack name from a git URL, e.g. git@github.com:acme/prompts.git is "prompts"
+func PackNameFromURL(gitURL string) string {
+	name := strings.TrimRight(gitURL, "/")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
@@ -0,0 +1,97 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestPackNameFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/acme/prompts.git":  "prompts",
+		"https://github.com/acme/review-pack/": "review-pack",
+		"git@github.com:acme/prompts.git":      "prompts",
+		"/srv/git/team.git":                    "team",
+	}
+
+	for gitURL, want := range tests {
+		if got := PackNameFromURL(gitURL); got != want {
+			t.Errorf("PackNameFromURL(%q) = %q, want %q", gitURL, got, want)
+		}
+	}
+}
+
+func TestValidatePackURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://github.com/acme/prompts.git", true},
+		{"ssh://git@github.com/acme/prompts.git", true},
+		{"git://example.com/prompts.git", true},
+		{"git@github.com:acme/prompts.git", true},
+		{"--upload-pack=touch /tmp/x", false},
+		{"-c", false},
+		{"ssh://-oProxyCommand=cmd/x", false},
+		{"ssh://-oProxyCommand=cmd@host/x", false},
+		{"http://example.com/prompts.git", false},
+		{"file:///srv/git/team.git", false},
+		{"ext::sh -c cmd", false},
+		{"/srv/git/team.git", false},
+		{"https:///prompts.git", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidatePackURL(tt.url); (err == nil) != tt.valid {
+			t.Errorf("ValidatePackURL(%q) error = %v, valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestProcessor_LoadPackTemplate(t *testing.T) {
+	promptsDir := t.TempDir()
+	files := map[string]string{
+		"pre/review.md":              "own review",
+		"packs/acme/pre/review.md":   "acme review",
+		"packs/acme/post/strict.md":  "acme strict",
+		"packs/other/pre/explain.md": "other explain",
+	}
+	writeTemplates(t, promptsDir, files)
+
+	processor := NewProcessor(promptsDir)
+	tests := []struct {
+		name      string
+		ref       string
+		want      string
+		wantError bool
+	}{
+		{name: "own template wins unqualified", ref: "review", want: "own review"},
+		{name: "pack prefix selects the pack", ref: "acme/review", want: "acme review"},
+		{name: "pack prefix is case-insensitive for names", ref: "acme/STRICT", want: "acme strict"},
+		{name: "unqualified falls back to packs", ref: "explain", want: "other explain"},
+		{name: "missing template in pack", ref: "acme/explain", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := processor.LoadTemplate(tt.ref)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LoadTemplate(%q) expected error", tt.ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTemplate(%q) error = %v", tt.ref, err)
+			}
+			got, err := processor.Execute(tmpl, interfaces.TemplateData{})
+			if err != nil || got != tt.want {
+				t.Errorf("LoadTemplate(%q) rendered %q, %v; want %q", tt.ref, got, err, tt.want)
+			}
+		})
+	}
+
+	if got := InstalledPacks(promptsDir); len(got) != 2 || got[0] != "acme" || got[1] != "other" {
+		t.Errorf("InstalledPacks() = %v, want [acme other]", got)
+	}
+}
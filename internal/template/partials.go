@@ -0,0 +1,109 @@
+package template
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// PartialsDir is the directory, next to pre/ and post/, holding fragments templates share
+const PartialsDir = "partials"
+
+// maxIncludeDepth bounds nested includes, so partials that include each other fail instead
+// of recursing forever
+const maxIncludeDepth = 32
+
+// PartialDirs returns the partials directory of each namespace, in precedence order
+func (p *Processor) PartialDirs() []string {
+	var directories []string
+	for _, ns := range p.Namespaces() {
+		directories = append(directories, filepath.Join(ns.Location, PartialsDir))
+	}
+	return directories
+}
+
+// addPartials parses every partial into tmpl's template set, named by its path under
+// partials/ without the extension, e.g. "shared/header". Lower-precedence namespaces are
+// parsed first so a local partial replaces a global one with the same name, and tmpl itself
+// is parsed afterwards so its {{define}}s fill a partial's {{block}}s.
+func (p *Processor) addPartials(tmpl *template.Template) error {
+	directories := p.PartialDirs()
+	slices.Reverse(directories)
+	for _, dir := range directories {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			stem, ok := TemplateName(entry.Name())
+			if !ok {
+				return nil
+			}
+
+			content, err := p.readTemplateFile(path)
+			if err != nil {
+				return err
+			}
+			_, content, err = splitFrontMatter(content)
+			if err != nil {
+				return fmt.Errorf("failed to parse partial %s: %w", path, err)
+			}
+
+			rel, err := filepath.Rel(dir, filepath.Join(filepath.Dir(path), stem))
+			if err != nil {
+				return err
+			}
+			if _, err := tmpl.New(filepath.ToSlash(rel)).Parse(string(content)); err != nil {
+				return fmt.Errorf("failed to parse partial %s: %w", path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeFunc returns the include helper for tmpl. It renders a partial, or a template tmpl
+// defines, and returns the text so it can be piped, e.g. {{include "shared/persona" | indent 2}}.
+// Without data it passes on the data tmpl is executing with, which scope holds.
+func includeFunc(tmpl *template.Template, scope *any) func(string, ...any) (string, error) {
+	depth := 0
+	return func(name string, data ...any) (string, error) {
+		included := tmpl.Lookup(name)
+		if included == nil {
+			return "", fmt.Errorf("include %q: no partial %s/%s.md", name, PartialsDir, name)
+		}
+		var dot any
+		switch len(data) {
+		case 0:
+			dot = *scope
+		case 1:
+			dot = data[0]
+		default:
+			return "", fmt.Errorf("include %q: takes at most one data argument, got %d", name, len(data))
+		}
+
+		if depth >= maxIncludeDepth {
+			return "", fmt.Errorf("include %q: nested more than %d deep; do partials include each other?", name, maxIncludeDepth)
+		}
+		depth++
+		defer func() { depth-- }()
+
+		var buf strings.Builder
+		if err := included.Execute(&buf, dot); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
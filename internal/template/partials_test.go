@@ -0,0 +1,65 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imdevan/prompter/internal/interfaces"
+)
+
+func TestPartials(t *testing.T) {
+	global, local := t.TempDir(), t.TempDir()
+	writeTemplates(t, global, map[string]string{
+		"partials/shared/persona.md": "---\ndescription: Who answers\n---\nYou are a senior engineer.",
+		"partials/ask.md":            "Task: {{.Prompt}}",
+		"partials/layout.md":         "[{{block \"body\" .}}default body{{end}}]",
+		"partials/loop.md":           `{{include "loop"}}`,
+		"pre/include.md":             `{{include "shared/persona"}} {{include "ask"}} {{include "ask" . | upper}}`,
+		"pre/template.md":            `{{template "shared/persona" .}}`,
+		"pre/block.md":               `{{define "body"}}custom body{{end}}{{template "layout" .}}`,
+		"pre/missing.md":             `{{include "nope"}}`,
+		"pre/loop.md":                `{{include "loop"}}`,
+	})
+	writeTemplates(t, local, map[string]string{"partials/ask.md": "Local task: {{.Prompt}}"})
+
+	processor := NewProcessor(global)
+	processor.SetLocalPromptsLocation(local)
+	data := interfaces.TemplateData{Prompt: "fix it"}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		// The local partial replaces the global one with the same name
+		{name: "include", want: "You are a senior engineer. Local task: fix it LOCAL TASK: FIX IT"},
+		{name: "template", want: "You are a senior engineer."},
+		{name: "block", want: "[custom body]"},
+		{name: "missing", wantErr: "no partial partials/nope.md"},
+		{name: "loop", wantErr: "nested more than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := processor.LoadTemplate(tt.name)
+			if err != nil {
+				t.Fatalf("LoadTemplate(%q) error = %v", tt.name, err)
+			}
+			got, err := processor.Execute(tmpl, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Execute() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	got, err := processor.RenderString("inline", `{{include "ask"}}`, data)
+	if err != nil || got != "Local task: fix it" {
+		t.Errorf("RenderString() = %q, %v; want the local partial", got, err)
+	}
+}
@@ -1,14 +1,17 @@
 package template
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
-	"prompter-cli/internal/interfaces"
+	"github.com/imdevan/prompter/internal/interfaces"
+	"github.com/imdevan/prompter/internal/warnings"
 )
 
 // Processor implements the TemplateProcessor interface
@@ -16,6 +19,14 @@ type Processor struct {
 	promptsLocation      string
 	localPromptsLocation string                                // Additional location for local prompts
 	customTemplates      map[string]interfaces.CustomTemplate // Custom template configurations
+	ageIdentity          string                                // Identity file for age-encrypted templates
+	frontMatter          map[*template.Template]FrontMatter    // Front matter of each loaded template
+	paths                map[*template.Template]string         // File each loaded template came from
+	scopes               map[*template.Template]*any           // Data each template is executing with, for include
+	execEnabled          bool                                  // Let templates run commands with exec (allow_template_exec)
+	execTimeout          time.Duration                         // How long an exec command may run
+	includer             FileIncluder                          // Reads files for includeFile and includeDir; nil when unset
+	warnings             *warnings.Channel                     // Where failed exec commands are reported
 }
 
 // NewProcessor creates a new template processor
@@ -24,6 +35,10 @@ func NewProcessor(promptsLocation string) *Processor {
 		promptsLocation:      promptsLocation,
 		localPromptsLocation: "", // Will be set by SetLocalPromptsLocation
 		customTemplates:      make(map[string]interfaces.CustomTemplate),
+		frontMatter:          make(map[*template.Template]FrontMatter),
+		paths:                make(map[*template.Template]string),
+		scopes:               make(map[*template.Template]*any),
+		warnings:             warnings.Default,
 	}
 }
 
@@ -32,11 +47,21 @@ func (p *Processor) SetPromptsLocation(location string) {
 	p.promptsLocation = location
 }
 
+// SetWarnings sets where warnings raised while rendering go, in place of warnings.Default
+func (p *Processor) SetWarnings(channel *warnings.Channel) {
+	p.warnings = channel
+}
+
 // SetLocalPromptsLocation updates the local prompts location
 func (p *Processor) SetLocalPromptsLocation(location string) {
 	p.localPromptsLocation = location
 }
 
+// LocalPromptsLocation returns the local prompts location in use, or "" when there is none
+func (p *Processor) LocalPromptsLocation() string {
+	return p.localPromptsLocation
+}
+
 // SetLocalPromptsFromConfig sets the local prompts location based on config
 func (p *Processor) SetLocalPromptsFromConfig(configLocation string) {
 	if configLocation != "" {
@@ -55,24 +80,24 @@ func (p *Processor) SetLocalPromptsFromConfig(configLocation string) {
 	}
 }
 
+// SetAgeIdentity sets the identity file used to decrypt age-encrypted templates
+func (p *Processor) SetAgeIdentity(identity string) {
+	p.ageIdentity = identity
+}
+
 // SetCustomTemplates sets the custom template configurations
 func (p *Processor) SetCustomTemplates(customTemplates map[string]interfaces.CustomTemplate) {
 	p.customTemplates = customTemplates
 }
 
-// GetPromptLocations returns all prompt locations (local first, then configured, then custom)
+// GetPromptLocations returns all prompt locations in precedence order (local, configured, custom, packs)
 func (p *Processor) GetPromptLocations() []string {
 	var locations []string
-	if p.localPromptsLocation != "" {
-		locations = append(locations, p.localPromptsLocation)
-	}
-	locations = append(locations, p.promptsLocation)
-	
-	// Add custom template locations
-	for _, customTemplate := range p.customTemplates {
-		locations = append(locations, customTemplate.Location)
+	for _, ns := range p.Namespaces() {
+		if !ns.Pack {
+			locations = append(locations, ns.Location)
+		}
 	}
-	
 	return locations
 }
 
@@ -83,13 +108,7 @@ func (p *Processor) GetCustomTemplates() map[string]interfaces.CustomTemplate {
 
 // LoadTemplate loads a template from the specified path or discovers it by name
 func (p *Processor) LoadTemplate(nameOrPath string) (*template.Template, error) {
-	// If it's an absolute path or contains path separators, load directly
-	if filepath.IsAbs(nameOrPath) || strings.Contains(nameOrPath, string(filepath.Separator)) {
-		return p.loadTemplateFromPath(nameOrPath)
-	}
-
-	// Otherwise, discover the template by name (case-insensitive)
-	templatePath, err := p.discoverTemplate(nameOrPath)
+	templatePath, err := p.ResolveTemplate(nameOrPath)
 	if err != nil {
 		return nil, err
 	}
@@ -97,34 +116,36 @@ func (p *Processor) LoadTemplate(nameOrPath string) (*template.Template, error)
 	return p.loadTemplateFromPath(templatePath)
 }
 
-// discoverTemplate finds a template file by name (case-insensitive matching by stem)
-func (p *Processor) discoverTemplate(name string) (string, error) {
-	// Build list of directories to check
-	// Priority: local prompts first, then configured prompts location, then custom templates
-	var directories []string
-	
-	// Add local prompts directories if available
-	if p.localPromptsLocation != "" {
-		directories = append(directories,
-			filepath.Join(p.localPromptsLocation, "pre"),
-			filepath.Join(p.localPromptsLocation, "post"),
-		)
+// ResolveTemplate returns the file LoadTemplate would read for nameOrPath, without reading it
+func (p *Processor) ResolveTemplate(nameOrPath string) (string, error) {
+	// namespace/name addresses a template in one prompt location, e.g. local/review or a pack
+	if !filepath.IsAbs(nameOrPath) {
+		templatePath, ok, err := p.findNamespacedTemplate(nameOrPath)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return templatePath, nil
+		}
 	}
-	
-	// Add configured prompts location directories
-	directories = append(directories,
-		filepath.Join(p.promptsLocation, "pre"),
-		filepath.Join(p.promptsLocation, "post"),
-	)
-	
-	// Add custom template directories
-	for _, customTemplate := range p.customTemplates {
-		directories = append(directories,
-			filepath.Join(customTemplate.Location, "pre"),
-			filepath.Join(customTemplate.Location, "post"),
-		)
+
+	// If it's an absolute path or contains path separators, use it directly
+	if filepath.IsAbs(nameOrPath) || strings.Contains(nameOrPath, string(filepath.Separator)) {
+		return nameOrPath, nil
 	}
 
+	// Otherwise, discover the template by name (case-insensitive)
+	return p.discoverTemplate(nameOrPath)
+}
+
+// discoverTemplate finds a template file by name (case-insensitive matching by stem),
+// searching the namespaces in precedence order
+func (p *Processor) discoverTemplate(name string) (string, error) {
+	return findTemplateInDirs(templateDirs(p.Namespaces()...), name)
+}
+
+// findTemplateInDirs returns the first template in directories whose name matches (case-insensitive)
+func findTemplateInDirs(directories []string, name string) (string, error) {
 	for _, dir := range directories {
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
 			continue
@@ -140,10 +161,13 @@ func (p *Processor) discoverTemplate(name string) (string, error) {
 				continue
 			}
 
-			// Get the file stem (filename without extension)
+			// Get the file stem (filename without extension); encrypted templates end in .md.age or .md.gpg
 			filename := entry.Name()
-			ext := filepath.Ext(filename)
-			stem := strings.TrimSuffix(filename, ext)
+			stem := filename
+			if encryptionTool(stem) != "" {
+				stem = strings.TrimSuffix(stem, filepath.Ext(stem))
+			}
+			stem = strings.TrimSuffix(stem, filepath.Ext(stem))
 
 			// Case-insensitive comparison - first try exact match
 			if strings.EqualFold(stem, name) {
@@ -175,9 +199,15 @@ func (p *Processor) discoverTemplate(name string) (string, error) {
 
 // loadTemplateFromPath loads a template from a specific file path
 func (p *Processor) loadTemplateFromPath(path string) (*template.Template, error) {
-	content, err := os.ReadFile(path)
+	content, err := p.readTemplateFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read template file %s: %w", path, err)
+		return nil, err
+	}
+
+	// Front matter configures the template and isn't part of its output
+	fm, content, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
 	}
 
 	// Create template with custom delimiters and helper functions
@@ -187,19 +217,53 @@ func (p *Processor) loadTemplateFromPath(path string) (*template.Template, error
 	if err := p.registerHelpersToTemplate(tmpl); err != nil {
 		return nil, fmt.Errorf("failed to register helper functions: %w", err)
 	}
+	if err := p.addPartials(tmpl); err != nil {
+		return nil, err
+	}
 
 	// Parse the template content
 	tmpl, err = tmpl.Parse(string(content))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
 	}
+	if errs := CheckFields(tmpl); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, errors.Join(errs...))
+	}
 
+	p.frontMatter[tmpl] = fm
+	p.paths[tmpl] = path
 	return tmpl, nil
 }
 
+// TemplatePath returns the file a template returned by LoadTemplate was read from
+func (p *Processor) TemplatePath(tmpl *template.Template) string {
+	return p.paths[tmpl]
+}
+
+// TemplateFrontMatter returns the front matter of a template returned by LoadTemplate
+func (p *Processor) TemplateFrontMatter(tmpl *template.Template) FrontMatter {
+	return p.frontMatter[tmpl]
+}
+
+// readTemplateFile reads a template, decrypting it first when it is stored encrypted
+func (p *Processor) readTemplateFile(path string) ([]byte, error) {
+	if encryptionTool(path) != "" {
+		return decryptTemplate(path, p.ageIdentity)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %w", path, err)
+	}
+	return content, nil
+}
+
 // Execute executes a template with the provided data
 func (p *Processor) Execute(tmpl *template.Template, data interfaces.TemplateData) (string, error) {
 	var buf strings.Builder
+	if scope := p.scopes[tmpl]; scope != nil {
+		*scope = data
+	}
 	
 	err := tmpl.Execute(&buf, data)
 	if err != nil {
@@ -209,6 +273,27 @@ func (p *Processor) Execute(tmpl *template.Template, data interfaces.TemplateDat
 	return buf.String(), nil
 }
 
+// RenderString parses and executes an inline template string, such as a config value
+func (p *Processor) RenderString(name, text string, data interfaces.TemplateData) (string, error) {
+	tmpl := template.New(name)
+	if err := p.registerHelpersToTemplate(tmpl); err != nil {
+		return "", fmt.Errorf("failed to register helper functions: %w", err)
+	}
+	if err := p.addPartials(tmpl); err != nil {
+		return "", err
+	}
+
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if errs := CheckFields(tmpl); len(errs) > 0 {
+		return "", fmt.Errorf("failed to parse %s: %w", name, errors.Join(errs...))
+	}
+
+	return p.Execute(tmpl, data)
+}
+
 // RegisterHelpers registers custom template helper functions (placeholder for now)
 func (p *Processor) RegisterHelpers() error {
 	// This method is for global registration if needed
@@ -223,11 +308,17 @@ func (p *Processor) registerHelpersToTemplate(tmpl *template.Template) error {
 	
 	// Add custom helper functions
 	customFuncs := template.FuncMap{
-		"truncate": truncateFunc,
-		"mdFence":  mdFenceFunc,
-		"indent":   indentFunc,
-		"dedent":   dedentFunc,
+		"truncate":    truncateFunc,
+		"mdFence":     mdFenceFunc,
+		"indent":      indentFunc,
+		"dedent":      dedentFunc,
+		"exec":        p.execFunc,
+		"includeFile": p.includeFileFunc,
+		"includeDir":  p.includeDirFunc,
 	}
+	scope := new(any)
+	p.scopes[tmpl] = scope
+	customFuncs["include"] = includeFunc(tmpl, scope)
 	
 	// Merge custom functions into sprig functions
 	for name, fn := range customFuncs {
@@ -7,7 +7,7 @@ import (
 	"text/template"
 	"time"
 
-	"prompter-cli/internal/interfaces"
+	"github.com/imdevan/prompter/internal/interfaces"
 )
 
 func TestProcessor_LoadTemplate(t *testing.T) {
@@ -244,6 +244,44 @@ func TestProcessor_Execute(t *testing.T) {
 	}
 }
 
+func TestProcessor_RenderString(t *testing.T) {
+	processor := NewProcessor("")
+	data := interfaces.TemplateData{
+		Project: interfaces.ProjectInfo{Name: "api", Type: "go"},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "plain text", text: "concise", expected: "concise"},
+		{name: "project type", text: "{{.Project.Type}}-style", expected: "go-style"},
+		{name: "sprig helper", text: "{{.Project.Name | upper}}", expected: "API"},
+		{name: "parse error", text: "{{.Project.Type", wantErr: true},
+		{name: "unknown field", text: "{{.Project.Missing}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processor.RenderString("default_pre", tt.text, data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestCustomHelperFunctions(t *testing.T) {
 	processor := NewProcessor("")
 	
@@ -0,0 +1,176 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReleaseURL is the GitHub API endpoint for the latest prompter release
+const DefaultReleaseURL = "https://api.github.com/repos/imdevan/prompter/releases/latest"
+
+// checkTimeout bounds the release request; the check is abandoned rather than delaying a run
+const checkTimeout = 3 * time.Second
+
+// cacheTTL is how long a check's result is reused before the release endpoint is asked again
+const cacheTTL = 24 * time.Hour
+
+// cachedCheck is the last check's result, saved so the endpoint is asked at most once a day
+type cachedCheck struct {
+	URL       string    `json:"url"`
+	Latest    string    `json:"latest"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// release is the subset of the GitHub release payload that is used
+type release struct {
+	TagName string `json:"tag_name"`
+}
+
+// Start checks for a newer release in the background. The returned channel receives a
+// one-line notice when current is outdated, or is closed without a value otherwise. When
+// cachePath is set, a result saved there in the last 24 hours is used instead of asking url.
+func Start(url, current, cachePath string) <-chan string {
+	notices := make(chan string, 1)
+
+	// Development builds have no release to compare against
+	if url == "" || !isReleaseVersion(current) {
+		close(notices)
+		return notices
+	}
+
+	go func() {
+		defer close(notices)
+
+		latest, ok := readCache(cachePath, url)
+		if !ok {
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			defer cancel()
+
+			var err error
+			if latest, err = LatestVersion(ctx, url); err != nil {
+				return // Network problems shouldn't produce noise
+			}
+			writeCache(cachePath, url, latest)
+		}
+		if CompareVersions(latest, current) > 0 {
+			notices <- fmt.Sprintf("prompter %s is available (you have %s)", latest, current)
+		}
+	}()
+
+	return notices
+}
+
+// readCache returns the latest version saved at path by a check of url in the last cacheTTL
+func readCache(path, url string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var cached cachedCheck
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != url || cached.Latest == "" {
+		return "", false
+	}
+	if age := time.Since(cached.CheckedAt); age < 0 || age > cacheTTL {
+		return "", false
+	}
+	return cached.Latest, true
+}
+
+// writeCache saves a check's result at path. Failures are ignored; the next run checks again.
+func writeCache(path, url, latest string) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cachedCheck{URL: url, Latest: latest, CheckedAt: time.Now()})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
+
+// LatestVersion fetches the latest release tag from url
+func LatestVersion(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release check failed: %s", resp.Status)
+	}
+
+	var latest release
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return "", fmt.Errorf("invalid release response: %w", err)
+	}
+	if latest.TagName == "" {
+		return "", fmt.Errorf("release response has no tag")
+	}
+
+	return latest.TagName, nil
+}
+
+// CompareVersions compares dotted versions such as "v1.2.10" and "1.3.0",
+// returning -1, 0, or 1. Pre-release and build suffixes are ignored.
+func CompareVersions(a, b string) int {
+	partsA, partsB := versionParts(a), versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts parses the numeric components of a version
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// isReleaseVersion reports whether version looks like a tagged release rather than "dev"
+func isReleaseVersion(version string) bool {
+	return len(versionParts(version)) > 0
+}
@@ -0,0 +1,103 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.0", "1.2.0", 0},
+		{"v1.2.10", "v1.2.9", 1},
+		{"1.3", "1.3.1", -1},
+		{"v2.0.0-rc1", "v1.9.9", 1},
+		{"v1.0.0+build5", "v1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		if result := CompareVersions(tt.a, tt.b); result != tt.expected {
+			t.Errorf("CompareVersions(%q, %q) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.4.0"}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		current  string
+		expected string
+	}{
+		{"outdated", "v1.3.2", "prompter v1.4.0 is available (you have v1.3.2)"},
+		{"up to date", "1.4.0", ""},
+		{"dev build", "dev", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			select {
+			case notice := <-Start(server.URL, tt.current, ""):
+				if notice != tt.expected {
+					t.Errorf("notice = %q, expected %q", notice, tt.expected)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("check did not finish")
+			}
+		})
+	}
+}
+
+func TestStartCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `{"tag_name": "v1.4.0"}`)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "update-check.json")
+	check := func() string {
+		select {
+		case notice := <-Start(server.URL, "v1.3.2", cachePath):
+			return notice
+		case <-time.After(5 * time.Second):
+			t.Fatal("check did not finish")
+			return ""
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if notice := check(); notice != "prompter v1.4.0 is available (you have v1.3.2)" {
+			t.Errorf("check %d notice = %q", i+1, notice)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("endpoint asked %d times, expected 1 with a fresh cache", n)
+	}
+
+	// A result older than a day is checked again
+	stale, _ := json.Marshal(cachedCheck{URL: server.URL, Latest: "v1.3.0", CheckedAt: time.Now().Add(-25 * time.Hour)})
+	if err := os.WriteFile(cachePath, stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if notice := check(); notice != "prompter v1.4.0 is available (you have v1.3.2)" {
+		t.Errorf("stale cache notice = %q", notice)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("endpoint asked %d times, expected 2 after the cache went stale", n)
+	}
+}
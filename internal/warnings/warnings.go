@@ -0,0 +1,83 @@
+package warnings
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// entry is one pending message and the label it is printed with
+type entry struct {
+	label   string
+	message string
+}
+
+// Channel collects warnings and notices raised during a run so they are printed
+// together, after the prompt output, instead of interleaving with interactive prompts
+type Channel struct {
+	mu      sync.Mutex
+	entries []entry
+}
+
+// Default is the channel used by the package-level helpers
+var Default = &Channel{}
+
+// Add records a warning; identical messages are only kept once
+func (c *Channel) Add(format string, args ...interface{}) {
+	c.add("Warning", fmt.Sprintf(format, args...))
+}
+
+// Notice records an informational one-line notice, such as an available update
+func (c *Channel) Notice(format string, args ...interface{}) {
+	c.add("Notice", fmt.Sprintf(format, args...))
+}
+
+// add appends a message unless it is already pending
+func (c *Channel) add(label, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, existing := range c.entries {
+		if existing.message == message {
+			return
+		}
+	}
+	c.entries = append(c.entries, entry{label: label, message: message})
+}
+
+// Messages returns the pending messages without their labels
+func (c *Channel) Messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	messages := make([]string, 0, len(c.entries))
+	for _, e := range c.entries {
+		messages = append(messages, e.message)
+	}
+	return messages
+}
+
+// Flush writes pending messages to w and clears them
+func (c *Channel) Flush(w io.Writer) {
+	c.mu.Lock()
+	entries := c.entries
+	c.entries = nil
+	c.mu.Unlock()
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s: %s\n", e.label, e.message)
+	}
+}
+
+// Add records a warning on the default channel
+func Add(format string, args ...interface{}) {
+	Default.Add(format, args...)
+}
+
+// Notice records a notice on the default channel
+func Notice(format string, args ...interface{}) {
+	Default.Notice(format, args...)
+}
+
+// Flush writes the default channel's pending messages to w
+func Flush(w io.Writer) {
+	Default.Flush(w)
+}
@@ -0,0 +1,28 @@
+package warnings
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannel(t *testing.T) {
+	c := &Channel{}
+	c.Add("config key %q is deprecated", "old_key")
+	c.Add("config key %q is deprecated", "old_key")
+	c.Notice("prompter %s is available", "v1.2.0")
+
+	if got := len(c.Messages()); got != 2 {
+		t.Fatalf("expected 2 deduplicated messages, got %d", got)
+	}
+
+	var out strings.Builder
+	c.Flush(&out)
+	expected := "Warning: config key \"old_key\" is deprecated\nNotice: prompter v1.2.0 is available\n"
+	if out.String() != expected {
+		t.Errorf("Flush() wrote %q, expected %q", out.String(), expected)
+	}
+
+	if len(c.Messages()) != 0 {
+		t.Error("expected Flush to clear messages")
+	}
+}
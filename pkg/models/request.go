@@ -1,23 +1,66 @@
 package models
 
+import "time"
+
 // PromptRequest represents the main application request with all user inputs
 type PromptRequest struct {
 	BasePrompt        string   `json:"base_prompt"`
 	PreTemplate       string   `json:"pre_template"`
 	PostTemplate      string   `json:"post_template"`
+	PreInline         string   `json:"pre_inline"`         // Pre-template text given on the command line (--pre-inline)
+	PostInline        string   `json:"post_inline"`        // Post-template text given on the command line (--post-inline)
 	Files             []string `json:"files"`
 	Directory         string   `json:"directory"`
+	URLs              []string `json:"urls"`               // Pages or raw files fetched as context (--url)
+	GitHubRefs        []string `json:"github_refs"`        // Issues or pull requests fetched as context (--github)
+	JiraKeys          []string `json:"jira_keys"`          // Jira tickets fetched as context (--jira)
+	ContextPacks      []string `json:"context_packs"`      // Bundles from .prompter-pack.toml (--pack)
+	FilesFromDiff     bool     `json:"files_from_diff"`    // Embed files changed in the working tree (--files-from-diff, --changed)
+	ChangedSince      string   `json:"changed_since"`      // Ref whose merge base the changed files are taken from; "" for HEAD (--changed ref)
+	ChangedUntracked  bool     `json:"changed_untracked"`  // Count untracked files as changed too (--changed)
+	IncludeBinary     bool     `json:"include_binary"`     // Embed binary files rather than listing them (--include-binary)
+	IncludeGenerated  bool     `json:"include_generated"`  // Embed lock files and minified and generated files rather than listing them (--include-generated)
+	RepoMap           bool     `json:"repo_map"`           // Include an outline of the repository's files and exported symbols (--map)
+	Symbols           []string `json:"symbols"`            // Go declarations to include instead of whole files, as pkg.Name (--symbol)
+	GrepPatterns      []string `json:"grep_patterns"`      // Regular expressions to search the workspace for (--grep)
+	GrepContext       int      `json:"grep_context"`       // Lines of context around each --grep match (--grep-context)
+	GrepFiles         bool     `json:"grep_files"`         // Include whole matching files rather than regions (--grep-files)
 	FixMode           bool     `json:"fix_mode"`
+	FixTemplate       string   `json:"fix_template"`       // Named fix prompt from fix/ instead of fix.md (--fix=name)
 	FixFile           string   `json:"fix_file"`
+	FixCommand        string   `json:"fix_command"`        // Command to run and capture in fix mode (--fix-cmd)
+	FixTasks          []string `json:"fix_tasks"`          // [tasks] names or name=command pairs to run and capture together (--task)
+	FixSource         string   `json:"fix_source"`         // Where fix content comes from: rerun or tmux (--fix-source)
+	FixContent        string   `json:"fix_content"`        // Captured output supplied directly, e.g. by pkg/prompter, instead of a fix source
+	NoFixFiles        bool     `json:"no_fix_files"`       // Don't attach files referenced in fix output (--no-fix-files)
+	NoRedact          bool     `json:"no_redact"`          // Keep secrets in included content for this run (--no-redact)
 	Target            string   `json:"target"`
+	AppendOutput      bool     `json:"append_output"`      // Append to a file: target instead of replacing it (--append)
 	Editor            string   `json:"editor"`
 	EditorRequested   bool     `json:"editor_requested"`   // Track if --editor flag was explicitly used
+	NoEditorWait      bool     `json:"no_editor_wait"`     // Return without waiting for a GUI editor (--no-editor-wait)
+	Preview           bool     `json:"preview"`            // Show the prompt and confirm before output (--preview)
 	Interactive       bool     `json:"interactive"`
 	ConfigPath        string   `json:"config_path"`
+	ConfigOverrides   []string `json:"config_overrides"`   // key=value config keys set for this run (--set)
 	NumberSelect      bool     `json:"number_select"`      // Enable number key selection for templates
 	FromClipboard     bool     `json:"from_clipboard"`     // Read base prompt from clipboard
+	ReadStdin         bool     `json:"read_stdin"`         // Read piped stdin along with a base prompt (a "-" argument)
 	ForceInteractive  bool     `json:"force_interactive"`  // -i flag was used
 	ForceNonInteractive bool   `json:"force_non_interactive"` // -y flag was used
+	Minimal           bool     `json:"minimal"`            // Turn off clipboard, editor, prompts, color, and network (--minimal)
+	NoMinimal         bool     `json:"no_minimal"`         // Never use minimal mode, even in CI or a container (--no-minimal)
+	CI                bool     `json:"ci"`                 // Never prompt; fail where a prompt would be needed (--ci)
+	NoUpdateCheck     bool     `json:"no_update_check"`    // Skip the update check for this run (--no-update-check)
+	Timeout           time.Duration `json:"timeout"`       // Stop the run after this long, 0 for no limit (--timeout)
+	TokenBudget       int      `json:"token_budget"`       // Maximum estimated tokens, 0 for unlimited
+	WrapWidth         int      `json:"wrap_width"`         // Reflow prose to this many columns, 0 to leave lines as they are
+	Format            string   `json:"format"`             // How the prompt is serialized: text (default) or json (--format)
+	SchemaFile        string   `json:"schema_file"`        // JSON Schema the response must match, added as an output contract (--schema)
+	Debug             bool     `json:"debug"`              // Report internal decisions, e.g. the clipboard backend, on stderr (--debug)
+	Quiet             bool     `json:"quiet"`              // Don't print the summary of what the prompt holds (--quiet)
+	UseCache          bool     `json:"use_cache"`          // Reuse the last prompt when nothing it is built from changed (--cache)
+	CommandLine       []string `json:"command_line"`       // Flags as given, base prompt redacted, for the invocation log
 }
 
 // NewPromptRequest creates a new PromptRequest with default values
@@ -0,0 +1,37 @@
+package models
+
+import "strings"
+
+// Section is one part of a generated prompt, in the order it appears
+type Section struct {
+	Type    string `json:"type"`    // Budget class: base, fix, diff, context, files, or tree
+	Source  string `json:"source"`  // What produced it, e.g. pre:review, prompt, or url:https://...
+	Content string `json:"content"`
+	Bytes   int    `json:"bytes"`
+	Tokens  int    `json:"tokens"` // Estimated tokens
+}
+
+// Result is a generated prompt as its ordered sections. Output formats serialize it; Text is
+// the plain prompt.
+type Result struct {
+	Sections []Section `json:"sections"`
+	Warnings []string  `json:"warnings,omitempty"` // Raised while assembling it, when the caller collects them
+}
+
+// Text joins the sections into the plain prompt, separated by blank lines
+func (r *Result) Text() string {
+	parts := make([]string, 0, len(r.Sections))
+	for _, section := range r.Sections {
+		parts = append(parts, section.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Tokens returns the estimated tokens of all sections
+func (r *Result) Tokens() int {
+	total := 0
+	for _, section := range r.Sections {
+		total += section.Tokens
+	}
+	return total
+}
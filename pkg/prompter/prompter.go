@@ -0,0 +1,162 @@
+// Package prompter assembles prompts the way the prompter command does, for Go programs such
+// as editor plugins and bots that embed it instead of running the CLI:
+//
+//	p := prompter.New(prompter.Options{})
+//	result, err := p.Generate(ctx, prompter.Request{Prompt: "review this", Pre: "review", Files: []string{"main.go"}})
+//	fmt.Println(result.Text())
+//
+// Generation reads the same configuration, templates, and state as the CLI, and never
+// prompts, edits, or outputs anything: the caller decides what to do with the result. Nothing
+// is written to stderr either; warnings come back in the result's Warnings.
+package prompter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/imdevan/prompter/internal/orchestrator"
+	"github.com/imdevan/prompter/internal/warnings"
+	"github.com/imdevan/prompter/pkg/models"
+)
+
+// Result is an assembled prompt as its ordered sections; Text returns the plain prompt
+type Result = models.Result
+
+// Section is one part of a Result, such as a template, the prompt, or an included file
+type Section = models.Section
+
+// Formats Format accepts
+const (
+	FormatText     = orchestrator.FormatText
+	FormatJSON     = orchestrator.FormatJSON
+	FormatMessages = orchestrator.FormatMessages
+)
+
+// Options configure a Prompter
+type Options struct {
+	ConfigPath string            // Config file to read; "" reads the default location
+	Set        map[string]string // Config keys to override, as --set key=value does
+}
+
+// Request describes the prompt to assemble. Its fields mirror the CLI flags.
+type Request struct {
+	Prompt           string   // The base prompt
+	Pre              string   // Pre-template name (--pre)
+	Post             string   // Post-template name (--post)
+	PreInline        string   // Pre-template text (--pre-inline)
+	PostInline       string   // Post-template text (--post-inline)
+	Files            []string // Files to include (--file)
+	Directory        string   // Directory to include (--directory)
+	ChangedFiles     bool     // Include files changed in the git working tree (--files-from-diff)
+	IncludeBinary    bool     // Embed binary files instead of listing them (--include-binary)
+	IncludeGenerated bool     // Embed lock files and minified and generated files instead of listing them (--include-generated)
+	URLs             []string // Pages to fetch as context (--url)
+	GitHub           []string // Issues and pull requests to fetch as context (--github)
+	Jira             []string // Jira tickets to fetch as context (--jira)
+	Packs            []string // Context packs from .prompter-pack.toml (--pack)
+	TokenBudget      int      // Maximum estimated tokens; 0 uses token_budget
+	WrapWidth        int      // Reflow prose to this many columns; 0 leaves lines alone
+	SchemaFile       string   // JSON Schema the response must match (--schema)
+	Fix              *Fix     // Assemble a fix prompt instead; Prompt is then ignored
+}
+
+// Fix describes what a fix prompt is assembled from. Set one of Output, Command, File, or
+// Tasks; with none, fix_source decides, as it does for the CLI.
+type Fix struct {
+	Template string   // Named fix prompt from fix/; "" uses fix.md
+	Output   string   // Captured output; a first line of "$ command" names the command that produced it
+	Command  string   // A command to run and capture (--fix-cmd)
+	File     string   // A saved capture (--fix-file)
+	Tasks    []string // [tasks] names or name=command pairs to run (--task)
+	NoFiles  bool     // Don't attach files the output mentions (--no-fix-files)
+}
+
+// Prompter assembles prompts. Each Generate call reads the configuration and templates afresh,
+// so edits to them apply to the next call.
+type Prompter struct {
+	opts Options
+}
+
+// New returns a Prompter that reads the configuration opts names
+func New(opts Options) *Prompter {
+	return &Prompter{opts: opts}
+}
+
+// Generate assembles the prompt req describes, with the warnings raised along the way, such
+// as a missing template or redacted secrets, in the result's Warnings. When ctx is done, the
+// commands and fetches it is waiting on are stopped and it returns ctx's error.
+func (p *Prompter) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Each call collects its own warnings, so concurrent calls don't see each other's
+	channel := &warnings.Channel{}
+	orch := orchestrator.New()
+	orch.SetDiagnostics(channel, io.Discard)
+	if err := orch.SetConfigOverrides(p.overrides()); err != nil {
+		return nil, err
+	}
+	result, err := orch.GeneratePrompt(ctx, req.promptRequest(p.opts.ConfigPath))
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = channel.Messages()
+	return result, nil
+}
+
+// Format serializes result as FormatText (the plain prompt), FormatJSON (its sections with
+// sources and token counts), or FormatMessages (a system/user messages array)
+func Format(result *Result, format string) (string, error) {
+	return orchestrator.FormatResult(result, format)
+}
+
+// overrides returns opts.Set as key=value assignments, in a stable order
+func (p *Prompter) overrides() []string {
+	assignments := make([]string, 0, len(p.opts.Set))
+	for key, value := range p.opts.Set {
+		assignments = append(assignments, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(assignments)
+	return assignments
+}
+
+// promptRequest converts req into the request the orchestrator assembles
+func (req Request) promptRequest(configPath string) *models.PromptRequest {
+	request := models.NewPromptRequest()
+	request.Interactive = false
+	request.ForceNonInteractive = true
+	request.ConfigPath = configPath
+
+	request.BasePrompt = req.Prompt
+	request.PreTemplate = req.Pre
+	request.PostTemplate = req.Post
+	request.PreInline = req.PreInline
+	request.PostInline = req.PostInline
+	request.Files = append(request.Files, req.Files...)
+	request.Directory = req.Directory
+	request.FilesFromDiff = req.ChangedFiles
+	request.IncludeBinary = req.IncludeBinary
+	request.IncludeGenerated = req.IncludeGenerated
+	request.URLs = req.URLs
+	request.GitHubRefs = req.GitHub
+	request.JiraKeys = req.Jira
+	request.ContextPacks = req.Packs
+	request.TokenBudget = req.TokenBudget
+	request.WrapWidth = req.WrapWidth
+	request.SchemaFile = req.SchemaFile
+
+	if fix := req.Fix; fix != nil {
+		request.FixMode = true
+		request.BasePrompt = ""
+		request.FixTemplate = fix.Template
+		request.FixContent = fix.Output
+		request.FixCommand = fix.Command
+		request.FixFile = fix.File
+		request.FixTasks = fix.Tasks
+		request.NoFixFiles = fix.NoFiles
+	}
+	return request
+}
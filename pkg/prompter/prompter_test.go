@@ -0,0 +1,136 @@
+package prompter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imdevan/prompter/internal/warnings"
+)
+
+// newTestPrompter returns a Prompter reading a config whose prompts location holds a review
+// pre-template and a fix prompt
+func newTestPrompter(t *testing.T, set map[string]string) *Prompter {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+	prompts := filepath.Join(dir, "prompts")
+	writeTree(t, dir, map[string]string{
+		"prompts/pre/review.md": "Review this carefully.",
+		"prompts/fix.md":        "Fix the failure below.",
+		"config.toml":           "prompts_location = \"" + prompts + "\"\nstate_file = \"" + filepath.Join(dir, "state.db") + "\"\n",
+	})
+	return New(Options{ConfigPath: filepath.Join(dir, "config.toml"), Set: set})
+}
+
+// writeTree writes files, keyed by path relative to root, creating their directories
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	p := newTestPrompter(t, nil)
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := p.Generate(context.Background(), Request{Prompt: "is this right?", Pre: "review", Files: []string{"main.go"}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var sources []string
+	for _, section := range result.Sections {
+		sources = append(sources, section.Source)
+	}
+	if got := strings.Join(sources, ","); got != "pre:review,prompt,files" {
+		t.Errorf("sections = %s, want pre:review,prompt,files", got)
+	}
+	if !strings.HasPrefix(result.Text(), "Review this carefully.\n\nis this right?") || !strings.HasSuffix(result.Text(), "main.go") {
+		t.Errorf("Text() = %q", result.Text())
+	}
+
+	formatted, err := Format(result, FormatJSON)
+	if err != nil || !strings.Contains(formatted, `"source": "pre:review"`) {
+		t.Errorf("Format(json) = %s, %v", formatted, err)
+	}
+}
+
+func TestGenerateFix(t *testing.T) {
+	p := newTestPrompter(t, nil)
+
+	result, err := p.Generate(context.Background(), Request{Fix: &Fix{Output: "$ go build\n./main.go:3:2: undefined: x", NoFiles: true}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if want := "Fix the failure below.\n\n$ go build\n./main.go:3:2: undefined: x"; result.Text() != want {
+		t.Errorf("Text() = %q, want %q", result.Text(), want)
+	}
+}
+
+func TestGenerateWarnings(t *testing.T) {
+	p := newTestPrompter(t, nil)
+
+	result, err := p.Generate(context.Background(), Request{Fix: &Fix{Template: "missing", Output: "$ go vet\nbad", NoFiles: true}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "using fix.md") {
+		t.Errorf("Warnings = %q, want the missing fix prompt reported", result.Warnings)
+	}
+	if pending := warnings.Default.Messages(); len(pending) != 0 {
+		t.Errorf("warnings.Default = %q, want the warnings kept out of the process-wide channel", pending)
+	}
+}
+
+func TestGenerateOptionsAndErrors(t *testing.T) {
+	p := newTestPrompter(t, map[string]string{"default_pre": "review"})
+	result, err := p.Generate(context.Background(), Request{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(result.Sections) != 2 || result.Sections[0].Source != "pre:review" {
+		t.Errorf("sections = %+v, want Options.Set's default_pre applied", result.Sections)
+	}
+
+	if _, err := p.Generate(context.Background(), Request{}); err == nil {
+		t.Error("expected an error for a request with no prompt")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Generate(ctx, Request{Prompt: "hi"}); err != context.Canceled {
+		t.Errorf("Generate() with a cancelled context error = %v", err)
+	}
+}
+
+func TestGenerateStopsFixCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+	p := newTestPrompter(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := p.Generate(ctx, Request{Fix: &Fix{Command: "sleep 10"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Generate() error = %v, expected the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Generate() waited %s for the command", elapsed)
+	}
+}
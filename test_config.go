@@ -6,7 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"prompter-cli/internal/config"
+	"github.com/imdevan/prompter/internal/config"
 )
 
 func main() {